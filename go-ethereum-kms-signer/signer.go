@@ -78,18 +78,7 @@ func NewAwsKmsTransactorWithChainIDCtx(
 
 		txHashBytes := signer.Hash(tx).Bytes()
 
-		rBytes, sBytes, err := getSignatureFromKms(ctx, svc, keyId, txHashBytes)
-		if err != nil {
-			return nil, err
-		}
-
-		// Adjust S value from signature according to Ethereum standard
-		sBigInt := new(big.Int).SetBytes(sBytes)
-		if sBigInt.Cmp(secp256k1HalfN) > 0 {
-			sBytes = new(big.Int).Sub(secp256k1N, sBigInt).Bytes()
-		}
-
-		signature, err := getEthereumSignature(pubKeyBytes, txHashBytes, rBytes, sBytes)
+		signature, err := signHash(ctx, svc, keyId, pubKeyBytes, txHashBytes)
 		if err != nil {
 			return nil, err
 		}
@@ -103,6 +92,27 @@ func NewAwsKmsTransactorWithChainIDCtx(
 	}, nil
 }
 
+// SignHash signs the hash with the KMS key of the given public key.
+// The signature is in the [R || S || V] format with V 0 or 1, like crypto.Sign.
+func SignHash(ctx context.Context, svc *kms.Client, keyId string, pubkey *ecdsa.PublicKey, hash []byte) ([]byte, error) {
+	return signHash(ctx, svc, keyId, secp256k1.S256().Marshal(pubkey.X, pubkey.Y), hash)
+}
+
+func signHash(ctx context.Context, svc *kms.Client, keyId string, pubKeyBytes []byte, hash []byte) ([]byte, error) {
+	rBytes, sBytes, err := getSignatureFromKms(ctx, svc, keyId, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Adjust S value from signature according to Ethereum standard
+	sBigInt := new(big.Int).SetBytes(sBytes)
+	if sBigInt.Cmp(secp256k1HalfN) > 0 {
+		sBytes = new(big.Int).Sub(secp256k1N, sBigInt).Bytes()
+	}
+
+	return getEthereumSignature(pubKeyBytes, hash, rBytes, sBytes)
+}
+
 func getPublicKeyDerBytesFromKMS(ctx context.Context, svc *kms.Client, keyId string) ([]byte, error) {
 	getPubKeyOutput, err := svc.GetPublicKey(ctx, &kms.GetPublicKeyInput{
 		KeyId: aws.String(keyId),
@@ -25,6 +25,10 @@ func (g *gossipNoop) OnUnsafeL2Payload(_ context.Context, _ peer.ID, _ *eth.Exec
 	return nil
 }
 
+func (g *gossipNoop) OnSafeHeadAttestation(_ context.Context, _ peer.ID, _ *p2p.SignedSafeHeadAttestation) error {
+	return nil
+}
+
 type gossipConfig struct{}
 
 func (g *gossipConfig) P2PSequencerAddress() common.Address {
@@ -154,7 +154,7 @@ func (e *L2Engine) RPCClient() client.RPC {
 }
 
 func (e *L2Engine) EngineClient(t Testing, cfg *rollup.Config) *sources.EngineClient {
-	l2Cl, err := sources.NewEngineClient(e.RPCClient(), e.log, nil, sources.EngineClientDefaultConfig(cfg))
+	l2Cl, err := sources.NewEngineClient(e.RPCClient(), e.log, nil, nil, sources.EngineClientDefaultConfig(cfg))
 	require.NoError(t, err)
 	return l2Cl
 }
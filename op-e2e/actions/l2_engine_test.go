@@ -36,7 +36,7 @@ func TestL2EngineAPI(gt *testing.T) {
 
 	engine := NewL2Engine(t, log, sd.L2Cfg, sd.RollupCfg.Genesis.L1, jwtPath)
 
-	l2Cl, err := sources.NewEngineClient(engine.RPCClient(), log, nil, sources.EngineClientDefaultConfig(sd.RollupCfg))
+	l2Cl, err := sources.NewEngineClient(engine.RPCClient(), log, nil, nil, sources.EngineClientDefaultConfig(sd.RollupCfg))
 	require.NoError(t, err)
 
 	// build an empty block
@@ -118,7 +118,7 @@ func TestL2EngineAPIBlockBuilding(gt *testing.T) {
 
 	buildBlock := func(includeAlice bool) {
 		parent := engine.l2Chain.CurrentBlock()
-		l2Cl, err := sources.NewEngineClient(engine.RPCClient(), log, nil, sources.EngineClientDefaultConfig(sd.RollupCfg))
+		l2Cl, err := sources.NewEngineClient(engine.RPCClient(), log, nil, nil, sources.EngineClientDefaultConfig(sd.RollupCfg))
 		require.NoError(t, err)
 
 		// Now let's ask the engine to build a block
@@ -59,7 +59,7 @@ func NewL2Verifier(t Testing, log log.Logger, l1 derive.L1Fetcher, eng L2API, cf
 	metrics := &testutils.TestDerivationMetrics{}
 	daCfg, err := rollup.NewDAConfig("http://localhost:26658", "", "0000e8e5f679bf7116cb", "", "us-west-2")
 	require.NoError(t, err)
-	pipeline := derive.NewDerivationPipeline(log, cfg, daCfg, l1, eng, metrics)
+	pipeline := derive.NewDerivationPipeline(log, cfg, daCfg, l1, eng, metrics, derive.NoopTracer)
 	pipeline.Reset()
 
 	rollupNode := &L2Verifier{
@@ -67,7 +67,7 @@ func NewL2Verifier(t Testing, log log.Logger, l1 derive.L1Fetcher, eng L2API, cf
 		eng:            eng,
 		derivation:     pipeline,
 		l1:             l1,
-		l1State:        driver.NewL1State(log, metrics),
+		l1State:        driver.NewL1State(log, metrics, l1, 0),
 		l2PipelineIdle: true,
 		l2Building:     false,
 		rollupCfg:      cfg,
@@ -88,7 +88,7 @@ func NewL2Verifier(t Testing, log log.Logger, l1 derive.L1Fetcher, eng L2API, cf
 		{
 			Namespace:     "admin",
 			Version:       "",
-			Service:       node.NewAdminAPI(backend, m),
+			Service:       node.NewAdminAPI(backend, nil, m),
 			Public:        true, // TODO: this field is deprecated. Do we even need this anymore?
 			Authenticated: false,
 		},
@@ -115,6 +115,10 @@ func (s *l2VerifierBackend) ResetDerivationPipeline(ctx context.Context) error {
 	return nil
 }
 
+func (s *l2VerifierBackend) RollbackSafeHead(ctx context.Context, number uint64, force bool) (eth.L2BlockRef, error) {
+	return eth.L2BlockRef{}, errors.New("rolling back the L2Verifier is not supported")
+}
+
 func (s *l2VerifierBackend) StartSequencer(ctx context.Context, blockHash common.Hash) error {
 	return nil
 }
@@ -127,6 +131,22 @@ func (s *l2VerifierBackend) SequencerActive(ctx context.Context) (bool, error) {
 	return false, nil
 }
 
+func (s *l2VerifierBackend) SequencerPolicy(ctx context.Context) (driver.SequencerPolicy, error) {
+	return driver.SequencerPolicy{}, errors.New("the L2Verifier has no sequencer policy")
+}
+
+func (s *l2VerifierBackend) SetSequencerPolicy(ctx context.Context, policy driver.SequencerPolicy) (driver.SequencerPolicy, error) {
+	return driver.SequencerPolicy{}, errors.New("setting the L2Verifier sequencer policy is not supported")
+}
+
+func (s *l2VerifierBackend) L1ReorgHalt(ctx context.Context) (*driver.L1Reorg, error) {
+	return nil, nil
+}
+
+func (s *l2VerifierBackend) AcknowledgeL1Reorg(ctx context.Context) (*driver.L1Reorg, error) {
+	return nil, errors.New("the L2Verifier does not halt on L1 re-orgs")
+}
+
 func (s *L2Verifier) L2Finalized() eth.L2BlockRef {
 	return s.derivation.Finalized()
 }
@@ -181,7 +201,7 @@ func (s *L2Verifier) ActRPCFail(t Testing) {
 func (s *L2Verifier) ActL1HeadSignal(t Testing) {
 	head, err := s.l1.L1BlockRefByLabel(t.Ctx(), eth.Unsafe)
 	require.NoError(t, err)
-	s.l1State.HandleNewL1HeadBlock(head)
+	s.l1State.HandleNewL1HeadBlock(t.Ctx(), head)
 }
 
 func (s *L2Verifier) ActL1SafeSignal(t Testing) {
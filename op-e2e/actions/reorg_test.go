@@ -576,7 +576,7 @@ func TestRestartOpGeth(gt *testing.T) {
 	// Sequencer
 	seqEng := NewL2Engine(t, log, sd.L2Cfg, sd.RollupCfg.Genesis.L1, jwtPath, dbOption)
 	engRpc := &rpcWrapper{seqEng.RPCClient()}
-	l2Cl, err := sources.NewEngineClient(engRpc, log, nil, sources.EngineClientDefaultConfig(sd.RollupCfg))
+	l2Cl, err := sources.NewEngineClient(engRpc, log, nil, nil, sources.EngineClientDefaultConfig(sd.RollupCfg))
 	require.NoError(t, err)
 	sequencer := NewL2Sequencer(t, log, l1F, l2Cl, sd.RollupCfg, 0)
 
@@ -664,7 +664,7 @@ func TestConflictingL2Blocks(gt *testing.T) {
 	// Extra setup: a full alternative sequencer, sequencer engine, and batcher
 	jwtPath := e2eutils.WriteDefaultJWT(t)
 	altSeqEng := NewL2Engine(t, log, sd.L2Cfg, sd.RollupCfg.Genesis.L1, jwtPath)
-	altSeqEngCl, err := sources.NewEngineClient(altSeqEng.RPCClient(), log, nil, sources.EngineClientDefaultConfig(sd.RollupCfg))
+	altSeqEngCl, err := sources.NewEngineClient(altSeqEng.RPCClient(), log, nil, nil, sources.EngineClientDefaultConfig(sd.RollupCfg))
 	require.NoError(t, err)
 	l1F, err := sources.NewL1Client(miner.RPCClient(), log, nil, sources.L1ClientDefaultConfig(sd.RollupCfg, false, sources.RPCKindBasic))
 	require.NoError(t, err)
@@ -83,6 +83,7 @@ func NewOpGeth(t *testing.T, ctx context.Context, cfg *SystemConfig) (*OpGeth, e
 		l2Node,
 		logger,
 		nil,
+		nil,
 		sources.EngineClientDefaultConfig(&rollup.Config{Genesis: rollupGenesis}),
 	)
 	require.Nil(t, err)
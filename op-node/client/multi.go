@@ -0,0 +1,267 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// healthAlpha is the weight of the latest request outcome in the health score of an endpoint.
+	healthAlpha = 0.2
+	// minHealthScore is the health score below which an endpoint is not used, unless no other endpoint is healthy.
+	minHealthScore = 0.5
+	// unhealthyProbeInterval is the time after the last failure of an unhealthy endpoint, after which it is tried again.
+	unhealthyProbeInterval = 30 * time.Second
+	// maxLabelLag is the number of blocks an endpoint may lag behind the others for a block label, before it is penalized.
+	maxLabelLag = 2
+	// crossCheckTimeout is the timeout for cross-checking a block label with the other endpoints.
+	crossCheckTimeout = 10 * time.Second
+)
+
+type MultiRPCMetrics interface {
+	RecordL1EndpointHealth(endpoint string, score float64)
+	RecordL1EndpointFailover(endpoint string)
+	RecordL1EndpointDisagreement(label string)
+}
+
+// MultiRPCEndpoint is an RPC endpoint of a MultiRPC, the name is used in logs and metrics.
+type MultiRPCEndpoint struct {
+	Name string
+	RPC  RPC
+}
+
+type multiEndpoint struct {
+	MultiRPCEndpoint
+
+	// health is the exponentially weighted average of request outcomes, 1 for success and 0 for failure.
+	health      float64
+	lastFailure time.Time
+}
+
+// MultiRPC spreads requests over multiple RPC endpoints of the same chain, and fails over to another endpoint
+// when a request fails on the transport level. JSON-RPC error responses are returned as-is, since they are valid responses.
+//
+// Each endpoint is scored by the outcome of its requests. Requests are spread round-robin over the healthy endpoints.
+// Block-label requests are cross-checked against the other endpoints, to detect lagging and disagreeing endpoints.
+type MultiRPC struct {
+	log     log.Logger
+	metrics MultiRPCMetrics
+
+	mu        sync.Mutex
+	endpoints []*multiEndpoint
+	next      int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ RPC = (*MultiRPC)(nil)
+
+func NewMultiRPC(log log.Logger, m MultiRPCMetrics, endpoints []MultiRPCEndpoint) (*MultiRPC, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("expected at least one RPC endpoint")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &MultiRPC{
+		log:     log,
+		metrics: m,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	for _, e := range endpoints {
+		out.endpoints = append(out.endpoints, &multiEndpoint{MultiRPCEndpoint: e, health: 1})
+		m.RecordL1EndpointHealth(e.Name, 1)
+	}
+	return out, nil
+}
+
+func (m *MultiRPC) Close() {
+	m.cancel()
+	m.wg.Wait()
+	for _, e := range m.endpoints {
+		e.RPC.Close()
+	}
+}
+
+// pick returns the endpoints in the order to try them: healthy endpoints first, starting with the next in line,
+// then the unhealthy endpoints, by health score.
+func (m *MultiRPC) pick() []*multiEndpoint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	var healthy, unhealthy []*multiEndpoint
+	for i := range m.endpoints {
+		e := m.endpoints[(m.next+i)%len(m.endpoints)]
+		if e.health >= minHealthScore || now.Sub(e.lastFailure) > unhealthyProbeInterval {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	m.next = (m.next + 1) % len(m.endpoints)
+	sort.SliceStable(unhealthy, func(i, j int) bool {
+		return unhealthy[i].health > unhealthy[j].health
+	})
+	return append(healthy, unhealthy...)
+}
+
+func (m *MultiRPC) record(e *multiEndpoint, success bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e.health *= 1 - healthAlpha
+	if success {
+		e.health += healthAlpha
+	} else {
+		e.lastFailure = time.Now()
+	}
+	m.metrics.RecordL1EndpointHealth(e.Name, e.health)
+}
+
+// isEndpointErr returns true if the error indicates a problem with the endpoint, rather than with the request.
+func isEndpointErr(err error) bool {
+	if err == nil || errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+// try runs the request against each endpoint in turn, until it does not fail because of the endpoint.
+func (m *MultiRPC) try(ctx context.Context, fn func(e *multiEndpoint) error) error {
+	var err error
+	for i, e := range m.pick() {
+		if i > 0 {
+			m.log.Warn("Failing over to next RPC endpoint", "endpoint", e.Name, "err", err)
+			m.metrics.RecordL1EndpointFailover(e.Name)
+		}
+		err = fn(e)
+		if ctx.Err() != nil {
+			// don't hold the caller context cancellation against the endpoint
+			return err
+		}
+		endpointErr := isEndpointErr(err)
+		m.record(e, !endpointErr)
+		if !endpointErr {
+			return err
+		}
+	}
+	return err
+}
+
+func (m *MultiRPC) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	return m.try(ctx, func(e *multiEndpoint) error {
+		err := e.RPC.CallContext(ctx, result, method, args...)
+		if err == nil && method == "eth_getBlockByNumber" && len(args) > 0 && len(m.endpoints) > 1 {
+			if label, ok := args[0].(string); ok && (label == "latest" || label == "safe" || label == "finalized") {
+				m.crossCheck(e, label, result)
+			}
+		}
+		return err
+	})
+}
+
+func (m *MultiRPC) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	return m.try(ctx, func(e *multiEndpoint) error {
+		return e.RPC.BatchCallContext(ctx, b)
+	})
+}
+
+func (m *MultiRPC) EthSubscribe(ctx context.Context, channel any, args ...any) (ethereum.Subscription, error) {
+	var sub ethereum.Subscription
+	err := m.try(ctx, func(e *multiEndpoint) error {
+		var err error
+		sub, err = e.RPC.EthSubscribe(ctx, channel, args...)
+		return err
+	})
+	return sub, err
+}
+
+// labeledBlock is the subset of a block RPC response that is cross-checked between endpoints.
+type labeledBlock struct {
+	Hash   common.Hash    `json:"hash"`
+	Number hexutil.Uint64 `json:"number"`
+}
+
+// crossCheck compares the block of the given label, as returned by the given endpoint, with the other endpoints.
+// It runs in the background, to not delay the response.
+func (m *MultiRPC) crossCheck(src *multiEndpoint, label string, result any) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	var srcBlock *labeledBlock
+	if err := json.Unmarshal(data, &srcBlock); err != nil || srcBlock == nil {
+		return
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ctx, cancel := context.WithTimeout(m.ctx, crossCheckTimeout)
+		defer cancel()
+		blocks := map[*multiEndpoint]labeledBlock{src: *srcBlock}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, e := range m.endpoints {
+			if e == src {
+				continue
+			}
+			e := e
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var block *labeledBlock
+				if err := e.RPC.CallContext(ctx, &block, "eth_getBlockByNumber", label, false); err != nil || block == nil {
+					return
+				}
+				mu.Lock()
+				blocks[e] = *block
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		m.compare(label, blocks)
+	}()
+}
+
+// compare penalizes endpoints that lag behind the others, and reports endpoints that disagree on the block hash.
+func (m *MultiRPC) compare(label string, blocks map[*multiEndpoint]labeledBlock) {
+	var highest uint64
+	for _, b := range blocks {
+		if uint64(b.Number) > highest {
+			highest = uint64(b.Number)
+		}
+	}
+	hashes := make(map[uint64]map[common.Hash][]string)
+	for e, b := range blocks {
+		if uint64(b.Number)+maxLabelLag < highest {
+			m.log.Warn("RPC endpoint is lagging behind", "endpoint", e.Name, "label", label, "number", uint64(b.Number), "highest", highest)
+			m.record(e, false)
+		}
+		if hashes[uint64(b.Number)] == nil {
+			hashes[uint64(b.Number)] = make(map[common.Hash][]string)
+		}
+		hashes[uint64(b.Number)][b.Hash] = append(hashes[uint64(b.Number)][b.Hash], e.Name)
+	}
+	for num, byHash := range hashes {
+		if len(byHash) > 1 {
+			var details []any
+			for h, names := range byHash {
+				details = append(details, h.String(), fmt.Sprintf("%v", names))
+			}
+			m.log.Error("RPC endpoints disagree on block", append([]any{"label", label, "number", num}, details...)...)
+			m.metrics.RecordL1EndpointDisagreement(label)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+// blockRPC serves the same block for every block request, or fails every request with err.
+type blockRPC struct {
+	mu    sync.Mutex
+	block labeledBlock
+	err   error
+	calls int
+}
+
+func (b *blockRPC) Close() {}
+
+func (b *blockRPC) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls++
+	if b.err != nil {
+		return b.err
+	}
+	data, err := json.Marshal(b.block)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+func (b *blockRPC) BatchCallContext(ctx context.Context, batch []rpc.BatchElem) error {
+	return errors.New("not supported")
+}
+
+func (b *blockRPC) EthSubscribe(ctx context.Context, channel any, args ...any) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func (b *blockRPC) callCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls
+}
+
+// jsonRPCError is a JSON-RPC error response, as returned by the RPC client.
+type jsonRPCError struct{}
+
+func (jsonRPCError) Error() string  { return "header not found" }
+func (jsonRPCError) ErrorCode() int { return -32000 }
+
+type testMultiRPCMetrics struct {
+	mu            sync.Mutex
+	health        map[string]float64
+	failovers     map[string]int
+	disagreements map[string]int
+}
+
+func newTestMultiRPCMetrics() *testMultiRPCMetrics {
+	return &testMultiRPCMetrics{
+		health:        make(map[string]float64),
+		failovers:     make(map[string]int),
+		disagreements: make(map[string]int),
+	}
+}
+
+func (m *testMultiRPCMetrics) RecordL1EndpointHealth(endpoint string, score float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health[endpoint] = score
+}
+
+func (m *testMultiRPCMetrics) RecordL1EndpointFailover(endpoint string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failovers[endpoint] += 1
+}
+
+func (m *testMultiRPCMetrics) RecordL1EndpointDisagreement(label string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.disagreements[label] += 1
+}
+
+func (m *testMultiRPCMetrics) get(fn func() any) any {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fn()
+}
+
+func TestMultiRPC_Failover(t *testing.T) {
+	block := labeledBlock{Hash: common.Hash{1}, Number: 10}
+	down := &blockRPC{err: errors.New("connection refused")}
+	up := &blockRPC{block: block}
+	m := newTestMultiRPCMetrics()
+	multi, err := NewMultiRPC(testlog.Logger(t, log.LvlError), m, []MultiRPCEndpoint{{Name: "down", RPC: down}, {Name: "up", RPC: up}})
+	require.NoError(t, err)
+	defer multi.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		var res labeledBlock
+		require.NoError(t, multi.CallContext(ctx, &res, "eth_getBlockByNumber", hexutil.EncodeUint64(10), false))
+		require.Equal(t, block, res)
+	}
+	require.Less(t, m.get(func() any { return m.health["down"] }).(float64), minHealthScore)
+	require.Equal(t, 1.0, m.get(func() any { return m.health["up"] }))
+	// once unhealthy, the failing endpoint is not tried anymore, until the probe interval passed
+	require.Less(t, down.callCount(), 10)
+	require.Equal(t, 10, up.callCount())
+
+	// JSON-RPC errors are valid responses, and are not retried on other endpoints
+	rpcErr := &blockRPC{err: jsonRPCError{}}
+	multi2, err := NewMultiRPC(testlog.Logger(t, log.LvlError), m, []MultiRPCEndpoint{{Name: "rpc_err", RPC: rpcErr}, {Name: "up2", RPC: up}})
+	require.NoError(t, err)
+	defer multi2.Close()
+	var res labeledBlock
+	require.Error(t, multi2.CallContext(ctx, &res, "eth_getBlockByNumber", hexutil.EncodeUint64(10), false))
+	require.Equal(t, 1.0, m.get(func() any { return m.health["rpc_err"] }))
+}
+
+func TestMultiRPC_CrossCheck(t *testing.T) {
+	a := &blockRPC{block: labeledBlock{Hash: common.Hash{1}, Number: 10}}
+	b := &blockRPC{block: labeledBlock{Hash: common.Hash{2}, Number: 10}}
+	lagging := &blockRPC{block: labeledBlock{Hash: common.Hash{3}, Number: 5}}
+	m := newTestMultiRPCMetrics()
+	multi, err := NewMultiRPC(testlog.Logger(t, log.LvlCrit), m, []MultiRPCEndpoint{{Name: "a", RPC: a}, {Name: "b", RPC: b}, {Name: "lagging", RPC: lagging}})
+	require.NoError(t, err)
+	defer multi.Close()
+
+	var res labeledBlock
+	require.NoError(t, multi.CallContext(context.Background(), &res, "eth_getBlockByNumber", "latest", false))
+	require.Eventually(t, func() bool {
+		return m.get(func() any { return m.disagreements["latest"] }).(int) == 1 &&
+			m.get(func() any { return m.health["lagging"] }).(float64) < 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1.0, m.get(func() any { return m.health["a"] }))
+	require.Equal(t, 1.0, m.get(func() any { return m.health["b"] }))
+}
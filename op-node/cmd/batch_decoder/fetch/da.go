@@ -0,0 +1,66 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-celestia/celestia"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+// inboxDataVersionCalldata is the version byte of inbox data that carries the frames in the calldata itself.
+const inboxDataVersionCalldata = 1
+
+// daSourceCalldata indicates that the frame data was read from the calldata itself.
+const daSourceCalldata = "calldata"
+
+// daTimeout is the timeout for resolving the frame data of a single FrameRef.
+const daTimeout = 30 * time.Second
+
+type daData struct {
+	version    uint8
+	height     uint64
+	commitment []byte
+	source     string
+	data       []byte
+}
+
+// inboxData resolves the frame data of the inbox transaction data.
+// The inbox data either carries the frames itself, prefixed with a version byte, or is a FrameRef to the frames on Celestia.
+// The returned daData is non-nil if the version byte is known, even if resolving the frame data fails.
+func inboxData(daCfg *rollup.DAConfig, data []byte) (*daData, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty inbox data")
+	}
+	switch data[0] {
+	case inboxDataVersionCalldata:
+		return &daData{version: data[0], source: daSourceCalldata, data: data[1:]}, nil
+	case celestia.CurrentVersion:
+		out := &daData{version: data[0]}
+		ref := celestia.FrameRef{}
+		if err := ref.UnmarshalBinary(data); err != nil {
+			return out, fmt.Errorf("invalid frame reference: %w", err)
+		}
+		out.height = ref.BlockHeight
+		out.commitment = ref.TxCommitment
+		if daCfg == nil || daCfg.Client == nil {
+			return out, errors.New("no Celestia RPC configured to resolve frame reference")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), daTimeout)
+		defer cancel()
+		frameData, source, err := derive.ResolveFrameRef(ctx, log.Root(), daCfg, ref, data)
+		if err != nil {
+			return out, fmt.Errorf("failed to resolve frame reference: %w", err)
+		}
+		out.source = source
+		out.data = frameData
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unknown inbox data version: %d", data[0])
+	}
+}
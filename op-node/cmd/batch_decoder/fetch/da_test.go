@@ -0,0 +1,103 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	openrpc "github.com/rollkit/celestia-openrpc"
+	"github.com/rollkit/celestia-openrpc/types/blob"
+	"github.com/rollkit/celestia-openrpc/types/share"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-celestia/celestia"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+func TestInboxData(t *testing.T) {
+	namespace, err := share.NewBlobNamespaceV0([]byte("batches"))
+	require.NoError(t, err)
+	frames := []byte("frame data")
+	b, err := blob.NewBlobV0(namespace, frames)
+	require.NoError(t, err)
+	commitment, err := blob.CreateCommitment(b)
+	require.NoError(t, err)
+	ref := celestia.FrameRef{BlockHeight: 42, TxCommitment: commitment}
+	refData, err := ref.MarshalBinary()
+	require.NoError(t, err)
+
+	daConfig := func(get func(context.Context, uint64, share.Namespace, blob.Commitment) (*blob.Blob, error)) *rollup.DAConfig {
+		client := &openrpc.Client{}
+		client.Blob.Get = get
+		return &rollup.DAConfig{Namespace: namespace, Client: client}
+	}
+
+	t.Run("Calldata", func(t *testing.T) {
+		da, err := inboxData(nil, append([]byte{inboxDataVersionCalldata}, frames...))
+		require.NoError(t, err)
+		require.Equal(t, &daData{version: inboxDataVersionCalldata, source: daSourceCalldata, data: frames}, da)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		da, err := inboxData(nil, nil)
+		require.ErrorContains(t, err, "empty inbox data")
+		require.Nil(t, da)
+	})
+
+	t.Run("UnknownVersion", func(t *testing.T) {
+		da, err := inboxData(nil, []byte{0x78, 0x01})
+		require.ErrorContains(t, err, "unknown inbox data version")
+		require.Nil(t, da)
+	})
+
+	t.Run("InvalidFrameRef", func(t *testing.T) {
+		da, err := inboxData(nil, []byte{celestia.CurrentVersion, 0x01})
+		require.ErrorIs(t, err, celestia.ErrInvalidSize)
+		require.Equal(t, &daData{version: celestia.CurrentVersion}, da)
+	})
+
+	t.Run("NoDAClient", func(t *testing.T) {
+		da, err := inboxData(&rollup.DAConfig{}, refData)
+		require.ErrorContains(t, err, "no Celestia RPC configured")
+		require.Equal(t, uint64(42), da.height, "the frame reference is recorded")
+		require.Equal(t, commitment, da.commitment)
+		require.Nil(t, da.data)
+	})
+
+	t.Run("Celestia", func(t *testing.T) {
+		da, err := inboxData(daConfig(func(ctx context.Context, height uint64, ns share.Namespace, com blob.Commitment) (*blob.Blob, error) {
+			require.Equal(t, uint64(42), height)
+			require.Equal(t, namespace, ns)
+			require.Equal(t, blob.Commitment(commitment), com)
+			return b, nil
+		}), refData)
+		require.NoError(t, err)
+		require.Equal(t, &daData{
+			version:    celestia.CurrentVersion,
+			height:     42,
+			commitment: commitment,
+			source:     derive.DASourceCelestia,
+			data:       frames,
+		}, da)
+	})
+
+	t.Run("CelestiaError", func(t *testing.T) {
+		da, err := inboxData(daConfig(func(context.Context, uint64, share.Namespace, blob.Commitment) (*blob.Blob, error) {
+			return nil, errors.New("unavailable")
+		}), refData)
+		require.ErrorContains(t, err, "unavailable")
+		require.Equal(t, uint64(42), da.height)
+		require.Empty(t, da.source)
+	})
+
+	t.Run("InvalidCommitment", func(t *testing.T) {
+		other, err := blob.NewBlobV0(namespace, []byte("other frame data"))
+		require.NoError(t, err)
+		da, err := inboxData(daConfig(func(context.Context, uint64, share.Namespace, blob.Commitment) (*blob.Blob, error) {
+			return other, nil
+		}), refData)
+		require.ErrorContains(t, err, "invalid celestia commitment")
+		require.Nil(t, da.data)
+	})
+}
@@ -10,8 +10,10 @@ import (
 	"path"
 	"time"
 
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
@@ -29,6 +31,14 @@ type TransactionWithMetadata struct {
 	FrameErr    string             `json:"frame_parse_error"`
 	ValidFrames bool               `json:"valid_data"`
 	Tx          *types.Transaction `json:"tx"`
+
+	// DAVersion is the version byte of the inbox data.
+	DAVersion uint8 `json:"da_version"`
+	// DAHeight and DACommitment locate the frame data on Celestia, if the inbox data is a FrameRef.
+	DAHeight     uint64        `json:"da_height,omitempty"`
+	DACommitment hexutil.Bytes `json:"da_commitment,omitempty"`
+	// DASource is where the frame data was read from: calldata, s3 or celestia.
+	DASource string `json:"da_source,omitempty"`
 }
 
 type Config struct {
@@ -37,6 +47,8 @@ type Config struct {
 	BatchInbox   common.Address
 	BatchSenders map[common.Address]struct{}
 	OutDirectory string
+	// DA is used to resolve FrameRefs, it may be nil if the inbox data carries the frames in the calldata only.
+	DA *rollup.DAConfig
 }
 
 // Batches fetches & stores all transactions sent to the batch inbox address in
@@ -81,7 +93,11 @@ func fetchBatchesPerBlock(client *ethclient.Client, number *big.Int, signer type
 
 			validFrames := true
 			frameError := ""
-			frames, err := derive.ParseFrames(tx.Data())
+			var frames []derive.Frame
+			da, err := inboxData(config.DA, tx.Data())
+			if err == nil {
+				frames, err = derive.ParseFrames(da.data)
+			}
 			if err != nil {
 				fmt.Printf("Found a transaction (%s) with invalid data: %v\n", tx.Hash().String(), err)
 				validFrames = false
@@ -108,6 +124,12 @@ func fetchBatchesPerBlock(client *ethclient.Client, number *big.Int, signer type
 				FrameErr:    frameError,
 				ValidFrames: validFrames,
 			}
+			if da != nil {
+				txm.DAVersion = da.version
+				txm.DAHeight = da.height
+				txm.DACommitment = da.commitment
+				txm.DASource = da.source
+			}
 			filename := path.Join(config.OutDirectory, fmt.Sprintf("%s.json", tx.Hash().String()))
 			file, err := os.Create(filename)
 			if err != nil {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-node/cmd/batch_decoder/fetch"
 	"github.com/ethereum-optimism/optimism/op-node/cmd/batch_decoder/reassemble"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -55,6 +56,31 @@ func main() {
 					Usage:    "L1 RPC URL",
 					EnvVars:  []string{"L1_RPC"},
 				},
+				&cli.StringFlag{
+					Name:    "da-rpc",
+					Usage:   "Celestia RPC URL, to resolve frame references with",
+					EnvVars: []string{"DA_RPC"},
+				},
+				&cli.StringFlag{
+					Name:    "auth-token",
+					Usage:   "Authentication token for the Celestia RPC",
+					EnvVars: []string{"AUTH_TOKEN"},
+				},
+				&cli.StringFlag{
+					Name:    "namespace-id",
+					Usage:   "Celestia namespace ID of the frame data",
+					EnvVars: []string{"NAMESPACE_ID"},
+				},
+				&cli.StringFlag{
+					Name:    "s3-bucket",
+					Usage:   "(Optional) S3 bucket of the frame data archive, tried before Celestia",
+					EnvVars: []string{"S3_BUCKET"},
+				},
+				&cli.StringFlag{
+					Name:    "s3-region",
+					Usage:   "(Optional) S3 region of the frame data archive",
+					EnvVars: []string{"S3_REGION"},
+				},
 			},
 			Action: func(cliCtx *cli.Context) error {
 				client, err := ethclient.Dial(cliCtx.String("l1"))
@@ -67,6 +93,16 @@ func main() {
 				if err != nil {
 					log.Fatal(err)
 				}
+				daCfg, err := rollup.NewDAConfig(
+					cliCtx.String("da-rpc"),
+					cliCtx.String("auth-token"),
+					cliCtx.String("namespace-id"),
+					cliCtx.String("s3-bucket"),
+					cliCtx.String("s3-region"),
+				)
+				if err != nil {
+					log.Fatal(err)
+				}
 				config := fetch.Config{
 					Start:   uint64(cliCtx.Int("start")),
 					End:     uint64(cliCtx.Int("end")),
@@ -76,6 +112,7 @@ func main() {
 					},
 					BatchInbox:   common.HexToAddress(cliCtx.String("inbox")),
 					OutDirectory: cliCtx.String("out"),
+					DA:           daCfg,
 				}
 				totalValid, totalInvalid := fetch.Batches(client, config)
 				fmt.Printf("Fetched batches in range [%v,%v). Found %v valid & %v invalid batches\n", config.Start, config.End, totalValid, totalInvalid)
@@ -13,6 +13,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 type ChannelWithMetadata struct {
@@ -22,14 +23,27 @@ type ChannelWithMetadata struct {
 	InvalidBatches bool                `json:"invalid_batches"`
 	Frames         []FrameWithMetadata `json:"frames"`
 	Batches        []derive.BatchV1    `json:"batches"`
+	DAProvenance   DAProvenance        `json:"da_provenance"`
 }
 
 type FrameWithMetadata struct {
-	TxHash         common.Hash  `json:"transaction_hash"`
-	InclusionBlock uint64       `json:"inclusion_block"`
-	Timestamp      uint64       `json:"timestamp"`
-	BlockHash      common.Hash  `json:"block_hash"`
-	Frame          derive.Frame `json:"frame"`
+	TxHash         common.Hash   `json:"transaction_hash"`
+	InclusionBlock uint64        `json:"inclusion_block"`
+	Timestamp      uint64        `json:"timestamp"`
+	BlockHash      common.Hash   `json:"block_hash"`
+	Frame          derive.Frame  `json:"frame"`
+	DAHeight       uint64        `json:"da_height,omitempty"`
+	DACommitment   hexutil.Bytes `json:"da_commitment,omitempty"`
+	DASource       string        `json:"da_source,omitempty"`
+}
+
+// DAProvenance summarizes where the frames of a channel were read from.
+type DAProvenance struct {
+	// Sources counts the frames per source: calldata, s3 or celestia.
+	Sources map[string]int `json:"sources"`
+	// MinHeight and MaxHeight are the range of Celestia heights the frames of the channel were posted at.
+	MinHeight uint64 `json:"min_da_height,omitempty"`
+	MaxHeight uint64 `json:"max_da_height,omitempty"`
 }
 
 type Config struct {
@@ -122,6 +136,7 @@ func processFrames(id derive.ChannelID, frames []FrameWithMetadata) ChannelWithM
 
 	return ChannelWithMetadata{
 		ID:             id,
+		DAProvenance:   daProvenance(frames),
 		Frames:         frames,
 		IsReady:        ch.IsReady(),
 		InvalidFrames:  invalidFrame,
@@ -130,6 +145,23 @@ func processFrames(id derive.ChannelID, frames []FrameWithMetadata) ChannelWithM
 	}
 }
 
+func daProvenance(frames []FrameWithMetadata) DAProvenance {
+	out := DAProvenance{Sources: make(map[string]int)}
+	for _, frame := range frames {
+		out.Sources[frame.DASource] += 1
+		if frame.DAHeight == 0 {
+			continue
+		}
+		if out.MinHeight == 0 || frame.DAHeight < out.MinHeight {
+			out.MinHeight = frame.DAHeight
+		}
+		if frame.DAHeight > out.MaxHeight {
+			out.MaxHeight = frame.DAHeight
+		}
+	}
+	return out
+}
+
 func transactionsToFrames(txns []fetch.TransactionWithMetadata) []FrameWithMetadata {
 	var out []FrameWithMetadata
 	for _, tx := range txns {
@@ -140,6 +172,9 @@ func transactionsToFrames(txns []fetch.TransactionWithMetadata) []FrameWithMetad
 				BlockHash:      tx.BlockHash,
 				Timestamp:      tx.BlockTime,
 				Frame:          frame,
+				DAHeight:       tx.DAHeight,
+				DACommitment:   tx.DACommitment,
+				DASource:       tx.DASource,
 			}
 			out = append(out, fm)
 		}
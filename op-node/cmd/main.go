@@ -16,6 +16,7 @@ import (
 	opnode "github.com/ethereum-optimism/optimism/op-node"
 	"github.com/ethereum-optimism/optimism/op-node/cmd/genesis"
 	"github.com/ethereum-optimism/optimism/op-node/cmd/p2p"
+	"github.com/ethereum-optimism/optimism/op-node/cmd/withdrawals"
 	"github.com/ethereum-optimism/optimism/op-node/flags"
 	"github.com/ethereum-optimism/optimism/op-node/heartbeat"
 	"github.com/ethereum-optimism/optimism/op-node/metrics"
@@ -72,6 +73,11 @@ func main() {
 			Name:        "doc",
 			Subcommands: doc.Subcommands,
 		},
+		{
+			Name:        "withdrawals",
+			Usage:       "Track, prove and finalize withdrawals from L2 to L1",
+			Subcommands: withdrawals.Subcommands,
+		},
 	}
 
 	err := app.Run(os.Args)
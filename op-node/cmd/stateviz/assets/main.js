@@ -120,6 +120,102 @@ async function pageTable() {
     })
 }
 
+function blockCell(ref) {
+    return `<td title="${tooltipFormat(ref)}" data-bs-html="true" data-toggle="tooltip" style="background-color:${colorCode(ref.hash)};">
+        ${prettyHex(ref.hash)}
+    </td>`
+}
+
+function originCell(ref) {
+    if (!ref.hasOwnProperty("l1origin")) {
+        return `<td></td>`
+    }
+    return `<td title="${ref["l1origin"]["hash"]}" data-toggle="tooltip" style="background-color:${colorCode(ref["l1origin"]["hash"])};">
+        ${ref["l1origin"]["number"]}
+    </td>`
+}
+
+function daCell(da) {
+    if (da === undefined || da === null) {
+        return `<td></td>`
+    }
+    var title = `<div>`
+    title += `<em>height</em>: <code>${da.height}</code><br/>`
+    title += `<em>commitment</em>: <code>${da.commitment}</code><br/>`
+    title += `<em>source</em>: <code>${da.source}</code><br/>`
+    title += `<em>L1 block</em>: <code>${da.origin.number}</code><br/>`
+    title += `</div>`
+    return `<td title="${title}" data-bs-html="true" data-toggle="tooltip">${da.height} (${da.source})</td>`
+}
+
+function liveRow(e) {
+    return $(`<tr>
+        <td>${e.t}</td>
+        <td>${e.event}</td>
+        ${blockCell(e.l1Head)}
+        ${blockCell(e.l1Current)}
+        ${blockCell(e.l2Head)}
+        ${originCell(e.l2Head)}
+        ${blockCell(e.l2Safe)}
+        ${originCell(e.l2Safe)}
+        ${blockCell(e.l2FinalizedHead)}
+        ${daCell(e.da)}
+    </tr>`)
+}
+
+// liveTable renders the rolling window of entries of a live op-node, newest first,
+// and adds the entries that the server streams as they come in.
+async function liveTable(window) {
+    const logs = await fetchLogs();
+
+    const dataEl = $(`<div id="snapshot-tables" class="row"><div class="col-12">
+        <table class="table">
+            <caption style="caption-side:top">live: <span id="live-status">connecting</span></caption>
+            <thead>
+                <tr>
+                    <th scope="col">Timestamp</th>
+                    <th scope="col">Event</th>
+                    <th scope="col">L1Head</th>
+                    <th scope="col">L1Current</th>
+                    <th scope="col">L2Head (unsafe)</th>
+                    <th scope="col">L1 origin</th>
+                    <th scope="col">L2Safe</th>
+                    <th scope="col">L1 origin</th>
+                    <th scope="col">L2FinalizedHead</th>
+                    <th scope="col">DA height</th>
+                </tr>
+            </thead>
+            <tbody id="live-rows"></tbody>
+        </table>
+    </div></div>`);
+    $("#logs").append(dataEl);
+    const rows = $("#live-rows");
+
+    const add = (e) => {
+        const row = liveRow(e);
+        rows.prepend(row);
+        row.find('[data-toggle="tooltip"]').tooltip();
+        while (rows.children().length > window) {
+            rows.children().last().remove();
+        }
+    }
+    for (const record of logs) {
+        for (const e of record) {
+            add(e);
+        }
+    }
+
+    const source = new EventSource("/live");
+    source.onopen = () => $("#live-status").text("connected");
+    source.onerror = () => $("#live-status").text("reconnecting");
+    source.onmessage = (msg) => add(JSON.parse(msg.data));
+}
+
 (async () => {
-    pageTable()
+    const mode = await (await fetch("/mode")).json();
+    if (mode.live) {
+        liveTable(mode.window)
+    } else {
+        pageTable()
+    }
 })()
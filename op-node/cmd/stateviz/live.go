@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+// liveTimeFormat is a fixed-width timestamp format, so live entries sort by their timestamp string.
+const liveTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+// liveResubscribeDelay is the time to wait before retrying a failed subscription to the op-node.
+const liveResubscribeDelay = 5 * time.Second
+
+// liveStreamDuration is how long a stream of live entries to the browser lasts, within the write timeout of the server.
+// The browser reconnects, and the entries it missed in between are replayed.
+const liveStreamDuration = 25 * time.Second
+
+// DAState is the last frame data that the op-node resolved from the DA layer.
+type DAState struct {
+	Origin     eth.BlockID `json:"origin"` // L1 block with the frame reference
+	Height     uint64      `json:"height"` // Celestia height of the frame data
+	Commitment string      `json:"commitment"`
+	Source     string      `json:"source"`
+}
+
+var (
+	liveDA      *DAState
+	liveSubs    = make(map[chan SnapshotState]struct{})
+	liveSubsMux sync.Mutex
+)
+
+// runLive follows a running op-node: it polls the sync status, and subscribes to the driver snapshots
+// and to the derivation pipeline events for DA info, if the op-node serves the debug API over websocket.
+func runLive(ctx context.Context, addr string) error {
+	client, err := rpc.DialContext(ctx, addr)
+	if err != nil {
+		return fmt.Errorf("failed to dial op-node: %w", err)
+	}
+	entriesMutex.Lock()
+	entries = make(map[string][]SnapshotState)
+	entriesMutex.Unlock()
+
+	go followLive(ctx, "driverSnapshots", func(ctx context.Context) error {
+		return subscribeDriverSnapshots(ctx, client, addr)
+	})
+	go followLive(ctx, "pipelineEvents", func(ctx context.Context) error {
+		return subscribePipelineEvents(ctx, client)
+	})
+	go pollSyncStatus(ctx, client, addr)
+	return nil
+}
+
+// followLive keeps a subscription running, and resubscribes if it fails.
+func followLive(ctx context.Context, name string, subscribe func(ctx context.Context) error) {
+	for {
+		err := subscribe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == rpc.ErrNotificationsUnsupported {
+			log.Warn("op-node RPC does not support subscriptions, use a websocket endpoint for live driver snapshots", "subscription", name)
+			return
+		}
+		log.Warn("subscription to op-node failed, resubscribing", "subscription", name, "err", err)
+		select {
+		case <-time.After(liveResubscribeDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func subscribeDriverSnapshots(ctx context.Context, client *rpc.Client, addr string) error {
+	ch := make(chan SnapshotState, 100)
+	sub, err := client.Subscribe(ctx, "debug", ch, "driverSnapshots")
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+	log.Info("subscribed to driver snapshots")
+	for {
+		select {
+		case entry := <-ch:
+			if t, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+				entry.Timestamp = t.UTC().Format(liveTimeFormat)
+			}
+			entry.EngineAddr = addr
+			addLiveEntry(entry)
+		case err := <-sub.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func subscribePipelineEvents(ctx context.Context, client *rpc.Client) error {
+	ch := make(chan derive.TraceEvent, 100)
+	sub, err := client.Subscribe(ctx, "debug", ch, "pipelineEvents")
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+	log.Info("subscribed to pipeline events")
+	for {
+		select {
+		case ev := <-ch:
+			if ev.Kind != derive.EventDataResolved || ev.DA == nil {
+				continue
+			}
+			entriesMutex.Lock()
+			liveDA = &DAState{
+				Origin:     ev.Origin,
+				Height:     ev.DA.Height,
+				Commitment: ev.DA.Commitment.String(),
+				Source:     ev.DA.Source,
+			}
+			entriesMutex.Unlock()
+		case err := <-sub.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// pollSyncStatus adds an entry for every change of the sync status of the op-node.
+func pollSyncStatus(ctx context.Context, client *rpc.Client, addr string) {
+	ticker := time.NewTicker(*poll)
+	defer ticker.Stop()
+	var last *eth.SyncStatus
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		var status *eth.SyncStatus
+		reqCtx, cancel := context.WithTimeout(ctx, *poll)
+		err := client.CallContext(reqCtx, &status, "optimism_syncStatus")
+		cancel()
+		if err != nil {
+			log.Warn("failed to fetch sync status", "err", err)
+			continue
+		}
+		if status == nil || (last != nil && *last == *status) {
+			continue
+		}
+		last = status
+		addLiveEntry(SnapshotState{
+			Timestamp:       time.Now().UTC().Format(liveTimeFormat),
+			EngineAddr:      addr,
+			Event:           "sync status",
+			L1Head:          status.HeadL1,
+			L1Current:       status.CurrentL1,
+			L2Head:          status.UnsafeL2,
+			L2Safe:          status.SafeL2,
+			L2FinalizedHead: status.FinalizedL2.ID(),
+		})
+	}
+}
+
+// addLiveEntry adds the entry to the rolling window of entries, and sends it to the live subscribers.
+func addLiveEntry(entry SnapshotState) {
+	entriesMutex.Lock()
+	if liveDA != nil {
+		da := *liveDA
+		entry.DA = &da
+	}
+	list := append(entries[entry.EngineAddr], entry)
+	if len(list) > *window {
+		list = list[len(list)-*window:]
+	}
+	entries[entry.EngineAddr] = list
+	entriesMutex.Unlock()
+
+	liveSubsMux.Lock()
+	defer liveSubsMux.Unlock()
+	for ch := range liveSubs {
+		select {
+		case ch <- entry:
+		default: // drop entries for slow browsers, the page can be reloaded
+		}
+	}
+}
+
+// liveHandler streams the new entries to the browser as server-sent events.
+// The timestamp of an entry is its event ID, to replay the missed entries to a reconnecting browser with.
+func liveHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	ch := make(chan SnapshotState, 100)
+	liveSubsMux.Lock()
+	liveSubs[ch] = struct{}{}
+	liveSubsMux.Unlock()
+	defer func() {
+		liveSubsMux.Lock()
+		delete(liveSubs, ch)
+		liveSubsMux.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	send := func(entry SnapshotState) bool {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			log.Warn("failed to encode live entry", "message", err)
+			return true
+		}
+		_, err = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", entry.Timestamp, data)
+		return err == nil
+	}
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID != "" {
+		entriesMutex.Lock()
+		var missed []SnapshotState
+		for _, list := range entries {
+			for _, entry := range list {
+				if entry.Timestamp > lastID {
+					missed = append(missed, entry)
+				}
+			}
+		}
+		entriesMutex.Unlock()
+		sort.Slice(missed, func(i, j int) bool { return missed[i].Timestamp < missed[j].Timestamp })
+		for _, entry := range missed {
+			if !send(entry) {
+				return
+			}
+		}
+	}
+	flusher.Flush()
+
+	timeout := time.NewTimer(liveStreamDuration)
+	defer timeout.Stop()
+	for {
+		select {
+		case entry := <-ch:
+			if entry.Timestamp <= lastID { // already replayed
+				continue
+			}
+			if !send(entry) {
+				return
+			}
+			flusher.Flush()
+		case <-timeout.C:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func modeHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{"live": *rpcAddr != "", "window": *window}); err != nil {
+		log.Warn("failed to encode mode", "message", err)
+	}
+}
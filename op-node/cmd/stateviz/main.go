@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"compress/gzip"
+	"context"
 	"embed"
 	"encoding/json"
 	"errors"
@@ -27,6 +28,9 @@ var (
 	snapshot   = flag.String("snapshot", "", "path to snapshot log")
 	listenAddr = flag.String("addr", "", "listen address of webserver")
 	refresh    = flag.Duration("refresh", 10*time.Second, "snapshot refresh rate")
+	rpcAddr    = flag.String("rpc", "", "RPC endpoint of a running op-node to follow live, instead of a snapshot log. Use a websocket endpoint with the debug API enabled for driver snapshots and DA info")
+	poll       = flag.Duration("poll", 2*time.Second, "sync status poll interval in live mode")
+	window     = flag.Int("window", 1000, "number of entries to keep per op-node in live mode")
 )
 
 var (
@@ -45,6 +49,7 @@ type SnapshotState struct {
 	L2Head          eth.L2BlockRef `json:"l2Head"`          // l2 block that was last optimistically accepted (unsafe head)
 	L2Safe          eth.L2BlockRef `json:"l2Safe"`          // l2 block that was last derived
 	L2FinalizedHead eth.BlockID    `json:"l2FinalizedHead"` // l2 block that is irreversible
+	DA              *DAState       `json:"da,omitempty"`    // last frame data resolved from the DA layer, live mode only
 }
 
 func (e *SnapshotState) UnmarshalJSON(data []byte) error {
@@ -98,8 +103,8 @@ func main() {
 		log.LvlFilterHandler(log.LvlDebug, log.StreamHandler(os.Stdout, log.TerminalFormat(true))),
 	)
 
-	if *snapshot == "" {
-		log.Crit("missing required -snapshot flag")
+	if *snapshot == "" && *rpcAddr == "" {
+		log.Crit("missing required -snapshot or -rpc flag")
 	}
 
 	sub, err := fs.Sub(embeddedAssets, "assets")
@@ -108,6 +113,14 @@ func main() {
 	}
 	assetFS = sub
 
+	if *rpcAddr != "" {
+		if err := runLive(context.Background(), *rpcAddr); err != nil {
+			log.Crit("Failed to follow op-node", "message", err)
+		}
+		runServer()
+		return
+	}
+
 	go func() {
 		ticker := time.NewTicker(*refresh)
 		defer ticker.Stop()
@@ -160,6 +173,8 @@ func runServer() {
 	mux := http.NewServeMux()
 	mux.Handle("/", http.FileServer(http.FS(assetFS)))
 	mux.HandleFunc("/logs", makeGzipHandler(logsHandler))
+	mux.HandleFunc("/live", liveHandler)
+	mux.HandleFunc("/mode", modeHandler)
 
 	log.Info("running webserver...")
 	httpServer := ophttp.NewHttpServer(mux)
@@ -237,7 +252,11 @@ func logsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "public, max-age=100000")
+	if *rpcAddr != "" {
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=100000")
+	}
 	if err := json.NewEncoder(w).Encode(output); err != nil {
 		log.Warn("failed to encode logs", "message", err)
 	}
@@ -0,0 +1,281 @@
+package withdrawals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	opservice "github.com/ethereum-optimism/optimism/op-service"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+	txmetrics "github.com/ethereum-optimism/optimism/op-service/txmgr/metrics"
+)
+
+const envVarPrefix = "OP_NODE_WITHDRAWALS"
+
+func prefixEnvVars(name string) []string {
+	return opservice.PrefixEnvVar(envVarPrefix, name)
+}
+
+var (
+	L1RPCFlag = &cli.StringFlag{
+		Name:     txmgr.L1RPCFlagName,
+		Usage:    "HTTP provider URL for L1",
+		Required: true,
+		EnvVars:  prefixEnvVars("L1_ETH_RPC"),
+	}
+	L2RPCFlag = &cli.StringFlag{
+		Name:     "l2-eth-rpc",
+		Usage:    "HTTP provider URL for L2, serving eth_getProof",
+		Required: true,
+		EnvVars:  prefixEnvVars("L2_ETH_RPC"),
+	}
+	PortalFlag = &cli.StringFlag{
+		Name:     "portal-address",
+		Usage:    "Address of the OptimismPortal contract on L1",
+		Required: true,
+		EnvVars:  prefixEnvVars("PORTAL_ADDRESS"),
+	}
+	TxFlag = &cli.StringFlag{
+		Name:     "tx",
+		Usage:    "Hash of the L2 transaction that initiated the withdrawal",
+		Required: true,
+	}
+	WaitFlag = &cli.BoolFlag{
+		Name:  "wait",
+		Usage: "Wait for the withdrawal to become provable or finalizable, instead of failing if it is not yet",
+	}
+	PollIntervalFlag = &cli.DurationFlag{
+		Name:    "poll-interval",
+		Usage:   "Interval to poll the withdrawal status at, when waiting",
+		Value:   12 * time.Second,
+		EnvVars: prefixEnvVars("POLL_INTERVAL"),
+	}
+)
+
+var statusFlags = []cli.Flag{L1RPCFlag, L2RPCFlag, PortalFlag, TxFlag}
+
+var sendFlags = append(append([]cli.Flag{WaitFlag, PollIntervalFlag}, statusFlags...), txmgr.CLIFlags(envVarPrefix)...)
+
+var Subcommands = cli.Commands{
+	{
+		Name:   "status",
+		Usage:  "Report the status of a withdrawal: initiated, provable, proven, finalizable or finalized",
+		Flags:  statusFlags,
+		Action: Status,
+	},
+	{
+		Name:   "prove",
+		Usage:  "Prove a withdrawal on L1, after verifying the proof locally",
+		Flags:  sendFlags,
+		Action: Prove,
+	},
+	{
+		Name:   "finalize",
+		Usage:  "Finalize a proven withdrawal on L1, once the finalization period has elapsed",
+		Flags:  sendFlags,
+		Action: Finalize,
+	},
+}
+
+type clients struct {
+	l1      *ethclient.Client
+	l2      *ethclient.Client
+	l2Proof *gethclient.Client
+	portal  common.Address
+	txHash  common.Hash
+}
+
+func dial(ctx *cli.Context) (*clients, error) {
+	portal := ctx.String(PortalFlag.Name)
+	if !common.IsHexAddress(portal) {
+		return nil, fmt.Errorf("invalid portal address: %q", portal)
+	}
+	var txHash common.Hash
+	if err := txHash.UnmarshalText([]byte(ctx.String(TxFlag.Name))); err != nil {
+		return nil, fmt.Errorf("invalid transaction hash: %w", err)
+	}
+	l1, err := ethclient.DialContext(ctx.Context, ctx.String(L1RPCFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial L1: %w", err)
+	}
+	l2RPC, err := rpc.DialContext(ctx.Context, ctx.String(L2RPCFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial L2: %w", err)
+	}
+	return &clients{
+		l1:      l1,
+		l2:      ethclient.NewClient(l2RPC),
+		l2Proof: gethclient.New(l2RPC),
+		portal:  common.HexToAddress(portal),
+		txHash:  txHash,
+	}, nil
+}
+
+func (c *clients) Close() {
+	c.l1.Close()
+	c.l2.Close()
+}
+
+func (c *clients) status(ctx context.Context) (*withdrawals.WithdrawalInfo, error) {
+	return withdrawals.GetWithdrawalStatus(ctx, c.l1, c.l2, c.portal, c.txHash)
+}
+
+// waitForStatus polls the withdrawal status until it reaches the target status, or a later one.
+// It only waits if waiting is enabled, and while the status is at least the given status to wait from:
+// e.g. there is no point in waiting for a withdrawal to become finalizable, if it is not proven.
+func (c *clients) waitForStatus(ctx *cli.Context, from, target withdrawals.WithdrawalStatus) (*withdrawals.WithdrawalInfo, error) {
+	ticker := time.NewTicker(ctx.Duration(PollIntervalFlag.Name))
+	defer ticker.Stop()
+	for {
+		info, err := c.status(ctx.Context)
+		if err != nil {
+			return nil, err
+		}
+		if info.Status >= target || info.Status < from || !ctx.Bool(WaitFlag.Name) {
+			return info, nil
+		}
+		log.Info("waiting for withdrawal", "status", info.Status, "target", target)
+		select {
+		case <-ticker.C:
+		case <-ctx.Context.Done():
+			return nil, ctx.Context.Err()
+		}
+	}
+}
+
+func newTxManager(ctx *cli.Context) (txmgr.TxManager, error) {
+	cfg := txmgr.ReadCLIConfig(ctx)
+	return txmgr.NewSimpleTxManager("withdrawals", log.Root(), &txmetrics.NoopTxMetrics{}, cfg, false)
+}
+
+func send(ctx *cli.Context, c *clients, data []byte) (*types.Receipt, error) {
+	m, err := newTxManager(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tx manager: %w", err)
+	}
+	receipt, err := m.Send(ctx.Context, txmgr.TxCandidate{TxData: data, To: &c.portal})
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, fmt.Errorf("transaction %s failed", receipt.TxHash)
+	}
+	return receipt, nil
+}
+
+func Status(ctx *cli.Context) error {
+	c, err := dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	info, err := c.status(ctx.Context)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(info)
+}
+
+func Prove(ctx *cli.Context) error {
+	c, err := dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	info, err := c.waitForStatus(ctx, withdrawals.StatusInitiated, withdrawals.StatusProvable)
+	if err != nil {
+		return err
+	}
+	switch info.Status {
+	case withdrawals.StatusInitiated:
+		return errors.New("withdrawal is not provable yet, no output proposal covers it")
+	case withdrawals.StatusProvable:
+	default:
+		log.Info("withdrawal is already proven", "status", info.Status)
+		return nil
+	}
+
+	opts := &bind.CallOpts{Context: ctx.Context}
+	l2OO, err := bindings.NewL2OutputOracleCaller(info.L2OutputOracle, c.l1)
+	if err != nil {
+		return err
+	}
+	output, err := l2OO.GetL2Output(opts, info.L2OutputIndex)
+	if err != nil {
+		return fmt.Errorf("failed to get output proposal %d: %w", info.L2OutputIndex, err)
+	}
+	header, err := c.l2.HeaderByNumber(ctx.Context, output.L2BlockNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get L2 block %d of the output proposal: %w", output.L2BlockNumber, err)
+	}
+	// This verifies the account and storage proofs against the L2 state root.
+	params, err := withdrawals.ProveWithdrawalParameters(ctx.Context, c.l2Proof, c.l2, c.txHash, header, l2OO)
+	if err != nil {
+		return fmt.Errorf("failed to get withdrawal proof: %w", err)
+	}
+	if err := withdrawals.VerifyWithdrawalProof(params, info.Hash, output.OutputRoot); err != nil {
+		return fmt.Errorf("invalid withdrawal proof: %w", err)
+	}
+	data, err := withdrawals.ProveWithdrawalTxData(params)
+	if err != nil {
+		return err
+	}
+	log.Info("proving withdrawal", "hash", info.Hash, "output_index", params.L2OutputIndex, "l2_block", header.Number)
+	receipt, err := send(ctx, c, data)
+	if err != nil {
+		return fmt.Errorf("failed to prove withdrawal: %w", err)
+	}
+	log.Info("proved withdrawal", "tx", receipt.TxHash, "l1_block", receipt.BlockNumber)
+	return nil
+}
+
+func Finalize(ctx *cli.Context) error {
+	c, err := dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	info, err := c.waitForStatus(ctx, withdrawals.StatusProven, withdrawals.StatusFinalizable)
+	if err != nil {
+		return err
+	}
+	switch info.Status {
+	case withdrawals.StatusFinalizable:
+	case withdrawals.StatusFinalized:
+		log.Info("withdrawal is already finalized")
+		return nil
+	case withdrawals.StatusProven:
+		return fmt.Errorf("withdrawal is not finalizable until after L1 time %d (%s)",
+			info.FinalizableAfter, time.Unix(int64(info.FinalizableAfter), 0).UTC())
+	default:
+		return fmt.Errorf("withdrawal is %s, it has to be proven first", info.Status)
+	}
+	data, err := withdrawals.FinalizeWithdrawalTxData(info.Withdrawal)
+	if err != nil {
+		return err
+	}
+	log.Info("finalizing withdrawal", "hash", info.Hash, "value", info.Withdrawal.Value)
+	receipt, err := send(ctx, c, data)
+	if err != nil {
+		return fmt.Errorf("failed to finalize withdrawal: %w", err)
+	}
+	log.Info("finalized withdrawal", "tx", receipt.TxHash, "l1_block", receipt.BlockNumber)
+	return nil
+}
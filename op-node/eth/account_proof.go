@@ -51,6 +51,13 @@ func (res *AccountResult) Verify(stateRoot common.Hash) error {
 		if err != nil {
 			return fmt.Errorf("failed to verify storage value %d with key %s (path %x) in storage trie %s: %w", i, entry.Key, path, res.StorageHash, err)
 		}
+		// zero values are not stored in the trie: the proof has to prove the absence of the key instead
+		if entry.Value.ToInt().Sign() == 0 {
+			if len(val) != 0 {
+				return fmt.Errorf("value %d in storage proof is zero, but a value is proven at key %s (path %x)", i, entry.Key, path)
+			}
+			continue
+		}
 		comparison, err := rlp.EncodeToBytes(entry.Value.ToInt().Bytes())
 		if err != nil {
 			return fmt.Errorf("failed to encode storage value %d with key %s (path %x) in storage trie %s: %w", i, entry.Key, path, res.StorageHash, err)
@@ -12,3 +12,19 @@ type OutputResponse struct {
 	StateRoot             common.Hash `json:"stateRoot"`
 	Status                *SyncStatus `json:"syncStatus"`
 }
+
+// OutputWithProofsResponse is the output root of an L2 block, with the preimage of the output root,
+// and the eth_getProof result of the L2ToL1MessagePasser, all at the same L2 block.
+type OutputWithProofsResponse struct {
+	Version    Bytes32    `json:"version"`
+	OutputRoot Bytes32    `json:"outputRoot"`
+	BlockRef   L2BlockRef `json:"blockRef"`
+	// StateRoot, WithdrawalStorageRoot and BlockHash are the version 0 output root preimage.
+	StateRoot             common.Hash `json:"stateRoot"`
+	WithdrawalStorageRoot common.Hash `json:"withdrawalStorageRoot"`
+	BlockHash             common.Hash `json:"blockHash"`
+	// MessagePasserProof is the account proof of the L2ToL1MessagePasser,
+	// with the storage proofs of the requested storage slots.
+	MessagePasserProof *AccountResult `json:"messagePasserProof"`
+	Status             *SyncStatus    `json:"syncStatus"`
+}
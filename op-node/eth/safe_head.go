@@ -0,0 +1,28 @@
+package eth
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// SafeHeadRecord describes how an L2 block was derived from L1, when it became safe.
+type SafeHeadRecord struct {
+	// L2Block is the safe L2 block, it includes the L1 origin (epoch) of the L2 block.
+	L2Block L2BlockRef `json:"l2Block"`
+	// DerivedFrom is the L1 block that the derivation pipeline was at when the L2 block became safe.
+	DerivedFrom BlockID `json:"derivedFrom"`
+	// L1InclusionBlock is the L1 block that included the batch of the L2 block.
+	// It is the zero block ID if the batch was not observed, e.g. when derivation was interrupted by a restart.
+	L1InclusionBlock BlockID `json:"l1InclusionBlock"`
+	// Generated is true if the batch was generated by the rollup node, because the sequencing window expired.
+	// L1InclusionBlock is the L1 block at which the batch was generated in that case.
+	Generated bool `json:"generated"`
+	// DA are the DA frame references that the batcher transactions of the L1 inclusion block referred to.
+	// It is empty if the frame data was posted to L1 directly, or if the batch was generated.
+	DA []DAFrameRef `json:"da,omitempty"`
+}
+
+// DAFrameRef is a reference to frame data on the DA layer.
+type DAFrameRef struct {
+	Height     uint64        `json:"height"`
+	Commitment hexutil.Bytes `json:"commitment"`
+}
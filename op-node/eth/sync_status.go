@@ -35,4 +35,8 @@ type SyncStatus struct {
 	// UnsafeL2SyncTarget points to the first unprocessed unsafe L2 block.
 	// It may be zeroed if there is no targeted block.
 	UnsafeL2SyncTarget L2BlockRef `json:"queued_unsafe_l2"`
+	// SafeL2Attestations is the number of distinct known peers that attested
+	// to the same SafeL2 block and output root as this node.
+	// It is always zero if safe-head attestations are not enabled.
+	SafeL2Attestations uint64 `json:"safe_l2_attestations"`
 }
@@ -5,10 +5,12 @@ import (
 	"strings"
 	"time"
 
+	kmssigner "github.com/ethereum-optimism/optimism/go-ethereum-kms-signer"
 	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
 	"github.com/ethereum-optimism/optimism/op-node/sources"
 	openum "github.com/ethereum-optimism/optimism/op-service/enum"
 	oplog "github.com/ethereum-optimism/optimism/op-service/log"
+	opsigner "github.com/ethereum-optimism/optimism/op-signer/client"
 
 	"github.com/urfave/cli/v2"
 )
@@ -25,7 +27,7 @@ var (
 	/* Required Flags */
 	L1NodeAddr = &cli.StringFlag{
 		Name:    "l1",
-		Usage:   "Address of L1 User JSON-RPC endpoint to use (eth namespace required)",
+		Usage:   "Address of L1 User JSON-RPC endpoint to use (eth namespace required). Multiple comma-separated addresses of the same chain fail over between each other.",
 		Value:   "http://127.0.0.1:8545",
 		EnvVars: prefixEnvVars("L1_ETH_RPC"),
 	}
@@ -87,6 +89,16 @@ var (
 		Usage:   "Enable the admin API (experimental)",
 		EnvVars: prefixEnvVars("RPC_ENABLE_ADMIN"),
 	}
+	RPCEnableDebug = &cli.BoolFlag{
+		Name:    "rpc.enable-debug",
+		Usage:   "Enable the debug API, to subscribe to derivation pipeline trace events over websocket",
+		EnvVars: prefixEnvVars("RPC_ENABLE_DEBUG"),
+	}
+	RPCEnableBlockFeed = &cli.BoolFlag{
+		Name:    "rpc.enable-unsafe-block-feed",
+		Usage:   "Serve the signed unsafe blocks published by the sequencer over RPC, for verifiers that cannot join the blocks gossip topic",
+		EnvVars: prefixEnvVars("RPC_ENABLE_UNSAFE_BLOCK_FEED"),
+	}
 	RPCAdminPersistence = &cli.StringFlag{
 		Name:    "rpc.admin-state",
 		Usage:   "File path used to persist state changes made via the admin API so they persist across restarts. Disabled if not set.",
@@ -119,6 +131,12 @@ var (
 		EnvVars: prefixEnvVars("L1_RPC_MAX_BATCH_SIZE"),
 		Value:   20,
 	}
+	L1PrefetchWindow = &cli.IntFlag{
+		Name:    "l1.prefetch-window",
+		Usage:   "Number of L1 blocks to prefetch the headers, transactions and receipts of, ahead of the derivation pipeline. Disabled if 0.",
+		EnvVars: prefixEnvVars("L1_PREFETCH_WINDOW"),
+		Value:   0,
+	}
 	L1HTTPPollInterval = &cli.DurationFlag{
 		Name:    "l1.http-poll-interval",
 		Usage:   "Polling interval for latest-block subscription when using an HTTP RPC provider. Ignored for other types of RPC endpoints.",
@@ -150,6 +168,17 @@ var (
 		Usage:   "Initialize the sequencer in a stopped state. The sequencer can be started using the admin_startSequencer RPC",
 		EnvVars: prefixEnvVars("SEQUENCER_STOPPED"),
 	}
+	SequencerLeaderLockFlag = &cli.StringFlag{
+		Name:    "sequencer.leader-lock",
+		Usage:   "Path of a lock file shared between op-nodes that run a warm-standby sequencer. Only the node holding the lock sequences. Disabled if not set.",
+		EnvVars: prefixEnvVars("SEQUENCER_LEADER_LOCK"),
+	}
+	SequencerLeaderRetryIntervalFlag = &cli.DurationFlag{
+		Name:    "sequencer.leader-retry-interval",
+		Usage:   "Interval between attempts of a standby sequencer to acquire the leader lock.",
+		EnvVars: prefixEnvVars("SEQUENCER_LEADER_RETRY_INTERVAL"),
+		Value:   time.Second,
+	}
 	SequencerMaxSafeLagFlag = &cli.Uint64Flag{
 		Name:     "sequencer.max-safe-lag",
 		Usage:    "Maximum number of L2 blocks for restricting the distance between L2 safe and unsafe. Disabled if 0.",
@@ -157,6 +186,13 @@ var (
 		Required: false,
 		Value:    0,
 	}
+	SequencerMaxGasPerBlockFlag = &cli.Uint64Flag{
+		Name:     "sequencer.max-gas-per-block",
+		Usage:    "Maximum L2 gas per block used by the sequencer, lower than the system config gas limit. Tx-pool transactions are excluded from the next block if a block exceeds it. Disabled if 0. Takes precedence over a persisted sequencer policy if set.",
+		EnvVars:  prefixEnvVars("SEQUENCER_MAX_GAS_PER_BLOCK"),
+		Required: false,
+		Value:    0,
+	}
 	SequencerL1Confs = &cli.Uint64Flag{
 		Name:     "sequencer.l1-confs",
 		Usage:    "Number of L1 blocks to keep distance from the L1 head as a sequencer for picking an L1 origin.",
@@ -171,6 +207,13 @@ var (
 		Required: false,
 		Value:    time.Second * 12 * 32,
 	}
+	L1ReorgHaltDepthFlag = &cli.Uint64Flag{
+		Name:     "l1.reorg-halt-depth",
+		Usage:    "Halt derivation and sequencing after an L1 reorg deeper than this number of blocks, until acknowledged with admin_acknowledgeL1Reorg. Disabled if 0.",
+		EnvVars:  prefixEnvVars("L1_REORG_HALT_DEPTH"),
+		Required: false,
+		Value:    0,
+	}
 	MetricsEnabledFlag = &cli.BoolFlag{
 		Name:    "metrics.enabled",
 		Usage:   "Enable the metrics server",
@@ -210,6 +253,36 @@ var (
 		Usage:   "Path to the snapshot log file",
 		EnvVars: prefixEnvVars("SNAPSHOT_LOG"),
 	}
+	PipelineTraceFile = &cli.StringFlag{
+		Name:    "pipeline.trace-file",
+		Usage:   "Path of the JSONL file to append derivation pipeline trace events to. Disabled if not set.",
+		EnvVars: prefixEnvVars("PIPELINE_TRACE_FILE"),
+	}
+	SafeDBPath = &cli.StringFlag{
+		Name: "safedb.path",
+		Usage: "Location of the database of the L1 derivation details of safe L2 blocks: L1 origin, batch inclusion block and DA frame references. " +
+			"Set to 'memory' to not persist the records. Disabled if not set.",
+		TakesFile: true,
+		EnvVars:   prefixEnvVars("SAFEDB_PATH"),
+	}
+	SafeDBRetention = &cli.Uint64Flag{
+		Name:    "safedb.retention",
+		Usage:   "Number of most recent safe L2 blocks to keep the derivation records of, older records are pruned. Keeps all records if 0.",
+		Value:   0,
+		EnvVars: prefixEnvVars("SAFEDB_RETENTION"),
+	}
+	UnsafeBlockFeedURL = &cli.StringFlag{
+		Name: "unsafe-block-feed.url",
+		Usage: "RPC endpoint of a sequencer to follow the signed unsafe blocks of, as an alternative to the blocks gossip topic. " +
+			"Websocket endpoints are subscribed to, HTTP endpoints are polled. Disabled if not set.",
+		EnvVars: prefixEnvVars("UNSAFE_BLOCK_FEED_URL"),
+	}
+	UnsafeBlockFeedPollInterval = &cli.DurationFlag{
+		Name:    "unsafe-block-feed.poll-interval",
+		Usage:   "Interval to poll an HTTP unsafe block feed endpoint at",
+		Value:   time.Second,
+		EnvVars: prefixEnvVars("UNSAFE_BLOCK_FEED_POLL_INTERVAL"),
+	}
 	HeartbeatEnabledFlag = &cli.BoolFlag{
 		Name:    "heartbeat.enabled",
 		Usage:   "Enables or disables heartbeating",
@@ -260,15 +333,22 @@ var optionalFlags = []cli.Flag{
 	L1RPCProviderKind,
 	L1RPCRateLimit,
 	L1RPCMaxBatchSize,
+	L1PrefetchWindow,
 	L1HTTPPollInterval,
 	L2EngineJWTSecret,
 	VerifierL1Confs,
 	SequencerEnabledFlag,
 	SequencerStoppedFlag,
+	SequencerLeaderLockFlag,
+	SequencerLeaderRetryIntervalFlag,
 	SequencerMaxSafeLagFlag,
+	SequencerMaxGasPerBlockFlag,
 	SequencerL1Confs,
 	L1EpochPollIntervalFlag,
+	L1ReorgHaltDepthFlag,
 	RPCEnableAdmin,
+	RPCEnableDebug,
+	RPCEnableBlockFeed,
 	RPCAdminPersistence,
 	MetricsEnabledFlag,
 	MetricsAddrFlag,
@@ -277,6 +357,11 @@ var optionalFlags = []cli.Flag{
 	PprofAddrFlag,
 	PprofPortFlag,
 	SnapshotLog,
+	PipelineTraceFile,
+	SafeDBPath,
+	SafeDBRetention,
+	UnsafeBlockFeedURL,
+	UnsafeBlockFeedPollInterval,
 	HeartbeatEnabledFlag,
 	HeartbeatMonikerFlag,
 	HeartbeatURLFlag,
@@ -289,6 +374,8 @@ var Flags []cli.Flag
 
 func init() {
 	optionalFlags = append(optionalFlags, p2pFlags...)
+	optionalFlags = append(optionalFlags, opsigner.CLIFlags(EnvVarPrefix)...)
+	optionalFlags = append(optionalFlags, kmssigner.CLIFlags(EnvVarPrefix)...)
 	optionalFlags = append(optionalFlags, oplog.CLIFlags(EnvVarPrefix)...)
 	Flags = append(requiredFlags, optionalFlags...)
 }
@@ -150,6 +150,15 @@ var (
 		Value:    "",
 		EnvVars:  p2pEnv("STATIC"),
 	}
+	TrustedPeers = &cli.StringFlag{
+		Name: "p2p.trusted-peers",
+		Usage: "Path to a JSON file of trusted peers, by multiaddr or ENR, with tags. " +
+			"Trusted peers are redialed when they disconnect, exempt from score-based bans, preferred for sync requests, " +
+			"and get dedicated connection slots (\"slots\" in the file) on top of the peer tides.",
+		Required:  false,
+		TakesFile: true,
+		EnvVars:   p2pEnv("TRUSTED_PEERS"),
+	}
 	HostMux = &cli.StringFlag{
 		Name:     "p2p.mux",
 		Usage:    "Comma-separated list of multiplexing protocols in order of preference. At least 1 required. Options: 'yamux','mplex'.",
@@ -250,6 +259,13 @@ var (
 		Value:    "",
 		EnvVars:  p2pEnv("SEQUENCER_KEY"),
 	}
+	SequencerP2PSignerTimeoutFlag = &cli.DurationFlag{
+		Name:     "p2p.sequencer.signer-timeout",
+		Usage:    "Timeout of a request to the remote signer (op-signer or KMS) to sign a block for gossip.",
+		Required: false,
+		Value:    p2p.DefaultRemoteSignerTimeout,
+		EnvVars:  p2pEnv("SEQUENCER_SIGNER_TIMEOUT"),
+	}
 	GossipMeshDFlag = &cli.UintFlag{
 		Name:     "p2p.gossip.mesh.d",
 		Usage:    "Configure GossipSub topic stable mesh target count, a.k.a. desired outbound degree, number of peers to gossip to",
@@ -295,6 +311,26 @@ var (
 		Required: false,
 		EnvVars:  p2pEnv("SYNC_REQ_RESP"),
 	}
+	SafeHeadAttestationsFlag = &cli.BoolFlag{
+		Name:     "p2p.attestations",
+		Usage:    "Enables the experimental safe-head attestations gossip topic: sign and publish the local safe head with the node key, and track agreement of known peers.",
+		Required: false,
+		EnvVars:  p2pEnv("ATTESTATIONS"),
+	}
+	SafeHeadAttestersFlag = &cli.StringFlag{
+		Name:     "p2p.attestations.peers",
+		Usage:    "Comma-separated list of peer IDs whose safe-head attestations are counted. If not set, the attestations of static and trusted peers are counted.",
+		Required: false,
+		Value:    "",
+		EnvVars:  p2pEnv("ATTESTATIONS_PEERS"),
+	}
+	SafeHeadAttestationsPathFlag = &cli.StringFlag{
+		Name:     "p2p.attestations.path",
+		Usage:    "File path to persist the received safe-head attestations to, so they are kept across restarts. Not persisted if not set.",
+		Required: false,
+		Value:    "",
+		EnvVars:  p2pEnv("ATTESTATIONS_PATH"),
+	}
 )
 
 // None of these flags are strictly required.
@@ -319,6 +355,7 @@ var p2pFlags = []cli.Flag{
 	AdvertiseUDPPort,
 	Bootnodes,
 	StaticPeers,
+	TrustedPeers,
 	HostMux,
 	HostSecurity,
 	PeersLo,
@@ -332,10 +369,14 @@ var p2pFlags = []cli.Flag{
 	PeerstorePath,
 	DiscoveryPath,
 	SequencerP2PKeyFlag,
+	SequencerP2PSignerTimeoutFlag,
 	GossipMeshDFlag,
 	GossipMeshDloFlag,
 	GossipMeshDhiFlag,
 	GossipMeshDlazyFlag,
 	GossipFloodPublishFlag,
 	SyncReqRespFlag,
+	SafeHeadAttestationsFlag,
+	SafeHeadAttestersFlag,
+	SafeHeadAttestationsPathFlag,
 }
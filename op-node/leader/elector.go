@@ -0,0 +1,47 @@
+// Package leader provides leader election between op-nodes that run a warm-standby sequencer.
+// Only the leader builds blocks. The leader records the last block it gossiped,
+// so a new leader can verify it is caught up before it takes over.
+package leader
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+var ErrNotLeader = errors.New("not the leader")
+
+// Elector decides which of multiple op-nodes is the sequencer leader.
+type Elector interface {
+	// Campaign blocks until leadership is acquired, or until the context is done.
+	Campaign(ctx context.Context) error
+	// IsLeader returns true if leadership is currently held.
+	// It checks the shared election state, so that a leader notices when another node may have taken over.
+	IsLeader() bool
+	// Resign gives up leadership, if it is held.
+	Resign(ctx context.Context) error
+	// RecordHead records the last block that the leader gossiped.
+	// It returns ErrNotLeader if leadership is not held.
+	RecordHead(ctx context.Context, head eth.BlockID) error
+	// LastHead returns the last block recorded by any leader, or a zeroed ID if there is none.
+	LastHead(ctx context.Context) (eth.BlockID, error)
+	// Close resigns and releases any resources.
+	Close() error
+}
+
+type ElectorSetup interface {
+	// SetupElector creates the elector, or returns nil if leader election is disabled.
+	SetupElector(ctx context.Context, log log.Logger) (Elector, error)
+}
+
+// PreparedElector wraps an already created Elector, e.g. a LocalElection member.
+type PreparedElector struct {
+	Elector
+}
+
+func (p *PreparedElector) SetupElector(ctx context.Context, log log.Logger) (Elector, error) {
+	return p.Elector, nil
+}
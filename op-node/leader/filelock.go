@@ -0,0 +1,163 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// FileLockConfig configures leader election with an exclusive lock on a file,
+// shared by all op-nodes that participate in the election.
+// The operating system releases the lock if the leader process dies.
+type FileLockConfig struct {
+	// Path of the lock file. Leader election is disabled if empty.
+	Path string
+	// RetryInterval is the delay between attempts to acquire the lock.
+	RetryInterval time.Duration
+}
+
+var _ ElectorSetup = (*FileLockConfig)(nil)
+
+func (c *FileLockConfig) SetupElector(ctx context.Context, log log.Logger) (Elector, error) {
+	if c.Path == "" {
+		return nil, nil
+	}
+	if c.RetryInterval <= 0 {
+		return nil, errors.New("leader lock retry interval must be positive")
+	}
+	return NewFileLockElector(log, c.Path, c.RetryInterval), nil
+}
+
+// FileLockElector holds leadership while it holds an exclusive lock on the lock file.
+// The last gossiped head is recorded in a separate file next to the lock file.
+type FileLockElector struct {
+	log           log.Logger
+	lock          *flock.Flock
+	headFile      string
+	retryInterval time.Duration
+
+	mu sync.Mutex
+	// locked is the lock file that the lock was acquired on,
+	// to detect when the lock file is removed or replaced while the lock is held.
+	locked os.FileInfo
+}
+
+var _ Elector = (*FileLockElector)(nil)
+
+func NewFileLockElector(log log.Logger, path string, retryInterval time.Duration) *FileLockElector {
+	return &FileLockElector{
+		log:           log,
+		lock:          flock.New(path),
+		headFile:      path + ".head",
+		retryInterval: retryInterval,
+	}
+}
+
+func (e *FileLockElector) Campaign(ctx context.Context) error {
+	ok, err := e.lock.TryLockContext(ctx, e.retryInterval)
+	if err != nil {
+		return fmt.Errorf("failed to acquire leader lock %s: %w", e.lock.Path(), err)
+	}
+	if !ok {
+		return fmt.Errorf("failed to acquire leader lock %s", e.lock.Path())
+	}
+	info, err := os.Stat(e.lock.Path())
+	if err != nil {
+		_ = e.lock.Unlock()
+		return fmt.Errorf("failed to stat leader lock %s: %w", e.lock.Path(), err)
+	}
+	e.mu.Lock()
+	e.locked = info
+	e.mu.Unlock()
+	e.log.Info("Acquired leader lock", "path", e.lock.Path())
+	return nil
+}
+
+// IsLeader returns true if the lock is held, and the lock file is still the file that the lock was acquired on.
+// If the lock file was removed or replaced, other nodes can acquire a lock on the new file,
+// and leadership is not held anymore.
+func (e *FileLockElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.isLeader()
+}
+
+func (e *FileLockElector) isLeader() bool {
+	if !e.lock.Locked() || e.locked == nil {
+		return false
+	}
+	info, err := os.Stat(e.lock.Path())
+	if err != nil {
+		e.log.Warn("Failed to stat leader lock, assuming leadership is lost", "path", e.lock.Path(), "err", err)
+		return false
+	}
+	if !os.SameFile(info, e.locked) {
+		e.log.Warn("Leader lock file was replaced, leadership is lost", "path", e.lock.Path())
+		return false
+	}
+	return true
+}
+
+func (e *FileLockElector) Resign(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.locked = nil
+	if !e.lock.Locked() {
+		return nil
+	}
+	if err := e.lock.Unlock(); err != nil {
+		return fmt.Errorf("failed to release leader lock %s: %w", e.lock.Path(), err)
+	}
+	e.log.Info("Released leader lock", "path", e.lock.Path())
+	return nil
+}
+
+// RecordHead writes the head to a temp file first, then renames it into place,
+// so that a standby node never reads a partially written head.
+func (e *FileLockElector) RecordHead(ctx context.Context, head eth.BlockID) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.isLeader() {
+		return ErrNotLeader
+	}
+	data, err := json.Marshal(head)
+	if err != nil {
+		return fmt.Errorf("failed to encode head: %w", err)
+	}
+	tmpFile := e.headFile + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write head to temp file (%v): %w", tmpFile, err)
+	}
+	if err := os.Rename(tmpFile, e.headFile); err != nil {
+		return fmt.Errorf("failed to move head temp file to final destination: %w", err)
+	}
+	return nil
+}
+
+func (e *FileLockElector) LastHead(ctx context.Context) (eth.BlockID, error) {
+	data, err := os.ReadFile(e.headFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return eth.BlockID{}, nil
+	} else if err != nil {
+		return eth.BlockID{}, fmt.Errorf("failed to read head file (%v): %w", e.headFile, err)
+	}
+	var head eth.BlockID
+	if err := json.Unmarshal(data, &head); err != nil {
+		return eth.BlockID{}, fmt.Errorf("failed to decode head file (%v): %w", e.headFile, err)
+	}
+	return head, nil
+}
+
+func (e *FileLockElector) Close() error {
+	return e.lock.Close()
+}
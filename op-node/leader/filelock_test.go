@@ -0,0 +1,115 @@
+package leader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func TestFileLockElector(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	logger := testlog.Logger(t, log.LvlInfo)
+	a := NewFileLockElector(logger, path, 10*time.Millisecond)
+	b := NewFileLockElector(logger, path, 10*time.Millisecond)
+	defer a.Close()
+	defer b.Close()
+
+	ctx := context.Background()
+	head, err := a.LastHead(ctx)
+	require.NoError(t, err)
+	require.Equal(t, eth.BlockID{}, head, "no head recorded yet")
+
+	require.NoError(t, a.Campaign(ctx))
+	require.True(t, a.IsLeader())
+
+	shortCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	require.Error(t, b.Campaign(shortCtx), "lock is held by a")
+	require.False(t, b.IsLeader())
+
+	id := eth.BlockID{Hash: common.Hash{0xaa}, Number: 123}
+	require.ErrorIs(t, b.RecordHead(ctx, id), ErrNotLeader)
+	require.NoError(t, a.RecordHead(ctx, id))
+	head, err = b.LastHead(ctx)
+	require.NoError(t, err)
+	require.Equal(t, id, head)
+
+	require.NoError(t, a.Resign(ctx))
+	require.False(t, a.IsLeader())
+	require.NoError(t, b.Campaign(ctx))
+	require.True(t, b.IsLeader())
+	head, err = b.LastHead(ctx)
+	require.NoError(t, err)
+	require.Equal(t, id, head, "head survives handover")
+}
+
+func TestFileLockElectorReplacedLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	logger := testlog.Logger(t, log.LvlInfo)
+	a := NewFileLockElector(logger, path, 10*time.Millisecond)
+	b := NewFileLockElector(logger, path, 10*time.Millisecond)
+	defer a.Close()
+	defer b.Close()
+
+	ctx := context.Background()
+	require.NoError(t, a.Campaign(ctx))
+	require.True(t, a.IsLeader())
+
+	// Once the lock file is replaced, b can lock the new file, and a must not consider itself leader anymore.
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, b.Campaign(ctx))
+	require.True(t, b.IsLeader())
+	require.False(t, a.IsLeader())
+	require.ErrorIs(t, a.RecordHead(ctx, eth.BlockID{Number: 1}), ErrNotLeader)
+
+	// a campaigns on the new lock file after resigning
+	require.NoError(t, a.Resign(ctx))
+	shortCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	require.Error(t, a.Campaign(shortCtx), "lock is held by b")
+}
+
+func TestLocalElection(t *testing.T) {
+	ctx := context.Background()
+	election := NewLocalElection()
+	a := election.Member("a")
+	b := election.Member("b")
+
+	require.Equal(t, "", election.Leader())
+	require.NoError(t, a.Campaign(ctx))
+	require.Equal(t, "a", election.Leader())
+
+	done := make(chan error)
+	go func() {
+		done <- b.Campaign(ctx)
+	}()
+	select {
+	case <-done:
+		t.Fatal("b must not be elected while a leads")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	id0 := eth.BlockID{Hash: common.Hash{0x01}, Number: 1}
+	require.NoError(t, a.RecordHead(ctx, id0))
+	require.ErrorIs(t, b.RecordHead(ctx, id0), ErrNotLeader)
+
+	require.NoError(t, a.Resign(ctx))
+	require.NoError(t, <-done)
+	require.True(t, b.IsLeader())
+	require.False(t, a.IsLeader())
+	require.Equal(t, "b", election.Leader())
+
+	head, err := b.LastHead(ctx)
+	require.NoError(t, err)
+	require.Equal(t, id0, head)
+}
@@ -0,0 +1,105 @@
+package leader
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// LocalElection runs an election between members in the same process:
+// a recorded head is only accepted from the current leader.
+// It is intended for tests and single-host setups, where all op-nodes share the process.
+type LocalElection struct {
+	mu     sync.Mutex
+	leader *LocalMember
+	head   eth.BlockID
+	// released is closed when the current leader resigns, to wake up campaigning members.
+	released chan struct{}
+}
+
+func NewLocalElection() *LocalElection {
+	return &LocalElection{released: make(chan struct{})}
+}
+
+// Member creates a new participant in the election.
+func (l *LocalElection) Member(id string) *LocalMember {
+	return &LocalMember{id: id, election: l}
+}
+
+// Leader returns the ID of the current leader, or an empty string if there is none.
+func (l *LocalElection) Leader() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.leader == nil {
+		return ""
+	}
+	return l.leader.id
+}
+
+type LocalMember struct {
+	id       string
+	election *LocalElection
+}
+
+var _ Elector = (*LocalMember)(nil)
+
+func (m *LocalMember) Campaign(ctx context.Context) error {
+	l := m.election
+	for {
+		l.mu.Lock()
+		if l.leader == nil || l.leader == m {
+			l.leader = m
+			l.mu.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mu.Unlock()
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *LocalMember) IsLeader() bool {
+	m.election.mu.Lock()
+	defer m.election.mu.Unlock()
+	return m.election.leader == m
+}
+
+func (m *LocalMember) Resign(ctx context.Context) error {
+	l := m.election
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.leader != m {
+		return nil
+	}
+	l.leader = nil
+	close(l.released)
+	l.released = make(chan struct{})
+	return nil
+}
+
+func (m *LocalMember) RecordHead(ctx context.Context, head eth.BlockID) error {
+	l := m.election
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.leader != m {
+		return ErrNotLeader
+	}
+	l.head = head
+	return nil
+}
+
+func (m *LocalMember) LastHead(ctx context.Context) (eth.BlockID, error) {
+	l := m.election
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.head, nil
+}
+
+func (m *LocalMember) Close() error {
+	return m.Resign(context.Background())
+}
@@ -42,6 +42,7 @@ type Metricer interface {
 	RecordRPCServerRequest(method string) func()
 	RecordRPCClientRequest(method string) func(err error)
 	RecordRPCClientResponse(method string, err error)
+	RecordEngineRequest(method string) func(outcome string)
 	SetDerivationIdle(status bool)
 	RecordPipelineReset()
 	RecordSequencingError()
@@ -54,8 +55,15 @@ type Metricer interface {
 	RecordUnsafePayloadsBuffer(length uint64, memSize uint64, next eth.BlockID)
 	CountSequencedTxs(count int)
 	RecordL1ReorgDepth(d uint64)
+	RecordL1Reorg(oldHead eth.L1BlockRef, newHead eth.L1BlockRef)
+	RecordL1ReorgHalt(halted bool)
+	RecordL1EndpointHealth(endpoint string, score float64)
+	RecordL1EndpointFailover(endpoint string)
+	RecordL1EndpointDisagreement(label string)
 	RecordSequencerInconsistentL1Origin(from eth.BlockID, to eth.BlockID)
 	RecordSequencerReset()
+	RecordSequencerPolicy(depositOnly bool, txPoolPaused bool, maxGasPerBlock uint64)
+	RecordSequencerNoTxPool(reason string)
 	RecordGossipEvent(evType int32)
 	IncPeerCount()
 	DecPeerCount()
@@ -70,7 +78,11 @@ type Metricer interface {
 	SetPeerScores(allScores []store.PeerScores)
 	ClientPayloadByNumberEvent(num uint64, resultCode byte, duration time.Duration)
 	ServerPayloadByNumberEvent(num uint64, resultCode byte, duration time.Duration)
+	ClientPayloadsByRangeEvent(start uint64, count uint64, resultCode byte, duration time.Duration)
+	ServerPayloadsByRangeEvent(start uint64, count uint64, resultCode byte, duration time.Duration)
 	PayloadsQuarantineSize(n int)
+	RecordSafeHeadAttestation()
+	RecordSafeHeadAgreement(agree uint64, disagree uint64)
 	RecordPeerUnban()
 	RecordIPUnban()
 	RecordDial(allow bool)
@@ -88,6 +100,8 @@ type Metrics struct {
 	RPCClientRequestDurationSeconds *prometheus.HistogramVec
 	RPCClientResponsesTotal         *prometheus.CounterVec
 
+	EngineRequestDurationSeconds *prometheus.HistogramVec
+
 	L1SourceCache *CacheMetrics
 	L2SourceCache *CacheMetrics
 
@@ -105,9 +119,15 @@ type Metrics struct {
 
 	PayloadsQuarantineTotal prometheus.Gauge
 
+	SafeHeadAttestationsTotal prometheus.Counter
+	SafeHeadAgreement         *prometheus.GaugeVec
+
 	SequencerInconsistentL1Origin *EventMetrics
 	SequencerResets               *EventMetrics
 
+	SequencerPolicy        *prometheus.GaugeVec
+	SequencerNoTxPoolTotal *prometheus.CounterVec
+
 	L1RequestDurationSeconds *prometheus.HistogramVec
 
 	SequencerBuildingDiffDurationSeconds prometheus.Histogram
@@ -129,6 +149,12 @@ type Metrics struct {
 	LatencySeen map[string]common.Hash
 
 	L1ReorgDepth prometheus.Histogram
+	L1Reorgs     *EventMetrics
+	L1ReorgHalt  prometheus.Gauge
+
+	L1EndpointHealth        *prometheus.GaugeVec
+	L1EndpointFailovers     *prometheus.CounterVec
+	L1EndpointDisagreements *prometheus.CounterVec
 
 	TransactionsSequencedTotal prometheus.Counter
 
@@ -221,6 +247,17 @@ func NewMetrics(procName string) *Metrics {
 			"error",
 		}),
 
+		EngineRequestDurationSeconds: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Subsystem: "engine",
+			Name:      "request_duration_seconds",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+			Help:      "Histogram of engine API request durations, by method and outcome: the payload status, or the error kind",
+		}, []string{
+			"method",
+			"outcome",
+		}),
+
 		L1SourceCache: NewCacheMetrics(factory, ns, "l1_source_cache", "L1 Source cache"),
 		L2SourceCache: NewCacheMetrics(factory, ns, "l2_source_cache", "L2 Source cache"),
 
@@ -297,6 +334,34 @@ func NewMetrics(procName string) *Metrics {
 			Buckets:   []float64{0.5, 1.5, 2.5, 3.5, 4.5, 5.5, 6.5, 7.5, 8.5, 9.5, 10.5, 20.5, 50.5, 100.5},
 			Help:      "Histogram of L1 Reorg Depths",
 		}),
+		L1Reorgs: NewEventMetrics(factory, ns, "l1_reorgs", "L1 reorgs"),
+		L1ReorgHalt: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "l1_reorg_halt",
+			Help:      "1 if derivation and sequencing are halted after a deep L1 reorg, until acknowledged by an operator, 0 otherwise",
+		}),
+
+		L1EndpointHealth: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "l1_endpoint_health",
+			Help:      "Health score of each L1 RPC endpoint, between 0 and 1",
+		}, []string{
+			"endpoint",
+		}),
+		L1EndpointFailovers: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "l1_endpoint_failovers_total",
+			Help:      "Count of L1 RPC requests that failed over to the labeled endpoint",
+		}, []string{
+			"endpoint",
+		}),
+		L1EndpointDisagreements: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "l1_endpoint_disagreements_total",
+			Help:      "Count of L1 RPC endpoints disagreeing on the block hash of a block label",
+		}, []string{
+			"label",
+		}),
 
 		TransactionsSequencedTotal: factory.NewGauge(prometheus.GaugeOpts{
 			Namespace: ns,
@@ -406,6 +471,20 @@ func NewMetrics(procName string) *Metrics {
 			Name:      "payloads_quarantine_total",
 			Help:      "number of unverified execution payloads buffered in quarantine",
 		}),
+		SafeHeadAttestationsTotal: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: "p2p",
+			Name:      "safe_head_attestations_total",
+			Help:      "number of safe head attestations received from known peers",
+		}),
+		SafeHeadAgreement: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: "p2p",
+			Name:      "safe_head_agreement",
+			Help:      "number of known peers that agree or disagree with the attested local safe head",
+		}, []string{
+			"agreement", // "agree" or "disagree"
+		}),
 
 		L1RequestDurationSeconds: factory.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: ns,
@@ -439,6 +518,20 @@ func NewMetrics(procName string) *Metrics {
 			Name:      "sequencer_sealing_total",
 			Help:      "Number of sequencer block sealing jobs",
 		}),
+		SequencerPolicy: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "sequencer_policy",
+			Help:      "Sequencer operator policy: deposit_only and tx_pool_paused are 1 if enabled, max_gas_per_block is 0 if disabled",
+		}, []string{
+			"policy",
+		}),
+		SequencerNoTxPoolTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "sequencer_no_tx_pool_total",
+			Help:      "Number of sequenced blocks that excluded tx-pool transactions, by reason",
+		}, []string{
+			"reason",
+		}),
 
 		registry: registry,
 		factory:  factory,
@@ -522,6 +615,15 @@ func (m *Metrics) RecordRPCClientResponse(method string, err error) {
 	m.RPCClientResponsesTotal.WithLabelValues(method, errStr).Inc()
 }
 
+// RecordEngineRequest is a helper method to record an engine API request.
+// It tracks the request duration, labeled with the outcome of the request.
+func (m *Metrics) RecordEngineRequest(method string) func(outcome string) {
+	start := time.Now()
+	return func(outcome string) {
+		m.EngineRequestDurationSeconds.WithLabelValues(method, outcome).Observe(time.Since(start).Seconds())
+	}
+}
+
 func (m *Metrics) SetDerivationIdle(status bool) {
 	var val float64
 	if status {
@@ -590,6 +692,32 @@ func (m *Metrics) RecordL1ReorgDepth(d uint64) {
 	m.L1ReorgDepth.Observe(float64(d))
 }
 
+func (m *Metrics) RecordL1Reorg(oldHead eth.L1BlockRef, newHead eth.L1BlockRef) {
+	m.L1Reorgs.RecordEvent()
+	m.recordRef("l1_reorg", "old_head", oldHead.Number, 0, oldHead.Hash)
+	m.recordRef("l1_reorg", "new_head", newHead.Number, 0, newHead.Hash)
+}
+
+func (m *Metrics) RecordL1ReorgHalt(halted bool) {
+	var val float64
+	if halted {
+		val = 1
+	}
+	m.L1ReorgHalt.Set(val)
+}
+
+func (m *Metrics) RecordL1EndpointHealth(endpoint string, score float64) {
+	m.L1EndpointHealth.WithLabelValues(endpoint).Set(score)
+}
+
+func (m *Metrics) RecordL1EndpointFailover(endpoint string) {
+	m.L1EndpointFailovers.WithLabelValues(endpoint).Inc()
+}
+
+func (m *Metrics) RecordL1EndpointDisagreement(label string) {
+	m.L1EndpointDisagreements.WithLabelValues(label).Inc()
+}
+
 func (m *Metrics) RecordSequencerInconsistentL1Origin(from eth.BlockID, to eth.BlockID) {
 	m.SequencerInconsistentL1Origin.RecordEvent()
 	m.recordRef("l1_origin", "inconsistent_from", from.Number, 0, from.Hash)
@@ -600,6 +728,23 @@ func (m *Metrics) RecordSequencerReset() {
 	m.SequencerResets.RecordEvent()
 }
 
+func (m *Metrics) RecordSequencerPolicy(depositOnly bool, txPoolPaused bool, maxGasPerBlock uint64) {
+	var depositOnlyVal, txPoolPausedVal float64
+	if depositOnly {
+		depositOnlyVal = 1
+	}
+	if txPoolPaused {
+		txPoolPausedVal = 1
+	}
+	m.SequencerPolicy.WithLabelValues("deposit_only").Set(depositOnlyVal)
+	m.SequencerPolicy.WithLabelValues("tx_pool_paused").Set(txPoolPausedVal)
+	m.SequencerPolicy.WithLabelValues("max_gas_per_block").Set(float64(maxGasPerBlock))
+}
+
+func (m *Metrics) RecordSequencerNoTxPool(reason string) {
+	m.SequencerNoTxPoolTotal.WithLabelValues(reason).Inc()
+}
+
 func (m *Metrics) RecordGossipEvent(evType int32) {
 	m.GossipEventsTotal.WithLabelValues(pb.TraceEvent_Type_name[evType]).Inc()
 }
@@ -692,10 +837,36 @@ func (m *Metrics) ServerPayloadByNumberEvent(num uint64, resultCode byte, durati
 	m.P2PPayloadByNumber.WithLabelValues("server").Set(float64(num))
 }
 
+func (m *Metrics) ClientPayloadsByRangeEvent(start uint64, count uint64, resultCode byte, duration time.Duration) {
+	if resultCode > 4 { // summarize all high codes to reduce metrics overhead
+		resultCode = 5
+	}
+	code := strconv.FormatUint(uint64(resultCode), 10)
+	m.P2PReqTotal.WithLabelValues("client", "payloads_by_range", code).Inc()
+	m.P2PReqDurationSeconds.WithLabelValues("client", "payloads_by_range", code).Observe(float64(duration) / float64(time.Second))
+	m.P2PPayloadByNumber.WithLabelValues("client").Set(float64(start + count - 1))
+}
+
+func (m *Metrics) ServerPayloadsByRangeEvent(start uint64, count uint64, resultCode byte, duration time.Duration) {
+	code := strconv.FormatUint(uint64(resultCode), 10)
+	m.P2PReqTotal.WithLabelValues("server", "payloads_by_range", code).Inc()
+	m.P2PReqDurationSeconds.WithLabelValues("server", "payloads_by_range", code).Observe(float64(duration) / float64(time.Second))
+	m.P2PPayloadByNumber.WithLabelValues("server").Set(float64(start + count - 1))
+}
+
 func (m *Metrics) PayloadsQuarantineSize(n int) {
 	m.PayloadsQuarantineTotal.Set(float64(n))
 }
 
+func (m *Metrics) RecordSafeHeadAttestation() {
+	m.SafeHeadAttestationsTotal.Inc()
+}
+
+func (m *Metrics) RecordSafeHeadAgreement(agree uint64, disagree uint64) {
+	m.SafeHeadAgreement.WithLabelValues("agree").Set(float64(agree))
+	m.SafeHeadAgreement.WithLabelValues("disagree").Set(float64(disagree))
+}
+
 func (m *Metrics) RecordChannelInputBytes(inputCompressedBytes int) {
 	m.ChannelInputBytes.Add(float64(inputCompressedBytes))
 }
@@ -742,6 +913,10 @@ func (n *noopMetricer) RecordRPCClientRequest(method string) func(err error) {
 	return func(err error) {}
 }
 
+func (n *noopMetricer) RecordEngineRequest(method string) func(outcome string) {
+	return func(outcome string) {}
+}
+
 func (n *noopMetricer) RecordRPCClientResponse(method string, err error) {
 }
 
@@ -781,12 +956,33 @@ func (n *noopMetricer) CountSequencedTxs(count int) {
 func (n *noopMetricer) RecordL1ReorgDepth(d uint64) {
 }
 
+func (n *noopMetricer) RecordL1Reorg(oldHead eth.L1BlockRef, newHead eth.L1BlockRef) {
+}
+
+func (n *noopMetricer) RecordL1ReorgHalt(halted bool) {
+}
+
+func (n *noopMetricer) RecordL1EndpointHealth(endpoint string, score float64) {
+}
+
+func (n *noopMetricer) RecordL1EndpointFailover(endpoint string) {
+}
+
+func (n *noopMetricer) RecordL1EndpointDisagreement(label string) {
+}
+
 func (n *noopMetricer) RecordSequencerInconsistentL1Origin(from eth.BlockID, to eth.BlockID) {
 }
 
 func (n *noopMetricer) RecordSequencerReset() {
 }
 
+func (n *noopMetricer) RecordSequencerPolicy(depositOnly bool, txPoolPaused bool, maxGasPerBlock uint64) {
+}
+
+func (n *noopMetricer) RecordSequencerNoTxPool(reason string) {
+}
+
 func (n *noopMetricer) RecordGossipEvent(evType int32) {
 }
 
@@ -824,9 +1020,21 @@ func (n *noopMetricer) ClientPayloadByNumberEvent(num uint64, resultCode byte, d
 func (n *noopMetricer) ServerPayloadByNumberEvent(num uint64, resultCode byte, duration time.Duration) {
 }
 
+func (n *noopMetricer) ClientPayloadsByRangeEvent(start uint64, count uint64, resultCode byte, duration time.Duration) {
+}
+
+func (n *noopMetricer) ServerPayloadsByRangeEvent(start uint64, count uint64, resultCode byte, duration time.Duration) {
+}
+
 func (n *noopMetricer) PayloadsQuarantineSize(int) {
 }
 
+func (n *noopMetricer) RecordSafeHeadAttestation() {
+}
+
+func (n *noopMetricer) RecordSafeHeadAgreement(agree uint64, disagree uint64) {
+}
+
 func (n *noopMetricer) RecordChannelInputBytes(int) {
 }
 
@@ -2,19 +2,27 @@ package node
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/p2p"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 	"github.com/ethereum-optimism/optimism/op-node/version"
 )
 
+// maxOutputProofSlots is the maximum number of message-passer storage slots
+// that can be proven in a single optimism_outputWithProofs request.
+const maxOutputProofSlots = 256
+
 type l2EthClient interface {
 	InfoByHash(ctx context.Context, hash common.Hash) (eth.BlockInfo, error)
 	// GetProof returns a proof of the account, it may return a nil result without error if the address was not found.
@@ -26,9 +34,19 @@ type driverClient interface {
 	SyncStatus(ctx context.Context) (*eth.SyncStatus, error)
 	BlockRefWithStatus(ctx context.Context, num uint64) (eth.L2BlockRef, *eth.SyncStatus, error)
 	ResetDerivationPipeline(context.Context) error
+	RollbackSafeHead(ctx context.Context, number uint64, force bool) (eth.L2BlockRef, error)
 	StartSequencer(ctx context.Context, blockHash common.Hash) error
 	StopSequencer(context.Context) (common.Hash, error)
 	SequencerActive(context.Context) (bool, error)
+	SequencerPolicy(ctx context.Context) (driver.SequencerPolicy, error)
+	SetSequencerPolicy(ctx context.Context, policy driver.SequencerPolicy) (driver.SequencerPolicy, error)
+	L1ReorgHalt(ctx context.Context) (*driver.L1Reorg, error)
+	AcknowledgeL1Reorg(ctx context.Context) (*driver.L1Reorg, error)
+}
+
+// sequencerLeadership hands over the sequencer leadership to another node.
+type sequencerLeadership interface {
+	Handover(ctx context.Context) (eth.BlockID, error)
 }
 
 type rpcMetrics interface {
@@ -37,14 +55,17 @@ type rpcMetrics interface {
 }
 
 type adminAPI struct {
-	dr driverClient
-	m  rpcMetrics
+	dr     driverClient
+	leader sequencerLeadership
+	m      rpcMetrics
 }
 
-func NewAdminAPI(dr driverClient, m rpcMetrics) *adminAPI {
+// NewAdminAPI creates the admin API. The leader is nil if sequencer leader election is disabled.
+func NewAdminAPI(dr driverClient, leader sequencerLeadership, m rpcMetrics) *adminAPI {
 	return &adminAPI{
-		dr: dr,
-		m:  m,
+		dr:     dr,
+		leader: leader,
+		m:      m,
 	}
 }
 
@@ -54,6 +75,14 @@ func (n *adminAPI) ResetDerivationPipeline(ctx context.Context) error {
 	return n.dr.ResetDerivationPipeline(ctx)
 }
 
+// RollbackSafeHead rolls back the unsafe, safe and finalized heads to the given L2 block, to re-derive from there.
+// Rolling back past the finalized head requires force to be set.
+func (n *adminAPI) RollbackSafeHead(ctx context.Context, number hexutil.Uint64, force bool) (eth.L2BlockRef, error) {
+	recordDur := n.m.RecordRPCServerRequest("admin_rollbackSafeHead")
+	defer recordDur()
+	return n.dr.RollbackSafeHead(ctx, uint64(number), force)
+}
+
 func (n *adminAPI) StartSequencer(ctx context.Context, blockHash common.Hash) error {
 	recordDur := n.m.RecordRPCServerRequest("admin_startSequencer")
 	defer recordDur()
@@ -72,6 +101,44 @@ func (n *adminAPI) SequencerActive(ctx context.Context) (bool, error) {
 	return n.dr.SequencerActive(ctx)
 }
 
+func (n *adminAPI) SequencerPolicy(ctx context.Context) (driver.SequencerPolicy, error) {
+	recordDur := n.m.RecordRPCServerRequest("admin_sequencerPolicy")
+	defer recordDur()
+	return n.dr.SequencerPolicy(ctx)
+}
+
+// SetSequencerPolicy replaces the sequencer policy, e.g. to force deposit-only blocks, and persists it.
+func (n *adminAPI) SetSequencerPolicy(ctx context.Context, policy driver.SequencerPolicy) (driver.SequencerPolicy, error) {
+	recordDur := n.m.RecordRPCServerRequest("admin_setSequencerPolicy")
+	defer recordDur()
+	return n.dr.SetSequencerPolicy(ctx, policy)
+}
+
+// HandoverSequencerLeadership stops sequencing and gives up the sequencer leadership, for another node to take over.
+// It returns the last gossiped block, that the next leader continues from.
+func (n *adminAPI) HandoverSequencerLeadership(ctx context.Context) (eth.BlockID, error) {
+	recordDur := n.m.RecordRPCServerRequest("admin_handoverSequencerLeadership")
+	defer recordDur()
+	if n.leader == nil {
+		return eth.BlockID{}, errors.New("sequencer leader election is not enabled")
+	}
+	return n.leader.Handover(ctx)
+}
+
+// L1ReorgHalt returns the L1 reorg that halted derivation and sequencing, or nil if not halted.
+func (n *adminAPI) L1ReorgHalt(ctx context.Context) (*driver.L1Reorg, error) {
+	recordDur := n.m.RecordRPCServerRequest("admin_l1ReorgHalt")
+	defer recordDur()
+	return n.dr.L1ReorgHalt(ctx)
+}
+
+// AcknowledgeL1Reorg resumes derivation and sequencing after a halt due to a deep L1 reorg.
+func (n *adminAPI) AcknowledgeL1Reorg(ctx context.Context) (*driver.L1Reorg, error) {
+	recordDur := n.m.RecordRPCServerRequest("admin_acknowledgeL1Reorg")
+	defer recordDur()
+	return n.dr.AcknowledgeL1Reorg(ctx)
+}
+
 type nodeAPI struct {
 	config *rollup.Config
 	client l2EthClient
@@ -99,44 +166,92 @@ func (n *nodeAPI) OutputAtBlock(ctx context.Context, number hexutil.Uint64) (*et
 		return nil, fmt.Errorf("failed to get L2 block ref with sync status: %w", err)
 	}
 
-	head, err := n.client.InfoByHash(ctx, ref.Hash)
+	head, proof, l2OutputRoot, err := outputV0AtBlock(ctx, n.client, ref, []common.Hash{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get L2 block by hash %s: %w", ref, err)
-	}
-	if head == nil {
-		return nil, ethereum.NotFound
+		n.log.Error("failed to compute L2 output root", "block", ref, "err", err)
+		return nil, err
 	}
+	var l2OutputRootVersion eth.Bytes32 // it's zero for now
 
-	proof, err := n.client.GetProof(ctx, predeploys.L2ToL1MessagePasserAddr, []common.Hash{}, ref.Hash.String())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get contract proof at block %s: %w", ref, err)
+	return &eth.OutputResponse{
+		Version:               l2OutputRootVersion,
+		OutputRoot:            l2OutputRoot,
+		BlockRef:              ref,
+		WithdrawalStorageRoot: proof.StorageHash,
+		StateRoot:             head.Root(),
+		Status:                status,
+	}, nil
+}
+
+// OutputWithProofs returns the output root of the given L2 block, with the preimage of the output root,
+// and the proof of the L2ToL1MessagePasser account and the given storage slots of it (e.g. sent withdrawals).
+// The block header and the proofs are all retrieved by block hash, and verified against the state root of the block.
+func (n *nodeAPI) OutputWithProofs(ctx context.Context, number hexutil.Uint64, slots []common.Hash) (*eth.OutputWithProofsResponse, error) {
+	recordDur := n.m.RecordRPCServerRequest("optimism_outputWithProofs")
+	defer recordDur()
+
+	if len(slots) > maxOutputProofSlots {
+		return nil, fmt.Errorf("too many storage slots: %d, max is %d", len(slots), maxOutputProofSlots)
 	}
-	if proof == nil {
-		return nil, fmt.Errorf("proof %w", ethereum.NotFound)
+	if slots == nil {
+		slots = []common.Hash{}
 	}
-	// make sure that the proof (including storage hash) that we retrieved is correct by verifying it against the state-root
-	if err := proof.Verify(head.Root()); err != nil {
-		n.log.Error("invalid withdrawal root detected in block", "stateRoot", head.Root(), "blocknum", number, "msg", err)
-		return nil, fmt.Errorf("invalid withdrawal root hash, state root was %s: %w", head.Root(), err)
+
+	ref, status, err := n.dr.BlockRefWithStatus(ctx, uint64(number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get L2 block ref with sync status: %w", err)
 	}
 
-	var l2OutputRootVersion eth.Bytes32 // it's zero for now
-	l2OutputRoot, err := rollup.ComputeL2OutputRootV0(head, proof.StorageHash)
+	head, proof, l2OutputRoot, err := outputV0AtBlock(ctx, n.client, ref, slots)
 	if err != nil {
-		n.log.Error("Error computing L2 output root, nil ptr passed to hashing function")
+		n.log.Error("failed to compute L2 output root with proofs", "block", ref, "slots", len(slots), "err", err)
 		return nil, err
 	}
+	var l2OutputRootVersion eth.Bytes32 // it's zero for now
 
-	return &eth.OutputResponse{
+	return &eth.OutputWithProofsResponse{
 		Version:               l2OutputRootVersion,
 		OutputRoot:            l2OutputRoot,
 		BlockRef:              ref,
-		WithdrawalStorageRoot: proof.StorageHash,
 		StateRoot:             head.Root(),
+		WithdrawalStorageRoot: proof.StorageHash,
+		BlockHash:             head.Hash(),
+		MessagePasserProof:    proof,
 		Status:                status,
 	}, nil
 }
 
+// outputV0AtBlock computes the version 0 output root of the given L2 block.
+// The message-passer account proof, including the proofs of the given storage slots,
+// is verified against the state-root of the block.
+func outputV0AtBlock(ctx context.Context, client l2EthClient, ref eth.L2BlockRef, slots []common.Hash) (eth.BlockInfo, *eth.AccountResult, eth.Bytes32, error) {
+	head, err := client.InfoByHash(ctx, ref.Hash)
+	if err != nil {
+		return nil, nil, eth.Bytes32{}, fmt.Errorf("failed to get L2 block by hash %s: %w", ref, err)
+	}
+	if head == nil {
+		return nil, nil, eth.Bytes32{}, ethereum.NotFound
+	}
+
+	proof, err := client.GetProof(ctx, predeploys.L2ToL1MessagePasserAddr, slots, ref.Hash.String())
+	if err != nil {
+		return nil, nil, eth.Bytes32{}, fmt.Errorf("failed to get contract proof at block %s: %w", ref, err)
+	}
+	if proof == nil {
+		return nil, nil, eth.Bytes32{}, fmt.Errorf("proof %w", ethereum.NotFound)
+	}
+	// make sure that the proof (including storage hash) that we retrieved is correct by verifying it against the state-root
+	if err := proof.Verify(head.Root()); err != nil {
+		return nil, nil, eth.Bytes32{}, fmt.Errorf("invalid withdrawal root hash, state root was %s: %w", head.Root(), err)
+	}
+
+	l2OutputRoot, err := rollup.ComputeL2OutputRootV0(head, proof.StorageHash)
+	if err != nil {
+		return nil, nil, eth.Bytes32{}, fmt.Errorf("failed to compute L2 output root: %w", err)
+	}
+	return head, proof, l2OutputRoot, nil
+}
+
 func (n *nodeAPI) SyncStatus(ctx context.Context) (*eth.SyncStatus, error) {
 	recordDur := n.m.RecordRPCServerRequest("optimism_syncStatus")
 	defer recordDur()
@@ -154,3 +269,157 @@ func (n *nodeAPI) Version(ctx context.Context) (string, error) {
 	defer recordDur()
 	return version.Version + "-" + version.Meta, nil
 }
+
+type safeHeadRecords interface {
+	RecordByL2Number(ctx context.Context, l2Num uint64) (*eth.SafeHeadRecord, error)
+	RecordsByL1Number(ctx context.Context, l1Num uint64) ([]eth.SafeHeadRecord, error)
+}
+
+type safeDBAPI struct {
+	db safeHeadRecords
+	m  rpcMetrics
+}
+
+func NewSafeDBAPI(db safeHeadRecords, m rpcMetrics) *safeDBAPI {
+	return &safeDBAPI{
+		db: db,
+		m:  m,
+	}
+}
+
+// SafeHeadRecordByL2Block returns how the given L2 block was derived from L1, when it became safe.
+func (n *safeDBAPI) SafeHeadRecordByL2Block(ctx context.Context, number hexutil.Uint64) (*eth.SafeHeadRecord, error) {
+	recordDur := n.m.RecordRPCServerRequest("optimism_safeHeadRecordByL2Block")
+	defer recordDur()
+	return n.db.RecordByL2Number(ctx, uint64(number))
+}
+
+// SafeHeadRecordsByL1Block returns the records of the L2 blocks that became safe when deriving from the given L1 block.
+func (n *safeDBAPI) SafeHeadRecordsByL1Block(ctx context.Context, number hexutil.Uint64) ([]eth.SafeHeadRecord, error) {
+	recordDur := n.m.RecordRPCServerRequest("optimism_safeHeadRecordsByL1Block")
+	defer recordDur()
+	return n.db.RecordsByL1Number(ctx, uint64(number))
+}
+
+type blockFeedAPI struct {
+	feed *p2p.BlockFeed
+	m    rpcMetrics
+}
+
+func NewBlockFeedAPI(feed *p2p.BlockFeed, m rpcMetrics) *blockFeedAPI {
+	return &blockFeedAPI{
+		feed: feed,
+		m:    m,
+	}
+}
+
+// RecentUnsafeBlocks returns the most recent signed unsafe blocks after the given block number.
+func (n *blockFeedAPI) RecentUnsafeBlocks(ctx context.Context, after hexutil.Uint64) ([]p2p.SignedBlock, error) {
+	recordDur := n.m.RecordRPCServerRequest("optimism_recentUnsafeBlocks")
+	defer recordDur()
+	return n.feed.Recent(uint64(after)), nil
+}
+
+// UnsafeBlocks subscribes to the signed unsafe blocks, starting with the most recent blocks after the given block number.
+// Blocks are dropped if the subscriber does not keep up.
+func (n *blockFeedAPI) UnsafeBlocks(ctx context.Context, after hexutil.Uint64) (*rpc.Subscription, error) {
+	recordDur := n.m.RecordRPCServerRequest("optimism_subscribe_unsafeBlocks")
+	defer recordDur()
+
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	backlog, blocks, unsubscribe := n.feed.Subscribe(uint64(after))
+	go func() {
+		defer unsubscribe()
+		// Blocks are sent regardless of their height, later blocks may replace blocks at the same or a lower height.
+		for _, block := range backlog {
+			if err := notifier.Notify(sub.ID, block); err != nil {
+				return
+			}
+		}
+		for {
+			select {
+			case block := <-blocks:
+				if err := notifier.Notify(sub.ID, block); err != nil {
+					return
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+type debugAPI struct {
+	tracer    *pipelineTracer
+	snapshots *snapshotFeed
+	m         rpcMetrics
+}
+
+func NewDebugAPI(tracer *pipelineTracer, snapshots *snapshotFeed, m rpcMetrics) *debugAPI {
+	return &debugAPI{
+		tracer:    tracer,
+		snapshots: snapshots,
+		m:         m,
+	}
+}
+
+// PipelineEvents subscribes to the structured trace events of the derivation pipeline stages.
+// Events are dropped if the subscriber does not keep up.
+func (n *debugAPI) PipelineEvents(ctx context.Context) (*rpc.Subscription, error) {
+	recordDur := n.m.RecordRPCServerRequest("debug_subscribe_pipelineEvents")
+	defer recordDur()
+
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	events, unsubscribe := n.tracer.Subscribe()
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case ev := <-events:
+				if err := notifier.Notify(sub.ID, ev); err != nil {
+					return
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// DriverSnapshots subscribes to the snapshots of the rollup driver state,
+// i.e. the entries of the snapshot log. Snapshots are dropped if the subscriber does not keep up.
+func (n *debugAPI) DriverSnapshots(ctx context.Context) (*rpc.Subscription, error) {
+	recordDur := n.m.RecordRPCServerRequest("debug_subscribe_driverSnapshots")
+	defer recordDur()
+
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	snapshots, unsubscribe := n.snapshots.Subscribe()
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case snap := <-snapshots:
+				if err := notifier.Notify(sub.ID, snap); err != nil {
+					return
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
@@ -0,0 +1,308 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/p2p"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+const (
+	// attestationsWindow is the range of L2 blocks, relative to the local safe head,
+	// for which safe-head attestations of other peers are retained.
+	attestationsWindow = 1024
+	// maxAttestedBlocks bounds the number of L2 blocks that attestations are retained for,
+	// also before the local safe head is known.
+	maxAttestedBlocks = 2*attestationsWindow + 1
+	// attestationsPersistInterval is the minimum time between two writes of the attestations file.
+	attestationsPersistInterval = 30 * time.Second
+)
+
+// safeHeadAttestations publishes attestations of the local safe head,
+// and records the attestations of trusted attesters, to compare them against the local safe head.
+// The recorded attestations are optionally persisted to a file, to be kept across restarts.
+type safeHeadAttestations struct {
+	log     log.Logger
+	cfg     *rollup.Config
+	metrics metrics.Metricer
+
+	l2  l2EthClient
+	dr  driverClient
+	out p2p.SafeHeadAttestationsOut
+
+	// isAttester filters out attestations by peers that are not trusted to attest,
+	// to not let arbitrary identities inflate the agreement.
+	isAttester func(id peer.ID) bool
+
+	// path is the file the attestations are persisted to, not persisted if empty.
+	path string
+
+	mu sync.Mutex
+	// local is the latest attestation of our own safe head, nil if not computed yet.
+	local *p2p.SafeHeadAttestation
+	// byNumber holds the attestations by L2 block number, with at most one attestation per attester per block.
+	byNumber map[uint64]map[peer.ID]*p2p.SignedSafeHeadAttestation
+	// dirty is true if there are attestations that are not persisted yet.
+	dirty bool
+}
+
+func newSafeHeadAttestations(log log.Logger, cfg *rollup.Config, m metrics.Metricer, l2 l2EthClient, dr driverClient,
+	out p2p.SafeHeadAttestationsOut, isAttester func(id peer.ID) bool, path string) *safeHeadAttestations {
+	s := &safeHeadAttestations{
+		log:        log,
+		cfg:        cfg,
+		metrics:    m,
+		l2:         l2,
+		dr:         dr,
+		out:        out,
+		isAttester: isAttester,
+		path:       path,
+		byNumber:   make(map[uint64]map[peer.ID]*p2p.SignedSafeHeadAttestation),
+	}
+	if err := s.load(); err != nil {
+		log.Warn("failed to load persisted safe head attestations", "path", path, "err", err)
+	}
+	return s
+}
+
+// OnAttestation records the attestation, if it is made by a trusted attester and relevant to the local safe head.
+func (s *safeHeadAttestations) OnAttestation(att *p2p.SignedSafeHeadAttestation) {
+	if !s.isAttester(att.Attester) {
+		s.log.Debug("ignoring safe head attestation of untrusted peer", "attester", att.Attester, "l2_block", att.L2Block)
+		return
+	}
+	s.metrics.RecordSafeHeadAttestation()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(att)
+	s.updateAgreementMetrics()
+}
+
+// add records the attestation, if it is within the window of the local safe head.
+// Before the local safe head is known, the attestations of the lowest blocks are dropped
+// once attestations of more than maxAttestedBlocks blocks are recorded.
+func (s *safeHeadAttestations) add(att *p2p.SignedSafeHeadAttestation) {
+	if s.local != nil {
+		if att.L2Block.Number+attestationsWindow < s.local.L2Block.Number || att.L2Block.Number > s.local.L2Block.Number+attestationsWindow {
+			return
+		}
+	}
+	atts, ok := s.byNumber[att.L2Block.Number]
+	if !ok {
+		if len(s.byNumber) >= maxAttestedBlocks {
+			lowest := att.L2Block.Number
+			for num := range s.byNumber {
+				if num < lowest {
+					lowest = num
+				}
+			}
+			if lowest == att.L2Block.Number {
+				return
+			}
+			delete(s.byNumber, lowest)
+		}
+		atts = make(map[peer.ID]*p2p.SignedSafeHeadAttestation)
+		s.byNumber[att.L2Block.Number] = atts
+	}
+	atts[att.Attester] = att
+	s.dirty = true
+}
+
+// Agreement returns the number of distinct trusted attesters that agree and disagree with the local attestation of the given safe block.
+func (s *safeHeadAttestations) Agreement(safe eth.BlockID) (agree uint64, disagree uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.local == nil || s.local.L2Block != safe {
+		return 0, 0
+	}
+	return s.agreement()
+}
+
+func (s *safeHeadAttestations) agreement() (agree uint64, disagree uint64) {
+	for _, att := range s.byNumber[s.local.L2Block.Number] {
+		if att.L2Block.Hash == s.local.L2Block.Hash && att.OutputRoot == s.local.OutputRoot {
+			agree += 1
+		} else {
+			disagree += 1
+		}
+	}
+	return
+}
+
+func (s *safeHeadAttestations) updateAgreementMetrics() {
+	if s.local == nil {
+		return
+	}
+	agree, disagree := s.agreement()
+	s.metrics.RecordSafeHeadAgreement(agree, disagree)
+	if disagree > 0 {
+		s.log.Warn("peers disagree with local safe head", "l2_block", s.local.L2Block, "output_root", s.local.OutputRoot, "agree", agree, "disagree", disagree)
+	}
+}
+
+// attest computes the output root of the given safe head, and publishes the attestation of it.
+func (s *safeHeadAttestations) attest(ctx context.Context, safe eth.L2BlockRef) error {
+	_, _, outputRoot, err := outputV0AtBlock(ctx, s.l2, safe, []common.Hash{})
+	if err != nil {
+		return err
+	}
+	att := &p2p.SafeHeadAttestation{
+		L2Block:    safe.ID(),
+		OutputRoot: outputRoot,
+		L1Origin:   safe.L1Origin,
+	}
+
+	s.mu.Lock()
+	s.local = att
+	for num := range s.byNumber {
+		if num+attestationsWindow < safe.Number || num > safe.Number+attestationsWindow {
+			delete(s.byNumber, num)
+		}
+	}
+	s.dirty = true
+	s.updateAgreementMetrics()
+	s.mu.Unlock()
+
+	return s.out.PublishSafeHeadAttestation(ctx, att)
+}
+
+// Run attests to every new safe head, until the context is closed.
+// The safe head is polled every L2 block time.
+// The attestations are persisted at most every attestationsPersistInterval, and when closing.
+func (s *safeHeadAttestations) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.cfg.BlockTime) * time.Second)
+	defer ticker.Stop()
+	persistTicker := time.NewTicker(attestationsPersistInterval)
+	defer persistTicker.Stop()
+	defer s.persist()
+
+	var lastAttested eth.BlockID
+	for {
+		select {
+		case <-persistTicker.C:
+			s.persist()
+		case <-ticker.C:
+			reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			status, err := s.dr.SyncStatus(reqCtx)
+			if err != nil {
+				s.log.Warn("failed to get sync status for safe head attestation", "err", err)
+				cancel()
+				continue
+			}
+			if status.SafeL2.ID() == lastAttested || status.SafeL2.Number <= s.cfg.Genesis.L2.Number {
+				cancel()
+				continue
+			}
+			if err := s.attest(reqCtx, status.SafeL2); err != nil {
+				s.log.Warn("failed to attest to safe head", "safe_l2", status.SafeL2, "err", err)
+			} else {
+				lastAttested = status.SafeL2.ID()
+			}
+			cancel()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// persistedAttestations is the file format of the persisted attestations.
+type persistedAttestations struct {
+	Local        *p2p.SafeHeadAttestation         `json:"local,omitempty"`
+	Attestations []*p2p.SignedSafeHeadAttestation `json:"attestations"`
+}
+
+// load reads the persisted attestations, if any.
+// Attestations of attesters that are not trusted anymore are dropped.
+func (s *safeHeadAttestations) load() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("read attestations file: %w", err)
+	}
+	var persisted persistedAttestations
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return fmt.Errorf("decode attestations file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.local = persisted.Local
+	for _, att := range persisted.Attestations {
+		if s.isAttester(att.Attester) {
+			s.add(att)
+		}
+	}
+	s.dirty = false
+	s.log.Info("loaded persisted safe head attestations", "path", s.path, "blocks", len(s.byNumber))
+	return nil
+}
+
+// persist writes the attestations to the file, if there are any changes.
+// The file is replaced atomically.
+func (s *safeHeadAttestations) persist() {
+	if s.path == "" {
+		return
+	}
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return
+	}
+	persisted := persistedAttestations{Local: s.local, Attestations: []*p2p.SignedSafeHeadAttestation{}}
+	for _, atts := range s.byNumber {
+		for _, att := range atts {
+			persisted.Attestations = append(persisted.Attestations, att)
+		}
+	}
+	s.dirty = false
+	s.mu.Unlock()
+
+	if err := writeFileAtomic(s.path, persisted); err != nil {
+		s.log.Error("failed to persist safe head attestations", "path", s.path, "err", err)
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+	}
+}
+
+// attestedDriverClient extends the sync status of the driver with the safe-head attestations agreement.
+type attestedDriverClient struct {
+	driverClient
+	att *safeHeadAttestations
+}
+
+func (d *attestedDriverClient) SyncStatus(ctx context.Context) (*eth.SyncStatus, error) {
+	status, err := d.driverClient.SyncStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status.SafeL2Attestations, _ = d.att.Agreement(status.SafeL2.ID())
+	return status, nil
+}
+
+func (d *attestedDriverClient) BlockRefWithStatus(ctx context.Context, num uint64) (eth.L2BlockRef, *eth.SyncStatus, error) {
+	ref, status, err := d.driverClient.BlockRefWithStatus(ctx, num)
+	if err != nil {
+		return ref, nil, err
+	}
+	status.SafeL2Attestations, _ = d.att.Agreement(status.SafeL2.ID())
+	return ref, status, nil
+}
@@ -0,0 +1,82 @@
+package node
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	peertest "github.com/libp2p/go-libp2p/core/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/p2p"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func TestSafeHeadAttestations(t *testing.T) {
+	trusted := peertest.RandPeerIDFatal(t)
+	untrusted := peertest.RandPeerIDFatal(t)
+	isAttester := func(id peer.ID) bool { return id == trusted }
+	create := func(path string) *safeHeadAttestations {
+		return newSafeHeadAttestations(testlog.Logger(t, log.LvlError), &rollup.Config{}, metrics.NoopMetrics,
+			nil, nil, nil, isAttester, path)
+	}
+	attestation := func(num uint64, attester peer.ID) *p2p.SignedSafeHeadAttestation {
+		return &p2p.SignedSafeHeadAttestation{
+			SafeHeadAttestation: p2p.SafeHeadAttestation{
+				L2Block:    eth.BlockID{Hash: common.Hash{byte(num)}, Number: num},
+				OutputRoot: eth.Bytes32{byte(num)},
+			},
+			Attester: attester,
+		}
+	}
+
+	t.Run("IgnoreUntrustedAttesters", func(t *testing.T) {
+		s := create("")
+		s.OnAttestation(attestation(1, untrusted))
+		require.Empty(t, s.byNumber)
+		s.OnAttestation(attestation(1, trusted))
+		require.Len(t, s.byNumber[1], 1)
+	})
+
+	t.Run("BoundedWithoutLocalSafeHead", func(t *testing.T) {
+		s := create("")
+		for i := uint64(0); i < maxAttestedBlocks+10; i++ {
+			s.OnAttestation(attestation(100+i, trusted))
+		}
+		require.Len(t, s.byNumber, maxAttestedBlocks)
+		require.NotContains(t, s.byNumber, uint64(100), "lowest blocks are evicted")
+		require.Contains(t, s.byNumber, uint64(100+maxAttestedBlocks+9))
+
+		s.OnAttestation(attestation(1, trusted))
+		require.NotContains(t, s.byNumber, uint64(1), "blocks below all retained blocks are not added when full")
+	})
+
+	t.Run("PersistAndLoad", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "attestations.json")
+		s := create(path)
+		local := attestation(10, trusted)
+		s.local = &local.SafeHeadAttestation
+		s.OnAttestation(attestation(10, trusted))
+		s.OnAttestation(attestation(11, trusted))
+		s.persist()
+		require.False(t, s.dirty)
+
+		loaded := create(path)
+		require.Equal(t, s.local, loaded.local)
+		require.Equal(t, s.byNumber, loaded.byNumber)
+		agree, disagree := loaded.Agreement(local.L2Block)
+		require.Equal(t, uint64(1), agree)
+		require.Zero(t, disagree)
+
+		// attestations of peers that are no longer trusted are dropped on load
+		isAttester = func(id peer.ID) bool { return false }
+		defer func() { isAttester = func(id peer.ID) bool { return id == trusted } }()
+		require.Empty(t, create(path).byNumber)
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/client"
@@ -32,7 +34,8 @@ type L1EndpointSetup interface {
 	// Setup a RPC client to a L1 node to pull rollup input-data from.
 	// The results of the RPC client may be trusted for faster processing, or strictly validated.
 	// The kind of the RPC may be non-basic, to optimize RPC usage.
-	Setup(ctx context.Context, log log.Logger, rollupCfg *rollup.Config) (cl client.RPC, rpcCfg *sources.L1ClientConfig, err error)
+	// The metrics are used to track the health of the L1 endpoints, if there are multiple.
+	Setup(ctx context.Context, log log.Logger, rollupCfg *rollup.Config, m client.MultiRPCMetrics) (cl client.RPC, rpcCfg *sources.L1ClientConfig, err error)
 	Check() error
 }
 
@@ -130,7 +133,9 @@ func (cfg *PreparedL2SyncEndpoint) Check() error {
 }
 
 type L1EndpointConfig struct {
-	L1NodeAddr string // Address of L1 User JSON-RPC endpoint to use (eth namespace required)
+	// Address of L1 User JSON-RPC endpoint to use (eth namespace required).
+	// Multiple comma-separated addresses of the same chain may be specified, to fail over between them.
+	L1NodeAddr string
 
 	// L1TrustRPC: if we trust the L1 RPC we do not have to validate L1 response contents like headers
 	// against block hashes, or cached transaction sender addresses.
@@ -147,6 +152,9 @@ type L1EndpointConfig struct {
 	// BatchSize specifies the maximum batch-size, which also applies as L1 rate-limit burst amount (if set).
 	BatchSize int
 
+	// PrefetchWindow is the number of L1 blocks to prefetch ahead of the derivation pipeline. Disabled if 0.
+	PrefetchWindow int
+
 	// HttpPollInterval specifies the interval between polling for the latest L1 block,
 	// when the RPC is detected to be an HTTP type.
 	// It is recommended to use websockets or IPC for efficient following of the changing block.
@@ -163,10 +171,34 @@ func (cfg *L1EndpointConfig) Check() error {
 	if cfg.RateLimit < 0 {
 		return fmt.Errorf("rate limit cannot be negative")
 	}
+	if cfg.PrefetchWindow < 0 {
+		return fmt.Errorf("prefetch window cannot be negative")
+	}
+	for _, addr := range cfg.addrs() {
+		if addr == "" {
+			return errors.New("empty L1 address")
+		}
+	}
 	return nil
 }
 
-func (cfg *L1EndpointConfig) Setup(ctx context.Context, log log.Logger, rollupCfg *rollup.Config) (client.RPC, *sources.L1ClientConfig, error) {
+func (cfg *L1EndpointConfig) addrs() []string {
+	addrs := strings.Split(cfg.L1NodeAddr, ",")
+	for i := range addrs {
+		addrs[i] = strings.TrimSpace(addrs[i])
+	}
+	return addrs
+}
+
+// endpointName names the L1 endpoint in logs and metrics, without the path and credentials of the address.
+func endpointName(i int, addr string) string {
+	if u, err := url.Parse(addr); err == nil && u.Host != "" {
+		return fmt.Sprintf("%d:%s", i, u.Host)
+	}
+	return fmt.Sprintf("%d", i)
+}
+
+func (cfg *L1EndpointConfig) Setup(ctx context.Context, log log.Logger, rollupCfg *rollup.Config, m client.MultiRPCMetrics) (client.RPC, *sources.L1ClientConfig, error) {
 	opts := []client.RPCOption{
 		client.WithHttpPollInterval(cfg.HttpPollInterval),
 		client.WithDialBackoff(10),
@@ -175,12 +207,29 @@ func (cfg *L1EndpointConfig) Setup(ctx context.Context, log log.Logger, rollupCf
 		opts = append(opts, client.WithRateLimit(cfg.RateLimit, cfg.BatchSize))
 	}
 
-	l1Node, err := client.NewRPC(ctx, log, cfg.L1NodeAddr, opts...)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to dial L1 address (%s): %w", cfg.L1NodeAddr, err)
+	var endpoints []client.MultiRPCEndpoint
+	for i, addr := range cfg.addrs() {
+		name := endpointName(i, addr)
+		l1Node, err := client.NewRPC(ctx, log, addr, opts...)
+		if err != nil {
+			for _, e := range endpoints {
+				e.RPC.Close()
+			}
+			return nil, nil, fmt.Errorf("failed to dial L1 address (%s): %w", name, err)
+		}
+		endpoints = append(endpoints, client.MultiRPCEndpoint{Name: name, RPC: l1Node})
+	}
+	var l1Node client.RPC = endpoints[0].RPC
+	if len(endpoints) > 1 {
+		multi, err := client.NewMultiRPC(log, m, endpoints)
+		if err != nil {
+			return nil, nil, err
+		}
+		l1Node = multi
 	}
 	rpcCfg := sources.L1ClientDefaultConfig(rollupCfg, cfg.L1TrustRPC, cfg.L1RPCKind)
 	rpcCfg.MaxRequestsPerBatch = cfg.BatchSize
+	rpcCfg.PrefetchWindow = cfg.PrefetchWindow
 	return l1Node, rpcCfg, nil
 }
 
@@ -193,7 +242,7 @@ type PreparedL1Endpoint struct {
 
 var _ L1EndpointSetup = (*PreparedL1Endpoint)(nil)
 
-func (p *PreparedL1Endpoint) Setup(ctx context.Context, log log.Logger, rollupCfg *rollup.Config) (client.RPC, *sources.L1ClientConfig, error) {
+func (p *PreparedL1Endpoint) Setup(ctx context.Context, log log.Logger, rollupCfg *rollup.Config, m client.MultiRPCMetrics) (client.RPC, *sources.L1ClientConfig, error) {
 	return p.Client, sources.L1ClientDefaultConfig(rollupCfg, p.TrustRPC, p.RPCProviderKind), nil
 }
 
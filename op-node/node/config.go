@@ -7,11 +7,14 @@ import (
 	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/flags"
+	"github.com/ethereum-optimism/optimism/op-node/leader"
+	"github.com/ethereum-optimism/optimism/op-node/node/safedb"
 	"github.com/ethereum-optimism/optimism/op-node/p2p"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 	oppprof "github.com/ethereum-optimism/optimism/op-service/pprof"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p/core/peer"
 )
 
 type Config struct {
@@ -42,15 +45,49 @@ type Config struct {
 
 	ConfigPersistence ConfigPersistence
 
+	// SequencerMaxGasPerBlockSet is true if the max-gas-per-block sequencer policy was explicitly configured,
+	// and takes precedence over the persisted sequencer policy.
+	SequencerMaxGasPerBlockSet bool
+
+	// SequencerLeader sets up leader election between warm-standby sequencers.
+	// Only the leader sequences. Leader election is disabled if nil, or if the setup creates no elector.
+	SequencerLeader leader.ElectorSetup
+
 	// Optional
 	Tracer    Tracer
 	Heartbeat HeartbeatConfig
+
+	// PipelineTraceFile is the path of the JSONL file to append derivation pipeline trace events to.
+	// Disabled if empty.
+	PipelineTraceFile string
+
+	// SafeDB configures the database of the L1 derivation details of safe L2 blocks.
+	SafeDB safedb.Config
+
+	// BlockFeed configures following the unsafe block feed of a sequencer over RPC, as alternative to gossip.
+	BlockFeed p2p.BlockFeedConfig
+
+	// SafeHeadAttestations configures the tracking of the safe-head attestations of other peers,
+	// if the attestations gossip topic is enabled.
+	SafeHeadAttestations SafeHeadAttestationsConfig
+}
+
+type SafeHeadAttestationsConfig struct {
+	// Attesters are the peers whose attestations are counted.
+	// If empty, the attestations of static and trusted peers are counted.
+	Attesters []peer.ID
+	// Path is the file that the received attestations are persisted to. Not persisted if empty.
+	Path string
 }
 
 type RPCConfig struct {
 	ListenAddr  string
 	ListenPort  int
 	EnableAdmin bool
+	EnableDebug bool
+	// EnableBlockFeed serves the signed unsafe blocks that the node publishes over RPC,
+	// for verifiers that cannot join the blocks gossip topic.
+	EnableBlockFeed bool
 }
 
 func (cfg *RPCConfig) HttpEndpoint() string {
@@ -82,6 +119,12 @@ type HeartbeatConfig struct {
 }
 
 func (cfg *Config) LoadPersisted(log log.Logger) error {
+	if reorg, err := cfg.ConfigPersistence.L1ReorgHalt(); err != nil {
+		return err
+	} else if reorg != nil {
+		log.Warn("Loaded persisted L1 re-org halt", "depth", reorg.Depth, "old_l1_head", reorg.OldHead, "new_l1_head", reorg.NewHead)
+		cfg.Driver.L1ReorgHalt = reorg
+	}
 	if !cfg.Driver.SequencerEnabled {
 		return nil
 	}
@@ -96,6 +139,18 @@ func (cfg *Config) LoadPersisted(log log.Logger) error {
 	} else {
 		log.Info("No persisted sequencer state loaded")
 	}
+	if policy, err := cfg.ConfigPersistence.SequencerPolicy(); err != nil {
+		return err
+	} else if policy != nil {
+		if cfg.SequencerMaxGasPerBlockSet && policy.MaxGasPerBlock != cfg.Driver.SequencerPolicy.MaxGasPerBlock {
+			log.Warn(fmt.Sprintf("Overriding persisted sequencer policy with %v", flags.SequencerMaxGasPerBlockFlag.Name),
+				"persisted", policy.MaxGasPerBlock, "max_gas_per_block", cfg.Driver.SequencerPolicy.MaxGasPerBlock)
+			policy.MaxGasPerBlock = cfg.Driver.SequencerPolicy.MaxGasPerBlock
+		}
+		log.Info("Loaded persisted sequencer policy", "deposit_only", policy.DepositOnly,
+			"tx_pool_paused", policy.TxPoolPaused, "max_gas_per_block", policy.MaxGasPerBlock)
+		cfg.Driver.SequencerPolicy = *policy
+	}
 	return nil
 }
 
@@ -121,5 +176,8 @@ func (cfg *Config) Check() error {
 			return fmt.Errorf("p2p config error: %w", err)
 		}
 	}
+	if err := cfg.BlockFeed.Check(); err != nil {
+		return fmt.Errorf("unsafe block feed config error: %w", err)
+	}
 	return nil
 }
@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 )
 
 type RunningState int
@@ -19,13 +21,21 @@ const (
 )
 
 type persistedState struct {
-	SequencerStarted *bool `json:"sequencerStarted,omitempty"`
+	SequencerStarted *bool                   `json:"sequencerStarted,omitempty"`
+	SequencerPolicy  *driver.SequencerPolicy `json:"sequencerPolicy,omitempty"`
+	L1ReorgHalt      *driver.L1Reorg         `json:"l1ReorgHalt,omitempty"`
 }
 
 type ConfigPersistence interface {
 	SequencerStarted() error
 	SequencerStopped() error
+	SequencerPolicyUpdated(policy driver.SequencerPolicy) error
+	L1ReorgHaltUpdated(reorg *driver.L1Reorg) error
 	SequencerState() (RunningState, error)
+	// SequencerPolicy returns the persisted sequencer policy, or nil if there is none.
+	SequencerPolicy() (*driver.SequencerPolicy, error)
+	// L1ReorgHalt returns the persisted L1 reorg that halted derivation and sequencing, or nil if not halted.
+	L1ReorgHalt() (*driver.L1Reorg, error)
 }
 
 var _ ConfigPersistence = (*ActiveConfigPersistence)(nil)
@@ -41,31 +51,62 @@ func NewConfigPersistence(file string) *ActiveConfigPersistence {
 }
 
 func (p *ActiveConfigPersistence) SequencerStarted() error {
-	return p.persist(true)
+	return p.persist(func(state *persistedState) {
+		started := true
+		state.SequencerStarted = &started
+	})
 }
 
 func (p *ActiveConfigPersistence) SequencerStopped() error {
-	return p.persist(false)
+	return p.persist(func(state *persistedState) {
+		started := false
+		state.SequencerStarted = &started
+	})
+}
+
+func (p *ActiveConfigPersistence) SequencerPolicyUpdated(policy driver.SequencerPolicy) error {
+	return p.persist(func(state *persistedState) {
+		state.SequencerPolicy = &policy
+	})
+}
+
+func (p *ActiveConfigPersistence) L1ReorgHaltUpdated(reorg *driver.L1Reorg) error {
+	return p.persist(func(state *persistedState) {
+		state.L1ReorgHalt = reorg
+	})
 }
 
 // persist writes the new config state to the file as safely as possible.
 // It uses sync to ensure the data is actually persisted to disk and initially writes to a temp file
 // before renaming it into place. On UNIX systems this rename is typically atomic, ensuring the
 // actual file isn't corrupted if IO errors occur during writing.
-func (p *ActiveConfigPersistence) persist(sequencerStarted bool) error {
+// The update is applied to the previously persisted state, to retain the values it does not change.
+func (p *ActiveConfigPersistence) persist(update func(state *persistedState)) error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
-	data, err := json.Marshal(persistedState{SequencerStarted: &sequencerStarted})
+	state, err := p.readLocked()
+	if err != nil {
+		return err
+	}
+	update(&state)
+	return writeFileAtomic(p.file, state)
+}
+
+// writeFileAtomic writes the JSON encoding of v to the file.
+// It uses sync to ensure the data is actually persisted to disk and initially writes to a temp file
+// before renaming it into place.
+func writeFileAtomic(path string, v any) error {
+	data, err := json.Marshal(v)
 	if err != nil {
 		return fmt.Errorf("marshall new config: %w", err)
 	}
-	dir := filepath.Dir(p.file)
+	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("create config dir (%v): %w", p.file, err)
+		return fmt.Errorf("create config dir (%v): %w", path, err)
 	}
 	// Write the new content to a temp file first, then rename into place
 	// Avoids corrupting the content if the disk is full or there are IO errors
-	tmpFile := p.file + ".tmp"
+	tmpFile := path + ".tmp"
 	file, err := os.OpenFile(tmpFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return fmt.Errorf("open file (%v) for writing: %w", tmpFile, err)
@@ -81,7 +122,7 @@ func (p *ActiveConfigPersistence) persist(sequencerStarted bool) error {
 		return fmt.Errorf("close new config temp file (%v): %w", tmpFile, err)
 	}
 	// Rename to replace the previous file
-	if err := os.Rename(tmpFile, p.file); err != nil {
+	if err := os.Rename(tmpFile, path); err != nil {
 		return fmt.Errorf("rename temp config file to final destination: %w", err)
 	}
 	return nil
@@ -102,9 +143,29 @@ func (p *ActiveConfigPersistence) SequencerState() (RunningState, error) {
 	}
 }
 
+func (p *ActiveConfigPersistence) SequencerPolicy() (*driver.SequencerPolicy, error) {
+	config, err := p.read()
+	if err != nil {
+		return nil, err
+	}
+	return config.SequencerPolicy, nil
+}
+
+func (p *ActiveConfigPersistence) L1ReorgHalt() (*driver.L1Reorg, error) {
+	config, err := p.read()
+	if err != nil {
+		return nil, err
+	}
+	return config.L1ReorgHalt, nil
+}
+
 func (p *ActiveConfigPersistence) read() (persistedState, error) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
+	return p.readLocked()
+}
+
+func (p *ActiveConfigPersistence) readLocked() (persistedState, error) {
 	data, err := os.ReadFile(p.file)
 	if errors.Is(err, os.ErrNotExist) {
 		// persistedState.SequencerStarted == nil: SequencerState() will return StateUnset if no state is found
@@ -118,9 +179,6 @@ func (p *ActiveConfigPersistence) read() (persistedState, error) {
 	if err = dec.Decode(&config); err != nil {
 		return persistedState{}, fmt.Errorf("invalid config file (%v): %w", p.file, err)
 	}
-	if config.SequencerStarted == nil {
-		return persistedState{}, fmt.Errorf("missing sequencerStarted value in config file (%v)", p.file)
-	}
 	return config, nil
 }
 
@@ -140,3 +198,19 @@ func (d DisabledConfigPersistence) SequencerStarted() error {
 func (d DisabledConfigPersistence) SequencerStopped() error {
 	return nil
 }
+
+func (d DisabledConfigPersistence) SequencerPolicyUpdated(policy driver.SequencerPolicy) error {
+	return nil
+}
+
+func (d DisabledConfigPersistence) SequencerPolicy() (*driver.SequencerPolicy, error) {
+	return nil, nil
+}
+
+func (d DisabledConfigPersistence) L1ReorgHaltUpdated(reorg *driver.L1Reorg) error {
+	return nil
+}
+
+func (d DisabledConfigPersistence) L1ReorgHalt() (*driver.L1Reorg, error) {
+	return nil, nil
+}
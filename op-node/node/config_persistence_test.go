@@ -4,6 +4,13 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
 )
 
 func TestActive(t *testing.T) {
@@ -76,6 +83,53 @@ func TestActive(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, StateStarted, state)
 	})
+
+	t.Run("PersistSequencerPolicy", func(t *testing.T) {
+		config1 := create()
+		policy, err := config1.SequencerPolicy()
+		require.NoError(t, err)
+		require.Nil(t, policy)
+
+		require.NoError(t, config1.SequencerStarted())
+		expected := driver.SequencerPolicy{DepositOnly: true, MaxGasPerBlock: 10_000_000}
+		require.NoError(t, config1.SequencerPolicyUpdated(expected))
+		// Sequencer state changes retain the policy
+		require.NoError(t, config1.SequencerStopped())
+
+		config2 := NewConfigPersistence(config1.file)
+		policy, err = config2.SequencerPolicy()
+		require.NoError(t, err)
+		require.Equal(t, &expected, policy)
+		state, err := config2.SequencerState()
+		require.NoError(t, err)
+		require.Equal(t, StateStopped, state)
+	})
+
+	t.Run("PersistL1ReorgHalt", func(t *testing.T) {
+		config1 := create()
+		reorg, err := config1.L1ReorgHalt()
+		require.NoError(t, err)
+		require.Nil(t, reorg)
+
+		expected := &driver.L1Reorg{
+			OldHead:        eth.L1BlockRef{Hash: common.Hash{0xaa}, Number: 100},
+			NewHead:        eth.L1BlockRef{Hash: common.Hash{0xbb}, Number: 101},
+			CommonAncestor: eth.BlockID{Hash: common.Hash{0xcc}, Number: 90},
+			Depth:          10,
+		}
+		require.NoError(t, config1.L1ReorgHaltUpdated(expected))
+
+		// a restarted node (e.g. a verifier without sequencer state) remains halted
+		cfg := &Config{ConfigPersistence: NewConfigPersistence(config1.file)}
+		require.NoError(t, cfg.LoadPersisted(testlog.Logger(t, log.LvlError)))
+		require.Equal(t, expected, cfg.Driver.L1ReorgHalt)
+
+		// the acknowledgement is persisted too
+		require.NoError(t, config1.L1ReorgHaltUpdated(nil))
+		reorg, err = NewConfigPersistence(config1.file).L1ReorgHalt()
+		require.NoError(t, err)
+		require.Nil(t, reorg)
+	})
 }
 
 func TestDisabledConfigPersistence_AlwaysUnset(t *testing.T) {
@@ -94,3 +148,37 @@ func TestDisabledConfigPersistence_AlwaysUnset(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, StateUnset, state)
 }
+
+func TestLoadPersistedSequencerPolicy(t *testing.T) {
+	persisted := driver.SequencerPolicy{DepositOnly: true, MaxGasPerBlock: 10_000_000}
+	create := func(maxGasPerBlock uint64, set bool) *Config {
+		persistence := NewConfigPersistence(t.TempDir() + "/state")
+		require.NoError(t, persistence.SequencerPolicyUpdated(persisted))
+		return &Config{
+			Driver: driver.Config{
+				SequencerEnabled: true,
+				SequencerPolicy:  driver.SequencerPolicy{MaxGasPerBlock: maxGasPerBlock},
+			},
+			ConfigPersistence:          persistence,
+			SequencerMaxGasPerBlockSet: set,
+		}
+	}
+
+	t.Run("PersistedPolicyWithoutFlag", func(t *testing.T) {
+		cfg := create(0, false)
+		require.NoError(t, cfg.LoadPersisted(testlog.Logger(t, log.LvlError)))
+		require.Equal(t, persisted, cfg.Driver.SequencerPolicy)
+	})
+
+	t.Run("ExplicitFlagTakesPrecedence", func(t *testing.T) {
+		cfg := create(20_000_000, true)
+		require.NoError(t, cfg.LoadPersisted(testlog.Logger(t, log.LvlError)))
+		require.Equal(t, driver.SequencerPolicy{DepositOnly: true, MaxGasPerBlock: 20_000_000}, cfg.Driver.SequencerPolicy)
+	})
+
+	t.Run("ExplicitlyDisabledByFlag", func(t *testing.T) {
+		cfg := create(0, true)
+		require.NoError(t, cfg.LoadPersisted(testlog.Logger(t, log.LvlError)))
+		require.Equal(t, driver.SequencerPolicy{DepositOnly: true}, cfg.Driver.SequencerPolicy)
+	})
+}
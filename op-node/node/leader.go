@@ -0,0 +1,223 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/leader"
+)
+
+const (
+	// leaderCheckInterval is the interval to check if leadership is still held, and to poll the sync status during handover.
+	leaderCheckInterval = time.Second
+	// leaderHandoverTimeout is how long a new leader waits for its unsafe head to catch up with the last gossiped block,
+	// before it gives up leadership again.
+	leaderHandoverTimeout = 30 * time.Second
+	// leaderHandoverBackoff is how long a leader that handed over leadership waits before campaigning again,
+	// to give the other nodes the chance to take over.
+	leaderHandoverBackoff = 30 * time.Second
+)
+
+// sequencerLeader starts and stops the sequencer of the driver, following the leadership of the elector.
+// Only the leader sequences. Leadership is only taken over once the unsafe head includes the last gossiped block.
+type sequencerLeader struct {
+	log     log.Logger
+	elector leader.Elector
+	dr      driverClient
+
+	checkInterval   time.Duration
+	handoverTimeout time.Duration
+	handoverBackoff time.Duration
+
+	// handover receives operator requests to hand over leadership, while leading.
+	handover chan chan handoverResult
+}
+
+type handoverResult struct {
+	lastHead eth.BlockID
+	err      error
+}
+
+func newSequencerLeader(log log.Logger, elector leader.Elector, dr driverClient) *sequencerLeader {
+	return &sequencerLeader{
+		log:             log,
+		elector:         elector,
+		dr:              dr,
+		checkInterval:   leaderCheckInterval,
+		handoverTimeout: leaderHandoverTimeout,
+		handoverBackoff: leaderHandoverBackoff,
+		handover:        make(chan chan handoverResult),
+	}
+}
+
+// OnPublished records the block that the leader gossiped.
+func (l *sequencerLeader) OnPublished(ctx context.Context, id eth.BlockID) {
+	if err := l.elector.RecordHead(ctx, id); err != nil {
+		l.log.Warn("failed to record gossiped block with leader election", "id", id, "err", err)
+	}
+}
+
+// Handover stops sequencing and gives up leadership, so that another node takes over.
+// This node does not campaign again until the handover backoff has passed.
+// It returns the last gossiped block, that the next leader continues from.
+func (l *sequencerLeader) Handover(ctx context.Context) (eth.BlockID, error) {
+	if !l.elector.IsLeader() {
+		return eth.BlockID{}, leader.ErrNotLeader
+	}
+	resp := make(chan handoverResult, 1)
+	select {
+	case l.handover <- resp:
+	case <-ctx.Done():
+		return eth.BlockID{}, ctx.Err()
+	}
+	select {
+	case res := <-resp:
+		return res.lastHead, res.err
+	case <-ctx.Done():
+		return eth.BlockID{}, ctx.Err()
+	}
+}
+
+// Run campaigns for leadership, and sequences while leader, until the context is closed.
+func (l *sequencerLeader) Run(ctx context.Context) {
+	for {
+		l.log.Info("Campaigning for sequencer leadership")
+		if err := l.elector.Campaign(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			l.log.Error("Failed to campaign for sequencer leadership", "err", err)
+			if !sleepCtx(ctx, l.checkInterval) {
+				return
+			}
+			continue
+		}
+		l.log.Info("Acquired sequencer leadership")
+		if err := l.takeOver(ctx); err != nil {
+			l.log.Error("Failed to take over sequencing, resigning leadership", "err", err)
+			l.resign()
+			if !sleepCtx(ctx, l.checkInterval) {
+				return
+			}
+			continue
+		}
+		handoverReq := l.lead(ctx)
+		l.stopSequencer()
+		// Resign also after losing leadership, to campaign from a clean state.
+		l.resign()
+		if handoverReq != nil {
+			lastHead, err := l.elector.LastHead(ctx)
+			handoverReq <- handoverResult{lastHead: lastHead, err: err}
+			l.log.Info("Handed over sequencer leadership", "last_head", lastHead, "backoff", l.handoverBackoff)
+			if !sleepCtx(ctx, l.handoverBackoff) {
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// takeOver waits for the unsafe head to include the last gossiped block, and then starts the sequencer on top of it.
+func (l *sequencerLeader) takeOver(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, l.handoverTimeout)
+	defer cancel()
+	ticker := time.NewTicker(l.checkInterval)
+	defer ticker.Stop()
+	for {
+		lastHead, err := l.elector.LastHead(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get last gossiped block: %w", err)
+		}
+		status, err := l.dr.SyncStatus(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get sync status: %w", err)
+		}
+		if l.includesHead(ctx, status.UnsafeL2, lastHead) {
+			if err := l.dr.StartSequencer(ctx, status.UnsafeL2.Hash); err != nil {
+				return fmt.Errorf("failed to start sequencer at %s: %w", status.UnsafeL2, err)
+			}
+			l.log.Info("Started sequencing as leader", "unsafe_head", status.UnsafeL2, "last_gossiped", lastHead)
+			return nil
+		}
+		l.log.Info("Waiting for unsafe head to include last gossiped block", "unsafe_head", status.UnsafeL2, "last_gossiped", lastHead)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("unsafe head %s did not include last gossiped block %s in time: %w", status.UnsafeL2, lastHead, ctx.Err())
+		}
+	}
+}
+
+// includesHead returns true if the last gossiped block is the unsafe head, or one of its ancestors.
+// A zeroed last head means no leader sequenced before, we can start from any head.
+func (l *sequencerLeader) includesHead(ctx context.Context, unsafe eth.L2BlockRef, lastHead eth.BlockID) bool {
+	if lastHead == (eth.BlockID{}) || unsafe.ID() == lastHead {
+		return true
+	}
+	if unsafe.Number < lastHead.Number {
+		return false
+	}
+	ref, _, err := l.dr.BlockRefWithStatus(ctx, lastHead.Number)
+	if err != nil {
+		l.log.Warn("Failed to get canonical block at height of last gossiped block", "last_gossiped", lastHead, "err", err)
+		return false
+	}
+	return ref.Hash == lastHead.Hash
+}
+
+// lead returns when leadership is lost, when the context is closed, or with the response channel of a handover request.
+func (l *sequencerLeader) lead(ctx context.Context) chan<- handoverResult {
+	ticker := time.NewTicker(l.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !l.elector.IsLeader() {
+				l.log.Warn("Lost sequencer leadership")
+				return nil
+			}
+		case resp := <-l.handover:
+			l.log.Info("Handing over sequencer leadership")
+			return resp
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (l *sequencerLeader) stopSequencer() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if active, err := l.dr.SequencerActive(ctx); err != nil || !active {
+		return
+	}
+	if hash, err := l.dr.StopSequencer(ctx); err != nil {
+		l.log.Error("Failed to stop sequencer after losing leadership", "err", err)
+	} else {
+		l.log.Info("Stopped sequencing", "last_head", hash)
+	}
+}
+
+func (l *sequencerLeader) resign() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := l.elector.Resign(ctx); err != nil {
+		l.log.Error("Failed to resign sequencer leadership", "err", err)
+	}
+}
+
+// sleepCtx sleeps for the given duration, and returns false if the context was closed before that.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,242 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/leader"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+// fakeSequencerDriver is a driver with a canonical unsafe chain, that tracks if the sequencer is active.
+type fakeSequencerDriver struct {
+	driverClient
+
+	mu      sync.Mutex
+	chain   []eth.L2BlockRef
+	active  bool
+	started []common.Hash
+}
+
+func (d *fakeSequencerDriver) SyncStatus(ctx context.Context) (*eth.SyncStatus, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return &eth.SyncStatus{UnsafeL2: d.chain[len(d.chain)-1]}, nil
+}
+
+func (d *fakeSequencerDriver) BlockRefWithStatus(ctx context.Context, num uint64) (eth.L2BlockRef, *eth.SyncStatus, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if num >= uint64(len(d.chain)) {
+		return eth.L2BlockRef{}, nil, errors.New("not found")
+	}
+	return d.chain[num], &eth.SyncStatus{UnsafeL2: d.chain[len(d.chain)-1]}, nil
+}
+
+func (d *fakeSequencerDriver) StartSequencer(ctx context.Context, blockHash common.Hash) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active = true
+	d.started = append(d.started, blockHash)
+	return nil
+}
+
+func (d *fakeSequencerDriver) StopSequencer(ctx context.Context) (common.Hash, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active = false
+	return d.chain[len(d.chain)-1].Hash, nil
+}
+
+func (d *fakeSequencerDriver) SequencerActive(ctx context.Context) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.active, nil
+}
+
+func (d *fakeSequencerDriver) Active() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.active
+}
+
+// extend adds a block to the canonical chain
+func (d *fakeSequencerDriver) extend(rng *rand.Rand) eth.L2BlockRef {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	next := testutils.NextRandomL2Ref(rng, 2, d.chain[len(d.chain)-1], eth.BlockID{})
+	d.chain = append(d.chain, next)
+	return next
+}
+
+func newFakeSequencerDriver(rng *rand.Rand, length int) *fakeSequencerDriver {
+	genesis := testutils.RandomL2BlockRef(rng)
+	genesis.Number = 0
+	d := &fakeSequencerDriver{chain: []eth.L2BlockRef{genesis}}
+	for i := 1; i < length; i++ {
+		d.extend(rng)
+	}
+	return d
+}
+
+func newTestSequencerLeader(t *testing.T, elector leader.Elector, dr driverClient) *sequencerLeader {
+	l := newSequencerLeader(testlog.Logger(t, log.LvlError), elector, dr)
+	l.checkInterval = 10 * time.Millisecond
+	l.handoverTimeout = time.Second
+	l.handoverBackoff = 100 * time.Millisecond
+	return l
+}
+
+func TestSequencerLeaderTakeOver(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	ctx := context.Background()
+
+	t.Run("NoLastHead", func(t *testing.T) {
+		dr := newFakeSequencerDriver(rng, 5)
+		l := newTestSequencerLeader(t, leader.NewLocalElection().Member("a"), dr)
+		require.NoError(t, l.takeOver(ctx))
+		require.Equal(t, []common.Hash{dr.chain[4].Hash}, dr.started)
+	})
+
+	t.Run("LastHeadIsUnsafeHead", func(t *testing.T) {
+		dr := newFakeSequencerDriver(rng, 5)
+		member := leader.NewLocalElection().Member("a")
+		require.NoError(t, member.Campaign(ctx))
+		require.NoError(t, member.RecordHead(ctx, dr.chain[4].ID()))
+		l := newTestSequencerLeader(t, member, dr)
+		require.NoError(t, l.takeOver(ctx))
+		require.Equal(t, []common.Hash{dr.chain[4].Hash}, dr.started)
+	})
+
+	t.Run("LastHeadIsAncestor", func(t *testing.T) {
+		dr := newFakeSequencerDriver(rng, 5)
+		member := leader.NewLocalElection().Member("a")
+		require.NoError(t, member.Campaign(ctx))
+		require.NoError(t, member.RecordHead(ctx, dr.chain[2].ID()))
+		l := newTestSequencerLeader(t, member, dr)
+		require.NoError(t, l.takeOver(ctx))
+		require.Equal(t, []common.Hash{dr.chain[4].Hash}, dr.started, "start on top of the unsafe head")
+	})
+
+	t.Run("WaitForUnsafeHead", func(t *testing.T) {
+		dr := newFakeSequencerDriver(rng, 5)
+		member := leader.NewLocalElection().Member("a")
+		require.NoError(t, member.Campaign(ctx))
+		l := newTestSequencerLeader(t, member, dr)
+
+		// the last gossiped block is not synced yet
+		next := testutils.NextRandomL2Ref(rng, 2, dr.chain[4], eth.BlockID{})
+		require.NoError(t, member.RecordHead(ctx, next.ID()))
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			dr.mu.Lock()
+			dr.chain = append(dr.chain, next)
+			dr.mu.Unlock()
+		}()
+		require.NoError(t, l.takeOver(ctx))
+		require.Equal(t, []common.Hash{next.Hash}, dr.started)
+	})
+
+	t.Run("DivergedUnsafeHead", func(t *testing.T) {
+		dr := newFakeSequencerDriver(rng, 5)
+		member := leader.NewLocalElection().Member("a")
+		require.NoError(t, member.Campaign(ctx))
+		l := newTestSequencerLeader(t, member, dr)
+		l.handoverTimeout = 100 * time.Millisecond
+
+		// the last gossiped block conflicts with the local chain
+		conflict := testutils.NextRandomL2Ref(rng, 2, dr.chain[1], eth.BlockID{})
+		require.NoError(t, member.RecordHead(ctx, conflict.ID()))
+		require.ErrorIs(t, l.takeOver(ctx), context.DeadlineExceeded)
+		require.Empty(t, dr.started)
+	})
+}
+
+func TestSequencerLeaderHandover(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	election := leader.NewLocalElection()
+	drA := newFakeSequencerDriver(rng, 5)
+	drB := &fakeSequencerDriver{chain: drA.chain}
+	a := newTestSequencerLeader(t, election.Member("a"), drA)
+	b := newTestSequencerLeader(t, election.Member("b"), drB)
+
+	_, err := a.Handover(ctx)
+	require.ErrorIs(t, err, leader.ErrNotLeader, "cannot hand over before leading")
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.Run(ctx)
+	}()
+	require.Eventually(t, drA.Active, time.Second, 10*time.Millisecond, "a sequences as leader")
+
+	// b only starts campaigning once a leads
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		b.Run(ctx)
+	}()
+
+	// a sequences and gossips a block, which b receives
+	head := drA.extend(rng)
+	a.OnPublished(ctx, head.ID())
+	drB.mu.Lock()
+	drB.chain = append(drB.chain[:len(drB.chain):len(drB.chain)], head)
+	drB.mu.Unlock()
+
+	handoverCtx, handoverCancel := context.WithTimeout(ctx, time.Second)
+	defer handoverCancel()
+	lastHead, err := a.Handover(handoverCtx)
+	require.NoError(t, err)
+	require.Equal(t, head.ID(), lastHead)
+	require.False(t, drA.Active(), "a stops sequencing")
+
+	require.Eventually(t, drB.Active, time.Second, 10*time.Millisecond, "b takes over")
+	require.Equal(t, []common.Hash{head.Hash}, drB.started)
+	require.Equal(t, "b", election.Leader())
+}
+
+func TestSequencerLeaderLostLeadership(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	election := leader.NewLocalElection()
+	member := election.Member("a")
+	dr := newFakeSequencerDriver(rng, 5)
+	l := newTestSequencerLeader(t, member, dr)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Run(ctx)
+	}()
+	require.Eventually(t, dr.Active, time.Second, 10*time.Millisecond)
+
+	// Another node takes over: the sequencer must stop.
+	require.NoError(t, member.Resign(ctx))
+	other := election.Member("b")
+	require.NoError(t, other.Campaign(ctx))
+	require.Eventually(t, func() bool { return !dr.Active() }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+	require.False(t, dr.Active())
+}
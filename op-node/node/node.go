@@ -8,6 +8,7 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"golang.org/x/exp/slices"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/event"
@@ -15,9 +16,12 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-node/client"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/leader"
 	"github.com/ethereum-optimism/optimism/op-node/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/node/safedb"
 	"github.com/ethereum-optimism/optimism/op-node/p2p"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 	"github.com/ethereum-optimism/optimism/op-node/sources"
 )
@@ -26,21 +30,30 @@ type OpNode struct {
 	log        log.Logger
 	appVersion string
 	metrics    *metrics.Metrics
+	rollupCfg  *rollup.Config
 
 	l1HeadsSub     ethereum.Subscription // Subscription to get L1 heads (automatically re-subscribes on error)
 	l1SafeSub      ethereum.Subscription // Subscription to get L1 safe blocks, a.k.a. justified data (polling)
 	l1FinalizedSub ethereum.Subscription // Subscription to get L1 safe blocks, a.k.a. justified data (polling)
 
-	l1Source  *sources.L1Client     // L1 Client to fetch data from
-	l2Driver  *driver.Driver        // L2 Engine to Sync
-	l2Source  *sources.EngineClient // L2 Execution Engine RPC bindings
-	rpcSync   *sources.SyncClient   // Alt-sync RPC client, optional (may be nil)
-	server    *rpcServer            // RPC server hosting the rollup-node API
-	p2pNode   *p2p.NodeP2P          // P2P node functionality
-	p2pSigner p2p.Signer            // p2p gogssip application messages will be signed with this signer
-	tracer    Tracer                // tracer to get events for testing/debugging
-	runCfg    *RuntimeConfig        // runtime configurables
-	daCfg     *rollup.DAConfig
+	l1Source       *sources.L1Client     // L1 Client to fetch data from
+	l2Driver       *driver.Driver        // L2 Engine to Sync
+	l2Source       *sources.EngineClient // L2 Execution Engine RPC bindings
+	rpcSync        *sources.SyncClient   // Alt-sync RPC client, optional (may be nil)
+	server         *rpcServer            // RPC server hosting the rollup-node API
+	p2pNode        *p2p.NodeP2P          // P2P node functionality
+	p2pSigner      p2p.Signer            // p2p gogssip application messages will be signed with this signer
+	safeHeadAtt    *safeHeadAttestations // safe-head attestations tracking, optional (may be nil)
+	tracer         Tracer                // tracer to get events for testing/debugging
+	pipelineTracer *pipelineTracer       // derivation pipeline trace events, optional (may be nil)
+	snapshotFeed   *snapshotFeed         // driver snapshots for debug RPC subscribers, optional (may be nil)
+	safeDB         *safedb.SafeDB        // L1 derivation details of safe L2 blocks, optional (may be nil)
+	blockFeed      *p2p.BlockFeed        // signed unsafe blocks served over RPC, optional (may be nil)
+	blockFeedCl    *p2p.BlockFeedClient  // unsafe blocks followed over RPC as alternative to gossip, optional (may be nil)
+	elector        leader.Elector        // sequencer leader election, optional (may be nil)
+	seqLeader      *sequencerLeader      // starts and stops the sequencer following the leader election, optional (may be nil)
+	runCfg         *RuntimeConfig        // runtime configurables
+	daCfg          *rollup.DAConfig
 
 	// some resources cannot be stopped directly, like the p2p gossipsub router (not our design),
 	// and depend on this ctx to be closed.
@@ -60,6 +73,7 @@ func New(ctx context.Context, cfg *Config, log log.Logger, snapshotLog log.Logge
 		log:        log,
 		appVersion: appVersion,
 		metrics:    m,
+		rollupCfg:  &cfg.Rollup,
 	}
 	// not a context leak, gossipsub is closed with a context.
 	n.resourcesCtx, n.resourcesClose = context.WithCancel(context.Background())
@@ -94,6 +108,15 @@ func (n *OpNode) init(ctx context.Context, cfg *Config, snapshotLog log.Logger)
 	if err := n.initRuntimeConfig(ctx, cfg); err != nil {
 		return err
 	}
+	if err := n.initPipelineTracer(ctx, cfg); err != nil {
+		return err
+	}
+	if err := n.initSafeDB(ctx, cfg); err != nil {
+		return err
+	}
+	if err := n.initSequencerLeader(ctx, cfg); err != nil {
+		return err
+	}
 	if err := n.initL2(ctx, cfg, snapshotLog); err != nil {
 		return err
 	}
@@ -106,6 +129,9 @@ func (n *OpNode) init(ctx context.Context, cfg *Config, snapshotLog log.Logger)
 	if err := n.initP2P(ctx, cfg); err != nil {
 		return err
 	}
+	if err := n.initBlockFeed(ctx, cfg); err != nil {
+		return err
+	}
 	// Only expose the server at the end, ensuring all RPC backend components are initialized.
 	if err := n.initRPCServer(ctx, cfg); err != nil {
 		return err
@@ -125,8 +151,54 @@ func (n *OpNode) initTracer(ctx context.Context, cfg *Config) error {
 	return nil
 }
 
+func (n *OpNode) initPipelineTracer(ctx context.Context, cfg *Config) error {
+	if !cfg.RPC.EnableDebug && cfg.PipelineTraceFile == "" {
+		return nil
+	}
+	tracer, err := newPipelineTracer(n.log, cfg.PipelineTraceFile)
+	if err != nil {
+		return err
+	}
+	n.pipelineTracer = tracer
+	return nil
+}
+
+func (n *OpNode) initSafeDB(ctx context.Context, cfg *Config) error {
+	if !cfg.SafeDB.Enabled() {
+		return nil
+	}
+	db, err := safedb.Open(n.log.New("module", "safedb"), &cfg.SafeDB)
+	if err != nil {
+		return err
+	}
+	n.safeDB = db
+	n.log.Info("Safe head DB enabled", "path", cfg.SafeDB.Path, "retention", cfg.SafeDB.Retention)
+	return nil
+}
+
+func (n *OpNode) initSequencerLeader(ctx context.Context, cfg *Config) error {
+	if cfg.SequencerLeader == nil {
+		return nil
+	}
+	elector, err := cfg.SequencerLeader.SetupElector(ctx, n.log.New("leader", "sequencer"))
+	if err != nil {
+		return fmt.Errorf("failed to setup sequencer leader election: %w", err)
+	}
+	if elector == nil {
+		return nil
+	}
+	if !cfg.Driver.SequencerEnabled {
+		_ = elector.Close()
+		return errors.New("sequencer leader election requires the sequencer to be enabled")
+	}
+	// The sequencer only starts once this node is elected as leader.
+	cfg.Driver.SequencerStopped = true
+	n.elector = elector
+	return nil
+}
+
 func (n *OpNode) initL1(ctx context.Context, cfg *Config) error {
-	l1Node, rpcCfg, err := cfg.L1.Setup(ctx, n.log, &cfg.Rollup)
+	l1Node, rpcCfg, err := cfg.L1.Setup(ctx, n.log, &cfg.Rollup, n.metrics)
 	if err != nil {
 		return fmt.Errorf("failed to get L1 RPC client: %w", err)
 	}
@@ -199,7 +271,7 @@ func (n *OpNode) initL2(ctx context.Context, cfg *Config, snapshotLog log.Logger
 	}
 
 	n.l2Source, err = sources.NewEngineClient(
-		client.NewInstrumentedRPC(rpcClient, n.metrics), n.log, n.metrics.L2SourceCache, rpcCfg,
+		client.NewInstrumentedRPC(rpcClient, n.metrics), n.log, n.metrics.L2SourceCache, n.metrics, rpcCfg,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create Engine client: %w", err)
@@ -209,7 +281,15 @@ func (n *OpNode) initL2(ctx context.Context, cfg *Config, snapshotLog log.Logger
 		return err
 	}
 
-	n.l2Driver = driver.NewDriver(&cfg.Driver, &cfg.Rollup, n.daCfg, n.l2Source, n.l1Source, n, n, n.log, snapshotLog, n.metrics, cfg.ConfigPersistence)
+	if cfg.RPC.EnableDebug {
+		n.snapshotFeed = newSnapshotFeed(n.log)
+		snapshotLog = n.snapshotFeed.Wrap(snapshotLog)
+	}
+
+	n.l2Driver = driver.NewDriver(&cfg.Driver, &cfg.Rollup, n.daCfg, n.l2Source, n.l1Source, n, n, n.log, snapshotLog, n.metrics, n.derivationTracer(), cfg.ConfigPersistence)
+	if n.elector != nil {
+		n.seqLeader = newSequencerLeader(n.log.New("leader", "sequencer"), n.elector, n.l2Driver)
+	}
 
 	return nil
 }
@@ -231,7 +311,11 @@ func (n *OpNode) initRPCSync(ctx context.Context, cfg *Config) error {
 }
 
 func (n *OpNode) initRPCServer(ctx context.Context, cfg *Config) error {
-	server, err := newRPCServer(ctx, &cfg.RPC, &cfg.Rollup, n.l2Source.L2Client, n.l2Driver, n.log, n.appVersion, n.metrics)
+	var dr driverClient = n.l2Driver
+	if n.safeHeadAtt != nil {
+		dr = &attestedDriverClient{driverClient: n.l2Driver, att: n.safeHeadAtt}
+	}
+	server, err := newRPCServer(ctx, &cfg.RPC, &cfg.Rollup, n.l2Source.L2Client, dr, n.log, n.appVersion, n.metrics)
 	if err != nil {
 		return err
 	}
@@ -239,9 +323,24 @@ func (n *OpNode) initRPCServer(ctx context.Context, cfg *Config) error {
 		server.EnableP2P(p2p.NewP2PAPIBackend(n.p2pNode, n.log, n.metrics))
 	}
 	if cfg.RPC.EnableAdmin {
-		server.EnableAdminAPI(NewAdminAPI(n.l2Driver, n.metrics))
+		var leadership sequencerLeadership
+		if n.seqLeader != nil {
+			leadership = n.seqLeader
+		}
+		server.EnableAdminAPI(NewAdminAPI(n.l2Driver, leadership, n.metrics))
 		n.log.Info("Admin RPC enabled")
 	}
+	if cfg.RPC.EnableDebug {
+		server.EnableDebugAPI(NewDebugAPI(n.pipelineTracer, n.snapshotFeed, n.metrics))
+		n.log.Info("Debug RPC enabled")
+	}
+	if n.safeDB != nil {
+		server.EnableSafeDBAPI(NewSafeDBAPI(n.safeDB, n.metrics))
+	}
+	if n.blockFeed != nil {
+		server.EnableBlockFeedAPI(NewBlockFeedAPI(n.blockFeed, n.metrics))
+		n.log.Info("Unsafe block feed RPC enabled")
+	}
 	n.log.Info("Starting JSON-RPC server")
 	if err := server.Start(); err != nil {
 		return fmt.Errorf("unable to start RPC server: %w", err)
@@ -271,6 +370,16 @@ func (n *OpNode) initP2P(ctx context.Context, cfg *Config) error {
 			return err
 		}
 		n.p2pNode = p2pNode
+		if attOut := n.p2pNode.SafeHeadAttestationsOut(); attOut != nil {
+			isAttester := n.p2pNode.IsStatic
+			if attesters := cfg.SafeHeadAttestations.Attesters; len(attesters) > 0 {
+				isAttester = func(id peer.ID) bool {
+					return slices.Contains(attesters, id)
+				}
+			}
+			n.safeHeadAtt = newSafeHeadAttestations(n.log.New("attestations", "safe-head"), &cfg.Rollup, n.metrics,
+				n.l2Source.L2Client, n.l2Driver, attOut, isAttester, cfg.SafeHeadAttestations.Path)
+		}
 		if n.p2pNode.Dv5Udp() != nil {
 			go n.p2pNode.DiscoveryProcess(n.resourcesCtx, n.log, &cfg.Rollup, cfg.P2P.TargetPeers())
 		}
@@ -278,6 +387,16 @@ func (n *OpNode) initP2P(ctx context.Context, cfg *Config) error {
 	return nil
 }
 
+func (n *OpNode) initBlockFeed(ctx context.Context, cfg *Config) error {
+	if cfg.RPC.EnableBlockFeed {
+		n.blockFeed = p2p.NewBlockFeed(n.log.New("feed", "unsafe-blocks"), &cfg.Rollup)
+	}
+	if cfg.BlockFeed.Enabled() {
+		n.blockFeedCl = p2p.NewBlockFeedClient(n.log.New("feed", "unsafe-blocks"), &cfg.Rollup, n.runCfg, &cfg.BlockFeed, n)
+	}
+	return nil
+}
+
 func (n *OpNode) initP2PSigner(ctx context.Context, cfg *Config) error {
 	// the p2p signer setup is optional
 	if cfg.P2PSigner == nil {
@@ -307,6 +426,24 @@ func (n *OpNode) Start(ctx context.Context) error {
 		n.log.Info("Started L2-RPC sync service")
 	}
 
+	// If the unsafe block feed client is enabled, follow the feed alongside gossip
+	if n.blockFeedCl != nil {
+		n.blockFeedCl.Start()
+		n.log.Info("Started unsafe block feed client")
+	}
+
+	// If leader election is enabled, campaign for leadership to start sequencing
+	if n.seqLeader != nil {
+		go n.seqLeader.Run(n.resourcesCtx)
+		n.log.Info("Started sequencer leader election")
+	}
+
+	// If safe-head attestations are enabled, start attesting to the local safe head
+	if n.safeHeadAtt != nil {
+		go n.safeHeadAtt.Run(n.resourcesCtx)
+		n.log.Info("Started safe head attestations")
+	}
+
 	return nil
 }
 
@@ -351,16 +488,35 @@ func (n *OpNode) OnNewL1Finalized(ctx context.Context, sig eth.L1BlockRef) {
 func (n *OpNode) PublishL2Payload(ctx context.Context, payload *eth.ExecutionPayload) error {
 	n.tracer.OnPublishL2Payload(ctx, payload)
 
+	// if neither p2p nor the unsafe block feed is enabled then we just don't publish the payload
+	if n.p2pNode == nil && n.blockFeed == nil {
+		return nil
+	}
+	if n.p2pSigner == nil {
+		return fmt.Errorf("node has no p2p signer, payload %s cannot be published", payload.ID())
+	}
+	// sign once, the same message is gossiped and added to the unsafe block feed
+	msg, err := p2p.SignL2Payload(ctx, n.rollupCfg, payload, n.p2pSigner)
+	if err != nil {
+		return err
+	}
 	// publish to p2p, if we are running p2p at all
+	var gossipErr error
 	if n.p2pNode != nil {
-		if n.p2pSigner == nil {
-			return fmt.Errorf("node has no p2p signer, payload %s cannot be published", payload.ID())
-		}
 		n.log.Info("Publishing signed execution payload on p2p", "id", payload.ID())
-		return n.p2pNode.GossipOut().PublishL2Payload(ctx, payload, n.p2pSigner)
+		if gossipErr = n.p2pNode.GossipOut().PublishSignedL2Payload(ctx, msg); gossipErr != nil {
+			n.log.Warn("Failed to publish signed execution payload on p2p", "id", payload.ID(), "err", gossipErr)
+		}
 	}
-	// if p2p is not enabled then we just don't publish the payload
-	return nil
+	// publish to the unsafe block feed, for verifiers that cannot join the gossip topic, also if gossip failed
+	if n.blockFeed != nil {
+		n.blockFeed.PublishSignedL2Payload(uint64(payload.BlockNumber), msg)
+	}
+	// record the published block, so a standby sequencer knows where to take over
+	if n.seqLeader != nil && (gossipErr == nil || n.blockFeed != nil) {
+		n.seqLeader.OnPublished(ctx, payload.ID())
+	}
+	return gossipErr
 }
 
 func (n *OpNode) OnUnsafeL2Payload(ctx context.Context, from peer.ID, payload *eth.ExecutionPayload) error {
@@ -383,6 +539,16 @@ func (n *OpNode) OnUnsafeL2Payload(ctx context.Context, from peer.ID, payload *e
 	return nil
 }
 
+func (n *OpNode) OnSafeHeadAttestation(ctx context.Context, from peer.ID, att *p2p.SignedSafeHeadAttestation) error {
+	// ignore if it's from ourselves, or if we are not tracking attestations (yet)
+	if n.safeHeadAtt == nil || (n.p2pNode != nil && att.Attester == n.p2pNode.Host().ID()) {
+		return nil
+	}
+	n.log.Debug("Received safe head attestation from p2p", "attester", att.Attester, "l2_block", att.L2Block, "output_root", att.OutputRoot, "peer", from)
+	n.safeHeadAtt.OnAttestation(att)
+	return nil
+}
+
 func (n *OpNode) RequestL2Range(ctx context.Context, start, end eth.L2BlockRef) error {
 	if n.rpcSync != nil {
 		return n.rpcSync.RequestL2Range(ctx, start, end)
@@ -403,6 +569,26 @@ func unixTimeStale(timestamp uint64, duration time.Duration) bool {
 	return time.Unix(int64(timestamp), 0).Before(time.Now().Add(-1 * duration))
 }
 
+// derivationTracer returns the tracer for the derivation pipeline, a no-op tracer if tracing is disabled.
+// The safe head DB is fed by the trace events too.
+func (n *OpNode) derivationTracer() derive.Tracer {
+	var out multiTracer
+	if n.pipelineTracer != nil {
+		out = append(out, n.pipelineTracer)
+	}
+	if n.safeDB != nil {
+		out = append(out, n.safeDB)
+	}
+	switch len(out) {
+	case 0:
+		return derive.NoopTracer
+	case 1:
+		return out[0]
+	default:
+		return out
+	}
+}
+
 func (n *OpNode) P2P() p2p.Node {
 	return n.p2pNode
 }
@@ -446,6 +632,33 @@ func (n *OpNode) Close() error {
 				result = multierror.Append(result, fmt.Errorf("failed to close L2 engine backup sync client cleanly: %w", err))
 			}
 		}
+
+		if n.blockFeedCl != nil {
+			if err := n.blockFeedCl.Close(); err != nil {
+				result = multierror.Append(result, fmt.Errorf("failed to close unsafe block feed client cleanly: %w", err))
+			}
+		}
+	}
+
+	// release sequencer leadership, after the driver stopped sequencing
+	if n.elector != nil {
+		if err := n.elector.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close sequencer leader election: %w", err))
+		}
+	}
+
+	// close pipeline trace file, after the driver stopped emitting events
+	if n.pipelineTracer != nil {
+		if err := n.pipelineTracer.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close pipeline tracer: %w", err))
+		}
+	}
+
+	// close safe head DB, after the driver stopped emitting events
+	if n.safeDB != nil {
+		if err := n.safeDB.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close safe head DB: %w", err))
+		}
 	}
 
 	// close L2 engine RPC client
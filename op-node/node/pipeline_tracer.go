@@ -0,0 +1,157 @@
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+const (
+	// pipelineSubBuffer is the number of trace events buffered per subscriber.
+	// Events are dropped for subscribers that do not keep up, rather than stalling the derivation pipeline.
+	pipelineSubBuffer = 1024
+	// pipelineEventsBuffer is the number of trace events buffered for the writer routine.
+	// Events are dropped if the trace file or the subscribers do not keep up.
+	pipelineEventsBuffer = 4096
+)
+
+// pipelineTracer distributes the trace events of the derivation pipeline to debug RPC subscribers,
+// and optionally appends them to a JSONL file.
+// Events are handed off to a writer routine, so emitting events never blocks the derivation pipeline.
+type pipelineTracer struct {
+	log log.Logger
+
+	events  chan derive.TraceEvent
+	dropped atomic.Uint64
+	closing chan struct{}
+	done    chan struct{}
+
+	mu   sync.Mutex
+	subs map[chan derive.TraceEvent]struct{}
+
+	file    *os.File
+	fileEnc *json.Encoder
+}
+
+var _ derive.Tracer = (*pipelineTracer)(nil)
+
+// newPipelineTracer creates a pipeline tracer. Events are appended to the given file path, if not empty.
+func newPipelineTracer(log log.Logger, path string) (*pipelineTracer, error) {
+	t := &pipelineTracer{
+		log:     log,
+		events:  make(chan derive.TraceEvent, pipelineEventsBuffer),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+		subs:    make(map[chan derive.TraceEvent]struct{}),
+	}
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open pipeline trace file: %w", err)
+		}
+		t.file = f
+		t.fileEnc = json.NewEncoder(f)
+	}
+	go t.writeLoop()
+	return t, nil
+}
+
+// OnPipelineEvent queues the event for the writer routine, or drops it if the queue is full.
+func (t *pipelineTracer) OnPipelineEvent(ev derive.TraceEvent) {
+	select {
+	case t.events <- ev:
+	default:
+		if t.dropped.Add(1) == 1 {
+			t.log.Warn("dropping pipeline trace events, the trace writer does not keep up", "stage", ev.Stage, "kind", ev.Kind)
+		}
+	}
+}
+
+// Dropped returns the number of trace events that were dropped because the writer did not keep up.
+func (t *pipelineTracer) Dropped() uint64 {
+	return t.dropped.Load()
+}
+
+func (t *pipelineTracer) writeLoop() {
+	defer close(t.done)
+	for {
+		select {
+		case ev := <-t.events:
+			t.write(ev)
+		case <-t.closing:
+			// write the remaining queued events before returning
+			for {
+				select {
+				case ev := <-t.events:
+					t.write(ev)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (t *pipelineTracer) write(ev derive.TraceEvent) {
+	t.mu.Lock()
+	for ch := range t.subs {
+		select {
+		case ch <- ev:
+		default:
+			t.log.Debug("dropping pipeline trace event for slow subscriber", "stage", ev.Stage, "kind", ev.Kind)
+		}
+	}
+	t.mu.Unlock()
+	if t.fileEnc != nil {
+		// one JSON object per line
+		if err := t.fileEnc.Encode(&ev); err != nil {
+			t.log.Warn("failed to write pipeline trace event", "err", err)
+		}
+	}
+}
+
+// Subscribe returns a channel with all future trace events, and a function to end the subscription with.
+func (t *pipelineTracer) Subscribe() (<-chan derive.TraceEvent, func()) {
+	ch := make(chan derive.TraceEvent, pipelineSubBuffer)
+	t.mu.Lock()
+	t.subs[ch] = struct{}{}
+	t.mu.Unlock()
+	return ch, func() {
+		t.mu.Lock()
+		delete(t.subs, ch)
+		t.mu.Unlock()
+	}
+}
+
+// Close stops the writer routine, after it wrote the queued events, and closes the trace file.
+// Events emitted after closing are dropped.
+func (t *pipelineTracer) Close() error {
+	select {
+	case <-t.closing:
+		return nil
+	default:
+		close(t.closing)
+	}
+	<-t.done
+	if t.file == nil {
+		return nil
+	}
+	err := t.file.Close()
+	t.file, t.fileEnc = nil, nil
+	return err
+}
+
+// multiTracer forwards the trace events of the derivation pipeline to all of the tracers.
+type multiTracer []derive.Tracer
+
+func (m multiTracer) OnPipelineEvent(ev derive.TraceEvent) {
+	for _, t := range m {
+		t.OnPipelineEvent(ev)
+	}
+}
@@ -0,0 +1,84 @@
+package node
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func TestPipelineTracer(t *testing.T) {
+	event := func(i int) derive.TraceEvent {
+		return derive.TraceEvent{
+			Time:  time.Unix(int64(i), 0).UTC(),
+			Stage: derive.StageBatchQueue,
+			Kind:  derive.EventBatchAccepted,
+		}
+	}
+
+	t.Run("WriteFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "trace.jsonl")
+		tracer, err := newPipelineTracer(testlog.Logger(t, log.LvlError), path)
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			tracer.OnPipelineEvent(event(i))
+		}
+		// closing writes the queued events
+		require.NoError(t, tracer.Close())
+
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		var i int
+		for scanner.Scan() {
+			var ev derive.TraceEvent
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev))
+			require.Equal(t, event(i), ev)
+			i++
+		}
+		require.NoError(t, scanner.Err())
+		require.Equal(t, 10, i)
+	})
+
+	t.Run("Subscribe", func(t *testing.T) {
+		tracer, err := newPipelineTracer(testlog.Logger(t, log.LvlError), "")
+		require.NoError(t, err)
+		defer tracer.Close()
+		events, unsubscribe := tracer.Subscribe()
+		tracer.OnPipelineEvent(event(1))
+		select {
+		case ev := <-events:
+			require.Equal(t, event(1), ev)
+		case <-time.After(10 * time.Second):
+			t.Fatal("expected trace event")
+		}
+
+		unsubscribe()
+		tracer.OnPipelineEvent(event(2))
+		require.NoError(t, tracer.Close())
+		require.Empty(t, events)
+	})
+
+	t.Run("DropWhenFull", func(t *testing.T) {
+		// no writer routine, to fill up the queue
+		tracer := &pipelineTracer{
+			log:    testlog.Logger(t, log.LvlError),
+			events: make(chan derive.TraceEvent, 2),
+		}
+		for i := 0; i < 5; i++ {
+			tracer.OnPipelineEvent(event(i))
+		}
+		require.Len(t, tracer.events, 2)
+		require.Equal(t, uint64(3), tracer.Dropped())
+	})
+}
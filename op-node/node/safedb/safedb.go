@@ -0,0 +1,361 @@
+// Package safedb persists, per safe L2 block, how it was derived from L1:
+// the L1 block it was derived from, the L1 inclusion block of its batch, and the DA frame references of the batch.
+package safedb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	ds "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	dssync "github.com/ipfs/go-datastore/sync"
+	leveldb "github.com/ipfs/go-ds-leveldb"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+)
+
+const (
+	l2Prefix = "/l2"
+	l1Prefix = "/l1"
+)
+
+// l2Key is the key of the record of the given safe L2 block.
+func l2Key(l2Num uint64) ds.Key {
+	return ds.NewKey(fmt.Sprintf("%s/%016x", l2Prefix, l2Num))
+}
+
+// l1Key is the key that indexes the record of the given L2 block by the L1 block it was derived from.
+func l1Key(l1Num uint64, l2Num uint64) ds.Key {
+	return ds.NewKey(fmt.Sprintf("%s/%016x/%016x", l1Prefix, l1Num, l2Num))
+}
+
+// parseNum parses the last hex-encoded number of a key.
+func parseNum(key string) (uint64, error) {
+	return strconv.ParseUint(key[strings.LastIndex(key, "/")+1:], 16, 64)
+}
+
+// Config configures the safe head DB.
+type Config struct {
+	// Path is the location of the database. The database is disabled if empty,
+	// and kept in memory only if set to "memory".
+	Path string
+	// Retention is the number of most recent safe L2 blocks to keep the records of. All records are kept if 0.
+	Retention uint64
+}
+
+func (c *Config) Enabled() bool {
+	return c.Path != ""
+}
+
+type pendingBatch struct {
+	parentHash common.Hash
+	inclusion  eth.BlockID
+	generated  bool
+}
+
+type pendingDA struct {
+	number uint64
+	refs   []eth.DAFrameRef
+}
+
+// SafeDB records the derivation of safe L2 blocks, based on the trace events of the derivation pipeline.
+// The records of L2 blocks after the safe head are removed when the derivation pipeline resets.
+type SafeDB struct {
+	log       log.Logger
+	store     ds.Batching
+	retention uint64
+
+	mu sync.Mutex
+	// latest is the number of the latest recorded L2 block, 0 if there is none
+	latest uint64
+	// batches are the batches that were accepted or generated, but did not become safe yet, by timestamp
+	batches map[uint64]pendingBatch
+	// da are the DA frame references resolved from the batcher transactions of L1 blocks, by L1 block hash
+	da map[common.Hash]*pendingDA
+}
+
+var _ derive.Tracer = (*SafeDB)(nil)
+
+// Open opens the safe head DB, and prunes it to the configured retention.
+func Open(log log.Logger, cfg *Config) (*SafeDB, error) {
+	var store ds.Batching
+	if cfg.Path == "memory" {
+		store = dssync.MutexWrap(ds.NewMapDatastore())
+	} else {
+		var err error
+		store, err = leveldb.NewDatastore(cfg.Path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open leveldb db for safe head records: %w", err)
+		}
+	}
+	return New(log, store, cfg.Retention)
+}
+
+// New creates a safe head DB on top of the given datastore, and prunes it to the given retention.
+func New(log log.Logger, store ds.Batching, retention uint64) (*SafeDB, error) {
+	db := &SafeDB{
+		log:       log,
+		store:     store,
+		retention: retention,
+		batches:   make(map[uint64]pendingBatch),
+		da:        make(map[common.Hash]*pendingDA),
+	}
+	if err := db.init(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// init finds the latest record, and removes the records that are older than the retention allows.
+func (db *SafeDB) init() error {
+	ctx := context.Background()
+	res, err := db.store.Query(ctx, query.Query{Prefix: l2Prefix, KeysOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to query safe head records: %w", err)
+	}
+	entries, err := res.Rest()
+	if err != nil {
+		return fmt.Errorf("failed to read safe head records: %w", err)
+	}
+	var nums []uint64
+	for _, e := range entries {
+		n, err := parseNum(e.Key)
+		if err != nil {
+			return fmt.Errorf("invalid safe head record key %q: %w", e.Key, err)
+		}
+		nums = append(nums, n)
+		if n > db.latest {
+			db.latest = n
+		}
+	}
+	if db.retention == 0 || db.latest < db.retention {
+		return nil
+	}
+	b, err := db.store.Batch(ctx)
+	if err != nil {
+		return err
+	}
+	pruned := 0
+	for _, n := range nums {
+		if n+db.retention <= db.latest {
+			if err := db.deleteRecord(ctx, b, n); err != nil {
+				return err
+			}
+			pruned++
+		}
+	}
+	if err := b.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to prune safe head records: %w", err)
+	}
+	if pruned > 0 {
+		db.log.Info("pruned safe head records", "count", pruned, "latest", db.latest, "retention", db.retention)
+	}
+	return nil
+}
+
+func (db *SafeDB) OnPipelineEvent(ev derive.TraceEvent) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	switch ev.Kind {
+	case derive.EventDataResolved:
+		if ev.DA == nil {
+			return
+		}
+		pending, ok := db.da[ev.Origin.Hash]
+		if !ok {
+			pending = &pendingDA{number: ev.Origin.Number}
+			db.da[ev.Origin.Hash] = pending
+		}
+		for _, ref := range pending.refs {
+			// the data of an L1 block may be resolved again, after a temporary error
+			if ref.Height == ev.DA.Height && bytes.Equal(ref.Commitment, ev.DA.Commitment) {
+				return
+			}
+		}
+		pending.refs = append(pending.refs, eth.DAFrameRef{Height: ev.DA.Height, Commitment: ev.DA.Commitment})
+	case derive.EventBatchAccepted, derive.EventBatchGenerated:
+		if ev.Batch == nil {
+			return
+		}
+		db.batches[ev.Batch.Timestamp] = pendingBatch{
+			parentHash: ev.Batch.ParentHash,
+			inclusion:  ev.Batch.L1InclusionBlock,
+			generated:  ev.Kind == derive.EventBatchGenerated,
+		}
+	case derive.EventSafeHeadUpdated:
+		if ev.SafeHead == nil {
+			return
+		}
+		if err := db.record(ev.Origin, *ev.SafeHead); err != nil {
+			db.log.Error("failed to record safe head", "safe_head", ev.SafeHead, "derived_from", ev.Origin, "err", err)
+		}
+	case derive.EventSafeHeadReset:
+		if ev.SafeHead == nil {
+			return
+		}
+		db.batches = make(map[uint64]pendingBatch)
+		db.da = make(map[common.Hash]*pendingDA)
+		if err := db.truncate(ev.SafeHead.Number); err != nil {
+			db.log.Error("failed to remove safe head records after reset", "safe_head", ev.SafeHead, "err", err)
+		}
+	}
+}
+
+// record writes the record of the given safe L2 block, derived from the given L1 block.
+func (db *SafeDB) record(derivedFrom eth.BlockID, head eth.L2BlockRef) error {
+	rec := eth.SafeHeadRecord{
+		L2Block:     head,
+		DerivedFrom: derivedFrom,
+	}
+	if batch, ok := db.batches[head.Time]; ok && batch.parentHash == head.ParentHash {
+		rec.L1InclusionBlock = batch.inclusion
+		rec.Generated = batch.generated
+		if pending, ok := db.da[batch.inclusion.Hash]; ok && !batch.generated {
+			rec.DA = pending.refs
+		}
+		// later batches cannot be included before this batch, the DA references of earlier L1 blocks are not needed anymore
+		for h, pending := range db.da {
+			if pending.number < batch.inclusion.Number {
+				delete(db.da, h)
+			}
+		}
+	}
+	for ts := range db.batches {
+		if ts <= head.Time {
+			delete(db.batches, ts)
+		}
+	}
+
+	data, err := json.Marshal(&rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode safe head record: %w", err)
+	}
+	ctx := context.Background()
+	b, err := db.store.Batch(ctx)
+	if err != nil {
+		return err
+	}
+	// the L2 block may have been recorded before, if derivation was reset without a reset event (e.g. a restart)
+	if err := db.deleteRecord(ctx, b, head.Number); err != nil {
+		return err
+	}
+	if err := b.Put(ctx, l2Key(head.Number), data); err != nil {
+		return err
+	}
+	if err := b.Put(ctx, l1Key(derivedFrom.Number, head.Number), []byte{}); err != nil {
+		return err
+	}
+	if db.retention > 0 && head.Number >= db.retention {
+		if err := db.deleteRecord(ctx, b, head.Number-db.retention); err != nil {
+			return err
+		}
+	}
+	if err := b.Commit(ctx); err != nil {
+		return err
+	}
+	db.latest = head.Number
+	return nil
+}
+
+// truncate removes the records of all L2 blocks after the given L2 block number.
+func (db *SafeDB) truncate(l2Num uint64) error {
+	if db.latest <= l2Num {
+		return nil
+	}
+	ctx := context.Background()
+	b, err := db.store.Batch(ctx)
+	if err != nil {
+		return err
+	}
+	for n := db.latest; n > l2Num; n-- {
+		if err := db.deleteRecord(ctx, b, n); err != nil {
+			return err
+		}
+	}
+	if err := b.Commit(ctx); err != nil {
+		return err
+	}
+	db.log.Info("removed safe head records after reset", "from", l2Num+1, "to", db.latest)
+	db.latest = l2Num
+	return nil
+}
+
+// deleteRecord adds the deletion of the record of the given L2 block, and its index, to the batch, if there is a record.
+func (db *SafeDB) deleteRecord(ctx context.Context, b ds.Batch, l2Num uint64) error {
+	rec, err := db.get(ctx, l2Num)
+	if err == ds.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := b.Delete(ctx, l2Key(l2Num)); err != nil {
+		return err
+	}
+	return b.Delete(ctx, l1Key(rec.DerivedFrom.Number, l2Num))
+}
+
+func (db *SafeDB) get(ctx context.Context, l2Num uint64) (*eth.SafeHeadRecord, error) {
+	data, err := db.store.Get(ctx, l2Key(l2Num))
+	if err != nil {
+		return nil, err
+	}
+	var rec eth.SafeHeadRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to decode safe head record of L2 block %d: %w", l2Num, err)
+	}
+	return &rec, nil
+}
+
+// RecordByL2Number returns the record of the given safe L2 block.
+func (db *SafeDB) RecordByL2Number(ctx context.Context, l2Num uint64) (*eth.SafeHeadRecord, error) {
+	rec, err := db.get(ctx, l2Num)
+	if err == ds.ErrNotFound {
+		return nil, fmt.Errorf("no safe head record of L2 block %d: %w", l2Num, ethereum.NotFound)
+	}
+	return rec, err
+}
+
+// RecordsByL1Number returns the records of the L2 blocks that were derived from the given L1 block,
+// ordered by L2 block number. It is empty if no L2 block became safe when the L1 block was derived from.
+func (db *SafeDB) RecordsByL1Number(ctx context.Context, l1Num uint64) ([]eth.SafeHeadRecord, error) {
+	res, err := db.store.Query(ctx, query.Query{Prefix: fmt.Sprintf("%s/%016x", l1Prefix, l1Num), KeysOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query safe head records of L1 block %d: %w", l1Num, err)
+	}
+	entries, err := res.Rest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read safe head records of L1 block %d: %w", l1Num, err)
+	}
+	nums := make([]uint64, 0, len(entries))
+	for _, e := range entries {
+		n, err := parseNum(e.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid safe head index key %q: %w", e.Key, err)
+		}
+		nums = append(nums, n)
+	}
+	sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+	out := make([]eth.SafeHeadRecord, 0, len(nums))
+	for _, n := range nums {
+		rec, err := db.get(ctx, n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get safe head record of L2 block %d: %w", n, err)
+		}
+		out = append(out, *rec)
+	}
+	return out, nil
+}
+
+func (db *SafeDB) Close() error {
+	return db.store.Close()
+}
@@ -0,0 +1,165 @@
+package safedb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	ds "github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func l1Block(num uint64) eth.BlockID {
+	return eth.BlockID{Hash: common.Hash{0x01, byte(num)}, Number: num}
+}
+
+func l2Block(num uint64) eth.L2BlockRef {
+	return eth.L2BlockRef{
+		Hash:       common.Hash{0x02, byte(num)},
+		Number:     num,
+		ParentHash: common.Hash{0x02, byte(num - 1)},
+		Time:       num * 2,
+		L1Origin:   l1Block(num / 4),
+	}
+}
+
+func batchEvent(kind string, inclusion eth.BlockID, l2 eth.L2BlockRef) derive.TraceEvent {
+	return derive.TraceEvent{
+		Stage:  derive.StageBatchQueue,
+		Kind:   kind,
+		Origin: inclusion,
+		Batch: &derive.BatchTrace{
+			Timestamp:        l2.Time,
+			ParentHash:       l2.ParentHash,
+			Epoch:            l2.L1Origin,
+			L1InclusionBlock: inclusion,
+		},
+	}
+}
+
+func safeHeadEvent(kind string, derivedFrom eth.BlockID, l2 eth.L2BlockRef) derive.TraceEvent {
+	return derive.TraceEvent{
+		Stage:    derive.StageEngineQueue,
+		Kind:     kind,
+		Origin:   derivedFrom,
+		SafeHead: &l2,
+	}
+}
+
+func daEvent(origin eth.BlockID, height uint64) derive.TraceEvent {
+	return derive.TraceEvent{
+		Stage:  derive.StageDataSource,
+		Kind:   derive.EventDataResolved,
+		Origin: origin,
+		DA:     &derive.DATrace{Height: height, Commitment: []byte{byte(height)}, Source: derive.DASourceCelestia},
+	}
+}
+
+func TestSafeDB(t *testing.T) {
+	ctx := context.Background()
+	db, err := New(testlog.Logger(t, log.LvlError), dssync.MutexWrap(ds.NewMapDatastore()), 0)
+	require.NoError(t, err)
+
+	// L2 block 1 is batched in L1 block 10, with frame data on Celestia, resolved twice after a retry
+	db.OnPipelineEvent(daEvent(l1Block(10), 100))
+	db.OnPipelineEvent(daEvent(l1Block(10), 100))
+	db.OnPipelineEvent(batchEvent(derive.EventBatchAccepted, l1Block(10), l2Block(1)))
+	db.OnPipelineEvent(safeHeadEvent(derive.EventSafeHeadUpdated, l1Block(10), l2Block(1)))
+	// L2 block 2 is generated, since the sequencing window expired
+	db.OnPipelineEvent(batchEvent(derive.EventBatchGenerated, l1Block(11), l2Block(2)))
+	db.OnPipelineEvent(safeHeadEvent(derive.EventSafeHeadUpdated, l1Block(11), l2Block(2)))
+	// L2 block 3 becomes safe without a known batch
+	db.OnPipelineEvent(safeHeadEvent(derive.EventSafeHeadUpdated, l1Block(11), l2Block(3)))
+
+	rec, err := db.RecordByL2Number(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, l2Block(1), rec.L2Block)
+	require.Equal(t, l1Block(10), rec.DerivedFrom)
+	require.Equal(t, l1Block(10), rec.L1InclusionBlock)
+	require.False(t, rec.Generated)
+	require.Equal(t, []eth.DAFrameRef{{Height: 100, Commitment: []byte{100}}}, rec.DA)
+
+	rec, err = db.RecordByL2Number(ctx, 2)
+	require.NoError(t, err)
+	require.True(t, rec.Generated)
+	require.Equal(t, l1Block(11), rec.L1InclusionBlock)
+	require.Empty(t, rec.DA)
+
+	rec, err = db.RecordByL2Number(ctx, 3)
+	require.NoError(t, err)
+	require.Equal(t, eth.BlockID{}, rec.L1InclusionBlock)
+
+	_, err = db.RecordByL2Number(ctx, 4)
+	require.ErrorIs(t, err, ethereum.NotFound)
+
+	recs, err := db.RecordsByL1Number(ctx, 11)
+	require.NoError(t, err)
+	require.Len(t, recs, 2)
+	require.Equal(t, l2Block(2), recs[0].L2Block)
+	require.Equal(t, l2Block(3), recs[1].L2Block)
+
+	recs, err = db.RecordsByL1Number(ctx, 12)
+	require.NoError(t, err)
+	require.Empty(t, recs)
+
+	// a reset removes the records after the new safe head
+	db.OnPipelineEvent(safeHeadEvent(derive.EventSafeHeadReset, l1Block(9), l2Block(1)))
+	_, err = db.RecordByL2Number(ctx, 2)
+	require.ErrorIs(t, err, ethereum.NotFound)
+	recs, err = db.RecordsByL1Number(ctx, 11)
+	require.NoError(t, err)
+	require.Empty(t, recs)
+	_, err = db.RecordByL2Number(ctx, 1)
+	require.NoError(t, err)
+
+	// and L2 block 2 can be derived again, from a different L1 block
+	db.OnPipelineEvent(batchEvent(derive.EventBatchAccepted, l1Block(12), l2Block(2)))
+	db.OnPipelineEvent(safeHeadEvent(derive.EventSafeHeadUpdated, l1Block(12), l2Block(2)))
+	rec, err = db.RecordByL2Number(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, l1Block(12), rec.DerivedFrom)
+	require.False(t, rec.Generated)
+}
+
+func TestSafeDBRetention(t *testing.T) {
+	ctx := context.Background()
+	logger := testlog.Logger(t, log.LvlError)
+	cfg := &Config{Path: t.TempDir()}
+	db, err := Open(logger, cfg)
+	require.NoError(t, err)
+	for i := uint64(1); i <= 10; i++ {
+		db.OnPipelineEvent(safeHeadEvent(derive.EventSafeHeadUpdated, l1Block(i), l2Block(i)))
+	}
+	require.NoError(t, db.Close())
+
+	// reopening with a retention prunes the older records
+	cfg.Retention = 4
+	db, err = Open(logger, cfg)
+	require.NoError(t, err)
+	for i := uint64(1); i <= 6; i++ {
+		_, err := db.RecordByL2Number(ctx, i)
+		require.ErrorIs(t, err, ethereum.NotFound, "block %d", i)
+		recs, err := db.RecordsByL1Number(ctx, i)
+		require.NoError(t, err)
+		require.Empty(t, recs)
+	}
+	for i := uint64(7); i <= 10; i++ {
+		_, err := db.RecordByL2Number(ctx, i)
+		require.NoError(t, err, "block %d", i)
+	}
+
+	// new records prune the oldest record
+	db.OnPipelineEvent(safeHeadEvent(derive.EventSafeHeadUpdated, l1Block(11), l2Block(11)))
+	_, err = db.RecordByL2Number(ctx, 7)
+	require.ErrorIs(t, err, ethereum.NotFound)
+	_, err = db.RecordByL2Number(ctx, 8)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+}
@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 
 	ophttp "github.com/ethereum-optimism/optimism/op-node/http"
 	"github.com/ethereum/go-ethereum/log"
@@ -54,6 +55,35 @@ func (s *rpcServer) EnableAdminAPI(api *adminAPI) {
 	})
 }
 
+func (s *rpcServer) EnableDebugAPI(api *debugAPI) {
+	s.apis = append(s.apis, rpc.API{
+		Namespace:     "debug",
+		Version:       "",
+		Service:       api,
+		Authenticated: false,
+	})
+}
+
+// EnableSafeDBAPI adds the methods to query the safe head DB to the optimism namespace.
+func (s *rpcServer) EnableSafeDBAPI(api *safeDBAPI) {
+	s.apis = append(s.apis, rpc.API{
+		Namespace:     "optimism",
+		Version:       "",
+		Service:       api,
+		Authenticated: false,
+	})
+}
+
+// EnableBlockFeedAPI adds the methods to pull and subscribe to the signed unsafe blocks to the optimism namespace.
+func (s *rpcServer) EnableBlockFeedAPI(api *blockFeedAPI) {
+	s.apis = append(s.apis, rpc.API{
+		Namespace:     p2p.BlockFeedNamespaceRPC,
+		Version:       "",
+		Service:       api,
+		Authenticated: false,
+	})
+}
+
 func (s *rpcServer) EnableP2P(backend *p2p.APIBackend) {
 	s.apis = append(s.apis, rpc.API{
 		Namespace:     p2p.NamespaceRPC,
@@ -74,9 +104,17 @@ func (s *rpcServer) Start() error {
 	// defaults to localhost, which will prevent containers from
 	// calling into the opnode without an "invalid host" error.
 	nodeHandler := node.NewHTTPHandlerStack(srv, []string{"*"}, []string{"*"}, nil)
+	// Websocket connections are served on the same endpoint, for subscriptions.
+	wsHandler := node.NewWSHandlerStack(srv.WebsocketHandler([]string{"*"}), nil)
 
 	mux := http.NewServeMux()
-	mux.Handle("/", nodeHandler)
+	mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isWebsocket(r) {
+			wsHandler.ServeHTTP(w, r)
+			return
+		}
+		nodeHandler.ServeHTTP(w, r)
+	}))
 	mux.HandleFunc("/healthz", healthzHandler(s.appVersion))
 
 	listener, err := net.Listen("tcp", s.endpoint)
@@ -102,6 +140,12 @@ func (r *rpcServer) Addr() net.Addr {
 	return r.listenAddr
 }
 
+// isWebsocket checks the header of an http request for a websocket upgrade request.
+func isWebsocket(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
 func healthzHandler(appVersion string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write([]byte(appVersion))
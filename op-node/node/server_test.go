@@ -3,23 +3,33 @@ package node
 import (
 	"context"
 	"encoding/json"
+	"math/big"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	rpcclient "github.com/ethereum-optimism/optimism/op-node/client"
 
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
+	"github.com/ethereum-optimism/optimism/op-e2e/e2eutils"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/p2p"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
 	"github.com/ethereum-optimism/optimism/op-node/testutils"
 	"github.com/ethereum-optimism/optimism/op-node/version"
@@ -131,6 +141,123 @@ func TestOutputAtBlock(t *testing.T) {
 	drClient.Mock.AssertExpectations(t)
 }
 
+// messagePasserProof builds an L2 state with the given message-passer storage,
+// and returns the state root and the eth_getProof result of the message-passer with the given storage slots.
+func messagePasserProof(t *testing.T, storage map[common.Hash]common.Hash, slots []common.Hash) (common.Hash, *eth.AccountResult) {
+	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+	statedb, err := state.New(common.Hash{}, db, nil)
+	require.NoError(t, err)
+	statedb.SetNonce(predeploys.L2ToL1MessagePasserAddr, 1)
+	for k, v := range storage {
+		statedb.SetState(predeploys.L2ToL1MessagePasserAddr, k, v)
+	}
+	root, err := statedb.Commit(true)
+	require.NoError(t, err)
+	statedb, err = state.New(root, db, nil)
+	require.NoError(t, err)
+
+	accountProof, err := statedb.GetProof(predeploys.L2ToL1MessagePasserAddr)
+	require.NoError(t, err)
+	storageTrie, err := statedb.StorageTrie(predeploys.L2ToL1MessagePasserAddr)
+	require.NoError(t, err)
+	result := &eth.AccountResult{
+		Address:     predeploys.L2ToL1MessagePasserAddr,
+		Balance:     (*hexutil.Big)(statedb.GetBalance(predeploys.L2ToL1MessagePasserAddr)),
+		CodeHash:    statedb.GetCodeHash(predeploys.L2ToL1MessagePasserAddr),
+		Nonce:       hexutil.Uint64(statedb.GetNonce(predeploys.L2ToL1MessagePasserAddr)),
+		StorageHash: storageTrie.Hash(),
+	}
+	for _, node := range accountProof {
+		result.AccountProof = append(result.AccountProof, node)
+	}
+	for _, slot := range slots {
+		storageProof, err := statedb.GetStorageProof(predeploys.L2ToL1MessagePasserAddr, slot)
+		require.NoError(t, err)
+		entry := eth.StorageProofEntry{
+			Key:   slot,
+			Value: hexutil.Big(*statedb.GetState(predeploys.L2ToL1MessagePasserAddr, slot).Big()),
+		}
+		for _, node := range storageProof {
+			entry.Proof = append(entry.Proof, node)
+		}
+		result.StorageProof = append(result.StorageProof, entry)
+	}
+	return root, result
+}
+
+func TestOutputWithProofs(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+	sent := common.Hash{0xaa}
+	notSent := common.Hash{0xbb}
+	stateRoot, result := messagePasserProof(t, map[common.Hash]common.Hash{
+		sent:              {31: 1},
+		common.Hash{0xcc}: {31: 1},
+	}, []common.Hash{sent, notSent})
+
+	info := &testutils.MockBlockInfo{
+		InfoHash: common.Hash{0x11},
+		InfoRoot: stateRoot,
+		InfoNum:  100,
+	}
+	ref := eth.L2BlockRef{Hash: info.InfoHash, Number: info.InfoNum}
+	status := randomSyncStatus(rand.New(rand.NewSource(123)))
+
+	l2Client := &testutils.MockL2Client{}
+	drClient := &mockDriverClient{}
+	server, err := newRPCServer(context.Background(), &RPCConfig{ListenAddr: "localhost"}, &rollup.Config{}, l2Client, drClient, log, "0.0", metrics.NoopMetrics)
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer server.Stop()
+	client, err := rpcclient.NewRPC(context.Background(), log, "http://"+server.Addr().String(), rpcclient.WithDialBackoff(3))
+	require.NoError(t, err)
+
+	t.Run("valid", func(t *testing.T) {
+		drClient.ExpectBlockRefWithStatus(100, ref, status, nil)
+		l2Client.ExpectInfoByHash(ref.Hash, info, nil)
+		l2Client.ExpectGetProof(predeploys.L2ToL1MessagePasserAddr, []common.Hash{sent, notSent}, ref.Hash.String(), result, nil)
+
+		var out *eth.OutputWithProofsResponse
+		err := client.CallContext(context.Background(), &out, "optimism_outputWithProofs", hexutil.Uint64(100), []common.Hash{sent, notSent})
+		require.NoError(t, err)
+
+		expectedRoot, err := rollup.ComputeL2OutputRootV0(info, result.StorageHash)
+		require.NoError(t, err)
+		require.Equal(t, expectedRoot, out.OutputRoot)
+		require.Equal(t, eth.Bytes32{}, out.Version)
+		require.Equal(t, ref, out.BlockRef)
+		require.Equal(t, stateRoot, out.StateRoot)
+		require.Equal(t, result.StorageHash, out.WithdrawalStorageRoot)
+		require.Equal(t, ref.Hash, out.BlockHash)
+		require.Equal(t, *status, *out.Status)
+		require.NoError(t, out.MessagePasserProof.Verify(out.StateRoot))
+		require.Len(t, out.MessagePasserProof.StorageProof, 2)
+		require.Equal(t, uint64(1), out.MessagePasserProof.StorageProof[0].Value.ToInt().Uint64())
+		require.Zero(t, out.MessagePasserProof.StorageProof[1].Value.ToInt().Sign())
+	})
+
+	t.Run("invalid storage proof", func(t *testing.T) {
+		forged := *result
+		forged.StorageProof = append([]eth.StorageProofEntry{}, result.StorageProof...)
+		forged.StorageProof[1].Value = hexutil.Big(*common.Big1)
+		drClient.ExpectBlockRefWithStatus(100, ref, status, nil)
+		l2Client.ExpectInfoByHash(ref.Hash, info, nil)
+		l2Client.ExpectGetProof(predeploys.L2ToL1MessagePasserAddr, []common.Hash{sent, notSent}, ref.Hash.String(), &forged, nil)
+
+		var out *eth.OutputWithProofsResponse
+		err := client.CallContext(context.Background(), &out, "optimism_outputWithProofs", hexutil.Uint64(100), []common.Hash{sent, notSent})
+		require.ErrorContains(t, err, "invalid withdrawal root hash")
+	})
+
+	t.Run("too many slots", func(t *testing.T) {
+		var out *eth.OutputWithProofsResponse
+		err := client.CallContext(context.Background(), &out, "optimism_outputWithProofs", hexutil.Uint64(100), make([]common.Hash, maxOutputProofSlots+1))
+		require.ErrorContains(t, err, "too many storage slots")
+	})
+
+	l2Client.Mock.AssertExpectations(t)
+	drClient.Mock.AssertExpectations(t)
+}
+
 func TestVersion(t *testing.T) {
 	log := testlog.Logger(t, log.LvlError)
 	l2Client := &testutils.MockL2Client{}
@@ -156,6 +283,127 @@ func TestVersion(t *testing.T) {
 	assert.Equal(t, version.Version+"-"+version.Meta, out)
 }
 
+type blockFeedGossipIn struct {
+	blocks chan *eth.ExecutionPayload
+}
+
+func (g *blockFeedGossipIn) OnUnsafeL2Payload(ctx context.Context, from peer.ID, payload *eth.ExecutionPayload) error {
+	g.blocks <- payload
+	return nil
+}
+
+func (g *blockFeedGossipIn) OnSafeHeadAttestation(ctx context.Context, from peer.ID, att *p2p.SignedSafeHeadAttestation) error {
+	return nil
+}
+
+func TestBlockFeed(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlError)
+	rollupCfg := &rollup.Config{L2ChainID: big.NewInt(100)}
+	secrets, err := e2eutils.DefaultMnemonicConfig.Secrets()
+	require.NoError(t, err)
+	signer := &p2p.PreparedSigner{Signer: p2p.NewLocalSigner(secrets.SequencerP2P)}
+	runCfg := &testutils.MockRuntimeConfig{P2PSeqAddress: crypto.PubkeyToAddress(secrets.SequencerP2P.PublicKey)}
+
+	feed := p2p.NewBlockFeed(logger, rollupCfg)
+	server, err := newRPCServer(context.Background(), &RPCConfig{ListenAddr: "localhost"}, rollupCfg,
+		&testutils.MockL2Client{}, &mockDriverClient{}, logger, "0.0", metrics.NoopMetrics)
+	require.NoError(t, err)
+	server.EnableBlockFeedAPI(NewBlockFeedAPI(feed, metrics.NoopMetrics))
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	var extra byte
+	publish := func(num uint64, signer p2p.Signer) *eth.ExecutionPayload {
+		extra++ // distinct blocks at the same height
+		payload := &eth.ExecutionPayload{
+			BlockNumber: eth.Uint64Quantity(num),
+			Timestamp:   eth.Uint64Quantity(time.Now().Unix()),
+			ExtraData:   eth.BytesMax32{extra},
+		}
+		payload.BlockHash, _ = payload.CheckBlockHash()
+		msg, err := p2p.SignL2Payload(context.Background(), rollupCfg, payload, signer)
+		require.NoError(t, err)
+		feed.PublishSignedL2Payload(num, msg)
+		return payload
+	}
+	// published before the clients connect
+	block1 := publish(1, signer)
+
+	for _, scheme := range []string{"ws", "http"} {
+		t.Run(scheme, func(t *testing.T) {
+			gossipIn := &blockFeedGossipIn{blocks: make(chan *eth.ExecutionPayload, 10)}
+			cl := p2p.NewBlockFeedClient(logger, rollupCfg, runCfg, &p2p.BlockFeedConfig{
+				URL:          scheme + "://" + server.Addr().String(),
+				PollInterval: 10 * time.Millisecond,
+			}, gossipIn)
+			cl.Start()
+			defer cl.Close()
+
+			next := func() *eth.ExecutionPayload {
+				select {
+				case payload := <-gossipIn.blocks:
+					return payload
+				case <-time.After(10 * time.Second):
+					t.Fatal("timed out waiting for block from feed")
+					return nil
+				}
+			}
+			require.Equal(t, block1.BlockHash, next().BlockHash, "recent blocks are replayed")
+		})
+	}
+
+	t.Run("live", func(t *testing.T) {
+		gossipIn := &blockFeedGossipIn{blocks: make(chan *eth.ExecutionPayload, 10)}
+		cl := p2p.NewBlockFeedClient(logger, rollupCfg, runCfg, &p2p.BlockFeedConfig{
+			URL: "ws://" + server.Addr().String(),
+		}, gossipIn)
+		cl.Start()
+		defer cl.Close()
+		// the backlog is sent after subscribing, so the subscription is live once it is received
+		require.Equal(t, block1.BlockHash, (<-gossipIn.blocks).BlockHash)
+
+		// blocks signed by anyone but the sequencer are rejected, like on gossip
+		publish(2, &p2p.PreparedSigner{Signer: p2p.NewLocalSigner(secrets.Alice)})
+		block3 := publish(3, signer)
+		select {
+		case payload := <-gossipIn.blocks:
+			require.Equal(t, block3.BlockHash, payload.BlockHash)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for block from feed")
+		}
+	})
+
+	for _, scheme := range []string{"ws", "http"} {
+		t.Run("replacement-"+scheme, func(t *testing.T) {
+			gossipIn := &blockFeedGossipIn{blocks: make(chan *eth.ExecutionPayload, 10)}
+			cl := p2p.NewBlockFeedClient(logger, rollupCfg, runCfg, &p2p.BlockFeedConfig{
+				URL:          scheme + "://" + server.Addr().String(),
+				PollInterval: 10 * time.Millisecond,
+			}, gossipIn)
+			cl.Start()
+			defer cl.Close()
+
+			waitFor := func(payload *eth.ExecutionPayload) {
+				for {
+					select {
+					case got := <-gossipIn.blocks:
+						if got.BlockHash == payload.BlockHash {
+							return
+						}
+					case <-time.After(10 * time.Second):
+						t.Fatalf("timed out waiting for block %s from feed", payload.ID())
+					}
+				}
+			}
+			head := publish(5, signer)
+			waitFor(head)
+			// blocks at the same and at a lower height replace the unsafe chain, and are passed on too
+			waitFor(publish(5, signer))
+			waitFor(publish(4, signer))
+		})
+	}
+}
+
 func randomSyncStatus(rng *rand.Rand) *eth.SyncStatus {
 	return &eth.SyncStatus{
 		CurrentL1:          testutils.RandomBlockRef(rng),
@@ -199,6 +447,38 @@ func TestSyncStatus(t *testing.T) {
 	assert.Equal(t, status, out)
 }
 
+func TestRollbackSafeHead(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+	l2Client := &testutils.MockL2Client{}
+	drClient := &mockDriverClient{}
+	rng := rand.New(rand.NewSource(1234))
+	target := testutils.RandomL2BlockRef(rng)
+	var noErr error
+	drClient.On("RollbackSafeHead", target.Number, true).Return(target, &noErr)
+
+	rpcCfg := &RPCConfig{
+		ListenAddr: "localhost",
+		ListenPort: 0,
+	}
+	rollupCfg := &rollup.Config{
+		// ignore other rollup config info in this test
+	}
+	server, err := newRPCServer(context.Background(), rpcCfg, rollupCfg, l2Client, drClient, log, "0.0", metrics.NoopMetrics)
+	assert.NoError(t, err)
+	server.EnableAdminAPI(NewAdminAPI(drClient, nil, metrics.NoopMetrics))
+	assert.NoError(t, server.Start())
+	defer server.Stop()
+
+	client, err := rpcclient.NewRPC(context.Background(), log, "http://"+server.Addr().String(), rpcclient.WithDialBackoff(3))
+	assert.NoError(t, err)
+
+	var out eth.L2BlockRef
+	err = client.CallContext(context.Background(), &out, "admin_rollbackSafeHead", hexutil.Uint64(target.Number), true)
+	assert.NoError(t, err)
+	assert.Equal(t, target, out)
+	drClient.Mock.AssertExpectations(t)
+}
+
 type mockDriverClient struct {
 	mock.Mock
 }
@@ -220,6 +500,11 @@ func (c *mockDriverClient) ResetDerivationPipeline(ctx context.Context) error {
 	return c.Mock.MethodCalled("ResetDerivationPipeline").Get(0).(error)
 }
 
+func (c *mockDriverClient) RollbackSafeHead(ctx context.Context, number uint64, force bool) (eth.L2BlockRef, error) {
+	m := c.Mock.MethodCalled("RollbackSafeHead", number, force)
+	return m[0].(eth.L2BlockRef), *m[1].(*error)
+}
+
 func (c *mockDriverClient) StartSequencer(ctx context.Context, blockHash common.Hash) error {
 	return c.Mock.MethodCalled("StartSequencer").Get(0).(error)
 }
@@ -231,3 +516,20 @@ func (c *mockDriverClient) StopSequencer(ctx context.Context) (common.Hash, erro
 func (c *mockDriverClient) SequencerActive(ctx context.Context) (bool, error) {
 	return c.Mock.MethodCalled("SequencerActive").Get(0).(bool), nil
 }
+
+func (c *mockDriverClient) SequencerPolicy(ctx context.Context) (driver.SequencerPolicy, error) {
+	return c.Mock.MethodCalled("SequencerPolicy").Get(0).(driver.SequencerPolicy), nil
+}
+
+func (c *mockDriverClient) L1ReorgHalt(ctx context.Context) (*driver.L1Reorg, error) {
+	return c.Mock.MethodCalled("L1ReorgHalt").Get(0).(*driver.L1Reorg), nil
+}
+
+func (c *mockDriverClient) AcknowledgeL1Reorg(ctx context.Context) (*driver.L1Reorg, error) {
+	m := c.Mock.MethodCalled("AcknowledgeL1Reorg")
+	return m[0].(*driver.L1Reorg), *m[1].(*error)
+}
+
+func (c *mockDriverClient) SetSequencerPolicy(ctx context.Context, policy driver.SequencerPolicy) (driver.SequencerPolicy, error) {
+	return c.Mock.MethodCalled("SetSequencerPolicy", policy).Get(0).(driver.SequencerPolicy), nil
+}
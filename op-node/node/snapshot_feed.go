@@ -0,0 +1,102 @@
+package node
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// snapshotSubBuffer is the number of driver snapshots buffered per subscriber.
+// Snapshots are dropped for subscribers that do not keep up, rather than stalling the driver.
+const snapshotSubBuffer = 256
+
+// DriverSnapshot is a snapshot of the rollup driver state.
+// It matches the entries of the snapshot log: the block references are JSON-encoded strings.
+type DriverSnapshot struct {
+	Time            time.Time `json:"t"`
+	Event           string    `json:"event"`
+	L1Head          string    `json:"l1Head"`
+	L1Current       string    `json:"l1Current"`
+	L2Head          string    `json:"l2Head"`
+	L2Safe          string    `json:"l2Safe"`
+	L2FinalizedHead string    `json:"l2FinalizedHead"`
+}
+
+// snapshotFeed is a log handler for the snapshot logger of the driver,
+// that distributes the driver snapshots to debug RPC subscribers.
+type snapshotFeed struct {
+	log log.Logger
+
+	mu   sync.Mutex
+	subs map[chan DriverSnapshot]struct{}
+}
+
+var _ log.Handler = (*snapshotFeed)(nil)
+
+func newSnapshotFeed(log log.Logger) *snapshotFeed {
+	return &snapshotFeed{
+		log:  log,
+		subs: make(map[chan DriverSnapshot]struct{}),
+	}
+}
+
+// Wrap returns a logger that logs to the given snapshot logger, and feeds the subscribers.
+func (f *snapshotFeed) Wrap(snapshotLog log.Logger) log.Logger {
+	out := snapshotLog.New()
+	out.SetHandler(log.MultiHandler(snapshotLog.GetHandler(), f))
+	return out
+}
+
+func (f *snapshotFeed) Log(r *log.Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// Don't encode the block references if nobody is listening
+	if len(f.subs) == 0 {
+		return nil
+	}
+	snap := DriverSnapshot{Time: r.Time}
+	for i := 0; i+1 < len(r.Ctx); i += 2 {
+		k, ok := r.Ctx[i].(string)
+		if !ok {
+			continue
+		}
+		v := fmt.Sprint(r.Ctx[i+1])
+		switch k {
+		case "event":
+			snap.Event = v
+		case "l1Head":
+			snap.L1Head = v
+		case "l1Current":
+			snap.L1Current = v
+		case "l2Head":
+			snap.L2Head = v
+		case "l2Safe":
+			snap.L2Safe = v
+		case "l2FinalizedHead":
+			snap.L2FinalizedHead = v
+		}
+	}
+	for ch := range f.subs {
+		select {
+		case ch <- snap:
+		default:
+			f.log.Debug("dropping driver snapshot for slow subscriber", "event", snap.Event)
+		}
+	}
+	return nil
+}
+
+// Subscribe returns a channel with all future driver snapshots, and a function to end the subscription with.
+func (f *snapshotFeed) Subscribe() (<-chan DriverSnapshot, func()) {
+	ch := make(chan DriverSnapshot, snapshotSubBuffer)
+	f.mu.Lock()
+	f.subs[ch] = struct{}{}
+	f.mu.Unlock()
+	return ch, func() {
+		f.mu.Lock()
+		delete(f.subs, ch)
+		f.mu.Unlock()
+	}
+}
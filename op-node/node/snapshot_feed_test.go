@@ -0,0 +1,53 @@
+package node
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+type jsonString struct {
+	x any
+}
+
+func (v jsonString) String() string {
+	out, _ := json.Marshal(v.x)
+	return string(out)
+}
+
+func TestSnapshotFeed(t *testing.T) {
+	feed := newSnapshotFeed(testlog.Logger(t, log.LvlError))
+	snapshotLog := feed.Wrap(testlog.Logger(t, log.LvlError))
+
+	// no subscribers, nothing is encoded
+	snapshotLog.Info("Rollup State Snapshot", "event", "ignored")
+
+	snapshots, unsubscribe := feed.Subscribe()
+	l1Head := eth.L1BlockRef{Hash: common.Hash{1}, Number: 10}
+	l2Head := eth.L2BlockRef{Hash: common.Hash{2}, Number: 20, L1Origin: l1Head.ID()}
+	snapshotLog.Info("Rollup State Snapshot",
+		"event", "New unsafe payload",
+		"l1Head", jsonString{l1Head},
+		"l1Current", jsonString{l1Head},
+		"l2Head", jsonString{l2Head},
+		"l2Safe", jsonString{l2Head},
+		"l2FinalizedHead", jsonString{l2Head.ID()})
+
+	require.Len(t, snapshots, 1)
+	snap := <-snapshots
+	require.Equal(t, "New unsafe payload", snap.Event)
+	var decoded eth.L2BlockRef
+	require.NoError(t, json.Unmarshal([]byte(snap.L2Head), &decoded))
+	require.Equal(t, l2Head, decoded)
+	require.Equal(t, jsonString{l2Head.ID()}.String(), snap.L2FinalizedHead)
+
+	unsubscribe()
+	snapshotLog.Info("Rollup State Snapshot", "event", "after unsubscribe")
+	require.Empty(t, snapshots)
+}
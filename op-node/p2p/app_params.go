@@ -19,6 +19,24 @@ type ApplicationScoreParams struct {
 	RejectedPayloadWeight float64
 	RejectedPayloadDecay  float64
 
+	// LatencyTarget is the average sync response latency per received block that is not penalized.
+	// LatencyWeight applies per second of average latency over the target.
+	LatencyTarget time.Duration
+	LatencyWeight float64
+	LatencyDecay  float64
+
+	// RangeCoverageWeight applies to the average fraction of the requested blocks that the peer served, between 0 and 1.
+	RangeCoverageWeight float64
+	RangeCoverageDecay  float64
+
+	// ResponseSampleWeight is the weight of a new response in the averages of latency and range coverage, between 0 and 1.
+	ResponseSampleWeight float64
+
+	// UsefulBytesCap and UsefulBytesWeight are in MiB of served payloads that turned out to be canonical.
+	UsefulBytesCap    float64
+	UsefulBytesWeight float64
+	UsefulBytesDecay  float64
+
 	DecayToZero   float64
 	DecayInterval time.Duration
 }
@@ -50,6 +68,22 @@ func LightApplicationScoreParams(cfg *rollup.Config) ApplicationScoreParams {
 		RejectedPayloadWeight: -20,
 		RejectedPayloadDecay:  ScoreDecay(tenEpochs, slot),
 
+		// A peer that takes 5 seconds on average to respond gets a score of -20
+		LatencyTarget: time.Second,
+		LatencyWeight: -5,
+		LatencyDecay:  ScoreDecay(tenEpochs, slot),
+
+		// Max positive score from serving all requested blocks: 2
+		RangeCoverageWeight: 2,
+		RangeCoverageDecay:  ScoreDecay(tenEpochs, slot),
+
+		ResponseSampleWeight: 0.2,
+
+		// Max positive score from serving canonical payloads: 5
+		UsefulBytesCap:    10,
+		UsefulBytesWeight: 0.5,
+		UsefulBytesDecay:  ScoreDecay(tenEpochs, slot),
+
 		DecayToZero:   DecayToZero,
 		DecayInterval: slot,
 	}
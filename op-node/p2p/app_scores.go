@@ -2,7 +2,9 @@ package p2p
 
 import (
 	"context"
+	"math"
 	"sync"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/p2p/store"
 	"github.com/ethereum-optimism/optimism/op-service/clock"
@@ -17,13 +19,41 @@ type ScoreBook interface {
 
 type ApplicationScorer interface {
 	ApplicationScore(id peer.ID) float64
-	onValidResponse(id peer.ID)
-	onResponseError(id peer.ID)
+	// ScoreBreakdown returns the sync-protocol scores of the peer, and the application score per scoring dimension.
+	ScoreBreakdown(id peer.ID) (store.ReqRespScores, ApplicationScoreBreakdown, error)
+	onValidResponse(id peer.ID, latency time.Duration, requested uint64, received uint64)
+	onResponseError(id peer.ID, latency time.Duration, requested uint64, received uint64)
 	onRejectedPayload(id peer.ID)
+	onUsefulPayload(id peer.ID, size uint64)
 	start()
 	stop()
 }
 
+// ApplicationScoreBreakdown is the application score of a peer, per scoring dimension.
+type ApplicationScoreBreakdown struct {
+	ValidResponses   float64 `json:"validResponses"`
+	ErrorResponses   float64 `json:"errorResponses"`
+	RejectedPayloads float64 `json:"rejectedPayloads"`
+	Latency          float64 `json:"latency"`
+	RangeCoverage    float64 `json:"rangeCoverage"`
+	UsefulBytes      float64 `json:"usefulBytes"`
+	Total            float64 `json:"total"`
+}
+
+// Breakdown computes the application score per scoring dimension, from the given sync-protocol scores.
+func (p *ApplicationScoreParams) Breakdown(scores store.ReqRespScores) ApplicationScoreBreakdown {
+	out := ApplicationScoreBreakdown{
+		ValidResponses:   scores.ValidResponses * p.ValidResponseWeight,
+		ErrorResponses:   scores.ErrorResponses * p.ErrorResponseWeight,
+		RejectedPayloads: scores.RejectedPayloads * p.RejectedPayloadWeight,
+		Latency:          math.Max(scores.Latency-p.LatencyTarget.Seconds(), 0) * p.LatencyWeight,
+		RangeCoverage:    scores.RangeCoverage * p.RangeCoverageWeight,
+		UsefulBytes:      scores.UsefulMiB * p.UsefulBytesWeight,
+	}
+	out.Total = out.ValidResponses + out.ErrorResponses + out.RejectedPayloads + out.Latency + out.RangeCoverage + out.UsefulBytes
+	return out
+}
+
 type peerApplicationScorer struct {
 	ctx            context.Context
 	cancelFunc     context.CancelFunc
@@ -52,31 +82,76 @@ func newPeerApplicationScorer(ctx context.Context, logger log.Logger, clock cloc
 }
 
 func (s *peerApplicationScorer) ApplicationScore(id peer.ID) float64 {
-	scores, err := s.scorebook.GetPeerScores(id)
+	_, breakdown, err := s.ScoreBreakdown(id)
 	if err != nil {
 		s.log.Error("Failed to load peer scores", "peer", id, "err", err)
 		return 0
 	}
-	score := scores.ReqResp.ValidResponses * s.params.ValidResponseWeight
-	score += scores.ReqResp.ErrorResponses * s.params.ErrorResponseWeight
-	score += scores.ReqResp.RejectedPayloads * s.params.RejectedPayloadWeight
-	return score
+	return breakdown.Total
+}
+
+func (s *peerApplicationScorer) ScoreBreakdown(id peer.ID) (store.ReqRespScores, ApplicationScoreBreakdown, error) {
+	scores, err := s.scorebook.GetPeerScores(id)
+	if err != nil {
+		return store.ReqRespScores{}, ApplicationScoreBreakdown{}, err
+	}
+	return scores.ReqResp, s.params.Breakdown(scores.ReqResp), nil
 }
 
-func (s *peerApplicationScorer) onValidResponse(id peer.ID) {
+func (s *peerApplicationScorer) onValidResponse(id peer.ID, latency time.Duration, requested uint64, received uint64) {
 	_, err := s.scorebook.SetScore(id, store.IncrementValidResponses{Cap: s.params.ValidResponseCap})
 	if err != nil {
 		s.log.Error("Unable to update peer score", "peer", id, "err", err)
 		return
 	}
+	s.recordResponse(id, latency, requested, received)
 }
 
-func (s *peerApplicationScorer) onResponseError(id peer.ID) {
+func (s *peerApplicationScorer) onResponseError(id peer.ID, latency time.Duration, requested uint64, received uint64) {
 	_, err := s.scorebook.SetScore(id, store.IncrementErrorResponses{Cap: s.params.ErrorResponseCap})
 	if err != nil {
 		s.log.Error("Unable to update peer score", "peer", id, "err", err)
 		return
 	}
+	s.recordResponse(id, latency, requested, received)
+}
+
+// recordResponse adds the latency and range coverage of a response to the averages of the peer.
+// The latency is normalized per received block, so range responses are measured against the same target
+// as single-block responses. Responses are not sampled if the sample weight is 0.
+func (s *peerApplicationScorer) recordResponse(id peer.ID, latency time.Duration, requested uint64, received uint64) {
+	if s.params.ResponseSampleWeight <= 0 || requested == 0 {
+		return
+	}
+	perBlock := latency.Seconds()
+	if received > 1 {
+		perBlock /= float64(received)
+	}
+	_, err := s.scorebook.SetScore(id, store.RecordResponse{
+		Latency:      perBlock,
+		Coverage:     float64(received) / float64(requested),
+		SampleWeight: s.params.ResponseSampleWeight,
+	})
+	if err != nil {
+		s.log.Error("Unable to update peer score", "peer", id, "err", err)
+		return
+	}
+}
+
+// onUsefulPayload is called when a payload that the peer served turns out to be canonical.
+// Useful bytes are not tracked if the cap is 0.
+func (s *peerApplicationScorer) onUsefulPayload(id peer.ID, size uint64) {
+	if s.params.UsefulBytesCap <= 0 {
+		return
+	}
+	_, err := s.scorebook.SetScore(id, store.IncrementUsefulBytes{
+		MiB: float64(size) / (1 << 20),
+		Cap: s.params.UsefulBytesCap,
+	})
+	if err != nil {
+		s.log.Error("Unable to update peer score", "peer", id, "err", err)
+		return
+	}
 }
 
 func (s *peerApplicationScorer) onRejectedPayload(id peer.ID) {
@@ -92,6 +167,9 @@ func (s *peerApplicationScorer) decayScores(id peer.ID) {
 		ValidResponseDecay:   s.params.ValidResponseDecay,
 		ErrorResponseDecay:   s.params.ErrorResponseDecay,
 		RejectedPayloadDecay: s.params.RejectedPayloadDecay,
+		LatencyDecay:         s.params.LatencyDecay,
+		RangeCoverageDecay:   s.params.RangeCoverageDecay,
+		UsefulBytesDecay:     s.params.UsefulBytesDecay,
 		DecayToZero:          s.params.DecayToZero,
 	})
 	if err != nil {
@@ -133,15 +211,22 @@ func (n *NoopApplicationScorer) ApplicationScore(_ peer.ID) float64 {
 	return 0
 }
 
-func (n *NoopApplicationScorer) onValidResponse(_ peer.ID) {
+func (n *NoopApplicationScorer) ScoreBreakdown(_ peer.ID) (store.ReqRespScores, ApplicationScoreBreakdown, error) {
+	return store.ReqRespScores{}, ApplicationScoreBreakdown{}, nil
 }
 
-func (n *NoopApplicationScorer) onResponseError(_ peer.ID) {
+func (n *NoopApplicationScorer) onValidResponse(_ peer.ID, _ time.Duration, _ uint64, _ uint64) {
+}
+
+func (n *NoopApplicationScorer) onResponseError(_ peer.ID, _ time.Duration, _ uint64, _ uint64) {
 }
 
 func (n *NoopApplicationScorer) onRejectedPayload(_ peer.ID) {
 }
 
+func (n *NoopApplicationScorer) onUsefulPayload(_ peer.ID, _ uint64) {
+}
+
 func (n *NoopApplicationScorer) start() {
 }
 
@@ -0,0 +1,175 @@
+package p2p
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	ds "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/sync"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/host/peerstore/pstoreds"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/p2p/store"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-service/clock"
+)
+
+type replayEventKind int
+
+const (
+	replayValidResponse replayEventKind = iota
+	replayResponseError
+	replayRejectedPayload
+	replayUsefulPayload
+)
+
+// replayEvent is a sync-protocol event of a peer, at an offset from the start of the replay.
+type replayEvent struct {
+	at        time.Duration
+	peer      peer.ID
+	kind      replayEventKind
+	latency   time.Duration
+	requested uint64
+	received  uint64
+	size      uint64
+}
+
+// appScoreReplay replays sync-protocol events against an application scorer,
+// backed by a real peerstore, and decays the scores of all peers at every decay interval.
+type appScoreReplay struct {
+	t      *testing.T
+	logger log.Logger
+	params *ApplicationScoreParams
+	clock  *clock.DeterministicClock
+	store  *sync.MutexDatastore
+	peers  []peer.ID
+
+	eps    store.ExtendedPeerstore
+	scorer *peerApplicationScorer
+	// lastDecay is the time of the last decay tick
+	lastDecay time.Time
+}
+
+func newAppScoreReplay(t *testing.T, params *ApplicationScoreParams, peers ...peer.ID) *appScoreReplay {
+	r := &appScoreReplay{
+		t:      t,
+		logger: testlog.Logger(t, log.LvlError),
+		params: params,
+		clock:  clock.NewDeterministicClock(time.Unix(1000, 0)),
+		store:  sync.MutexWrap(ds.NewMapDatastore()),
+		peers:  peers,
+	}
+	r.lastDecay = r.clock.Now()
+	r.open()
+	return r
+}
+
+// open (re)opens the peerstore on the backing datastore, e.g. to simulate a restart of the node.
+func (r *appScoreReplay) open() {
+	ps, err := pstoreds.NewPeerstore(context.Background(), r.store, pstoreds.DefaultOpts())
+	require.NoError(r.t, err)
+	r.eps, err = store.NewExtendedPeerstore(context.Background(), r.logger, r.clock, ps, r.store, 24*time.Hour)
+	require.NoError(r.t, err)
+	r.scorer = newPeerApplicationScorer(context.Background(), r.logger, r.clock, r.params, r.eps, func() []peer.ID {
+		return r.peers
+	})
+}
+
+func (r *appScoreReplay) restart() {
+	require.NoError(r.t, r.eps.Close())
+	r.open()
+}
+
+// advance moves the clock forward to the given time, decaying the scores at every decay interval on the way.
+func (r *appScoreReplay) advance(to time.Time) {
+	for next := r.lastDecay.Add(r.params.DecayInterval); !next.After(to); next = next.Add(r.params.DecayInterval) {
+		r.clock.AdvanceTime(next.Sub(r.clock.Now()))
+		r.scorer.decayConnectedPeerScores()
+		r.lastDecay = next
+	}
+	r.clock.AdvanceTime(to.Sub(r.clock.Now()))
+}
+
+func (r *appScoreReplay) replay(start time.Time, events []replayEvent) {
+	for _, ev := range events {
+		r.advance(start.Add(ev.at))
+		switch ev.kind {
+		case replayValidResponse:
+			r.scorer.onValidResponse(ev.peer, ev.latency, ev.requested, ev.received)
+		case replayResponseError:
+			r.scorer.onResponseError(ev.peer, ev.latency, ev.requested, ev.received)
+		case replayRejectedPayload:
+			r.scorer.onRejectedPayload(ev.peer)
+		case replayUsefulPayload:
+			r.scorer.onUsefulPayload(ev.peer, ev.size)
+		}
+	}
+}
+
+func (r *appScoreReplay) breakdowns() map[peer.ID]ApplicationScoreBreakdown {
+	out := make(map[peer.ID]ApplicationScoreBreakdown)
+	for _, id := range r.peers {
+		_, breakdown, err := r.scorer.ScoreBreakdown(id)
+		require.NoError(r.t, err)
+		out[id] = breakdown
+	}
+	return out
+}
+
+func replayTestParams() *ApplicationScoreParams {
+	params := LightApplicationScoreParams(&rollup.Config{BlockTime: 2})
+	return &params
+}
+
+// replayTestEvents scripts a fast and complete peer "fast", and a slow peer "slow" that serves partial ranges.
+func replayTestEvents() []replayEvent {
+	var events []replayEvent
+	for i := 0; i < 20; i++ {
+		at := time.Duration(i) * 5 * time.Second
+		events = append(events,
+			replayEvent{at: at, peer: "fast", kind: replayValidResponse, latency: 100 * time.Millisecond, requested: 8, received: 8},
+			replayEvent{at: at, peer: "fast", kind: replayUsefulPayload, size: 256 << 10},
+			replayEvent{at: at, peer: "slow", kind: replayValidResponse, latency: 4 * time.Second, requested: 8, received: 2},
+		)
+		if i%4 == 0 {
+			events = append(events, replayEvent{at: at, peer: "slow", kind: replayResponseError, latency: 5 * time.Second, requested: 8, received: 0})
+		}
+	}
+	return events
+}
+
+func TestApplicationScoreReplay(t *testing.T) {
+	params := replayTestParams()
+	r := newAppScoreReplay(t, params, "fast", "slow")
+	r.replay(r.clock.Now(), replayTestEvents())
+	result := r.breakdowns()
+
+	fast, slow := result["fast"], result["slow"]
+	require.Zero(t, fast.Latency, "fast peer is within the latency target")
+	require.Less(t, slow.Latency, 0.0, "slow peer is penalized for latency")
+	require.Greater(t, fast.RangeCoverage, slow.RangeCoverage)
+	require.Greater(t, fast.UsefulBytes, 0.0)
+	require.Zero(t, slow.UsefulBytes)
+	require.Less(t, slow.ErrorResponses, 0.0)
+	require.Greater(t, fast.Total, slow.Total)
+
+	// replaying the same events results in the exact same scores
+	again := newAppScoreReplay(t, params, "fast", "slow")
+	again.replay(again.clock.Now(), replayTestEvents())
+	require.Equal(t, result, again.breakdowns())
+
+	// the scores persist across a restart
+	r.restart()
+	require.Equal(t, result, r.breakdowns())
+
+	// and decay once the peers go quiet
+	r.advance(r.clock.Now().Add(100 * params.DecayInterval))
+	for id, breakdown := range r.breakdowns() {
+		require.Less(t, math.Abs(breakdown.Total), math.Abs(result[id].Total)/2, "peer %s", id)
+	}
+}
@@ -82,7 +82,7 @@ func TestIncrementValidResponses(t *testing.T) {
 		ValidResponseCap: 10,
 	})
 
-	appScorer.onValidResponse("aaa")
+	appScorer.onValidResponse("aaa", time.Second, 1, 1)
 	require.Len(t, data.scorebook.updates, 1)
 	update := <-data.scorebook.updates
 	require.Equal(t, stubScoreBookUpdate{peer.ID("aaa"), store.IncrementValidResponses{Cap: 10}}, update)
@@ -93,7 +93,7 @@ func TestIncrementErrorResponses(t *testing.T) {
 		ErrorResponseCap: 10,
 	})
 
-	appScorer.onResponseError("aaa")
+	appScorer.onResponseError("aaa", time.Second, 1, 0)
 	require.Len(t, data.scorebook.updates, 1)
 	update := <-data.scorebook.updates
 	require.Equal(t, stubScoreBookUpdate{peer.ID("aaa"), store.IncrementErrorResponses{Cap: 10}}, update)
@@ -110,6 +110,36 @@ func TestIncrementRejectedPayloads(t *testing.T) {
 	require.Equal(t, stubScoreBookUpdate{peer.ID("aaa"), store.IncrementRejectedPayloads{Cap: 10}}, update)
 }
 
+func TestRecordResponse(t *testing.T) {
+	data, appScorer := setupPeerApplicationScorerTest(t, &ApplicationScoreParams{
+		ValidResponseCap:     10,
+		ResponseSampleWeight: 0.2,
+	})
+
+	appScorer.onValidResponse("aaa", 500*time.Millisecond, 4, 3)
+	require.Len(t, data.scorebook.updates, 2)
+	require.Equal(t, stubScoreBookUpdate{peer.ID("aaa"), store.IncrementValidResponses{Cap: 10}}, <-data.scorebook.updates)
+	// the latency is normalized per received block
+	require.Equal(t, stubScoreBookUpdate{peer.ID("aaa"), store.RecordResponse{Latency: 0.5 / 3, Coverage: 0.75, SampleWeight: 0.2}}, <-data.scorebook.updates)
+
+	// a response without blocks counts its full latency
+	appScorer.onResponseError("aaa", 2*time.Second, 4, 0)
+	require.Len(t, data.scorebook.updates, 2)
+	<-data.scorebook.updates
+	require.Equal(t, stubScoreBookUpdate{peer.ID("aaa"), store.RecordResponse{Latency: 2, Coverage: 0, SampleWeight: 0.2}}, <-data.scorebook.updates)
+}
+
+func TestIncrementUsefulPayload(t *testing.T) {
+	data, appScorer := setupPeerApplicationScorerTest(t, &ApplicationScoreParams{
+		UsefulBytesCap: 10,
+	})
+
+	appScorer.onUsefulPayload("aaa", 1<<19)
+	require.Len(t, data.scorebook.updates, 1)
+	update := <-data.scorebook.updates
+	require.Equal(t, stubScoreBookUpdate{peer.ID("aaa"), store.IncrementUsefulBytes{MiB: 0.5, Cap: 10}}, update)
+}
+
 func TestApplicationScore(t *testing.T) {
 	data, appScorer := setupPeerApplicationScorerTest(t, &ApplicationScoreParams{
 		ValidResponseWeight:   0.8,
@@ -137,6 +167,42 @@ func TestApplicationScoreZeroWhenScoreDoesNotLoad(t *testing.T) {
 	require.Zero(t, score)
 }
 
+func TestApplicationScoreBreakdown(t *testing.T) {
+	data, appScorer := setupPeerApplicationScorerTest(t, &ApplicationScoreParams{
+		ValidResponseWeight: 0.8,
+		LatencyTarget:       time.Second,
+		LatencyWeight:       -2,
+		RangeCoverageWeight: 3,
+		UsefulBytesWeight:   0.5,
+	})
+
+	data.scorebook.scores["aaa"] = store.PeerScores{
+		ReqResp: store.ReqRespScores{
+			ValidResponses: 1,
+			Latency:        1.5,
+			RangeCoverage:  0.5,
+			UsefulMiB:      4,
+		},
+	}
+	scores, breakdown, err := appScorer.ScoreBreakdown("aaa")
+	require.NoError(t, err)
+	require.Equal(t, data.scorebook.scores["aaa"].ReqResp, scores)
+	require.Equal(t, ApplicationScoreBreakdown{
+		ValidResponses: 0.8,
+		Latency:        -1,
+		RangeCoverage:  1.5,
+		UsefulBytes:    2,
+		Total:          0.8 - 1 + 1.5 + 2,
+	}, breakdown)
+	require.Equal(t, breakdown.Total, appScorer.ApplicationScore("aaa"))
+
+	// latency below the target is not rewarded
+	data.scorebook.scores["bbb"] = store.PeerScores{ReqResp: store.ReqRespScores{Latency: 0.2}}
+	_, breakdown, err = appScorer.ScoreBreakdown("bbb")
+	require.NoError(t, err)
+	require.Zero(t, breakdown.Latency)
+}
+
 func TestDecayScoresAfterDecayInterval(t *testing.T) {
 	params := &ApplicationScoreParams{
 		ValidResponseDecay:   0.8,
@@ -0,0 +1,230 @@
+package p2p
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	decredSecp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/golang/snappy"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/ethereum/go-ethereum/common"
+	gcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+// SigningDomainSafeHeadAttestationsV1 separates safe-head attestation signatures from block signatures.
+var SigningDomainSafeHeadAttestationsV1 = [32]byte{31: 1}
+
+// safeHeadAttestationSize is the size of an encoded SafeHeadAttestation:
+// L2 block hash and number, output root, L1 origin hash and number.
+const safeHeadAttestationSize = 32 + 8 + 32 + 32 + 8
+
+func safeHeadAttestationsTopicV1(cfg *rollup.Config) string {
+	return fmt.Sprintf("/optimism/%s/0/safe-head-attestations", cfg.L2ChainID.String())
+}
+
+// SafeHeadAttestation is a claim by a verifier that it derived the given L2 block,
+// with the given output root, as safe from the given L1 origin.
+type SafeHeadAttestation struct {
+	L2Block    eth.BlockID `json:"l2_block"`
+	OutputRoot eth.Bytes32 `json:"output_root"`
+	L1Origin   eth.BlockID `json:"l1_origin"`
+}
+
+func (a *SafeHeadAttestation) MarshalBinary() ([]byte, error) {
+	out := make([]byte, safeHeadAttestationSize)
+	copy(out[0:32], a.L2Block.Hash[:])
+	binary.BigEndian.PutUint64(out[32:40], a.L2Block.Number)
+	copy(out[40:72], a.OutputRoot[:])
+	copy(out[72:104], a.L1Origin.Hash[:])
+	binary.BigEndian.PutUint64(out[104:112], a.L1Origin.Number)
+	return out, nil
+}
+
+func (a *SafeHeadAttestation) UnmarshalBinary(data []byte) error {
+	if len(data) != safeHeadAttestationSize {
+		return fmt.Errorf("expected %d bytes safe head attestation, but got %d", safeHeadAttestationSize, len(data))
+	}
+	copy(a.L2Block.Hash[:], data[0:32])
+	a.L2Block.Number = binary.BigEndian.Uint64(data[32:40])
+	copy(a.OutputRoot[:], data[40:72])
+	copy(a.L1Origin.Hash[:], data[72:104])
+	a.L1Origin.Number = binary.BigEndian.Uint64(data[104:112])
+	return nil
+}
+
+// SignedSafeHeadAttestation is a safe-head attestation with a verified signature of the attesting peer.
+type SignedSafeHeadAttestation struct {
+	SafeHeadAttestation
+	// Attester is the peer that signed the attestation with its node key.
+	// This is not necessarily the peer that relayed the attestation to us.
+	Attester peer.ID `json:"attester"`
+}
+
+func SafeHeadAttestationSigningHash(cfg *rollup.Config, attestationBytes []byte) (common.Hash, error) {
+	return SigningHash(SigningDomainSafeHeadAttestationsV1, cfg.L2ChainID, attestationBytes)
+}
+
+// signWithNodeKey signs the hash with the secp256k1 node key, the same key that determines the peer ID.
+func signWithNodeKey(priv crypto.PrivKey, h common.Hash) ([]byte, error) {
+	secpPriv, ok := priv.(*crypto.Secp256k1PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("node key of type %T cannot sign attestations, need secp256k1 key", priv)
+	}
+	return gcrypto.Sign(h[:], (*decredSecp.PrivateKey)(secpPriv).ToECDSA())
+}
+
+// recoverAttester recovers the peer ID of the node key that signed the hash.
+func recoverAttester(h common.Hash, sig []byte) (peer.ID, error) {
+	pub, err := gcrypto.SigToPub(h[:], sig)
+	if err != nil {
+		return "", err
+	}
+	secpPub, err := decredSecp.ParsePubKey(gcrypto.CompressPubkey(pub))
+	if err != nil {
+		return "", err
+	}
+	return peer.IDFromPublicKey((*crypto.Secp256k1PublicKey)(secpPub))
+}
+
+func BuildSafeHeadAttestationsValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx {
+	return func(ctx context.Context, id peer.ID, message *pubsub.Message) pubsub.ValidationResult {
+		// [REJECT] if the compression is not valid, or the size does not match
+		outLen, err := snappy.DecodedLen(message.Data)
+		if err != nil {
+			log.Warn("invalid snappy compression length data", "err", err, "peer", id)
+			return pubsub.ValidationReject
+		}
+		if outLen != 65+safeHeadAttestationSize {
+			log.Warn("rejecting safe head attestation of unexpected size", "decoded_length", outLen, "peer", id)
+			return pubsub.ValidationReject
+		}
+		data, err := snappy.Decode(nil, message.Data)
+		if err != nil {
+			log.Warn("invalid snappy compression", "err", err, "peer", id)
+			return pubsub.ValidationReject
+		}
+
+		// message starts with compact-encoding secp256k1 encoded signature
+		signatureBytes, attestationBytes := data[:65], data[65:]
+
+		var att SignedSafeHeadAttestation
+		if err := att.UnmarshalBinary(attestationBytes); err != nil {
+			log.Warn("invalid safe head attestation", "err", err, "peer", id)
+			return pubsub.ValidationReject
+		}
+
+		// [REJECT] if the attestation is for a block before genesis
+		if att.L2Block.Number < cfg.Genesis.L2.Number {
+			log.Warn("safe head attestation is before genesis", "l2_block", att.L2Block, "peer", id)
+			return pubsub.ValidationReject
+		}
+
+		// [REJECT] if the signature by the attester is not valid
+		signingHash, err := SafeHeadAttestationSigningHash(cfg, attestationBytes)
+		if err != nil {
+			log.Warn("failed to compute safe head attestation signing hash", "err", err, "peer", id)
+			return pubsub.ValidationReject
+		}
+		att.Attester, err = recoverAttester(signingHash, signatureBytes)
+		if err != nil {
+			log.Warn("invalid safe head attestation signature", "err", err, "peer", id)
+			return pubsub.ValidationReject
+		}
+
+		// remember the decoded attestation for later usage in topic subscriber.
+		message.ValidatorData = &att
+		return pubsub.ValidationAccept
+	}
+}
+
+type SafeHeadAttestationsOut interface {
+	PublishSafeHeadAttestation(ctx context.Context, att *SafeHeadAttestation) error
+	Close() error
+}
+
+type attestationsPublisher struct {
+	cfg      *rollup.Config
+	priv     crypto.PrivKey
+	topic    *pubsub.Topic
+	topicLog log.Logger
+}
+
+var _ SafeHeadAttestationsOut = (*attestationsPublisher)(nil)
+
+func (p *attestationsPublisher) PublishSafeHeadAttestation(ctx context.Context, att *SafeHeadAttestation) error {
+	attestationBytes, err := att.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode safe head attestation: %w", err)
+	}
+	signingHash, err := SafeHeadAttestationSigningHash(p.cfg, attestationBytes)
+	if err != nil {
+		return fmt.Errorf("failed to compute safe head attestation signing hash: %w", err)
+	}
+	sig, err := signWithNodeKey(p.priv, signingHash)
+	if err != nil {
+		return fmt.Errorf("failed to sign safe head attestation: %w", err)
+	}
+	p.topicLog.Debug("publishing safe head attestation", "l2_block", att.L2Block, "output_root", att.OutputRoot, "l1_origin", att.L1Origin)
+	return p.topic.Publish(ctx, snappy.Encode(nil, append(sig, attestationBytes...)))
+}
+
+func (p *attestationsPublisher) Close() error {
+	return p.topic.Close()
+}
+
+// JoinSafeHeadAttestationsGossip joins the optional safe-head attestations topic.
+// Attestations are signed with the node key, so other nodes can tell which peer made the attestation.
+func JoinSafeHeadAttestationsGossip(p2pCtx context.Context, self peer.ID, priv crypto.PrivKey, ps *pubsub.PubSub, log log.Logger, cfg *rollup.Config, gossipIn GossipIn) (SafeHeadAttestationsOut, error) {
+	if priv == nil {
+		return nil, errors.New("cannot attest to safe head without node key")
+	}
+	val := guardGossipValidator(log, logValidationResult(self, "validated safe head attestation", log, BuildSafeHeadAttestationsValidator(log, cfg)))
+	topicName := safeHeadAttestationsTopicV1(cfg)
+	err := ps.RegisterTopicValidator(topicName,
+		val,
+		pubsub.WithValidatorTimeout(3*time.Second),
+		pubsub.WithValidatorConcurrency(4))
+	if err != nil {
+		return nil, fmt.Errorf("failed to register safe head attestations gossip topic: %w", err)
+	}
+	topic, err := ps.Join(topicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join safe head attestations gossip topic: %w", err)
+	}
+	topicEvents, err := topic.EventHandler()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create safe head attestations gossip topic handler: %w", err)
+	}
+	topicLog := log.New("topic", "safe-head-attestations")
+	go LogTopicEvents(p2pCtx, topicLog, topicEvents)
+
+	subscription, err := topic.Subscribe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to safe head attestations gossip topic: %w", err)
+	}
+
+	subscriber := MakeSubscriber(log, SafeHeadAttestationsHandler(gossipIn.OnSafeHeadAttestation))
+	go subscriber(p2pCtx, subscription)
+
+	return &attestationsPublisher{cfg: cfg, priv: priv, topic: topic, topicLog: topicLog}, nil
+}
+
+func SafeHeadAttestationsHandler(onAttestation func(ctx context.Context, from peer.ID, att *SignedSafeHeadAttestation) error) MessageHandler {
+	return func(ctx context.Context, from peer.ID, msg any) error {
+		att, ok := msg.(*SignedSafeHeadAttestation)
+		if !ok {
+			return fmt.Errorf("expected topic validator to parse and validate data into safe head attestation, but got %T", msg)
+		}
+		return onAttestation(ctx, from, att)
+	}
+}
@@ -0,0 +1,305 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+const (
+	// blockFeedSize is the number of most recent signed blocks that the feed keeps, for pulling clients and reconnects.
+	// Gossip validation rejects blocks older than 60 seconds, so there is no use in keeping many more than that.
+	blockFeedSize = 64
+	// blockFeedSubBuffer is the number of blocks buffered per subscriber.
+	// Blocks are dropped for subscribers that do not keep up, rather than stalling the sequencer.
+	blockFeedSubBuffer = 64
+	// blockFeedRetryDelay is the time to wait before reconnecting to the feed after an error.
+	blockFeedRetryDelay = 5 * time.Second
+	// blockFeedResumeDepth is how many blocks below the last received block the client resumes and polls the feed from,
+	// to also receive blocks that replace recently received blocks. Blocks that were already received are skipped by hash.
+	blockFeedResumeDepth = 16
+
+	// BlockFeedNamespaceRPC is the RPC namespace that the feed of signed unsafe blocks is served on.
+	BlockFeedNamespaceRPC = "optimism"
+)
+
+// SignedBlock is a signed unsafe block of the feed.
+// Message is encoded exactly like a gossip message of the blocks topic:
+// the snappy compression of the 65-byte sequencer signature followed by the SSZ-encoded execution payload.
+type SignedBlock struct {
+	Number  hexutil.Uint64 `json:"number"`
+	Message hexutil.Bytes  `json:"message"`
+}
+
+// BlockFeed keeps the most recent signed unsafe blocks of the sequencer,
+// to serve them to verifiers over RPC, as an alternative to the blocks gossip topic.
+type BlockFeed struct {
+	log log.Logger
+	cfg *rollup.Config
+
+	mu     sync.Mutex
+	recent []SignedBlock
+	subs   map[chan SignedBlock]struct{}
+}
+
+func NewBlockFeed(log log.Logger, cfg *rollup.Config) *BlockFeed {
+	return &BlockFeed{
+		log:  log,
+		cfg:  cfg,
+		subs: make(map[chan SignedBlock]struct{}),
+	}
+}
+
+// PublishSignedL2Payload adds a block to the feed, with the message that was signed and encoded with SignL2Payload.
+func (f *BlockFeed) PublishSignedL2Payload(number uint64, msg []byte) {
+	block := SignedBlock{Number: hexutil.Uint64(number), Message: msg}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.recent = append(f.recent, block)
+	if len(f.recent) > blockFeedSize {
+		f.recent = f.recent[len(f.recent)-blockFeedSize:]
+	}
+	for ch := range f.subs {
+		select {
+		case ch <- block:
+		default:
+			f.log.Debug("dropping unsafe block for slow feed subscriber", "number", uint64(block.Number))
+		}
+	}
+}
+
+// Recent returns the most recent signed blocks with a block number larger than after, in the order they were published.
+func (f *BlockFeed) Recent(after uint64) []SignedBlock {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.recentAfter(after)
+}
+
+func (f *BlockFeed) recentAfter(after uint64) []SignedBlock {
+	out := make([]SignedBlock, 0, len(f.recent))
+	for _, block := range f.recent {
+		if uint64(block.Number) > after {
+			out = append(out, block)
+		}
+	}
+	return out
+}
+
+// Subscribe returns the most recent signed blocks with a block number larger than after,
+// a channel with all future signed blocks, and a function to end the subscription with.
+// No block is both in the returned recent blocks and sent on the channel.
+func (f *BlockFeed) Subscribe(after uint64) ([]SignedBlock, <-chan SignedBlock, func()) {
+	ch := make(chan SignedBlock, blockFeedSubBuffer)
+	f.mu.Lock()
+	f.subs[ch] = struct{}{}
+	backlog := f.recentAfter(after)
+	f.mu.Unlock()
+	return backlog, ch, func() {
+		f.mu.Lock()
+		delete(f.subs, ch)
+		f.mu.Unlock()
+	}
+}
+
+// BlockFeedConfig configures the client of the feed of signed unsafe blocks of a sequencer.
+type BlockFeedConfig struct {
+	// URL is the RPC endpoint of the node to follow the unsafe block feed of.
+	// Websocket endpoints are subscribed to, HTTP endpoints are polled.
+	// The client is disabled if empty.
+	URL string
+	// PollInterval is the interval to poll HTTP endpoints at.
+	PollInterval time.Duration
+}
+
+func (c *BlockFeedConfig) Enabled() bool {
+	return c.URL != ""
+}
+
+func (c *BlockFeedConfig) Check() error {
+	if !c.Enabled() {
+		return nil
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("invalid unsafe block feed URL: %w", err)
+	}
+	switch u.Scheme {
+	case "ws", "wss":
+	case "http", "https":
+		if c.PollInterval <= 0 {
+			return errors.New("unsafe block feed poll interval must be positive to poll an HTTP endpoint")
+		}
+	default:
+		return fmt.Errorf("unsupported unsafe block feed URL scheme %q", u.Scheme)
+	}
+	return nil
+}
+
+// BlockFeedClient follows the unsafe block feed of a sequencer over RPC,
+// as an alternative source of unsafe blocks to the blocks gossip topic.
+// The blocks are validated with the same rules as blocks received from gossip.
+type BlockFeedClient struct {
+	log      log.Logger
+	cfg      *BlockFeedConfig
+	validate pubsub.ValidatorEx
+	gossipIn GossipIn
+
+	// last is the number of the last block received from the feed, to resume the feed from after reconnecting.
+	last uint64
+	// seen holds the message hashes of the recently received blocks, oldest first,
+	// to skip blocks that are received again when resuming the feed below the last block.
+	seen    map[common.Hash]struct{}
+	seenLog []common.Hash
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewBlockFeedClient(log log.Logger, rollupCfg *rollup.Config, runCfg GossipRuntimeConfig, cfg *BlockFeedConfig, gossipIn GossipIn) *BlockFeedClient {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &BlockFeedClient{
+		log:      log,
+		cfg:      cfg,
+		validate: guardGossipValidator(log, BuildBlocksValidator(log, rollupCfg, runCfg)),
+		gossipIn: gossipIn,
+		seen:     make(map[common.Hash]struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func (c *BlockFeedClient) Start() {
+	c.wg.Add(1)
+	go c.eventLoop()
+}
+
+func (c *BlockFeedClient) Close() error {
+	c.cancel()
+	c.wg.Wait()
+	return nil
+}
+
+func (c *BlockFeedClient) eventLoop() {
+	defer c.wg.Done()
+	c.log.Info("Following unsafe block feed", "url", c.cfg.URL)
+	for {
+		err := c.follow(c.ctx)
+		if c.ctx.Err() != nil {
+			return
+		}
+		c.log.Warn("Unsafe block feed interrupted, reconnecting", "err", err, "delay", blockFeedRetryDelay)
+		select {
+		case <-time.After(blockFeedRetryDelay):
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// follow connects to the feed, and processes blocks until the connection fails.
+func (c *BlockFeedClient) follow(ctx context.Context) error {
+	cl, err := rpc.DialContext(ctx, c.cfg.URL)
+	if err != nil {
+		return fmt.Errorf("failed to dial unsafe block feed: %w", err)
+	}
+	defer cl.Close()
+
+	blocks := make(chan SignedBlock, blockFeedSubBuffer)
+	sub, err := cl.Subscribe(ctx, BlockFeedNamespaceRPC, blocks, "unsafeBlocks", hexutil.Uint64(c.resumeAfter()))
+	if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+		return c.poll(ctx, cl)
+	} else if err != nil {
+		return fmt.Errorf("failed to subscribe to unsafe block feed: %w", err)
+	}
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case block := <-blocks:
+			c.onBlock(ctx, block)
+		case err := <-sub.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *BlockFeedClient) poll(ctx context.Context, cl *rpc.Client) error {
+	ticker := time.NewTicker(c.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		var blocks []SignedBlock
+		if err := cl.CallContext(ctx, &blocks, BlockFeedNamespaceRPC+"_recentUnsafeBlocks", hexutil.Uint64(c.resumeAfter())); err != nil {
+			return fmt.Errorf("failed to poll unsafe block feed: %w", err)
+		}
+		for _, block := range blocks {
+			c.onBlock(ctx, block)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// resumeAfter returns the block number to resume the feed after.
+// The feed is resumed below the last received block, so blocks that replaced it, or its parents, are received too.
+func (c *BlockFeedClient) resumeAfter() uint64 {
+	if c.last > blockFeedResumeDepth {
+		return c.last - blockFeedResumeDepth
+	}
+	return 0
+}
+
+// markSeen records the message hash of a received block, and returns false if it was received before.
+func (c *BlockFeedClient) markSeen(block SignedBlock) bool {
+	h := crypto.Keccak256Hash(block.Message)
+	if _, ok := c.seen[h]; ok {
+		return false
+	}
+	c.seen[h] = struct{}{}
+	c.seenLog = append(c.seenLog, h)
+	if len(c.seenLog) > blockFeedSize {
+		delete(c.seen, c.seenLog[0])
+		c.seenLog = c.seenLog[1:]
+	}
+	return true
+}
+
+// onBlock validates a block of the feed like a gossip message, and passes it on if valid.
+// The block number of the feed entry is only used to resume the feed, it is not trusted otherwise.
+// Blocks at the same or a lower height than previous blocks are processed too, they may replace unsafe blocks.
+func (c *BlockFeedClient) onBlock(ctx context.Context, block SignedBlock) {
+	if !c.markSeen(block) {
+		return
+	}
+	c.last = uint64(block.Number)
+	msg := &pubsub.Message{Message: &pb.Message{Data: block.Message}}
+	// blocks from the feed are not attributed to any peer
+	if res := c.validate(ctx, peer.ID(""), msg); res != pubsub.ValidationAccept {
+		c.log.Debug("Ignoring unsafe block from feed", "number", uint64(block.Number), "result", validationResultString(res))
+		return
+	}
+	payload := msg.ValidatorData.(*eth.ExecutionPayload)
+	if err := c.gossipIn.OnUnsafeL2Payload(ctx, peer.ID(""), payload); err != nil {
+		c.log.Warn("Failed to process unsafe block from feed", "id", payload.ID(), "err", err)
+	}
+}
@@ -64,6 +64,7 @@ func NewConfig(ctx *cli.Context, rollupCfg *rollup.Config) (*p2p.Config, error)
 	}
 
 	conf.EnableReqRespSync = ctx.Bool(flags.SyncReqRespFlag.Name)
+	conf.EnableSafeHeadAttestations = ctx.Bool(flags.SafeHeadAttestationsFlag.Name)
 
 	return conf, nil
 }
@@ -210,6 +211,14 @@ func loadLibp2pOpts(conf *p2p.Config, ctx *cli.Context) error {
 		conf.StaticPeers = append(conf.StaticPeers, a)
 	}
 
+	if path := ctx.String(flags.TrustedPeers.Name); path != "" {
+		trusted, err := p2p.LoadTrustedPeers(path)
+		if err != nil {
+			return fmt.Errorf("failed to load trusted peers: %w", err)
+		}
+		conf.TrustedPeers = trusted
+	}
+
 	for _, v := range strings.Split(ctx.String(flags.HostMux.Name), ",") {
 		v = strings.ToLower(strings.TrimSpace(v))
 		switch v {
@@ -4,30 +4,51 @@ import (
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/urfave/cli/v2"
 
+	kmssigner "github.com/ethereum-optimism/optimism/go-ethereum-kms-signer"
 	"github.com/ethereum-optimism/optimism/op-node/flags"
 	"github.com/ethereum-optimism/optimism/op-node/p2p"
+	opsigner "github.com/ethereum-optimism/optimism/op-signer/client"
 )
 
-// TODO: implement remote signer setup (config to authenticated endpoint)
-// and remote signer itself (e.g. a open http client to make signing requests)
-
-// LoadSignerSetup loads a configuration for a Signer to be set up later
-func LoadSignerSetup(ctx *cli.Context) (p2p.SignerSetup, error) {
+// LoadSignerSetup loads a configuration for a Signer to be set up later.
+// The signer is either a local key, an op-signer service, or an AWS KMS key.
+func LoadSignerSetup(ctx *cli.Context, log log.Logger) (p2p.SignerSetup, error) {
 	key := ctx.String(flags.SequencerP2PKeyFlag.Name)
-	if key != "" {
+	signerCfg := opsigner.ReadCLIConfig(ctx)
+	kmsCfg := kmssigner.ReadCLIConfig(ctx)
+	if err := signerCfg.Check(); err != nil {
+		return nil, fmt.Errorf("invalid remote signer config: %w", err)
+	}
+	if err := kmsCfg.Check(); err != nil {
+		return nil, fmt.Errorf("invalid KMS signer config: %w", err)
+	}
+	configured := 0
+	for _, enabled := range []bool{key != "", signerCfg.Enabled(), kmsCfg.Enabled()} {
+		if enabled {
+			configured += 1
+		}
+	}
+	if configured > 1 {
+		return nil, fmt.Errorf("only one of a sequencer p2p key, remote signer or KMS signer may be configured")
+	}
+	timeout := ctx.Duration(flags.SequencerP2PSignerTimeoutFlag.Name)
+
+	switch {
+	case key != "":
 		// Mnemonics are bad because they leak *all* keys when they leak.
 		// Unencrypted keys from file are bad because they are easy to leak (and we are not checking file permissions).
 		priv, err := crypto.HexToECDSA(key)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read batch submitter key: %w", err)
 		}
-
 		return &p2p.PreparedSigner{Signer: p2p.NewLocalSigner(priv)}, nil
+	case signerCfg.Enabled():
+		return &p2p.RemoteSignerSetup{Log: log, Config: signerCfg, Timeout: timeout}, nil
+	case kmsCfg.Enabled():
+		return &p2p.KMSSignerSetup{Config: kmsCfg, Timeout: timeout}, nil
 	}
-
-	// TODO: create remote signer
-
 	return nil, nil
 }
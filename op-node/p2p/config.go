@@ -49,6 +49,7 @@ type SetupP2P interface {
 	BanDuration() time.Duration
 	GossipSetupConfigurables
 	ReqRespSyncEnabled() bool
+	SafeHeadAttestationsEnabled() bool
 }
 
 // ScoringParams defines the various types of peer scoring parameters.
@@ -89,6 +90,8 @@ type Config struct {
 	DiscoveryDB      *enode.DB
 
 	StaticPeers []core.Multiaddr
+	// TrustedPeers are kept connected within a dedicated slot budget, and exempt from score-based bans. May be nil.
+	TrustedPeers *TrustedPeers
 
 	HostMux             []libp2p.Option
 	HostSecurity        []libp2p.Option
@@ -119,12 +122,17 @@ type Config struct {
 	Store ds.Batching
 
 	EnableReqRespSync bool
+
+	// Join the safe-head attestations gossip topic, to publish and receive safe-head attestations
+	EnableSafeHeadAttestations bool
 }
 
 func DefaultConnManager(conf *Config) (connmgr.ConnManager, error) {
+	// The trusted peers get dedicated connection slots, on top of the regular peer tides.
+	trustedSlots := conf.TrustedPeers.SlotBudget()
 	return cmgr.NewConnManager(
-		int(conf.PeersLo),
-		int(conf.PeersHi),
+		int(conf.PeersLo+trustedSlots),
+		int(conf.PeersHi+trustedSlots),
 		cmgr.WithGracePeriod(conf.PeersGrace),
 		cmgr.WithSilencePeriod(time.Minute),
 		cmgr.WithEmergencyTrim(true))
@@ -161,6 +169,10 @@ func (conf *Config) ReqRespSyncEnabled() bool {
 	return conf.EnableReqRespSync
 }
 
+func (conf *Config) SafeHeadAttestationsEnabled() bool {
+	return conf.EnableSafeHeadAttestations
+}
+
 const maxMeshParam = 1000
 
 func (conf *Config) Check() error {
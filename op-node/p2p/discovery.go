@@ -366,11 +366,18 @@ func (n *NodeP2P) DiscoveryProcess(ctx context.Context, log log.Logger, cfg *rol
 			log.Debug("discovered peer", "peer", info.ID, "nodeID", found.ID(), "addr", info.Addrs[0])
 		case <-connectTicker.C:
 			connected := n.Host().Network().Peers()
-			log.Debug("peering tick", "connected", len(connected),
+			// trusted peers have dedicated connection slots, and do not count towards the connect goal
+			regular := 0
+			for _, id := range connected {
+				if !IsTrusted(n.ConnectionManager(), id) {
+					regular += 1
+				}
+			}
+			log.Debug("peering tick", "connected", len(connected), "regular", regular,
 				"advertised_udp", n.dv5Local.Node().UDP(),
 				"advertised_tcp", n.dv5Local.Node().TCP(),
 				"advertised_ip", n.dv5Local.Node().IP())
-			if uint(len(connected)) < connectGoal {
+			if uint(regular) < connectGoal {
 				// Start looking for more peers more actively again
 				faster()
 
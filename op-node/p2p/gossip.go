@@ -72,7 +72,7 @@ func blocksTopicV1(cfg *rollup.Config) string {
 // BuildSubscriptionFilter builds a simple subscription filter,
 // to help protect against peers spamming useless subscriptions.
 func BuildSubscriptionFilter(cfg *rollup.Config) pubsub.SubscriptionFilter {
-	return pubsub.NewAllowlistSubscriptionFilter(blocksTopicV1(cfg)) // add more topics here in the future, if any.
+	return pubsub.NewAllowlistSubscriptionFilter(blocksTopicV1(cfg), safeHeadAttestationsTopicV1(cfg)) // add more topics here in the future, if any.
 }
 
 var msgBufPool = sync.Pool{New: func() any {
@@ -366,6 +366,9 @@ func verifyBlockSignature(log log.Logger, cfg *rollup.Config, runCfg GossipRunti
 
 type GossipIn interface {
 	OnUnsafeL2Payload(ctx context.Context, from peer.ID, msg *eth.ExecutionPayload) error
+	// OnSafeHeadAttestation is called for every valid safe-head attestation,
+	// if the node joined the optional safe-head attestations topic.
+	OnSafeHeadAttestation(ctx context.Context, from peer.ID, att *SignedSafeHeadAttestation) error
 }
 
 type GossipTopicInfo interface {
@@ -375,6 +378,8 @@ type GossipTopicInfo interface {
 type GossipOut interface {
 	GossipTopicInfo
 	PublishL2Payload(ctx context.Context, msg *eth.ExecutionPayload, signer Signer) error
+	// PublishSignedL2Payload publishes a message that was already signed and encoded with SignL2Payload.
+	PublishSignedL2Payload(ctx context.Context, msg []byte) error
 	Close() error
 }
 
@@ -392,6 +397,19 @@ func (p *publisher) BlocksTopicPeers() []peer.ID {
 }
 
 func (p *publisher) PublishL2Payload(ctx context.Context, payload *eth.ExecutionPayload, signer Signer) error {
+	out, err := SignL2Payload(ctx, p.cfg, payload, signer)
+	if err != nil {
+		return err
+	}
+	return p.PublishSignedL2Payload(ctx, out)
+}
+
+func (p *publisher) PublishSignedL2Payload(ctx context.Context, msg []byte) error {
+	return p.blocksTopic.Publish(ctx, msg)
+}
+
+// SignL2Payload signs the payload, and encodes it as message of the blocks topic.
+func SignL2Payload(ctx context.Context, cfg *rollup.Config, payload *eth.ExecutionPayload, signer Signer) ([]byte, error) {
 	res := msgBufPool.Get().(*[]byte)
 	buf := bytes.NewBuffer((*res)[:0])
 	defer func() {
@@ -401,21 +419,19 @@ func (p *publisher) PublishL2Payload(ctx context.Context, payload *eth.Execution
 
 	buf.Write(make([]byte, 65))
 	if _, err := payload.MarshalSSZ(buf); err != nil {
-		return fmt.Errorf("failed to encoded execution payload to publish: %w", err)
+		return nil, fmt.Errorf("failed to encoded execution payload to publish: %w", err)
 	}
 	data := buf.Bytes()
 	payloadData := data[65:]
-	sig, err := signer.Sign(ctx, SigningDomainBlocksV1, p.cfg.L2ChainID, payloadData)
+	sig, err := signer.Sign(ctx, SigningDomainBlocksV1, cfg.L2ChainID, payloadData)
 	if err != nil {
-		return fmt.Errorf("failed to sign execution payload with signer: %w", err)
+		return nil, fmt.Errorf("failed to sign execution payload with signer: %w", err)
 	}
 	copy(data[:65], sig[:])
 
 	// compress the full message
 	// This also copies the data, freeing up the original buffer to go back into the pool
-	out := snappy.Encode(nil, data)
-
-	return p.blocksTopic.Publish(ctx, out)
+	return snappy.Encode(nil, data), nil
 }
 
 func (p *publisher) Close() error {
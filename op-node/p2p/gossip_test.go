@@ -2,9 +2,16 @@ package p2p
 
 import (
 	"context"
+	"crypto/rand"
 	"math/big"
 	"testing"
 
+	"github.com/golang/snappy"
+	pubsub_pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	gcrypto "github.com/libp2p/go-libp2p/core/crypto"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+
 	"github.com/ethereum-optimism/optimism/op-e2e/e2eutils"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/testutils"
@@ -84,3 +91,51 @@ func TestVerifyBlockSignature(t *testing.T) {
 		require.Equal(t, pubsub.ValidationIgnore, result)
 	})
 }
+
+func TestSafeHeadAttestationsValidator(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlCrit)
+	cfg := &rollup.Config{
+		L2ChainID: big.NewInt(100),
+	}
+	priv, _, err := gcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	attester, err := peer.IDFromPrivateKey(priv)
+	require.NoError(t, err)
+
+	att := &SafeHeadAttestation{
+		L2Block:    eth.BlockID{Hash: common.Hash{0xaa}, Number: 123},
+		OutputRoot: eth.Bytes32{0xbb},
+		L1Origin:   eth.BlockID{Hash: common.Hash{0xcc}, Number: 42},
+	}
+	attBytes, err := att.MarshalBinary()
+	require.NoError(t, err)
+	signingHash, err := SafeHeadAttestationSigningHash(cfg, attBytes)
+	require.NoError(t, err)
+	sig, err := signWithNodeKey(priv, signingHash)
+	require.NoError(t, err)
+
+	val := BuildSafeHeadAttestationsValidator(logger, cfg)
+
+	t.Run("Valid", func(t *testing.T) {
+		msg := &pubsub.Message{Message: &pubsub_pb.Message{Data: snappy.Encode(nil, append(sig, attBytes...))}}
+		require.Equal(t, pubsub.ValidationAccept, val(context.Background(), "relayer", msg))
+		got, ok := msg.ValidatorData.(*SignedSafeHeadAttestation)
+		require.True(t, ok)
+		require.Equal(t, *att, got.SafeHeadAttestation)
+		require.Equal(t, attester, got.Attester, "attester is the signer, not the relayer")
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		tampered := append([]byte{}, attBytes...)
+		tampered[40] ^= 1 // flip a bit of the output root
+		msg := &pubsub.Message{Message: &pubsub_pb.Message{Data: snappy.Encode(nil, append(append([]byte{}, sig...), tampered...))}}
+		require.Equal(t, pubsub.ValidationAccept, val(context.Background(), "relayer", msg))
+		got := msg.ValidatorData.(*SignedSafeHeadAttestation)
+		require.NotEqual(t, attester, got.Attester, "tampered attestation cannot be attributed to the original attester")
+	})
+
+	t.Run("WrongSize", func(t *testing.T) {
+		msg := &pubsub.Message{Message: &pubsub_pb.Message{Data: snappy.Encode(nil, append(sig, attBytes[:10]...))}}
+		require.Equal(t, pubsub.ValidationReject, val(context.Background(), "relayer", msg))
+	})
+}
@@ -49,6 +49,7 @@ type extraHost struct {
 	log     log.Logger
 
 	staticPeers []*peer.AddrInfo
+	trusted     *trustedPeerManager // nil if there are no trusted peers
 
 	quitC chan struct{}
 }
@@ -63,6 +64,9 @@ func (e *extraHost) ConnectionManager() connmgr.ConnManager {
 
 func (e *extraHost) Close() error {
 	close(e.quitC)
+	if e.trusted != nil {
+		e.trusted.Close()
+	}
 	return e.Host.Close()
 }
 
@@ -250,6 +254,10 @@ func (conf *Config) Host(log log.Logger, reporter metrics.Reporter, metrics Host
 	if len(conf.StaticPeers) > 0 {
 		go out.monitorStaticPeers()
 	}
+	if conf.TrustedPeers != nil && len(conf.TrustedPeers.Peers) > 0 {
+		out.trusted = newTrustedPeerManager(log.New("p2p", "trusted"), h, connMngr, conf.TrustedPeers)
+		out.trusted.Start()
+	}
 
 	out.gater = connGtr
 	return out, nil
@@ -71,7 +71,8 @@ func TestP2PSimple(t *testing.T) {
 }
 
 type mockGossipIn struct {
-	OnUnsafeL2PayloadFn func(ctx context.Context, from peer.ID, msg *eth.ExecutionPayload) error
+	OnUnsafeL2PayloadFn     func(ctx context.Context, from peer.ID, msg *eth.ExecutionPayload) error
+	OnSafeHeadAttestationFn func(ctx context.Context, from peer.ID, att *SignedSafeHeadAttestation) error
 }
 
 func (m *mockGossipIn) OnUnsafeL2Payload(ctx context.Context, from peer.ID, msg *eth.ExecutionPayload) error {
@@ -81,6 +82,13 @@ func (m *mockGossipIn) OnUnsafeL2Payload(ctx context.Context, from peer.ID, msg
 	return nil
 }
 
+func (m *mockGossipIn) OnSafeHeadAttestation(ctx context.Context, from peer.ID, att *SignedSafeHeadAttestation) error {
+	if m.OnSafeHeadAttestationFn != nil {
+		return m.OnSafeHeadAttestationFn(ctx, from, att)
+	}
+	return nil
+}
+
 // Full setup, using negotiated transport security and muxes
 func TestP2PFull(t *testing.T) {
 	pA, _, err := crypto.GenerateSecp256k1Key(rand.Reader)
@@ -40,10 +40,11 @@ type NodeP2P struct {
 	appScorer   ApplicationScorer
 	log         log.Logger
 	// the below components are all optional, and may be nil. They require the host to not be nil.
-	dv5Local *enode.LocalNode // p2p discovery identity
-	dv5Udp   *discover.UDPv5  // p2p discovery service
-	gs       *pubsub.PubSub   // p2p gossip router
-	gsOut    GossipOut        // p2p gossip application interface for publishing
+	dv5Local *enode.LocalNode        // p2p discovery identity
+	dv5Udp   *discover.UDPv5         // p2p discovery service
+	gs       *pubsub.PubSub          // p2p gossip router
+	gsOut    GossipOut               // p2p gossip application interface for publishing
+	attOut   SafeHeadAttestationsOut // p2p safe-head attestations publishing, nil if disabled
 	syncCl   *SyncClient
 	syncSrv  *ReqRespServer
 }
@@ -104,7 +105,7 @@ func (n *NodeP2P) init(resourcesCtx context.Context, rollupCfg *rollup.Config, l
 		}
 		// Activate the P2P req-resp sync if enabled by feature-flag.
 		if setup.ReqRespSyncEnabled() {
-			n.syncCl = NewSyncClient(log, rollupCfg, n.host.NewStream, gossipIn.OnUnsafeL2Payload, metrics, n.appScorer)
+			n.syncCl = NewSyncClient(log, rollupCfg, n.host.NewStream, gossipIn.OnUnsafeL2Payload, metrics, n.appScorer, n.isTrusted)
 			n.host.Network().Notify(&network.NotifyBundle{
 				ConnectedF: func(nw network.Network, conn network.Conn) {
 					n.syncCl.AddPeer(conn.RemotePeer())
@@ -123,6 +124,8 @@ func (n *NodeP2P) init(resourcesCtx context.Context, rollupCfg *rollup.Config, l
 				// register the sync protocol with libp2p host
 				payloadByNumber := MakeStreamHandler(resourcesCtx, log.New("serve", "payloads_by_number"), n.syncSrv.HandleSyncRequest)
 				n.host.SetStreamHandler(PayloadByNumberProtocolID(rollupCfg.L2ChainID), payloadByNumber)
+				payloadsByRange := MakeStreamHandler(resourcesCtx, log.New("serve", "payloads_by_range"), n.syncSrv.HandleRangeSyncRequest)
+				n.host.SetStreamHandler(PayloadsByRangeProtocolID(rollupCfg.L2ChainID), payloadsByRange)
 			}
 		}
 		n.scorer = NewScorer(rollupCfg, eps, metrics, n.appScorer, log)
@@ -137,6 +140,12 @@ func (n *NodeP2P) init(resourcesCtx context.Context, rollupCfg *rollup.Config, l
 		if err != nil {
 			return fmt.Errorf("failed to join blocks gossip topic: %w", err)
 		}
+		if setup.SafeHeadAttestationsEnabled() {
+			n.attOut, err = JoinSafeHeadAttestationsGossip(resourcesCtx, n.host.ID(), n.host.Peerstore().PrivKey(n.host.ID()), n.gs, log, rollupCfg, gossipIn)
+			if err != nil {
+				return fmt.Errorf("failed to join safe head attestations gossip topic: %w", err)
+			}
+		}
 		log.Info("started p2p host", "addrs", n.host.Addrs(), "peerID", n.host.ID().Pretty())
 
 		tcpPort, err := FindActiveTCPPort(n.host)
@@ -194,6 +203,11 @@ func (n *NodeP2P) GossipOut() GossipOut {
 	return n.gsOut
 }
 
+// SafeHeadAttestationsOut returns the safe-head attestations publisher, nil if attestations are disabled.
+func (n *NodeP2P) SafeHeadAttestationsOut() SafeHeadAttestationsOut {
+	return n.attOut
+}
+
 func (n *NodeP2P) ConnectionGater() gating.BlockingConnectionGater {
 	return n.gater
 }
@@ -202,6 +216,10 @@ func (n *NodeP2P) ConnectionManager() connmgr.ConnManager {
 	return n.connMgr
 }
 
+func (n *NodeP2P) AppScorer() ApplicationScorer {
+	return n.appScorer
+}
+
 func (n *NodeP2P) Peers() []peer.ID {
 	return n.host.Network().Peers()
 }
@@ -210,8 +228,13 @@ func (n *NodeP2P) GetPeerScore(id peer.ID) (float64, error) {
 	return n.store.GetPeerScore(id)
 }
 
+// IsStatic returns true if the peer is a static or trusted peer, which are exempt from score-based bans.
 func (n *NodeP2P) IsStatic(id peer.ID) bool {
-	return n.connMgr != nil && n.connMgr.IsProtected(id, staticPeerTag)
+	return n.connMgr != nil && (n.connMgr.IsProtected(id, staticPeerTag) || IsTrusted(n.connMgr, id))
+}
+
+func (n *NodeP2P) isTrusted(id peer.ID) bool {
+	return IsTrusted(n.connMgr, id)
 }
 
 func (n *NodeP2P) BanPeer(id peer.ID, expiration time.Time) error {
@@ -257,6 +280,11 @@ func (n *NodeP2P) Close() error {
 			result = multierror.Append(result, fmt.Errorf("failed to close gossip cleanly: %w", err))
 		}
 	}
+	if n.attOut != nil {
+		if err := n.attOut.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close safe head attestations gossip cleanly: %w", err))
+		}
+	}
 	if n.host != nil {
 		if err := n.host.Close(); err != nil {
 			result = multierror.Append(result, fmt.Errorf("failed to close p2p host cleanly: %w", err))
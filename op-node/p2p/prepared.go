@@ -25,6 +25,8 @@ type Prepared struct {
 	UDPv5     *discover.UDPv5
 
 	EnableReqRespSync bool
+
+	EnableSafeHeadAttestations bool
 }
 
 var _ SetupP2P = (*Prepared)(nil)
@@ -92,3 +94,7 @@ func (p *Prepared) Disabled() bool {
 func (p *Prepared) ReqRespSyncEnabled() bool {
 	return p.EnableReqRespSync
 }
+
+func (p *Prepared) SafeHeadAttestationsEnabled() bool {
+	return p.EnableSafeHeadAttestations
+}
@@ -0,0 +1,149 @@
+package p2p
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	kmssigner "github.com/ethereum-optimism/optimism/go-ethereum-kms-signer"
+	opsigner "github.com/ethereum-optimism/optimism/op-signer/client"
+)
+
+// DefaultRemoteSignerTimeout is the default timeout of a single remote signing request.
+const DefaultRemoteSignerTimeout = 5 * time.Second
+
+// checkSignature checks that the signature over the signing hash recovers to the expected signer address,
+// to not gossip blocks that are signed with the wrong key.
+func checkSignature(signingHash common.Hash, sig []byte, expected common.Address) error {
+	pub, err := crypto.SigToPub(signingHash[:], sig)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	if addr := crypto.PubkeyToAddress(*pub); addr != expected {
+		return fmt.Errorf("signature recovers to %s, expected %s", addr, expected)
+	}
+	return nil
+}
+
+// RemoteSigner signs block payloads with an op-signer service.
+// The signer computes the signing hash from the domain, chain ID and payload hash,
+// and the signature is checked against the signing hash computed locally.
+type RemoteSigner struct {
+	client  *opsigner.SignerClient
+	address common.Address
+	timeout time.Duration
+}
+
+func NewRemoteSigner(client *opsigner.SignerClient, address common.Address, timeout time.Duration) *RemoteSigner {
+	return &RemoteSigner{client: client, address: address, timeout: timeout}
+}
+
+func (s *RemoteSigner) Sign(ctx context.Context, domain [32]byte, chainID *big.Int, encodedMsg []byte) (sig *[65]byte, err error) {
+	signingHash, err := SigningHash(domain, chainID, encodedMsg)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	signature, err := s.client.SignBlockPayload(ctx, &opsigner.BlockPayloadArgs{
+		Domain:        domain[:],
+		ChainID:       (*hexutil.Big)(chainID),
+		PayloadHash:   crypto.Keccak256(encodedMsg),
+		SenderAddress: &s.address,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSignature(signingHash, signature[:], s.address); err != nil {
+		return nil, err
+	}
+	return &signature, nil
+}
+
+func (s *RemoteSigner) Close() error {
+	s.client.Close()
+	return nil
+}
+
+// KMSSigner signs block payloads with an AWS KMS key.
+type KMSSigner struct {
+	client  *kms.Client
+	keyID   string
+	pubkey  *ecdsa.PublicKey
+	timeout time.Duration
+}
+
+func NewKMSSigner(client *kms.Client, keyID string, pubkey *ecdsa.PublicKey, timeout time.Duration) *KMSSigner {
+	return &KMSSigner{client: client, keyID: keyID, pubkey: pubkey, timeout: timeout}
+}
+
+func (s *KMSSigner) Sign(ctx context.Context, domain [32]byte, chainID *big.Int, encodedMsg []byte) (sig *[65]byte, err error) {
+	signingHash, err := SigningHash(domain, chainID, encodedMsg)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	signature, err := kmssigner.SignHash(ctx, s.client, s.keyID, s.pubkey, signingHash[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with KMS key %s: %w", s.keyID, err)
+	}
+	if len(signature) != 65 {
+		return nil, fmt.Errorf("invalid signature length: %d", len(signature))
+	}
+	return (*[65]byte)(signature), nil
+}
+
+func (s *KMSSigner) Close() error {
+	return nil
+}
+
+// RemoteSignerSetup sets up a RemoteSigner, and checks that the op-signer service is reachable.
+type RemoteSignerSetup struct {
+	Log     log.Logger
+	Config  opsigner.CLIConfig
+	Timeout time.Duration
+}
+
+func (s *RemoteSignerSetup) SetupSigner(ctx context.Context) (Signer, error) {
+	if !common.IsHexAddress(s.Config.Address) {
+		return nil, fmt.Errorf("invalid signer address: %q", s.Config.Address)
+	}
+	client, err := opsigner.NewSignerClientFromConfig(s.Log, s.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to remote signer: %w", err)
+	}
+	return NewRemoteSigner(client, common.HexToAddress(s.Config.Address), s.Timeout), nil
+}
+
+// KMSSignerSetup sets up a KMSSigner, and loads the public key of the KMS key.
+type KMSSignerSetup struct {
+	Config  kmssigner.CLIConfig
+	Timeout time.Duration
+}
+
+func (s *KMSSignerSetup) SetupSigner(ctx context.Context) (Signer, error) {
+	if s.Config.Id == "" {
+		return nil, errors.New("missing KMS key ID")
+	}
+	client, err := kmssigner.NewKmsClientFromConfig(ctx, s.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create KMS client: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+	pubkey, err := kmssigner.GetPubKeyCtx(ctx, client, s.Config.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key of KMS key %s: %w", s.Config.Id, err)
+	}
+	return NewKMSSigner(client, s.Config.Id, pubkey, s.Timeout), nil
+}
@@ -22,10 +22,12 @@ type PeerInfo struct {
 	Protocols       []string `json:"protocols"` // negotiated protocols list
 	//GossipScore float64
 	//PeerScore float64
-	Connectedness network.Connectedness `json:"connectedness"` // "NotConnected", "Connected", "CanConnect" (gracefully disconnected), or "CannotConnect" (tried but failed)
-	Direction     network.Direction     `json:"direction"`     // "Unknown", "Inbound" (if the peer contacted us), "Outbound" (if we connected to them)
-	Protected     bool                  `json:"protected"`     // Protected peers do not get
-	ChainID       uint64                `json:"chainID"`       // some peers might try to connect, but we figure out they are on a different chain later. This may be 0 if the peer is not an optimism node at all.
+	Connectedness network.Connectedness `json:"connectedness"`         // "NotConnected", "Connected", "CanConnect" (gracefully disconnected), or "CannotConnect" (tried but failed)
+	Direction     network.Direction     `json:"direction"`             // "Unknown", "Inbound" (if the peer contacted us), "Outbound" (if we connected to them)
+	Protected     bool                  `json:"protected"`             // Protected peers do not get
+	Trusted       bool                  `json:"trusted"`               // Trusted peers are kept connected, and exempt from score-based bans
+	TrustedTags   []string              `json:"trustedTags,omitempty"` // tags of the trusted peer, from the trusted peers file
+	ChainID       uint64                `json:"chainID"`               // some peers might try to connect, but we figure out they are on a different chain later. This may be 0 if the peer is not an optimism node at all.
 	Latency       time.Duration         `json:"latency"`
 
 	GossipBlocks bool `json:"gossipBlocks"` // if the peer is in our gossip topic
@@ -41,10 +43,18 @@ type PeerDump struct {
 	BannedSubnets  []*net.IPNet         `json:"bannedSubnets"`
 }
 
+// ApplicationScoreInfo is the application score of a peer per scoring dimension,
+// with the sync-protocol scores it is computed from.
+type ApplicationScoreInfo struct {
+	Scores    store.ReqRespScores       `json:"scores"`
+	Breakdown ApplicationScoreBreakdown `json:"breakdown"`
+}
+
 type API interface {
 	Self(ctx context.Context) (*PeerInfo, error)
 	Peers(ctx context.Context, connected bool) (*PeerDump, error)
 	PeerStats(ctx context.Context) (*PeerStats, error)
+	ApplicationScores(ctx context.Context, connected bool) (map[string]*ApplicationScoreInfo, error)
 	DiscoveryTable(ctx context.Context) ([]*enode.Node, error)
 	BlockPeer(ctx context.Context, p peer.ID) error
 	UnblockPeer(ctx context.Context, p peer.ID) error
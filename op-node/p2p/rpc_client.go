@@ -44,6 +44,12 @@ func (c *Client) PeerStats(ctx context.Context) (*PeerStats, error) {
 	return out, err
 }
 
+func (c *Client) ApplicationScores(ctx context.Context, connected bool) (map[string]*ApplicationScoreInfo, error) {
+	var out map[string]*ApplicationScoreInfo
+	err := c.c.CallContext(ctx, &out, prefixRPC("applicationScores"), connected)
+	return out, err
+}
+
 func (c *Client) DiscoveryTable(ctx context.Context) ([]*enode.Node, error) {
 	var out []*enode.Node
 	err := c.c.CallContext(ctx, &out, prefixRPC("discoveryTable"))
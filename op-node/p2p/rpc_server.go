@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/p2p/gating"
@@ -36,6 +38,7 @@ var (
 	ErrDisabledDiscovery   = errors.New("discovery disabled")
 	ErrNoConnectionManager = errors.New("no connection manager")
 	ErrNoConnectionGater   = errors.New("no connection gater")
+	ErrDisabledAppScoring  = errors.New("application scoring disabled")
 )
 
 type Node interface {
@@ -53,6 +56,8 @@ type Node interface {
 	ConnectionGater() gating.BlockingConnectionGater
 	// ConnectionManager returns the connection manager, to protect peers with, may be nil
 	ConnectionManager() connmgr.ConnManager
+	// AppScorer returns the application scorer of sync-protocol peers, may be nil
+	AppScorer() ApplicationScorer
 }
 
 type APIBackend struct {
@@ -161,6 +166,15 @@ func dumpPeer(id peer.ID, nw network.Network, pstore peerstore.Peerstore, connMg
 	info.Latency = pstore.LatencyEWMA(id)
 	if connMgr != nil {
 		info.Protected = connMgr.IsProtected(id, "")
+		info.Trusted = IsTrusted(connMgr, id)
+		if tags := connMgr.GetTagInfo(id); tags != nil {
+			for tag := range tags.Tags {
+				if strings.HasPrefix(tag, trustedPeerTagPrefix) {
+					info.TrustedTags = append(info.TrustedTags, strings.TrimPrefix(tag, trustedPeerTagPrefix))
+				}
+			}
+			sort.Strings(info.TrustedTags)
+		}
 	}
 
 	return info, nil
@@ -238,6 +252,33 @@ func (s *APIBackend) PeerStats(_ context.Context) (*PeerStats, error) {
 	return stats, nil
 }
 
+// ApplicationScores lists the application score breakdown of peers. Optionally filter to only retrieve connected peers.
+func (s *APIBackend) ApplicationScores(_ context.Context, connected bool) (map[string]*ApplicationScoreInfo, error) {
+	recordDur := s.m.RecordRPCServerRequest("opp2p_applicationScores")
+	defer recordDur()
+	scorer := s.node.AppScorer()
+	if _, noop := scorer.(*NoopApplicationScorer); scorer == nil || noop {
+		return nil, ErrDisabledAppScoring
+	}
+	h := s.node.Host()
+	var peers []peer.ID
+	if connected {
+		peers = h.Network().Peers()
+	} else {
+		peers = h.Peerstore().Peers()
+	}
+	out := make(map[string]*ApplicationScoreInfo, len(peers))
+	for _, id := range peers {
+		scores, breakdown, err := scorer.ScoreBreakdown(id)
+		if err != nil {
+			s.log.Debug("failed to load application scores in RPC request", "peer", id, "err", err)
+			continue
+		}
+		out[id.String()] = &ApplicationScoreInfo{Scores: scores, Breakdown: breakdown}
+	}
+	return out, nil
+}
+
 func (s *APIBackend) DiscoveryTable(_ context.Context) ([]*enode.Node, error) {
 	recordDur := s.m.RecordRPCServerRequest("opp2p_discoveryTable")
 	defer recordDur()
@@ -1,10 +1,21 @@
 package p2p
 
 import (
+	"context"
+	"crypto/ecdsa"
 	"math/big"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	opsigner "github.com/ethereum-optimism/optimism/op-signer/client"
 	"github.com/stretchr/testify/require"
 )
 
@@ -65,3 +76,58 @@ func TestSigningHash_LimitChainID(t *testing.T) {
 	_, err := SigningHash(SigningDomainBlocksV1, cfg.L2ChainID, []byte("arbitraryData"))
 	require.ErrorContains(t, err, "chain_id is too large")
 }
+
+// mockOpSigner serves the op-signer block payload signing API with a local key.
+type mockOpSigner struct {
+	priv *ecdsa.PrivateKey
+}
+
+func (m *mockOpSigner) Status() string {
+	return "ok"
+}
+
+func (m *mockOpSigner) SignBlockPayload(args opsigner.BlockPayloadArgs) (hexutil.Bytes, error) {
+	var msgInput [32 + 32 + 32]byte
+	copy(msgInput[:32], args.Domain)
+	args.ChainID.ToInt().FillBytes(msgInput[32:64])
+	copy(msgInput[64:], args.PayloadHash)
+	return crypto.Sign(crypto.Keccak256(msgInput[:]), m.priv)
+}
+
+func TestRemoteSigner(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("health", &mockOpSigner{priv: priv}))
+	require.NoError(t, server.RegisterName("opsigner", &mockOpSigner{priv: priv}))
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	defer server.Stop()
+
+	ctx := context.Background()
+	chainID := big.NewInt(100)
+	payloadBytes := []byte("arbitraryData")
+	expected, err := NewLocalSigner(priv).Sign(ctx, SigningDomainBlocksV1, chainID, payloadBytes)
+	require.NoError(t, err)
+
+	setup := &RemoteSignerSetup{
+		Log:     testlog.Logger(t, log.LvlError),
+		Config:  opsigner.CLIConfig{Endpoint: httpServer.URL, Address: crypto.PubkeyToAddress(priv.PublicKey).Hex()},
+		Timeout: time.Second,
+	}
+	signer, err := setup.SetupSigner(ctx)
+	require.NoError(t, err)
+	defer signer.Close()
+	sig, err := signer.Sign(ctx, SigningDomainBlocksV1, chainID, payloadBytes)
+	require.NoError(t, err)
+	require.Equal(t, expected, sig, "remote signature must be identical to the local signature")
+
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	setup.Config.Address = crypto.PubkeyToAddress(other.PublicKey).Hex()
+	signer, err = setup.SetupSigner(ctx)
+	require.NoError(t, err)
+	defer signer.Close()
+	_, err = signer.Sign(ctx, SigningDomainBlocksV1, chainID, payloadBytes)
+	require.ErrorContains(t, err, "signature recovers to", "signatures of another key are rejected")
+}
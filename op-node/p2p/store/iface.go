@@ -32,6 +32,14 @@ type ReqRespScores struct {
 	ValidResponses   float64 `json:"validResponses"`
 	ErrorResponses   float64 `json:"errorResponses"`
 	RejectedPayloads float64 `json:"rejectedPayloads"`
+	// Latency is the moving average of the response latency per received block, in seconds.
+	Latency float64 `json:"latency"`
+	// RangeCoverage is the moving average of the fraction of the requested blocks that were served, between 0 and 1.
+	RangeCoverage float64 `json:"rangeCoverage"`
+	// ResponseSamples is the number of responses that the latency and range coverage averages are based on.
+	ResponseSamples uint64 `json:"responseSamples"`
+	// UsefulMiB is the size of the served payloads that turned out to be canonical, in MiB.
+	UsefulMiB float64 `json:"usefulMiB"`
 }
 
 type IncrementValidResponses struct {
@@ -58,10 +66,43 @@ func (i IncrementRejectedPayloads) Apply(rec *scoreRecord) {
 	rec.PeerScores.ReqResp.RejectedPayloads = math.Min(rec.PeerScores.ReqResp.RejectedPayloads+1, i.Cap)
 }
 
+// RecordResponse adds the latency and the range coverage of a response to the moving averages.
+// SampleWeight is the weight of the new sample, between 0 and 1.
+// The first sample is taken as-is, the averages of a peer without samples are 0.
+type RecordResponse struct {
+	Latency      float64
+	Coverage     float64
+	SampleWeight float64
+}
+
+func (r RecordResponse) Apply(rec *scoreRecord) {
+	scores := &rec.PeerScores.ReqResp
+	scores.ResponseSamples++
+	if scores.ResponseSamples == 1 {
+		scores.Latency = r.Latency
+		scores.RangeCoverage = r.Coverage
+		return
+	}
+	scores.Latency += (r.Latency - scores.Latency) * r.SampleWeight
+	scores.RangeCoverage += (r.Coverage - scores.RangeCoverage) * r.SampleWeight
+}
+
+type IncrementUsefulBytes struct {
+	MiB float64
+	Cap float64
+}
+
+func (i IncrementUsefulBytes) Apply(rec *scoreRecord) {
+	rec.PeerScores.ReqResp.UsefulMiB = math.Min(rec.PeerScores.ReqResp.UsefulMiB+i.MiB, i.Cap)
+}
+
 type DecayApplicationScores struct {
 	ValidResponseDecay   float64
 	ErrorResponseDecay   float64
 	RejectedPayloadDecay float64
+	LatencyDecay         float64
+	RangeCoverageDecay   float64
+	UsefulBytesDecay     float64
 	DecayToZero          float64
 }
 
@@ -76,6 +117,9 @@ func (d *DecayApplicationScores) Apply(rec *scoreRecord) {
 	rec.PeerScores.ReqResp.ValidResponses = decay(rec.PeerScores.ReqResp.ValidResponses, d.ValidResponseDecay)
 	rec.PeerScores.ReqResp.ErrorResponses = decay(rec.PeerScores.ReqResp.ErrorResponses, d.ErrorResponseDecay)
 	rec.PeerScores.ReqResp.RejectedPayloads = decay(rec.PeerScores.ReqResp.RejectedPayloads, d.RejectedPayloadDecay)
+	rec.PeerScores.ReqResp.Latency = decay(rec.PeerScores.ReqResp.Latency, d.LatencyDecay)
+	rec.PeerScores.ReqResp.RangeCoverage = decay(rec.PeerScores.ReqResp.RangeCoverage, d.RangeCoverageDecay)
+	rec.PeerScores.ReqResp.UsefulMiB = decay(rec.PeerScores.ReqResp.UsefulMiB, d.UsefulBytesDecay)
 }
 
 type PeerScores struct {
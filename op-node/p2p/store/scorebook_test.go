@@ -301,3 +301,37 @@ func setScoreRequired(t *testing.T, store ScoreDatastore, id peer.ID, diff Score
 	_, err := store.SetScore(id, diff)
 	require.NoError(t, err)
 }
+
+func TestRecordResponse(t *testing.T) {
+	id := peer.ID("aaaa")
+	store := createMemoryStore(t)
+	// the first sample is taken as-is
+	setScoreRequired(t, store, id, RecordResponse{Latency: 2, Coverage: 0.5, SampleWeight: 0.25})
+	assertPeerScores(t, store, id, PeerScores{ReqResp: ReqRespScores{Latency: 2, RangeCoverage: 0.5, ResponseSamples: 1}})
+	// later samples are averaged
+	setScoreRequired(t, store, id, RecordResponse{Latency: 6, Coverage: 1, SampleWeight: 0.25})
+	assertPeerScores(t, store, id, PeerScores{ReqResp: ReqRespScores{Latency: 3, RangeCoverage: 0.625, ResponseSamples: 2}})
+	// a zero sample is averaged too, and not mistaken for the first sample
+	setScoreRequired(t, store, id, RecordResponse{Latency: 0, Coverage: 0, SampleWeight: 0.5})
+	assertPeerScores(t, store, id, PeerScores{ReqResp: ReqRespScores{Latency: 1.5, RangeCoverage: 0.3125, ResponseSamples: 3}})
+	setScoreRequired(t, store, id, RecordResponse{Latency: 0.5, Coverage: 0.3125, SampleWeight: 0.5})
+	assertPeerScores(t, store, id, PeerScores{ReqResp: ReqRespScores{Latency: 1, RangeCoverage: 0.3125, ResponseSamples: 4}})
+}
+
+func TestRecordResponseAfterZeroSample(t *testing.T) {
+	id := peer.ID("aaaa")
+	store := createMemoryStore(t)
+	// a first sample of zero latency and coverage, e.g. a fast empty response, still counts as a sample
+	setScoreRequired(t, store, id, RecordResponse{Latency: 0, Coverage: 0, SampleWeight: 0.25})
+	setScoreRequired(t, store, id, RecordResponse{Latency: 4, Coverage: 1, SampleWeight: 0.25})
+	assertPeerScores(t, store, id, PeerScores{ReqResp: ReqRespScores{Latency: 1, RangeCoverage: 0.25, ResponseSamples: 2}})
+}
+
+func TestIncrementUsefulBytes(t *testing.T) {
+	id := peer.ID("aaaa")
+	store := createMemoryStore(t)
+	setScoreRequired(t, store, id, IncrementUsefulBytes{MiB: 1.5, Cap: 2})
+	assertPeerScores(t, store, id, PeerScores{ReqResp: ReqRespScores{UsefulMiB: 1.5}})
+	setScoreRequired(t, store, id, IncrementUsefulBytes{MiB: 1.5, Cap: 2})
+	assertPeerScores(t, store, id, PeerScores{ReqResp: ReqRespScores{UsefulMiB: 2}})
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/multiformats/go-multistream"
 	"golang.org/x/time/rate"
 
 	"github.com/ethereum/go-ethereum"
@@ -59,12 +60,29 @@ const (
 	// and eventually kick the peer based on degraded scoring if it's really not serving us well.
 	// TODO(CLI-4009): Use a backoff rather than this mechanism.
 	clientErrRateCost = peerServerBlocksBurst
+	// Do not serve more than 16 payloads in a single range request
+	maxRangeRequestCount = 16
+	// Stop writing range response chunks once the (compressed) response data would exceed this size.
+	// The client simply re-requests the remaining payloads later.
+	maxRangeResponseSize = 4 * maxGossipSize
+	// Do not serve more than 64 payloads per second through range requests
+	globalServerRangeBlocksRateLimit rate.Limit = 64
+	// Allows a burst of 2x our rate limit
+	globalServerRangeBlocksBurst = 128
+	// Do not serve more than 16 payloads per second through range requests to the same peer
+	peerServerRangeBlocksRateLimit rate.Limit = 16
+	// Allow a peer to request 2 full ranges at once
+	peerServerRangeBlocksBurst = 2 * maxRangeRequestCount
 )
 
 func PayloadByNumberProtocolID(l2ChainID *big.Int) protocol.ID {
 	return protocol.ID(fmt.Sprintf("/opstack/req/payload_by_number/%d/0", l2ChainID))
 }
 
+func PayloadsByRangeProtocolID(l2ChainID *big.Int) protocol.ID {
+	return protocol.ID(fmt.Sprintf("/opstack/req/payloads_by_range/%d/0", l2ChainID))
+}
+
 type requestHandlerFn func(ctx context.Context, log log.Logger, stream network.Stream)
 
 func MakeStreamHandler(resourcesCtx context.Context, log log.Logger, fn requestHandlerFn) network.StreamHandler {
@@ -95,7 +113,10 @@ type syncResult struct {
 }
 
 type peerRequest struct {
+	// num is the lowest block number of the request
 	num uint64
+	// count is the number of consecutive blocks to fetch, starting at num
+	count uint64
 
 	complete *atomic.Bool
 }
@@ -108,13 +129,15 @@ type inFlightCheck struct {
 
 type SyncClientMetrics interface {
 	ClientPayloadByNumberEvent(num uint64, resultCode byte, duration time.Duration)
+	ClientPayloadsByRangeEvent(start uint64, count uint64, resultCode byte, duration time.Duration)
 	PayloadsQuarantineSize(n int)
 }
 
 type SyncPeerScorer interface {
-	onValidResponse(id peer.ID)
-	onResponseError(id peer.ID)
+	onValidResponse(id peer.ID, latency time.Duration, requested uint64, received uint64)
+	onResponseError(id peer.ID, latency time.Duration, requested uint64, received uint64)
 	onRejectedPayload(id peer.ID)
+	onUsefulPayload(id peer.ID, size uint64)
 }
 
 // SyncClient implements a reverse chain sync with a minimal interface:
@@ -130,6 +153,8 @@ type SyncPeerScorer interface {
 // The sync mechanism is implemented as following:
 // - User sends range request: blocks on sync main loop (with ctx timeout)
 // - Main loop processes range request (from high to low), dividing block requests by number between parallel peers.
+//   - Consecutive block numbers are grouped into spans of up to maxRangeRequestCount blocks,
+//     so a peer can serve the span with a single payloads_by_range request.
 //   - The high part of the range has a known block-hash, and is marked as trusted.
 //   - Once there are no more peers available for buffering requests, we stop the range request processing.
 //   - Every request buffered for a peer is tracked as in-flight, by block number.
@@ -138,7 +163,8 @@ type SyncPeerScorer interface {
 //   - Data already in the quarantine that is trusted is attempted to be promoted.
 //
 // - Peers each have their own routine for processing requests.
-//   - They fetch the requested block by number, parse and validate it, and then send it back to the main loop
+//   - They fetch the requested span of blocks by range, parse and validate it, and then send it back to the main loop
+//   - Peers that do not support the range protocol are asked for the blocks of the span one by one, by number.
 //   - If peers fail to fetch or process it, or fail to send it back to the main loop within timeout,
 //     then the doRequest returns an error. It then marks the in-flight request as completed.
 //
@@ -191,6 +217,7 @@ type SyncClient struct {
 
 	newStreamFn     newStreamFn
 	payloadByNumber protocol.ID
+	payloadsByRange protocol.ID
 
 	peersLock sync.Mutex
 	// syncing worker per peer
@@ -211,9 +238,14 @@ type SyncClient struct {
 	// inFlight requests are not repeated
 	inFlight map[uint64]*atomic.Bool
 
-	requests       chan rangeRequest
-	peerRequests   chan peerRequest
-	inFlightChecks chan inFlightCheck
+	requests     chan rangeRequest
+	peerRequests chan peerRequest
+	// preferredPeerRequests is unbuffered, requests are only sent to it if a preferred peer is ready to take them.
+	preferredPeerRequests chan peerRequest
+	inFlightChecks        chan inFlightCheck
+
+	// isPreferred returns true if the peer is preferred for sync requests, e.g. a trusted peer. May be nil.
+	isPreferred func(id peer.ID) bool
 
 	results chan syncResult
 
@@ -234,27 +266,32 @@ type SyncClient struct {
 	closingPeers bool
 }
 
-func NewSyncClient(log log.Logger, cfg *rollup.Config, newStream newStreamFn, rcv receivePayloadFn, metrics SyncClientMetrics, appScorer SyncPeerScorer) *SyncClient {
+// NewSyncClient creates a new SyncClient. Sync requests are preferably served by the peers that isPreferred
+// returns true for, if they are ready to take them. isPreferred may be nil.
+func NewSyncClient(log log.Logger, cfg *rollup.Config, newStream newStreamFn, rcv receivePayloadFn, metrics SyncClientMetrics, appScorer SyncPeerScorer, isPreferred func(id peer.ID) bool) *SyncClient {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	c := &SyncClient{
-		log:             log,
-		cfg:             cfg,
-		metrics:         metrics,
-		appScorer:       appScorer,
-		newStreamFn:     newStream,
-		payloadByNumber: PayloadByNumberProtocolID(cfg.L2ChainID),
-		peers:           make(map[peer.ID]context.CancelFunc),
-		quarantineByNum: make(map[uint64]common.Hash),
-		inFlight:        make(map[uint64]*atomic.Bool),
-		requests:        make(chan rangeRequest), // blocking
-		peerRequests:    make(chan peerRequest, 128),
-		results:         make(chan syncResult, 128),
-		inFlightChecks:  make(chan inFlightCheck, 128),
-		globalRL:        rate.NewLimiter(globalServerBlocksRateLimit, globalServerBlocksBurst),
-		resCtx:          ctx,
-		resCancel:       cancel,
-		receivePayload:  rcv,
+		log:                   log,
+		cfg:                   cfg,
+		metrics:               metrics,
+		appScorer:             appScorer,
+		newStreamFn:           newStream,
+		payloadByNumber:       PayloadByNumberProtocolID(cfg.L2ChainID),
+		payloadsByRange:       PayloadsByRangeProtocolID(cfg.L2ChainID),
+		peers:                 make(map[peer.ID]context.CancelFunc),
+		quarantineByNum:       make(map[uint64]common.Hash),
+		inFlight:              make(map[uint64]*atomic.Bool),
+		requests:              make(chan rangeRequest), // blocking
+		peerRequests:          make(chan peerRequest, 128),
+		preferredPeerRequests: make(chan peerRequest),
+		isPreferred:           isPreferred,
+		results:               make(chan syncResult, 128),
+		inFlightChecks:        make(chan inFlightCheck, 128),
+		globalRL:              rate.NewLimiter(globalServerBlocksRateLimit, globalServerBlocksBurst),
+		resCtx:                ctx,
+		resCancel:             cancel,
+		receivePayload:        rcv,
 	}
 	// never errors with positive LRU cache size
 	// TODO(CLI-3733): if we had an LRU based on on total payloads size, instead of payload count,
@@ -390,10 +427,47 @@ func (s *SyncClient) onRangeRequest(ctx context.Context, req rangeRequest) {
 		}
 	}
 
+	// span of consecutive block numbers that still needs to be scheduled, from spanTop down to spanTop-spanCount+1.
+	var spanTop, spanCount uint64
+	// schedule the pending span, if any. Returns false if no more work can be scheduled.
+	schedule := func() bool {
+		if spanCount == 0 {
+			return true
+		}
+		pr := peerRequest{num: spanTop - spanCount + 1, count: spanCount, complete: new(atomic.Bool)}
+		spanCount = 0
+
+		log.Debug("Scheduling P2P block request", "num", pr.num, "count", pr.count)
+		markInFlight := func() {
+			for i := uint64(0); i < pr.count; i++ {
+				s.inFlight[pr.num+i] = pr.complete
+			}
+		}
+		// prefer handing the request to a preferred peer that is ready for it
+		select {
+		case s.preferredPeerRequests <- pr:
+			markInFlight()
+			return true
+		default:
+		}
+		select {
+		case s.peerRequests <- pr:
+			markInFlight()
+			return true
+		case <-ctx.Done():
+			log.Info("did not schedule full P2P sync range", "current", pr.num, "err", ctx.Err())
+			return false
+		default: // peers may all be busy processing requests already
+			log.Info("no peers ready to handle block requests for more P2P requests for L2 block history", "current", pr.num)
+			return false
+		}
+	}
+
 	// Now try to fetch lower numbers than current end, to traverse back towards the updated start.
 	for i := uint64(0); ; i++ {
 		num := req.end.Number - 1 - i
 		if num <= req.start {
+			schedule()
 			return
 		}
 		// check if we have something in quarantine already
@@ -403,26 +477,29 @@ func (s *SyncClient) onRangeRequest(ctx context.Context, req rangeRequest) {
 			}
 			// Don't fetch things that we have a candidate for already.
 			// We'll evict it from quarantine by finding a conflict, or if we sync enough other blocks
+			if !schedule() {
+				return
+			}
 			continue
 		}
 
 		if _, ok := s.inFlight[num]; ok {
 			log.Debug("request still in-flight, not rescheduling sync request", "num", num)
+			if !schedule() {
+				return
+			}
 			continue // request still in flight
 		}
-		pr := peerRequest{num: num, complete: new(atomic.Bool)}
 
-		log.Debug("Scheduling P2P block request", "num", num)
-		// schedule number
-		select {
-		case s.peerRequests <- pr:
-			s.inFlight[num] = pr.complete
-		case <-ctx.Done():
-			log.Info("did not schedule full P2P sync range", "current", num, "err", ctx.Err())
-			return
-		default: // peers may all be busy processing requests already
-			log.Info("no peers ready to handle block requests for more P2P requests for L2 block history", "current", num)
-			return
+		// extend the span downwards, and schedule it once it reaches the max range size
+		if spanCount == 0 {
+			spanTop = num
+		}
+		spanCount += 1
+		if spanCount == maxRangeRequestCount {
+			if !schedule() {
+				return
+			}
 		}
 	}
 }
@@ -461,6 +538,7 @@ func (s *SyncClient) promote(ctx context.Context, res syncResult) {
 		return
 	}
 	s.trusted.Add(res.payload.BlockHash, struct{}{})
+	s.appScorer.onUsefulPayload(res.peer, uint64(res.payload.SizeSSZ()))
 	if s.quarantine.Remove(res.payload.BlockHash) {
 		s.log.Debug("promoted previously p2p-synced block from quarantine to main", "id", res.payload.ID())
 	} else {
@@ -512,6 +590,17 @@ func (s *SyncClient) peerLoop(ctx context.Context, id peer.ID) {
 	// Implement the same rate limits as the server does per-peer,
 	// so we don't be too aggressive to the server.
 	rl := rate.NewLimiter(peerServerBlocksRateLimit, peerServerBlocksBurst)
+	rangeRL := rate.NewLimiter(peerServerRangeBlocksRateLimit, peerServerRangeBlocksBurst)
+
+	// Assume the peer can serve payloads by range, until it turns out it cannot.
+	rangeSupported := true
+
+	// Preferred peers take requests from the preferred requests channel too, other peers never receive from it.
+	var preferredRequests chan peerRequest
+	if s.isPreferred != nil && s.isPreferred(id) {
+		log.Info("Peer is preferred for P2P sync requests")
+		preferredRequests = s.preferredPeerRequests
+	}
 
 	for {
 		// wait for a global allocation to be available
@@ -524,41 +613,94 @@ func (s *SyncClient) peerLoop(ctx context.Context, id peer.ID) {
 		}
 
 		// once the peer is available, wait for a sync request.
+		var pr peerRequest
 		select {
-		case pr := <-s.peerRequests:
-			// We already established the peer is available w.r.t. rate-limiting,
-			// and this is the only loop over this peer, so we can request now.
-			start := time.Now()
-			err := s.doRequest(ctx, id, pr.num)
-			if err != nil {
-				// mark as complete if there's an error: we are not sending any result and can complete immediately.
-				pr.complete.Store(true)
-				log.Warn("failed p2p sync request", "num", pr.num, "err", err)
-				s.appScorer.onResponseError(id)
-				// If we hit an error, then count it as many requests.
-				// We'd like to avoid making more requests for a while, to back off.
-				if err := rl.WaitN(ctx, clientErrRateCost); err != nil {
-					return
-				}
-			} else {
-				log.Debug("completed p2p sync request", "num", pr.num)
-				s.appScorer.onValidResponse(id)
-			}
-			took := time.Since(start)
-
-			resultCode := byte(0)
-			if err != nil {
-				if re, ok := err.(requestResultErr); ok {
-					resultCode = re.ResultCode()
-				} else {
-					resultCode = 1
-				}
-			}
-			s.metrics.ClientPayloadByNumberEvent(pr.num, resultCode, took)
+		case pr = <-preferredRequests:
+		case pr = <-s.peerRequests:
 		case <-ctx.Done():
 			return
 		}
+		// We already established the peer is available w.r.t. rate-limiting,
+		// and this is the only loop over this peer, so we can request now.
+		var received uint64
+		var err error
+		if pr.count > 1 && rangeSupported {
+			received, err = s.peerRangeRequest(ctx, log, id, pr, rangeRL)
+			if errors.Is(err, errRangeNotSupported) {
+				log.Info("peer does not support payloads by range, falling back to requests by number")
+				rangeSupported = false
+			}
+		}
+		if pr.count <= 1 || !rangeSupported {
+			received, err = s.peerNumberRequests(ctx, log, id, pr, rl)
+		}
+		// mark as complete if we did not send all results: the remaining blocks can then be rescheduled.
+		if received < pr.count {
+			pr.complete.Store(true)
+		}
+		if err != nil {
+			// If we hit an error, then count it as many requests.
+			// We'd like to avoid making more requests for a while, to back off.
+			if err := rl.WaitN(ctx, clientErrRateCost); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// peerNumberRequests fetches the blocks of the peer request one by one, from high to low,
+// through the payload_by_number protocol. It stops at the first error.
+// The number of results that were sent to the main loop is returned.
+func (s *SyncClient) peerNumberRequests(ctx context.Context, log log.Logger, id peer.ID, pr peerRequest, rl *rate.Limiter) (uint64, error) {
+	for i := uint64(0); i < pr.count; i++ {
+		// Every block after the first is another request, and subject to the same rate limits.
+		if i > 0 {
+			if err := s.globalRL.Wait(ctx); err != nil {
+				return i, err
+			}
+			if err := rl.Wait(ctx); err != nil {
+				return i, err
+			}
+		}
+		num := pr.num + pr.count - 1 - i
+		start := time.Now()
+		err := s.doRequest(ctx, id, num)
+		latency := time.Since(start)
+		s.metrics.ClientPayloadByNumberEvent(num, resultCodeOf(err), latency)
+		if err != nil {
+			log.Warn("failed p2p sync request", "num", num, "err", err)
+			s.appScorer.onResponseError(id, latency, 1, 0)
+			return i, err
+		}
+		log.Debug("completed p2p sync request", "num", num)
+		s.appScorer.onValidResponse(id, latency, 1, 1)
 	}
+	return pr.count, nil
+}
+
+// peerRangeRequest fetches all blocks of the peer request in one go, through the payloads_by_range protocol.
+// The number of results that were sent to the main loop is returned.
+// If the peer does not support the range protocol, errRangeNotSupported is returned.
+func (s *SyncClient) peerRangeRequest(ctx context.Context, log log.Logger, id peer.ID, pr peerRequest, rangeRL *rate.Limiter) (uint64, error) {
+	// Range requests are additionally rate-limited by the number of blocks they span
+	if err := rangeRL.WaitN(ctx, int(pr.count)); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	received, err := s.doRangeRequest(ctx, id, pr.num, pr.count)
+	if errors.Is(err, errRangeNotSupported) {
+		return received, err
+	}
+	latency := time.Since(start)
+	s.metrics.ClientPayloadsByRangeEvent(pr.num, pr.count, resultCodeOf(err), latency)
+	if err != nil {
+		log.Warn("failed p2p range sync request", "start", pr.num, "count", pr.count, "received", received, "err", err)
+		s.appScorer.onResponseError(id, latency, pr.count, received)
+		return received, err
+	}
+	log.Debug("completed p2p range sync request", "start", pr.num, "count", pr.count, "received", received)
+	s.appScorer.onValidResponse(id, latency, pr.count, received)
+	return received, nil
 }
 
 type requestResultErr byte
@@ -571,6 +713,19 @@ func (r requestResultErr) ResultCode() byte {
 	return byte(r)
 }
 
+// resultCodeOf translates a request error into a result code for metrics
+func resultCodeOf(err error) byte {
+	if err == nil {
+		return 0
+	}
+	if re, ok := err.(requestResultErr); ok {
+		return re.ResultCode()
+	}
+	return 1
+}
+
+var errRangeNotSupported = errors.New("peer does not support payloads by range")
+
 func (s *SyncClient) doRequest(ctx context.Context, id peer.ID, n uint64) error {
 	// open stream to peer
 	reqCtx, reqCancel := context.WithTimeout(ctx, streamTimeout)
@@ -638,6 +793,104 @@ func (s *SyncClient) doRequest(ctx context.Context, id peer.ID, n uint64) error
 	return nil
 }
 
+// doRangeRequest requests count payloads, starting at block number start, from the given peer.
+// The peer serves them from high to low. Every payload is verified and sent to the main loop as it comes in.
+// The number of payloads sent to the main loop is returned; this may be less than count if the peer
+// limited the size of the response.
+func (s *SyncClient) doRangeRequest(ctx context.Context, id peer.ID, start uint64, count uint64) (uint64, error) {
+	// open stream to peer. Only the range protocol is offered: if the peer does not support it,
+	// the blocks are requested by number on new streams instead.
+	reqCtx, reqCancel := context.WithTimeout(ctx, streamTimeout)
+	str, err := s.newStreamFn(reqCtx, id, s.payloadsByRange)
+	reqCancel()
+	if errors.Is(err, multistream.ErrNotSupported[protocol.ID]{}) {
+		return 0, errRangeNotSupported
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open stream: %w", err)
+	}
+	defer str.Close()
+	// set write timeout (if available)
+	_ = str.SetWriteDeadline(time.Now().Add(clientWriteRequestTimeout))
+	var req [16]byte
+	binary.LittleEndian.PutUint64(req[0:8], start)
+	binary.LittleEndian.PutUint64(req[8:16], count)
+	if _, err := str.Write(req[:]); err != nil {
+		return 0, fmt.Errorf("failed to write range request (%d, %d): %w", start, count, err)
+	}
+	if err := str.CloseWrite(); err != nil {
+		return 0, fmt.Errorf("failed to close writer side while making request: %w", err)
+	}
+
+	// Limit the total input. Individual chunks are limited further below.
+	r := io.LimitReader(str, maxRangeResponseSize)
+	var parent *eth.ExecutionPayload
+	for i := uint64(0); i < count; i++ {
+		// set read timeout (if available), per response chunk
+		_ = str.SetReadDeadline(time.Now().Add(clientReadResponsetimeout))
+
+		var result [1]byte
+		if _, err := io.ReadFull(r, result[:]); err != nil {
+			if errors.Is(err, io.EOF) && i > 0 {
+				// The server limited the size of the response. We'll request the remaining blocks later.
+				return i, nil
+			}
+			return i, fmt.Errorf("failed to read result part of response chunk %d: %w", i, err)
+		}
+		if res := result[0]; res != 0 {
+			return i, requestResultErr(res)
+		}
+		var header [8]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return i, fmt.Errorf("failed to read header part of response chunk %d: %w", i, err)
+		}
+		version := binary.LittleEndian.Uint32(header[0:4])
+		if version != 0 {
+			return i, fmt.Errorf("unrecognized ExecutionPayload version: %d", version)
+		}
+		size := binary.LittleEndian.Uint32(header[4:8])
+		if size > maxGossipSize {
+			return i, fmt.Errorf("response chunk %d of %d bytes is too large", i, size)
+		}
+		compressed := make([]byte, size)
+		if _, err := io.ReadFull(r, compressed); err != nil {
+			return i, fmt.Errorf("failed to read payload part of response chunk %d: %w", i, err)
+		}
+		// payload is SSZ encoded with Snappy block compression. Limit the output, to avoid a zip-bomb.
+		if n, err := snappy.DecodedLen(compressed); err != nil {
+			return i, fmt.Errorf("failed to read decoded length of response chunk %d: %w", i, err)
+		} else if n > maxGossipSize {
+			return i, fmt.Errorf("response chunk %d decodes to %d bytes, exceeding the limit", i, n)
+		}
+		data, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			return i, fmt.Errorf("failed to decompress response chunk %d: %w", i, err)
+		}
+		var res eth.ExecutionPayload
+		if err := res.UnmarshalSSZ(uint32(len(data)), bytes.NewReader(data)); err != nil {
+			return i, fmt.Errorf("failed to decode response chunk %d: %w", i, err)
+		}
+		if err := verifyBlock(&res, start+count-1-i); err != nil {
+			return i, fmt.Errorf("received execution payload is invalid: %w", err)
+		}
+		// The payloads of the range must form a chain
+		if parent != nil && parent.ParentHash != res.BlockHash {
+			return i, fmt.Errorf("received execution payload %s does not match parent hash %s of block %d",
+				res.ID(), parent.ParentHash, parent.BlockNumber)
+		}
+		parent = &res
+		select {
+		case s.results <- syncResult{payload: &res, peer: id}:
+		case <-ctx.Done():
+			return i, fmt.Errorf("failed to process response, sync client is too busy: %w", ctx.Err())
+		}
+	}
+	if err := str.CloseRead(); err != nil {
+		return count, fmt.Errorf("failed to close reading side")
+	}
+	return count, nil
+}
+
 func verifyBlock(payload *eth.ExecutionPayload, expectedNum uint64) error {
 	// verify L2 block
 	if expectedNum != uint64(payload.BlockNumber) {
@@ -654,6 +907,8 @@ func verifyBlock(payload *eth.ExecutionPayload, expectedNum uint64) error {
 type peerStat struct {
 	// Requests tokenizes each request to sync
 	Requests *rate.Limiter
+	// RangeBlocks tokenizes each block served to the peer through range requests
+	RangeBlocks *rate.Limiter
 }
 
 type L2Chain interface {
@@ -662,6 +917,7 @@ type L2Chain interface {
 
 type ReqRespServerMetrics interface {
 	ServerPayloadByNumberEvent(num uint64, resultCode byte, duration time.Duration)
+	ServerPayloadsByRangeEvent(start uint64, count uint64, resultCode byte, duration time.Duration)
 }
 
 type ReqRespServer struct {
@@ -675,6 +931,8 @@ type ReqRespServer struct {
 	peerStatsLock  sync.Mutex
 
 	globalRequestsRL *rate.Limiter
+	// globalRangeBlocksRL limits the total number of blocks served through range requests
+	globalRangeBlocksRL *rate.Limiter
 }
 
 func NewReqRespServer(cfg *rollup.Config, l2 L2Chain, metrics ReqRespServerMetrics) *ReqRespServer {
@@ -683,13 +941,15 @@ func NewReqRespServer(cfg *rollup.Config, l2 L2Chain, metrics ReqRespServerMetri
 
 	peerRateLimits, _ := simplelru.NewLRU[peer.ID, *peerStat](1000, nil)
 	globalRequestsRL := rate.NewLimiter(globalServerBlocksRateLimit, globalServerBlocksBurst)
+	globalRangeBlocksRL := rate.NewLimiter(globalServerRangeBlocksRateLimit, globalServerRangeBlocksBurst)
 
 	return &ReqRespServer{
-		cfg:              cfg,
-		l2:               l2,
-		metrics:          metrics,
-		peerRateLimits:   peerRateLimits,
-		globalRequestsRL: globalRequestsRL,
+		cfg:                 cfg,
+		l2:                  l2,
+		metrics:             metrics,
+		peerRateLimits:      peerRateLimits,
+		globalRequestsRL:    globalRequestsRL,
+		globalRangeBlocksRL: globalRangeBlocksRL,
 	}
 }
 
@@ -729,21 +989,23 @@ func (srv *ReqRespServer) HandleSyncRequest(ctx context.Context, log log.Logger,
 
 var invalidRequestErr = errors.New("invalid request")
 
-func (srv *ReqRespServer) handleSyncRequest(ctx context.Context, stream network.Stream) (uint64, error) {
-	peerId := stream.Conn().RemotePeer()
-
+// takeRequest takes a request token from the global and the peer rate-limiters,
+// and returns the rate-limiting data of the peer.
+func (srv *ReqRespServer) takeRequest(ctx context.Context, peerId peer.ID) (*peerStat, error) {
 	// take a token from the global rate-limiter,
 	// to make sure there's not too much concurrent server work between different peers.
 	if err := srv.globalRequestsRL.Wait(ctx); err != nil {
-		return 0, fmt.Errorf("timed out waiting for global sync rate limit: %w", err)
+		return nil, fmt.Errorf("timed out waiting for global sync rate limit: %w", err)
 	}
 
 	// find rate limiting data of peer, or add otherwise
 	srv.peerStatsLock.Lock()
+	defer srv.peerStatsLock.Unlock()
 	ps, _ := srv.peerRateLimits.Get(peerId)
 	if ps == nil {
 		ps = &peerStat{
-			Requests: rate.NewLimiter(peerServerBlocksRateLimit, peerServerBlocksBurst),
+			Requests:    rate.NewLimiter(peerServerBlocksRateLimit, peerServerBlocksBurst),
+			RangeBlocks: rate.NewLimiter(peerServerRangeBlocksRateLimit, peerServerRangeBlocksBurst),
 		}
 		srv.peerRateLimits.Add(peerId, ps)
 		ps.Requests.Reserve() // count the hit, but make it delay the next request rather than immediately waiting
@@ -754,10 +1016,16 @@ func (srv *ReqRespServer) handleSyncRequest(ctx context.Context, stream network.
 		// We'll disconnect ourselves only when failing to read/write,
 		// if the work is invalid (range validation), or when individual sub tasks timeout.
 		if err := ps.Requests.Wait(ctx); err != nil {
-			return 0, fmt.Errorf("timed out waiting for global sync rate limit: %w", err)
+			return nil, fmt.Errorf("timed out waiting for peer sync rate limit: %w", err)
 		}
 	}
-	srv.peerStatsLock.Unlock()
+	return ps, nil
+}
+
+func (srv *ReqRespServer) handleSyncRequest(ctx context.Context, stream network.Stream) (uint64, error) {
+	if _, err := srv.takeRequest(ctx, stream.Conn().RemotePeer()); err != nil {
+		return 0, err
+	}
 
 	// Set read deadline, if available
 	_ = stream.SetReadDeadline(time.Now().Add(serverReadRequestTimeout))
@@ -810,3 +1078,131 @@ func (srv *ReqRespServer) handleSyncRequest(ctx context.Context, stream network.
 	}
 	return req, nil
 }
+
+type payloadsByRangeRequest struct {
+	start uint64
+	count uint64
+}
+
+// HandleRangeSyncRequest is a stream handler function to register the L2 unsafe payloads-by-range alt-sync protocol.
+// See MakeStreamHandler to transform this into a LibP2P handler function.
+//
+// Note that the same peer may open parallel streams.
+//
+// The caller must Close the stream.
+func (srv *ReqRespServer) HandleRangeSyncRequest(ctx context.Context, log log.Logger, stream network.Stream) {
+	// may stay 0 if we fail to decode the request
+	start := time.Now()
+
+	// We wait as long as necessary; we throttle the peer instead of disconnecting,
+	// unless the delay reaches a threshold that is unreasonable to wait for.
+	ctx, cancel := context.WithTimeout(ctx, maxThrottleDelay)
+	req, served, err := srv.handleRangeSyncRequest(ctx, stream)
+	cancel()
+
+	resultCode := byte(0)
+	if err != nil {
+		log.Warn("failed to serve p2p range sync request", "start", req.start, "count", req.count, "served", served, "err", err)
+		if errors.Is(err, ethereum.NotFound) {
+			resultCode = 1
+		} else if errors.Is(err, invalidRequestErr) {
+			resultCode = 2
+		} else {
+			resultCode = 3
+		}
+		// try to write error code, so the other peer can understand the reason for failure.
+		// Any payloads that were already served precede it.
+		_, _ = stream.Write([]byte{resultCode})
+	} else {
+		log.Debug("successfully served range sync response", "start", req.start, "count", req.count, "served", served)
+	}
+	srv.metrics.ServerPayloadsByRangeEvent(req.start, req.count, resultCode, time.Since(start))
+}
+
+// handleRangeSyncRequest serves the requested payloads from high to low, each as a separate response chunk.
+// It returns the request, and the number of payloads that were written to the stream.
+func (srv *ReqRespServer) handleRangeSyncRequest(ctx context.Context, stream network.Stream) (payloadsByRangeRequest, uint64, error) {
+	var req payloadsByRangeRequest
+	ps, err := srv.takeRequest(ctx, stream.Conn().RemotePeer())
+	if err != nil {
+		return req, 0, err
+	}
+
+	// Set read deadline, if available
+	_ = stream.SetReadDeadline(time.Now().Add(serverReadRequestTimeout))
+
+	// Read the request
+	var data [16]byte
+	if _, err := io.ReadFull(stream, data[:]); err != nil {
+		return req, 0, fmt.Errorf("failed to read requested block range: %w", err)
+	}
+	req.start = binary.LittleEndian.Uint64(data[0:8])
+	req.count = binary.LittleEndian.Uint64(data[8:16])
+	if err := stream.CloseRead(); err != nil {
+		return req, 0, fmt.Errorf("failed to close reading-side of a P2P range sync request call: %w", err)
+	}
+
+	// Check the request is within the expected range of blocks
+	if req.count == 0 || req.count > maxRangeRequestCount {
+		return req, 0, fmt.Errorf("cannot serve request for %d blocks, expected 1 to %d: %w", req.count, maxRangeRequestCount, invalidRequestErr)
+	}
+	if req.start < srv.cfg.Genesis.L2.Number {
+		return req, 0, fmt.Errorf("cannot serve request for L2 block %d before genesis %d: %w", req.start, srv.cfg.Genesis.L2.Number, invalidRequestErr)
+	}
+	max, err := srv.cfg.TargetBlockNumber(uint64(time.Now().Unix()))
+	if err != nil {
+		return req, 0, fmt.Errorf("cannot determine max target block number to verify request: %w", invalidRequestErr)
+	}
+	if req.start > max || req.count-1 > max-req.start {
+		return req, 0, fmt.Errorf("cannot serve request for L2 blocks %d - %d after max expected block (%v): %w", req.start, req.start+req.count-1, max, invalidRequestErr)
+	}
+
+	// The range is valid: now rate-limit by the number of blocks, both for this peer and globally.
+	if err := ps.RangeBlocks.WaitN(ctx, int(req.count)); err != nil {
+		return req, 0, fmt.Errorf("timed out waiting for peer range sync rate limit: %w", err)
+	}
+	if err := srv.globalRangeBlocksRL.WaitN(ctx, int(req.count)); err != nil {
+		return req, 0, fmt.Errorf("timed out waiting for global range sync rate limit: %w", err)
+	}
+
+	var buf bytes.Buffer
+	var written int
+	for i := uint64(0); i < req.count; i++ {
+		num := req.start + req.count - 1 - i
+		payload, err := srv.l2.PayloadByNumber(ctx, num)
+		if err != nil {
+			if errors.Is(err, ethereum.NotFound) {
+				return req, i, fmt.Errorf("peer requested unknown block %d by range: %w", num, err)
+			} else {
+				return req, i, fmt.Errorf("failed to retrieve payload %d to serve to peer: %w", num, err)
+			}
+		}
+		buf.Reset()
+		if _, err := payload.MarshalSSZ(&buf); err != nil {
+			return req, i, fmt.Errorf("failed to encode payload %d for range sync response: %w", num, err)
+		}
+		compressed := snappy.Encode(nil, buf.Bytes())
+
+		// 0 - resultCode: success = 0
+		// 1:5 - version: 0
+		// 5:9 - length of the compressed payload
+		var header [9]byte
+		binary.LittleEndian.PutUint32(header[5:9], uint32(len(compressed)))
+
+		// Stop early if the response gets too large. The remainder can be requested again.
+		if written+len(header)+len(compressed) > maxRangeResponseSize {
+			return req, i, nil
+		}
+
+		// We set write deadline, if available, to safely write without blocking on a throttling peer connection
+		_ = stream.SetWriteDeadline(time.Now().Add(serverWriteChunkTimeout))
+		if _, err := stream.Write(header[:]); err != nil {
+			return req, i, fmt.Errorf("failed to write response chunk header: %w", err)
+		}
+		if _, err := stream.Write(compressed); err != nil {
+			return req, i, fmt.Errorf("failed to write payload %d to range sync response: %w", num, err)
+		}
+		written += len(header) + len(compressed)
+	}
+	return req, req.count, nil
+}
@@ -2,8 +2,12 @@ package p2p
 
 import (
 	"context"
+	"encoding/binary"
+	"io"
 	"math/big"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -137,7 +141,7 @@ func TestSinglePeerSync(t *testing.T) {
 	hostA.SetStreamHandler(PayloadByNumberProtocolID(cfg.L2ChainID), payloadByNumber)
 
 	// Setup host B as the client
-	cl := NewSyncClient(log.New("role", "client"), cfg, hostB.NewStream, receivePayload, metrics.NoopMetrics, &NoopApplicationScorer{})
+	cl := NewSyncClient(log.New("role", "client"), cfg, hostB.NewStream, receivePayload, metrics.NoopMetrics, &NoopApplicationScorer{}, nil)
 
 	// Setup host B (client) to sync from its peer Host A (server)
 	cl.AddPeer(hostA.ID())
@@ -157,6 +161,231 @@ func TestSinglePeerSync(t *testing.T) {
 	}
 }
 
+func TestSinglePeerRangeSync(t *testing.T) {
+	t.Parallel() // Takes a while, but can run in parallel
+
+	log := testlog.Logger(t, log.LvlError)
+
+	cfg, payloads := setupSyncTestData(60)
+
+	// Serving payloads: just load them from the map, if they exist
+	servePayload := mockPayloadFn(func(n uint64) (*eth.ExecutionPayload, error) {
+		p, ok := payloads.getPayload(n)
+		if !ok {
+			return nil, ethereum.NotFound
+		}
+		return p, nil
+	})
+
+	// collect received payloads in a buffered channel, so we can verify we get everything
+	received := make(chan *eth.ExecutionPayload, 100)
+	receivePayload := receivePayloadFn(func(ctx context.Context, from peer.ID, payload *eth.ExecutionPayload) error {
+		received <- payload
+		return nil
+	})
+
+	// Setup 2 minimal test hosts to attach the sync protocol to
+	mnet, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err, "failed to setup mocknet")
+	defer mnet.Close()
+	hosts := mnet.Hosts()
+	hostA, hostB := hosts[0], hosts[1]
+	require.Equal(t, hostA.Network().Connectedness(hostB.ID()), network.Connected)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Setup host A as the server, only serving payloads by range.
+	srv := NewReqRespServer(cfg, servePayload, metrics.NoopMetrics)
+	payloadsByRange := MakeStreamHandler(ctx, log.New("role", "server"), srv.HandleRangeSyncRequest)
+	hostA.SetStreamHandler(PayloadsByRangeProtocolID(cfg.L2ChainID), payloadsByRange)
+
+	// Setup host B as the client
+	cl := NewSyncClient(log.New("role", "client"), cfg, hostB.NewStream, receivePayload, metrics.NoopMetrics, &NoopApplicationScorer{}, nil)
+
+	// Setup host B (client) to sync from its peer Host A (server)
+	cl.AddPeer(hostA.ID())
+	cl.Start()
+	defer cl.Close()
+
+	// request to start syncing between 10 and 50, this spans multiple range requests
+	require.NoError(t, cl.RequestL2Range(ctx, payloads.getBlockRef(10), payloads.getBlockRef(50)))
+
+	// and wait for the sync results to come in (in reverse order)
+	for i := uint64(49); i > 10; i-- {
+		p := <-received
+		require.Equal(t, uint64(p.BlockNumber), i, "expecting payloads in order")
+		exp, ok := payloads.getPayload(uint64(p.BlockNumber))
+		require.True(t, ok, "expecting known payload")
+		require.Equal(t, exp.BlockHash, p.BlockHash, "expecting the correct payload")
+	}
+}
+
+func TestRangeSyncFallbackToNumber(t *testing.T) {
+	t.Parallel() // Takes a while, but can run in parallel
+
+	log := testlog.Logger(t, log.LvlError)
+
+	cfg, payloads := setupSyncTestData(25)
+
+	// Count the payloads that are served, to verify that no stream is left without a response
+	var served atomic.Uint64
+	servePayload := mockPayloadFn(func(n uint64) (*eth.ExecutionPayload, error) {
+		served.Add(1)
+		p, ok := payloads.getPayload(n)
+		if !ok {
+			return nil, ethereum.NotFound
+		}
+		return p, nil
+	})
+
+	received := make(chan *eth.ExecutionPayload, 100)
+	receivePayload := receivePayloadFn(func(ctx context.Context, from peer.ID, payload *eth.ExecutionPayload) error {
+		received <- payload
+		return nil
+	})
+
+	mnet, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err, "failed to setup mocknet")
+	defer mnet.Close()
+	hosts := mnet.Hosts()
+	hostA, hostB := hosts[0], hosts[1]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Setup host A as a server that only supports payloads by number
+	srv := NewReqRespServer(cfg, servePayload, metrics.NoopMetrics)
+	payloadByNumber := MakeStreamHandler(ctx, log.New("role", "server"), srv.HandleSyncRequest)
+	var streams atomic.Uint64
+	hostA.SetStreamHandler(PayloadByNumberProtocolID(cfg.L2ChainID), func(stream network.Stream) {
+		streams.Add(1)
+		payloadByNumber(stream)
+	})
+
+	cl := NewSyncClient(log.New("role", "client"), cfg, hostB.NewStream, receivePayload, metrics.NoopMetrics, &NoopApplicationScorer{}, nil)
+	cl.AddPeer(hostA.ID())
+	cl.Start()
+	defer cl.Close()
+
+	// the range is requested in spans, which are requested by number instead
+	require.NoError(t, cl.RequestL2Range(ctx, payloads.getBlockRef(10), payloads.getBlockRef(20)))
+	for i := uint64(19); i > 10; i-- {
+		p := <-received
+		require.Equal(t, uint64(p.BlockNumber), i, "expecting payloads in order")
+	}
+	// every by-number stream was a request for a block, and none was opened for the range protocol
+	require.Equal(t, served.Load(), streams.Load())
+}
+
+func TestRangeSyncResponseSizeLimit(t *testing.T) {
+	t.Parallel() // Takes a while, but can run in parallel
+
+	log := testlog.Logger(t, log.LvlError)
+
+	cfg, payloads := setupSyncTestData(0)
+	// Blocks with incompressible transactions of half the gossip size,
+	// so that only 7 blocks fit in a single range response.
+	rng := rand.New(rand.NewSource(1234))
+	count := uint64(10)
+	for i := uint64(1); i <= count; i++ {
+		tx := make([]byte, maxGossipSize/2)
+		rng.Read(tx)
+		parent, _ := payloads.getPayload(i - 1)
+		payload := &eth.ExecutionPayload{
+			ParentHash:   parent.BlockHash,
+			BlockNumber:  eth.Uint64Quantity(i),
+			Timestamp:    eth.Uint64Quantity(cfg.Genesis.L2Time + i*cfg.BlockTime),
+			Transactions: []eth.Data{tx},
+		}
+		payload.BlockHash, _ = payload.CheckBlockHash()
+		payloads.addPayload(payload)
+	}
+	servePayload := mockPayloadFn(func(n uint64) (*eth.ExecutionPayload, error) {
+		p, ok := payloads.getPayload(n)
+		if !ok {
+			return nil, ethereum.NotFound
+		}
+		return p, nil
+	})
+
+	mnet, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err, "failed to setup mocknet")
+	defer mnet.Close()
+	hosts := mnet.Hosts()
+	hostA, hostB := hosts[0], hosts[1]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := NewReqRespServer(cfg, servePayload, metrics.NoopMetrics)
+	payloadsByRange := MakeStreamHandler(ctx, log.New("role", "server"), srv.HandleRangeSyncRequest)
+	hostA.SetStreamHandler(PayloadsByRangeProtocolID(cfg.L2ChainID), payloadsByRange)
+
+	received := make(chan *eth.ExecutionPayload, count)
+	receivePayload := receivePayloadFn(func(ctx context.Context, from peer.ID, payload *eth.ExecutionPayload) error {
+		return nil
+	})
+	cl := NewSyncClient(log.New("role", "client"), cfg, hostB.NewStream, receivePayload, metrics.NoopMetrics, &NoopApplicationScorer{}, nil)
+	go func() {
+		for res := range cl.results {
+			received <- res.payload
+		}
+	}()
+
+	// the response is truncated, without an error, and the remainder can be requested again
+	n, err := cl.doRangeRequest(ctx, hostA.ID(), 1, count)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), n)
+	for i := count; i > count-n; i-- {
+		p := <-received
+		require.Equal(t, i, uint64(p.BlockNumber), "expecting payloads from high to low")
+	}
+	close(cl.results)
+}
+
+func TestRangeSyncInvalidCount(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+
+	cfg, payloads := setupSyncTestData(25)
+	servePayload := mockPayloadFn(func(n uint64) (*eth.ExecutionPayload, error) {
+		p, ok := payloads.getPayload(n)
+		if !ok {
+			return nil, ethereum.NotFound
+		}
+		return p, nil
+	})
+
+	mnet, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err, "failed to setup mocknet")
+	defer mnet.Close()
+	hosts := mnet.Hosts()
+	hostA, hostB := hosts[0], hosts[1]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := NewReqRespServer(cfg, servePayload, metrics.NoopMetrics)
+	payloadsByRange := MakeStreamHandler(ctx, log.New("role", "server"), srv.HandleRangeSyncRequest)
+	hostA.SetStreamHandler(PayloadsByRangeProtocolID(cfg.L2ChainID), payloadsByRange)
+
+	for _, count := range []uint64{0, maxRangeRequestCount + 1} {
+		str, err := hostB.NewStream(ctx, hostA.ID(), PayloadsByRangeProtocolID(cfg.L2ChainID))
+		require.NoError(t, err)
+		var req [16]byte
+		binary.LittleEndian.PutUint64(req[0:8], 1)
+		binary.LittleEndian.PutUint64(req[8:16], count)
+		_, err = str.Write(req[:])
+		require.NoError(t, err)
+		require.NoError(t, str.CloseWrite())
+
+		res, err := io.ReadAll(str)
+		require.NoError(t, err)
+		require.Equal(t, []byte{2}, res, "expecting invalid request result code for count %d", count)
+		require.NoError(t, str.Close())
+	}
+}
+
 func TestMultiPeerSync(t *testing.T) {
 	t.Parallel() // Takes a while, but can run in parallel
 
@@ -190,7 +419,7 @@ func TestMultiPeerSync(t *testing.T) {
 		payloadByNumber := MakeStreamHandler(ctx, log.New("serve", "payloads_by_number"), srv.HandleSyncRequest)
 		h.SetStreamHandler(PayloadByNumberProtocolID(cfg.L2ChainID), payloadByNumber)
 
-		cl := NewSyncClient(log.New("role", "client"), cfg, h.NewStream, receivePayload, metrics.NoopMetrics, &NoopApplicationScorer{})
+		cl := NewSyncClient(log.New("role", "client"), cfg, h.NewStream, receivePayload, metrics.NoopMetrics, &NoopApplicationScorer{}, nil)
 		return cl, received
 	}
 
@@ -288,3 +517,67 @@ func TestMultiPeerSync(t *testing.T) {
 		require.Equal(t, exp.BlockHash, p.BlockHash, "expecting the correct payload")
 	}
 }
+
+func TestPreferredPeerSync(t *testing.T) {
+	t.Parallel() // Takes a while, but can run in parallel
+
+	log := testlog.Logger(t, log.LvlError)
+
+	cfg, payloads := setupSyncTestData(25)
+
+	received := make(chan *eth.ExecutionPayload, 100)
+	receivePayload := receivePayloadFn(func(ctx context.Context, from peer.ID, payload *eth.ExecutionPayload) error {
+		received <- payload
+		return nil
+	})
+
+	// Setup 3 minimal test hosts: A and B serve payloads, C syncs from them
+	mnet, err := mocknet.FullMeshConnected(3)
+	require.NoError(t, err, "failed to setup mocknet")
+	defer mnet.Close()
+	hosts := mnet.Hosts()
+	hostA, hostB, hostC := hosts[0], hosts[1], hosts[2]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// count the range requests that every server receives
+	serve := func(h host.Host) *atomic.Uint64 {
+		var streams atomic.Uint64
+		srv := NewReqRespServer(cfg, mockPayloadFn(func(n uint64) (*eth.ExecutionPayload, error) {
+			p, ok := payloads.getPayload(n)
+			if !ok {
+				return nil, ethereum.NotFound
+			}
+			return p, nil
+		}), metrics.NoopMetrics)
+		payloadsByRange := MakeStreamHandler(ctx, log.New("role", "server"), srv.HandleRangeSyncRequest)
+		h.SetStreamHandler(PayloadsByRangeProtocolID(cfg.L2ChainID), func(stream network.Stream) {
+			streams.Add(1)
+			payloadsByRange(stream)
+		})
+		return &streams
+	}
+	streamsA := serve(hostA)
+	streamsB := serve(hostB)
+
+	// A is preferred, e.g. a trusted peer
+	isPreferred := func(id peer.ID) bool { return id == hostA.ID() }
+	cl := NewSyncClient(log.New("role", "client"), cfg, hostC.NewStream, receivePayload, metrics.NoopMetrics, &NoopApplicationScorer{}, isPreferred)
+	cl.AddPeer(hostA.ID())
+	cl.AddPeer(hostB.ID())
+	cl.Start()
+	defer cl.Close()
+
+	// give the peer loops the time to get ready for requests
+	time.Sleep(100 * time.Millisecond)
+
+	require.NoError(t, cl.RequestL2Range(ctx, payloads.getBlockRef(10), payloads.getBlockRef(20)))
+	for i := uint64(19); i > 10; i-- {
+		p := <-received
+		require.Equal(t, uint64(p.BlockNumber), i, "expecting payloads in order")
+	}
+	// the idle preferred peer takes the request, even though the other peer is ready too
+	require.Equal(t, uint64(1), streamsA.Load())
+	require.Zero(t, streamsB.Load())
+}
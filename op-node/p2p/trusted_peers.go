@@ -0,0 +1,283 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/libp2p/go-libp2p/core/connmgr"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	trustedPeerTag = "trusted"
+	// trustedPeerTagPrefix prefixes the tags of trusted peers in the connection manager
+	trustedPeerTagPrefix = "trusted-"
+	// trustedPeerTagValue is the connection manager tag value of trusted peers, above the value of discovered peers.
+	trustedPeerTagValue = 100
+
+	trustedPeerDialTimeout   = 30 * time.Second
+	trustedPeerMinRedialWait = time.Second
+	trustedPeerMaxRedialWait = 5 * time.Minute
+	// trustedPeerPollInterval is the interval to check the trusted peer connections at, in case a disconnect was missed.
+	trustedPeerPollInterval = time.Minute
+)
+
+// TrustedPeersFile is the JSON config file of trusted peers.
+type TrustedPeersFile struct {
+	// Slots is the number of connection slots dedicated to trusted peers, on top of the regular peer limits.
+	// At most this many trusted peers are kept connected, in the order of the file. Defaults to the number of peers.
+	Slots uint `json:"slots,omitempty"`
+	Peers []struct {
+		// Addr is a multiaddr with a /p2p/ component, or an ENR.
+		Addr string `json:"addr"`
+		// Tags are applied to the peer in the connection manager, to identify it with.
+		Tags []string `json:"tags,omitempty"`
+	} `json:"peers"`
+}
+
+// TrustedPeer is a peer that is kept connected, and is exempt from score-based bans.
+type TrustedPeer struct {
+	Info *peer.AddrInfo
+	Tags []string
+}
+
+// TrustedPeers are the trusted peers, and the connection slots dedicated to them.
+type TrustedPeers struct {
+	Slots uint
+	Peers []TrustedPeer
+}
+
+// LoadTrustedPeers loads the trusted peers from a JSON file, see TrustedPeersFile.
+func LoadTrustedPeers(path string) (*TrustedPeers, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted peers file: %w", err)
+	}
+	var file TrustedPeersFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode trusted peers file: %w", err)
+	}
+	out := &TrustedPeers{Slots: file.Slots}
+	seen := make(map[peer.ID]struct{})
+	for i, p := range file.Peers {
+		info, err := parseTrustedPeerAddr(p.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address of trusted peer %d: %w", i, err)
+		}
+		if _, ok := seen[info.ID]; ok {
+			return nil, fmt.Errorf("duplicate trusted peer %s", info.ID)
+		}
+		seen[info.ID] = struct{}{}
+		out.Peers = append(out.Peers, TrustedPeer{Info: info, Tags: p.Tags})
+	}
+	if out.Slots == 0 || out.Slots > uint(len(out.Peers)) {
+		out.Slots = uint(len(out.Peers))
+	}
+	return out, nil
+}
+
+func parseTrustedPeerAddr(addr string) (*peer.AddrInfo, error) {
+	if strings.HasPrefix(addr, "enr:") {
+		rec, err := enode.Parse(enode.ValidSchemes, addr)
+		if err != nil {
+			return nil, err
+		}
+		info, _, err := enrToAddrInfo(rec)
+		return info, err
+	}
+	a, err := ma.NewMultiaddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	return peer.AddrInfoFromP2pAddr(a)
+}
+
+// SlotBudget returns the number of connection slots dedicated to trusted peers. It is safe to call on a nil TrustedPeers.
+func (t *TrustedPeers) SlotBudget() uint {
+	if t == nil {
+		return 0
+	}
+	return t.Slots
+}
+
+// IsTrusted returns true if the peer is a trusted peer, i.e. is protected with the trusted tag.
+func IsTrusted(connMgr connmgr.ConnManager, id peer.ID) bool {
+	return connMgr != nil && connMgr.IsProtected(id, trustedPeerTag)
+}
+
+// trustedPeerManager keeps up to the slot budget of trusted peers connected,
+// and redials them when they disconnect, with exponential backoff on failed dials.
+type trustedPeerManager struct {
+	log     log.Logger
+	host    host.Host
+	connMgr connmgr.ConnManager
+	peers   *TrustedPeers
+
+	redial chan struct{}
+
+	mu sync.Mutex
+	// dialing tracks the trusted peers that are being dialed
+	dialing map[peer.ID]struct{}
+	// backoff tracks the wait after the last failed dial per trusted peer, and when to dial next.
+	backoff  map[peer.ID]time.Duration
+	nextDial map[peer.ID]time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newTrustedPeerManager(log log.Logger, h host.Host, connMgr connmgr.ConnManager, peers *TrustedPeers) *trustedPeerManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &trustedPeerManager{
+		log:      log,
+		host:     h,
+		connMgr:  connMgr,
+		peers:    peers,
+		redial:   make(chan struct{}, 1),
+		dialing:  make(map[peer.ID]struct{}),
+		backoff:  make(map[peer.ID]time.Duration),
+		nextDial: make(map[peer.ID]time.Time),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func (m *trustedPeerManager) Start() {
+	for _, p := range m.peers.Peers {
+		m.host.Peerstore().AddAddrs(p.Info.ID, p.Info.Addrs, time.Hour*24*7)
+		// Protect the peer with a dedicated tag, so the connection manager doesn't prune it,
+		// and other protects/unprotects with different tags don't affect this protection.
+		m.connMgr.Protect(p.Info.ID, trustedPeerTag)
+		for _, tag := range p.Tags {
+			m.connMgr.TagPeer(p.Info.ID, trustedPeerTagPrefix+tag, trustedPeerTagValue)
+		}
+	}
+	m.host.Network().Notify(&network.NotifyBundle{
+		DisconnectedF: func(nw network.Network, conn network.Conn) {
+			if IsTrusted(m.connMgr, conn.RemotePeer()) && nw.Connectedness(conn.RemotePeer()) != network.Connected {
+				m.log.Warn("trusted peer disconnected, redialing", "peer", conn.RemotePeer())
+				m.scheduleRedial()
+			}
+		},
+	})
+	m.wg.Add(1)
+	go m.loop()
+}
+
+func (m *trustedPeerManager) Close() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+func (m *trustedPeerManager) scheduleRedial() {
+	select {
+	case m.redial <- struct{}{}:
+	default: // already scheduled
+	}
+}
+
+func (m *trustedPeerManager) loop() {
+	defer m.wg.Done()
+	poll := time.NewTicker(trustedPeerPollInterval)
+	defer poll.Stop()
+	// retry is the timer to retry dialing trusted peers that failed to dial, after their backoff.
+	retry := time.NewTimer(0)
+	defer retry.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-poll.C:
+		case <-m.redial:
+		case <-retry.C:
+		}
+		if wait := m.fill(); wait > 0 {
+			if !retry.Stop() {
+				select {
+				case <-retry.C:
+				default:
+				}
+			}
+			retry.Reset(wait)
+		}
+	}
+}
+
+// fill dials trusted peers, in the order of the config, until the slot budget is used.
+// It returns the time to wait until the next trusted peer may be dialed, or 0 if there is none waiting.
+func (m *trustedPeerManager) fill() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	used := uint(0)
+	for _, p := range m.peers.Peers {
+		_, dialing := m.dialing[p.Info.ID]
+		if dialing || m.host.Network().Connectedness(p.Info.ID) == network.Connected {
+			used += 1
+		}
+	}
+	var wait time.Duration
+	for _, p := range m.peers.Peers {
+		if used >= m.peers.Slots {
+			break
+		}
+		id := p.Info.ID
+		if _, ok := m.dialing[id]; ok || m.host.Network().Connectedness(id) == network.Connected {
+			continue
+		}
+		if next := m.nextDial[id]; next.After(now) {
+			if d := next.Sub(now); wait == 0 || d < wait {
+				wait = d
+			}
+			continue
+		}
+		used += 1
+		m.dialing[id] = struct{}{}
+		m.wg.Add(1)
+		go m.dial(p)
+	}
+	return wait
+}
+
+func (m *trustedPeerManager) dial(p TrustedPeer) {
+	defer m.wg.Done()
+	id := p.Info.ID
+	ctx, cancel := context.WithTimeout(m.ctx, trustedPeerDialTimeout)
+	defer cancel()
+	m.log.Info("dialing trusted peer", "peer", id, "addrs", p.Info.Addrs, "tags", p.Tags)
+	_, err := m.host.Network().DialPeer(ctx, id)
+
+	m.mu.Lock()
+	delete(m.dialing, id)
+	if err != nil {
+		backoff := m.backoff[id] * 2
+		if backoff < trustedPeerMinRedialWait {
+			backoff = trustedPeerMinRedialWait
+		} else if backoff > trustedPeerMaxRedialWait {
+			backoff = trustedPeerMaxRedialWait
+		}
+		m.backoff[id] = backoff
+		m.nextDial[id] = time.Now().Add(backoff)
+		if !errors.Is(err, context.Canceled) {
+			m.log.Warn("failed to dial trusted peer", "peer", id, "retry_in", backoff, "err", err)
+		}
+	} else {
+		delete(m.backoff, id)
+		delete(m.nextDial, id)
+	}
+	m.mu.Unlock()
+	// use the freed slot or retry timing
+	m.scheduleRedial()
+}
@@ -0,0 +1,195 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	cmgr "github.com/libp2p/go-libp2p/p2p/net/connmgr"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func writeTrustedPeersFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "trusted.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadTrustedPeers(t *testing.T) {
+	priv, _, err := libp2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	pid, err := peer.IDFromPrivateKey(priv)
+	require.NoError(t, err)
+	maddr := "/ip4/127.0.0.1/tcp/9222/p2p/" + pid.String()
+
+	enrKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	db, err := enode.OpenDB("")
+	require.NoError(t, err)
+	defer db.Close()
+	localNode := enode.NewLocalNode(db, enrKey)
+	localNode.Set(enr.IP(net.IPv4(10, 0, 0, 1)))
+	localNode.Set(enr.TCP(9223))
+	enrPeer, _, err := enrToAddrInfo(localNode.Node())
+	require.NoError(t, err)
+
+	t.Run("multiaddr and enr", func(t *testing.T) {
+		path := writeTrustedPeersFile(t, `{"peers": [
+			{"addr": "`+maddr+`", "tags": ["sequencer"]},
+			{"addr": "`+localNode.Node().String()+`"}
+		]}`)
+		peers, err := LoadTrustedPeers(path)
+		require.NoError(t, err)
+		require.Len(t, peers.Peers, 2)
+		require.Equal(t, uint(2), peers.SlotBudget(), "slots default to the number of peers")
+
+		require.Equal(t, pid, peers.Peers[0].Info.ID)
+		require.Equal(t, "/ip4/127.0.0.1/tcp/9222", peers.Peers[0].Info.Addrs[0].String())
+		require.Equal(t, []string{"sequencer"}, peers.Peers[0].Tags)
+
+		require.Equal(t, enrPeer.ID, peers.Peers[1].Info.ID)
+		require.Equal(t, "/ip4/10.0.0.1/tcp/9223", peers.Peers[1].Info.Addrs[0].String())
+		require.Empty(t, peers.Peers[1].Tags)
+	})
+
+	t.Run("slots", func(t *testing.T) {
+		path := writeTrustedPeersFile(t, `{"slots": 1, "peers": [{"addr": "`+maddr+`"}, {"addr": "`+localNode.Node().String()+`"}]}`)
+		peers, err := LoadTrustedPeers(path)
+		require.NoError(t, err)
+		require.Equal(t, uint(1), peers.SlotBudget())
+
+		path = writeTrustedPeersFile(t, `{"slots": 5, "peers": [{"addr": "`+maddr+`"}]}`)
+		peers, err = LoadTrustedPeers(path)
+		require.NoError(t, err)
+		require.Equal(t, uint(1), peers.SlotBudget(), "slots are capped at the number of peers")
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		path := writeTrustedPeersFile(t, `{"peers": [{"addr": "`+maddr+`"}, {"addr": "`+maddr+`"}]}`)
+		_, err := LoadTrustedPeers(path)
+		require.ErrorContains(t, err, "duplicate trusted peer")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		path := writeTrustedPeersFile(t, `{"peers": [{"addr": "/ip4/127.0.0.1/tcp/9222"}]}`)
+		_, err := LoadTrustedPeers(path)
+		require.ErrorContains(t, err, "invalid address of trusted peer 0")
+	})
+
+	var nilPeers *TrustedPeers
+	require.Equal(t, uint(0), nilPeers.SlotBudget())
+}
+
+func TestTrustedPeersConnManagerSlots(t *testing.T) {
+	conf := &Config{PeersLo: 20, PeersHi: 30, PeersGrace: time.Minute}
+	cm, err := DefaultConnManager(conf)
+	require.NoError(t, err)
+	defer cm.Close()
+	info := cm.(*cmgr.BasicConnMgr).GetInfo()
+	require.Equal(t, 20, info.LowWater)
+	require.Equal(t, 30, info.HighWater)
+
+	// trusted peers get dedicated slots on top of the regular peer tides
+	conf.TrustedPeers = &TrustedPeers{Slots: 2}
+	cm, err = DefaultConnManager(conf)
+	require.NoError(t, err)
+	defer cm.Close()
+	info = cm.(*cmgr.BasicConnMgr).GetInfo()
+	require.Equal(t, 22, info.LowWater)
+	require.Equal(t, 32, info.HighWater)
+}
+
+func TestTrustedPeersExemptFromBans(t *testing.T) {
+	cm, err := cmgr.NewConnManager(1, 10)
+	require.NoError(t, err)
+	defer cm.Close()
+	n := &NodeP2P{connMgr: cm}
+
+	trusted, regular := peer.ID("trusted"), peer.ID("regular")
+	cm.Protect(trusted, trustedPeerTag)
+	// the peer monitor does not ban static peers, which includes trusted peers
+	require.True(t, n.IsStatic(trusted))
+	require.True(t, n.isTrusted(trusted))
+	require.False(t, n.IsStatic(regular))
+	require.False(t, n.isTrusted(regular))
+}
+
+func TestTrustedPeerManager(t *testing.T) {
+	mnet := mocknet.New()
+	defer mnet.Close()
+	var hosts []host.Host
+	for i := 0; i < 3; i++ {
+		h, err := mnet.GenPeer()
+		require.NoError(t, err)
+		hosts = append(hosts, h)
+	}
+	require.NoError(t, mnet.LinkAll())
+	hostA, hostB, hostC := hosts[0], hosts[1], hosts[2]
+
+	cm, err := cmgr.NewConnManager(1, 10)
+	require.NoError(t, err)
+	defer cm.Close()
+	trustedPeer := func(h host.Host, tags ...string) TrustedPeer {
+		return TrustedPeer{Info: &peer.AddrInfo{ID: h.ID(), Addrs: h.Addrs()}, Tags: tags}
+	}
+	// a single slot: B is preferred over C by the order of the config
+	peers := &TrustedPeers{Slots: 1, Peers: []TrustedPeer{trustedPeer(hostB, "sequencer"), trustedPeer(hostC)}}
+	m := newTrustedPeerManager(testlog.Logger(t, log.LvlError), hostA, cm, peers)
+	m.Start()
+	defer m.Close()
+
+	connected := func(h host.Host) func() bool {
+		return func() bool { return hostA.Network().Connectedness(h.ID()) == network.Connected }
+	}
+	require.Eventually(t, connected(hostB), 5*time.Second, 10*time.Millisecond, "dials the first trusted peer")
+	require.True(t, IsTrusted(cm, hostB.ID()))
+	require.True(t, IsTrusted(cm, hostC.ID()))
+	require.Equal(t, trustedPeerTagValue, cm.GetTagInfo(hostB.ID()).Tags[trustedPeerTagPrefix+"sequencer"])
+	require.Never(t, connected(hostC), 200*time.Millisecond, 10*time.Millisecond, "the slot budget is used")
+
+	t.Run("Redial", func(t *testing.T) {
+		require.NoError(t, mnet.DisconnectPeers(hostA.ID(), hostB.ID()))
+		require.Eventually(t, connected(hostB), 5*time.Second, 10*time.Millisecond, "redials the disconnected trusted peer")
+		require.False(t, connected(hostC)())
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		// B becomes unreachable: it is backed off, and C takes its slot
+		require.NoError(t, mnet.UnlinkPeers(hostA.ID(), hostB.ID()))
+		require.NoError(t, mnet.DisconnectPeers(hostA.ID(), hostB.ID()))
+		require.Eventually(t, connected(hostC), 5*time.Second, 10*time.Millisecond, "dials the next trusted peer")
+		backoff := func() time.Duration {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			return m.backoff[hostB.ID()]
+		}
+		require.Equal(t, trustedPeerMinRedialWait, backoff())
+
+		// the wait doubles with every failed dial
+		m.wg.Add(1)
+		m.dial(peers.Peers[0])
+		require.Equal(t, 2*trustedPeerMinRedialWait, backoff())
+
+		// a successful dial resets the backoff
+		_, err := mnet.LinkPeers(hostA.ID(), hostB.ID())
+		require.NoError(t, err)
+		m.wg.Add(1)
+		m.dial(peers.Peers[0])
+		require.Zero(t, backoff())
+		require.True(t, connected(hostB)())
+	})
+}
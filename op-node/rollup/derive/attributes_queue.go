@@ -30,15 +30,17 @@ type AttributesBuilder interface {
 type AttributesQueue struct {
 	log     log.Logger
 	config  *rollup.Config
+	tracer  Tracer
 	builder AttributesBuilder
 	prev    *BatchQueue
 	batch   *BatchData
 }
 
-func NewAttributesQueue(log log.Logger, cfg *rollup.Config, builder AttributesBuilder, prev *BatchQueue) *AttributesQueue {
+func NewAttributesQueue(log log.Logger, cfg *rollup.Config, tracer Tracer, builder AttributesBuilder, prev *BatchQueue) *AttributesQueue {
 	return &AttributesQueue{
 		log:     log,
 		config:  cfg,
+		tracer:  tracer,
 		builder: builder,
 		prev:    prev,
 	}
@@ -62,6 +64,13 @@ func (aq *AttributesQueue) NextAttributes(ctx context.Context, l2SafeHead eth.L2
 	if attrs, err := aq.createNextAttributes(ctx, aq.batch, l2SafeHead); err != nil {
 		return nil, err
 	} else {
+		ev := newTraceEvent(StageAttributesQueue, EventAttributesGenerated, aq.Origin())
+		ev.Attributes = &AttributesTrace{
+			Timestamp:    aq.batch.Timestamp,
+			Parent:       l2SafeHead.ID(),
+			Transactions: len(attrs.Transactions),
+		}
+		aq.tracer.OnPipelineEvent(ev)
 		// Clear out the local state once we will succeed
 		aq.batch = nil
 		return attrs, nil
@@ -78,7 +78,7 @@ func TestAttributesQueue(t *testing.T) {
 	}
 	attrBuilder := NewFetchingAttributesBuilder(cfg, l1Fetcher, l2Fetcher)
 
-	aq := NewAttributesQueue(testlog.Logger(t, log.LvlError), cfg, attrBuilder, nil)
+	aq := NewAttributesQueue(testlog.Logger(t, log.LvlError), cfg, NoopTracer, attrBuilder, nil)
 
 	actual, err := aq.createNextAttributes(context.Background(), batch, safeHead)
 
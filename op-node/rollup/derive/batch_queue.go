@@ -37,6 +37,7 @@ type NextBatchProvider interface {
 type BatchQueue struct {
 	log    log.Logger
 	config *rollup.Config
+	tracer Tracer
 	prev   NextBatchProvider
 	origin eth.L1BlockRef
 
@@ -47,10 +48,11 @@ type BatchQueue struct {
 }
 
 // NewBatchQueue creates a BatchQueue, which should be Reset(origin) before use.
-func NewBatchQueue(log log.Logger, cfg *rollup.Config, prev NextBatchProvider) *BatchQueue {
+func NewBatchQueue(log log.Logger, cfg *rollup.Config, tracer Tracer, prev NextBatchProvider) *BatchQueue {
 	return &BatchQueue{
 		log:    log,
 		config: cfg,
+		tracer: tracer,
 		prev:   prev,
 	}
 }
@@ -135,8 +137,9 @@ func (bq *BatchQueue) AddBatch(batch *BatchData, l2SafeHead eth.L2BlockRef) {
 		L1InclusionBlock: bq.origin,
 		Batch:            batch,
 	}
-	validity := CheckBatch(bq.config, bq.log, bq.l1Blocks, l2SafeHead, &data)
+	validity, reason := checkBatch(bq.config, bq.log, bq.l1Blocks, l2SafeHead, &data)
 	if validity == BatchDrop {
+		bq.traceBatch(EventBatchDropped, &data, reason)
 		return // if we do drop the batch, CheckBatch will log the drop reason with WARN level.
 	}
 	bq.log.Debug("Adding batch", "batch_timestamp", batch.Timestamp, "parent_hash", batch.ParentHash, "batch_epoch", batch.Epoch(), "txs", len(batch.Transactions))
@@ -173,7 +176,7 @@ func (bq *BatchQueue) deriveNextBatch(ctx context.Context, outOfData bool, l2Saf
 	candidates := bq.batches[nextTimestamp]
 batchLoop:
 	for i, batch := range candidates {
-		validity := CheckBatch(bq.config, bq.log.New("batch_index", i), bq.l1Blocks, l2SafeHead, batch)
+		validity, reason := checkBatch(bq.config, bq.log.New("batch_index", i), bq.l1Blocks, l2SafeHead, batch)
 		switch validity {
 		case BatchFuture:
 			return nil, NewCriticalError(fmt.Errorf("found batch with timestamp %d marked as future batch, but expected timestamp %d", batch.Batch.Timestamp, nextTimestamp))
@@ -186,6 +189,7 @@ batchLoop:
 				"l2_safe_head", l2SafeHead.ID(),
 				"l2_safe_head_time", l2SafeHead.Time,
 			)
+			bq.traceBatch(EventBatchDropped, batch, reason)
 			continue
 		case BatchAccept:
 			nextBatch = batch
@@ -214,6 +218,7 @@ batchLoop:
 			bq.l1Blocks = bq.l1Blocks[1:]
 		}
 		bq.log.Info("Found next batch", "epoch", epoch, "batch_epoch", nextBatch.Batch.EpochNum, "batch_timestamp", nextBatch.Batch.Timestamp)
+		bq.traceBatch(EventBatchAccepted, nextBatch, "")
 		return nextBatch.Batch, nil
 	}
 
@@ -243,7 +248,7 @@ batchLoop:
 	// batch to ensure that we at least have one batch per epoch.
 	if nextTimestamp < nextEpoch.Time || firstOfEpoch {
 		bq.log.Info("Generating next batch", "epoch", epoch, "timestamp", nextTimestamp)
-		return &BatchData{
+		batch := &BatchData{
 			BatchV1{
 				ParentHash:   l2SafeHead.Hash,
 				EpochNum:     rollup.Epoch(epoch.Number),
@@ -251,7 +256,9 @@ batchLoop:
 				Timestamp:    nextTimestamp,
 				Transactions: nil,
 			},
-		}, nil
+		}
+		bq.traceBatch(EventBatchGenerated, &BatchWithL1InclusionBlock{L1InclusionBlock: bq.origin, Batch: batch}, "sequence window expired")
+		return batch, nil
 	}
 
 	// At this point we have auto generated every batch for the current epoch
@@ -260,3 +267,10 @@ batchLoop:
 	bq.l1Blocks = bq.l1Blocks[1:]
 	return nil, io.EOF
 }
+
+func (bq *BatchQueue) traceBatch(kind string, batch *BatchWithL1InclusionBlock, reason string) {
+	ev := newTraceEvent(StageBatchQueue, kind, bq.origin)
+	ev.Batch = batchTrace(batch)
+	ev.Reason = reason
+	bq.tracer.OnPipelineEvent(ev)
+}
@@ -102,7 +102,7 @@ func TestBatchQueueNewOrigin(t *testing.T) {
 		origin:  l1[0],
 	}
 
-	bq := NewBatchQueue(log, cfg, input)
+	bq := NewBatchQueue(log, cfg, NoopTracer, input)
 	_ = bq.Reset(context.Background(), l1[0], eth.SystemConfig{})
 	require.Equal(t, []eth.L1BlockRef{l1[0]}, bq.l1Blocks)
 
@@ -133,6 +133,69 @@ func TestBatchQueueNewOrigin(t *testing.T) {
 	require.Equal(t, l1[2], bq.origin)
 }
 
+type recordingTracer struct {
+	events []TraceEvent
+}
+
+func (r *recordingTracer) OnPipelineEvent(ev TraceEvent) {
+	r.events = append(r.events, ev)
+}
+
+// TestBatchQueueTrace asserts that dropped and accepted batches are traced, with the reason of dropping.
+func TestBatchQueueTrace(t *testing.T) {
+	log := testlog.Logger(t, log.LvlCrit)
+	l1 := L1Chain([]uint64{10, 20, 30})
+	safeHead := eth.L2BlockRef{
+		Hash:           mockHash(10, 2),
+		Number:         0,
+		ParentHash:     common.Hash{},
+		Time:           10,
+		L1Origin:       l1[0].ID(),
+		SequenceNumber: 0,
+	}
+	cfg := &rollup.Config{
+		Genesis: rollup.Genesis{
+			L2Time: 10,
+		},
+		BlockTime:         2,
+		MaxSequencerDrift: 600,
+		SeqWindowSize:     30,
+	}
+
+	wrongParent := b(12, l1[0])
+	wrongParent.ParentHash = common.Hash{0xff}
+	batches := []*BatchData{b(8, l1[0]), wrongParent, b(12, l1[0]), nil}
+	errors := []error{nil, nil, nil, io.EOF}
+
+	input := &fakeBatchQueueInput{
+		batches: batches,
+		errors:  errors,
+		origin:  l1[0],
+	}
+
+	tracer := new(recordingTracer)
+	bq := NewBatchQueue(log, cfg, tracer, input)
+	_ = bq.Reset(context.Background(), l1[0], eth.SystemConfig{})
+	input.origin = l1[1]
+
+	for i := 0; i < 3; i++ {
+		_, _ = bq.NextBatch(context.Background(), safeHead)
+	}
+
+	require.Len(t, tracer.events, 3)
+	require.Equal(t, EventBatchDropped, tracer.events[0].Kind)
+	require.Equal(t, "old timestamp", tracer.events[0].Reason)
+	require.Equal(t, uint64(8), tracer.events[0].Batch.Timestamp)
+	require.Equal(t, EventBatchDropped, tracer.events[1].Kind)
+	require.Equal(t, "mismatching parent hash", tracer.events[1].Reason)
+	require.Equal(t, EventBatchAccepted, tracer.events[2].Kind)
+	require.Equal(t, uint64(12), tracer.events[2].Batch.Timestamp)
+	require.Equal(t, l1[1].ID(), tracer.events[2].Batch.L1InclusionBlock)
+	for _, ev := range tracer.events {
+		require.Equal(t, StageBatchQueue, ev.Stage)
+	}
+}
+
 // TestBatchQueueEager adds a bunch of contiguous batches and asserts that
 // enough calls to `NextBatch` return all of those batches.
 func TestBatchQueueEager(t *testing.T) {
@@ -164,7 +227,7 @@ func TestBatchQueueEager(t *testing.T) {
 		origin:  l1[0],
 	}
 
-	bq := NewBatchQueue(log, cfg, input)
+	bq := NewBatchQueue(log, cfg, NoopTracer, input)
 	_ = bq.Reset(context.Background(), l1[0], eth.SystemConfig{})
 	// Advance the origin
 	input.origin = l1[1]
@@ -214,7 +277,7 @@ func TestBatchQueueInvalidInternalAdvance(t *testing.T) {
 		origin:  l1[0],
 	}
 
-	bq := NewBatchQueue(log, cfg, input)
+	bq := NewBatchQueue(log, cfg, NoopTracer, input)
 	_ = bq.Reset(context.Background(), l1[0], eth.SystemConfig{})
 
 	// Load continuous batches for epoch 0
@@ -308,7 +371,7 @@ func TestBatchQueueMissing(t *testing.T) {
 		origin:  l1[0],
 	}
 
-	bq := NewBatchQueue(log, cfg, input)
+	bq := NewBatchQueue(log, cfg, NoopTracer, input)
 	_ = bq.Reset(context.Background(), l1[0], eth.SystemConfig{})
 
 	for i := 0; i < len(batches); i++ {
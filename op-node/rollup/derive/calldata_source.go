@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -40,15 +41,16 @@ type DataSourceFactory struct {
 	cfg     *rollup.Config
 	daCfg   *rollup.DAConfig
 	fetcher L1TransactionFetcher
+	tracer  Tracer
 }
 
-func NewDataSourceFactory(log log.Logger, cfg *rollup.Config, daCfg *rollup.DAConfig, fetcher L1TransactionFetcher) *DataSourceFactory {
-	return &DataSourceFactory{log: log, cfg: cfg, daCfg: daCfg, fetcher: fetcher}
+func NewDataSourceFactory(log log.Logger, cfg *rollup.Config, daCfg *rollup.DAConfig, tracer Tracer, fetcher L1TransactionFetcher) *DataSourceFactory {
+	return &DataSourceFactory{log: log, cfg: cfg, daCfg: daCfg, fetcher: fetcher, tracer: tracer}
 }
 
 // OpenData returns a DataIter. This struct implements the `Next` function.
 func (ds *DataSourceFactory) OpenData(ctx context.Context, id eth.BlockID, batcherAddr common.Address) (DataIter, error) {
-	return NewDataSource(ctx, ds.log, ds.cfg, ds.daCfg, ds.fetcher, id, batcherAddr)
+	return NewDataSource(ctx, ds.log, ds.cfg, ds.daCfg, ds.tracer, ds.fetcher, id, batcherAddr)
 }
 
 // DataSource is a fault tolerant approach to fetching data.
@@ -64,13 +66,14 @@ type DataSource struct {
 	daCfg   *rollup.DAConfig
 	fetcher L1TransactionFetcher
 	log     log.Logger
+	tracer  Tracer
 
 	batcherAddr common.Address
 }
 
 // NewDataSource creates a new calldata source. It suppresses errors in fetching the L1 block if they occur.
 // If there is an error, it will attempt to fetch the result on the next call to `Next`.
-func NewDataSource(ctx context.Context, log log.Logger, cfg *rollup.Config, daCfg *rollup.DAConfig, fetcher L1TransactionFetcher, block eth.BlockID, batcherAddr common.Address) (DataIter, error) {
+func NewDataSource(ctx context.Context, log log.Logger, cfg *rollup.Config, daCfg *rollup.DAConfig, tracer Tracer, fetcher L1TransactionFetcher, block eth.BlockID, batcherAddr common.Address) (DataIter, error) {
 	_, txs, err := fetcher.InfoAndTxsByHash(ctx, block.Hash)
 	if err != nil {
 		return &DataSource{
@@ -80,10 +83,11 @@ func NewDataSource(ctx context.Context, log log.Logger, cfg *rollup.Config, daCf
 			daCfg:       daCfg,
 			fetcher:     fetcher,
 			log:         log,
+			tracer:      tracer,
 			batcherAddr: batcherAddr,
 		}, nil
 	} else {
-		data, err := DataFromEVMTransactions(ctx, cfg, daCfg, batcherAddr, txs, log.New("origin", block))
+		data, err := DataFromEVMTransactions(ctx, cfg, daCfg, batcherAddr, txs, log.New("origin", block), tracer, block)
 		if err != nil {
 			return &DataSource{
 				open:        false,
@@ -92,6 +96,7 @@ func NewDataSource(ctx context.Context, log log.Logger, cfg *rollup.Config, daCf
 				daCfg:       daCfg,
 				fetcher:     fetcher,
 				log:         log,
+				tracer:      tracer,
 				batcherAddr: batcherAddr,
 			}, err
 		}
@@ -109,7 +114,7 @@ func (ds *DataSource) Next(ctx context.Context) (eth.Data, error) {
 	if !ds.open {
 		if _, txs, err := ds.fetcher.InfoAndTxsByHash(ctx, ds.id.Hash); err == nil {
 			ds.open = true
-			ds.data, err = DataFromEVMTransactions(ctx, ds.cfg, ds.daCfg, ds.batcherAddr, txs, log.New("origin", ds.id))
+			ds.data, err = DataFromEVMTransactions(ctx, ds.cfg, ds.daCfg, ds.batcherAddr, txs, log.New("origin", ds.id), ds.tracer, ds.id)
 			if err != nil {
 				// already wrapped
 				return nil, err
@@ -141,10 +146,57 @@ func downloadS3Data(ctx context.Context, daCfg *rollup.DAConfig, frameRefData []
 	return ioutil.ReadAll(resp.Body)
 }
 
+const (
+	// DASourceS3 indicates that the frame data of a FrameRef was resolved from the S3 archive.
+	DASourceS3 = "s3"
+	// DASourceCelestia indicates that the frame data of a FrameRef was resolved from Celestia.
+	DASourceCelestia = "celestia"
+)
+
+// ResolveFrameRef resolves the frame data that the FrameRef refers to, with the DA client of daCfg.
+// The data is downloaded from the S3 archive if one is configured, and requested from Celestia otherwise.
+// It is checked against the commitment of the FrameRef either way.
+// It returns the frame data, and the source of the data.
+func ResolveFrameRef(ctx context.Context, log log.Logger, daCfg *rollup.DAConfig, frameRef celestia.FrameRef, inboxData []byte) ([]byte, string, error) {
+	var txblob *blob.Blob
+	source := DASourceS3
+	if daCfg.S3Client != nil && daCfg.S3Bucket != "" {
+		log.Info("requesting data from aws", "url", fmt.Sprintf("s3://%s/%s/%x", daCfg.S3Bucket, daCfg.Namespace.String(), inboxData))
+		data, err := downloadS3Data(ctx, daCfg, inboxData)
+		if err != nil {
+			log.Error("aws request failed", "err", err)
+		} else if txblob, err = blob.NewBlobV0(daCfg.Namespace, data); err != nil {
+			log.Error("unable to create celestia blob", "err", err)
+			return nil, "", NewTemporaryError(err)
+		}
+	}
+	if txblob == nil {
+		log.Info("requesting data from celestia", "namespace", hex.EncodeToString(daCfg.Namespace), "height", frameRef.BlockHeight, "commitment", hex.EncodeToString(frameRef.TxCommitment))
+		source = DASourceCelestia
+		var err error
+		txblob, err = daCfg.Client.Blob.Get(ctx, frameRef.BlockHeight, daCfg.Namespace, frameRef.TxCommitment)
+		if err != nil {
+			log.Error("celestia request failed", "err", err)
+			return nil, "", NewTemporaryError(err)
+		}
+	}
+	com, err := blob.CreateCommitment(txblob)
+	if err != nil {
+		log.Error("unable to create celestia commitment", "err", err)
+		return nil, "", NewTemporaryError(err)
+	}
+	if !bytes.Equal(com, frameRef.TxCommitment) {
+		log.Error("invalid celestia commitment")
+		return nil, "", NewCriticalError(errors.New("invalid celestia commitment"))
+	}
+	return txblob.Data, source, nil
+}
+
 // DataFromEVMTransactions filters all of the transactions and returns the calldata from transactions
 // that are sent to the batch inbox address from the batch sender address.
 // This will return an empty array if no valid transactions are found.
-func DataFromEVMTransactions(ctx context.Context, config *rollup.Config, daCfg *rollup.DAConfig, batcherAddr common.Address, txs types.Transactions, log log.Logger) ([]eth.Data, error) {
+// The resolution of frame references from the DA layer is traced, with the given L1 origin.
+func DataFromEVMTransactions(ctx context.Context, config *rollup.Config, daCfg *rollup.DAConfig, batcherAddr common.Address, txs types.Transactions, log log.Logger, tracer Tracer, origin eth.BlockID) ([]eth.Data, error) {
 	var out []eth.Data
 	l1Signer := config.L1Signer()
 	for j, tx := range txs {
@@ -202,46 +254,32 @@ func DataFromEVMTransactions(ctx context.Context, config *rollup.Config, daCfg *
 				out = append(out, tx.Data()[1:])
 
 			case celestia.CurrentVersion: // 2
-				if daCfg == nil {
+				if daCfg == nil || daCfg.Client == nil {
 					log.Error("missing DA_RPC url")
 					return nil, NewCriticalError(errors.New("missing DA_RPC url"))
 				}
-
 				frameRef := celestia.FrameRef{}
-				frameRef.UnmarshalBinary(tx.Data())
-
-				if err != nil {
+				if err := frameRef.UnmarshalBinary(tx.Data()); err != nil {
 					log.Error("unable to decode frame reference", "index", j, "err", err)
 					return nil, NewCriticalError(err)
 				}
-				log.Info("requesting data from aws", "url", fmt.Sprintf("s3://%s/%s/%x", daCfg.S3Bucket, daCfg.Namespace.String(), tx.Data()))
-				var txblob *blob.Blob
-				data, err := downloadS3Data(ctx, daCfg, tx.Data())
+				data, source, err := ResolveFrameRef(ctx, log, daCfg, frameRef, tx.Data())
 				if err != nil {
-					log.Error("aws request failed", "err", err)
-					log.Info("requesting data from celestia", "namespace", hex.EncodeToString(daCfg.Namespace), "height", frameRef.BlockHeight, "commitment", hex.EncodeToString(frameRef.TxCommitment))
-					txblob, err = daCfg.Client.Blob.Get(ctx, frameRef.BlockHeight, daCfg.Namespace, frameRef.TxCommitment)
-					if err != nil {
-						log.Error("celestia request failed", "err", err)
-						return nil, NewTemporaryError(err)
-					}
-				} else {
-					txblob, err = blob.NewBlobV0(daCfg.Namespace, data)
-					if err != nil {
-						log.Error("unable to create celestia blob", "err", err)
-						return nil, NewTemporaryError(err)
-					}
-				}
-				com, err := blob.CreateCommitment(txblob)
-				if err != nil {
-					log.Error("unable to create celestia commitment", "err", err)
-					return nil, NewTemporaryError(err)
-				}
-				if !bytes.Equal(com, frameRef.TxCommitment) {
-					log.Error("invalid celestia commitment")
-					return nil, NewCriticalError(errors.New("invalid celestia commitment"))
+					return nil, err
 				}
-				out = append(out, txblob.Data)
+				tracer.OnPipelineEvent(TraceEvent{
+					Time:   time.Now(),
+					Stage:  StageDataSource,
+					Kind:   EventDataResolved,
+					Origin: origin,
+					DA: &DATrace{
+						Height:     frameRef.BlockHeight,
+						Commitment: frameRef.TxCommitment,
+						Source:     source,
+						Length:     len(data),
+					},
+				})
+				out = append(out, data)
 
 			default:
 				log.Error("invalid data type", "type", tx.Data()[0])
@@ -122,7 +122,7 @@ func TestDataFromEVMTransactions(t *testing.T) {
 			}
 		}
 
-		out, err := DataFromEVMTransactions(context.Background(), cfg, nil, batcherAddr, txs, testlog.Logger(t, log.LvlWarn))
+		out, err := DataFromEVMTransactions(context.Background(), cfg, nil, batcherAddr, txs, testlog.Logger(t, log.LvlWarn), NoopTracer, eth.BlockID{})
 		require.ElementsMatch(t, expectedData, out)
 		require.NoError(t, err)
 	}
@@ -29,8 +29,9 @@ type NextFrameProvider interface {
 
 // ChannelBank buffers channel frames, and emits full channel data
 type ChannelBank struct {
-	log log.Logger
-	cfg *rollup.Config
+	log    log.Logger
+	cfg    *rollup.Config
+	tracer Tracer
 
 	channels     map[ChannelID]*Channel // channels by ID
 	channelQueue []ChannelID            // channels in FIFO order
@@ -42,10 +43,11 @@ type ChannelBank struct {
 var _ ResetableStage = (*ChannelBank)(nil)
 
 // NewChannelBank creates a ChannelBank, which should be Reset(origin) before use.
-func NewChannelBank(log log.Logger, cfg *rollup.Config, prev NextFrameProvider, fetcher L1Fetcher) *ChannelBank {
+func NewChannelBank(log log.Logger, cfg *rollup.Config, tracer Tracer, prev NextFrameProvider, fetcher L1Fetcher) *ChannelBank {
 	return &ChannelBank{
 		log:          log,
 		cfg:          cfg,
+		tracer:       tracer,
 		channels:     make(map[ChannelID]*Channel),
 		channelQueue: make([]ChannelID, 0, 10),
 		prev:         prev,
@@ -70,6 +72,7 @@ func (cb *ChannelBank) prune() {
 		cb.channelQueue = cb.channelQueue[1:]
 		delete(cb.channels, id)
 		cb.log.Info("pruning channel", "channel", id, "totalSize", totalSize, "channel_size", ch.size, "remaining_channel_count", len(cb.channels))
+		cb.traceChannel(EventChannelPruned, ch, "channel bank exceeded max size")
 		totalSize -= ch.size
 	}
 }
@@ -88,17 +91,20 @@ func (cb *ChannelBank) IngestFrame(f Frame) {
 		cb.channels[f.ID] = currentCh
 		cb.channelQueue = append(cb.channelQueue, f.ID)
 		log.Info("created new channel")
+		cb.traceChannel(EventChannelOpened, currentCh, "")
 	}
 
 	// check if the channel is not timed out
 	if currentCh.OpenBlockNumber()+cb.cfg.ChannelTimeout < origin.Number {
 		log.Warn("channel is timed out, ignore frame")
+		cb.traceChannel(EventChannelFrameDropped, currentCh, "channel is timed out")
 		return
 	}
 
 	log.Trace("ingesting frame")
 	if err := currentCh.AddFrame(f, origin); err != nil {
 		log.Warn("failed to ingest frame into channel", "err", err)
+		cb.traceChannel(EventChannelFrameDropped, currentCh, err.Error())
 		return
 	}
 
@@ -117,6 +123,7 @@ func (cb *ChannelBank) Read() (data []byte, err error) {
 	timedOut := ch.OpenBlockNumber()+cb.cfg.ChannelTimeout < cb.Origin().Number
 	if timedOut {
 		cb.log.Info("channel timed out", "channel", first, "frames", len(ch.inputs))
+		cb.traceChannel(EventChannelTimedOut, ch, "")
 		delete(cb.channels, first)
 		cb.channelQueue = cb.channelQueue[1:]
 		return nil, nil // multiple different channels may all be timed out
@@ -125,6 +132,7 @@ func (cb *ChannelBank) Read() (data []byte, err error) {
 		return nil, io.EOF
 	}
 	cb.log.Info("Reading channel", "channel", first, "frames", len(ch.inputs))
+	cb.traceChannel(EventChannelRead, ch, "")
 
 	delete(cb.channels, first)
 	cb.channelQueue = cb.channelQueue[1:]
@@ -134,6 +142,13 @@ func (cb *ChannelBank) Read() (data []byte, err error) {
 	return data, nil
 }
 
+func (cb *ChannelBank) traceChannel(kind string, ch *Channel, reason string) {
+	ev := newTraceEvent(StageChannelBank, kind, cb.Origin())
+	ev.Channel = channelTrace(ch)
+	ev.Reason = reason
+	cb.tracer.OnPipelineEvent(ev)
+}
+
 // NextData pulls the next piece of data from the channel bank.
 // Note that it attempts to pull data out of the channel bank prior to
 // loading data in (unlike most other stages). This is to ensure maintain
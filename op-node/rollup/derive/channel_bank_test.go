@@ -101,7 +101,7 @@ func TestChannelBankSimple(t *testing.T) {
 
 	cfg := &rollup.Config{ChannelTimeout: 10}
 
-	cb := NewChannelBank(testlog.Logger(t, log.LvlCrit), cfg, input, nil)
+	cb := NewChannelBank(testlog.Logger(t, log.LvlCrit), cfg, NoopTracer, input, nil)
 
 	// Load the first frame
 	out, err := cb.NextData(context.Background())
@@ -145,7 +145,7 @@ func TestChannelBankInterleaved(t *testing.T) {
 
 	cfg := &rollup.Config{ChannelTimeout: 10}
 
-	cb := NewChannelBank(testlog.Logger(t, log.LvlCrit), cfg, input, nil)
+	cb := NewChannelBank(testlog.Logger(t, log.LvlCrit), cfg, NoopTracer, input, nil)
 
 	// Load a:0
 	out, err := cb.NextData(context.Background())
@@ -205,7 +205,7 @@ func TestChannelBankDuplicates(t *testing.T) {
 
 	cfg := &rollup.Config{ChannelTimeout: 10}
 
-	cb := NewChannelBank(testlog.Logger(t, log.LvlCrit), cfg, input, nil)
+	cb := NewChannelBank(testlog.Logger(t, log.LvlCrit), cfg, NoopTracer, input, nil)
 
 	// Load the first frame
 	out, err := cb.NextData(context.Background())
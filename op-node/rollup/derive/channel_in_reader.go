@@ -23,16 +23,18 @@ type ChannelInReader struct {
 	prev *ChannelBank
 
 	metrics Metrics
+	tracer  Tracer
 }
 
 var _ ResetableStage = (*ChannelInReader)(nil)
 
 // NewChannelInReader creates a ChannelInReader, which should be Reset(origin) before use.
-func NewChannelInReader(log log.Logger, prev *ChannelBank, metrics Metrics) *ChannelInReader {
+func NewChannelInReader(log log.Logger, prev *ChannelBank, metrics Metrics, tracer Tracer) *ChannelInReader {
 	return &ChannelInReader{
 		log:     log,
 		prev:    prev,
 		metrics: metrics,
+		tracer:  tracer,
 	}
 }
 
@@ -48,6 +50,7 @@ func (cr *ChannelInReader) WriteChannel(data []byte) error {
 		return nil
 	} else {
 		cr.log.Error("Error creating batch reader from channel data", "err", err)
+		cr.traceInvalidChannel(err)
 		return err
 	}
 }
@@ -82,12 +85,19 @@ func (cr *ChannelInReader) NextBatch(ctx context.Context) (*BatchData, error) {
 		return nil, NotEnoughData
 	} else if err != nil {
 		cr.log.Warn("failed to read batch from channel reader, skipping to next channel now", "err", err)
+		cr.traceInvalidChannel(err)
 		cr.NextChannel()
 		return nil, NotEnoughData
 	}
 	return batch.Batch, nil
 }
 
+func (cr *ChannelInReader) traceInvalidChannel(err error) {
+	ev := newTraceEvent(StageChannelInReader, EventChannelInvalid, cr.Origin())
+	ev.Reason = err.Error()
+	cr.tracer.OnPipelineEvent(ev)
+}
+
 func (cr *ChannelInReader) Reset(ctx context.Context, _ eth.L1BlockRef, _ eth.SystemConfig) error {
 	cr.nextBatchFn = nil
 	return io.EOF
@@ -95,13 +95,18 @@ type FinalityData struct {
 
 // EngineQueue queues up payload attributes to consolidate or process with the provided Engine
 type EngineQueue struct {
-	log log.Logger
-	cfg *rollup.Config
+	log    log.Logger
+	cfg    *rollup.Config
+	tracer Tracer
 
 	finalized  eth.L2BlockRef
 	safeHead   eth.L2BlockRef
 	unsafeHead eth.L2BlockRef
 
+	// resetTarget are the L2 heads to start from on the next reset, instead of the heads found
+	// by walking back from the engine forkchoice state. Nil if there is no explicit reset target.
+	resetTarget *sync.FindHeadsResult
+
 	buildingOnto eth.L2BlockRef
 	buildingID   eth.PayloadID
 	buildingSafe bool
@@ -138,10 +143,11 @@ type EngineQueue struct {
 var _ EngineControl = (*EngineQueue)(nil)
 
 // NewEngineQueue creates a new EngineQueue, which should be Reset(origin) before use.
-func NewEngineQueue(log log.Logger, cfg *rollup.Config, engine Engine, metrics Metrics, prev NextAttributesProvider, l1Fetcher L1Fetcher) *EngineQueue {
+func NewEngineQueue(log log.Logger, cfg *rollup.Config, tracer Tracer, engine Engine, metrics Metrics, prev NextAttributesProvider, l1Fetcher L1Fetcher) *EngineQueue {
 	return &EngineQueue{
 		log:            log,
 		cfg:            cfg,
+		tracer:         tracer,
 		engine:         engine,
 		metrics:        metrics,
 		finalityData:   make([]FinalityData, 0, finalityLookback),
@@ -542,6 +548,7 @@ func (eq *EngineQueue) consolidateNextSafeAttributes(ctx context.Context) error
 	eq.safeHead = ref
 	eq.needForkchoiceUpdate = true
 	eq.metrics.RecordL2Ref("l2_safe", ref)
+	eq.traceSafeHead(EventSafeHeadUpdated)
 	// unsafe head stays the same, we did not reorg the chain.
 	eq.safeAttributes = nil
 	eq.postProcessSafeL2()
@@ -650,6 +657,7 @@ func (eq *EngineQueue) ConfirmPayload(ctx context.Context) (out *eth.ExecutionPa
 		eq.safeHead = ref
 		eq.postProcessSafeL2()
 		eq.metrics.RecordL2Ref("l2_safe", ref)
+		eq.traceSafeHead(EventSafeHeadUpdated)
 	}
 	eq.resetBuildingState()
 	return payload, BlockInsertOK, nil
@@ -685,9 +693,19 @@ func (eq *EngineQueue) resetBuildingState() {
 // ResetStep Walks the L2 chain backwards until it finds an L2 block whose L1 origin is canonical.
 // The unsafe head is set to the head of the L2 chain, unless the existing safe head is not canonical.
 func (eq *EngineQueue) Reset(ctx context.Context, _ eth.L1BlockRef, _ eth.SystemConfig) error {
-	result, err := sync.FindL2Heads(ctx, eq.cfg, eq.l1Fetcher, eq.engine, eq.log)
-	if err != nil {
-		return NewTemporaryError(fmt.Errorf("failed to find the L2 Heads to start from: %w", err))
+	// Channel data is buffered from the channel timeout before the L1 origin of the safe head.
+	// An explicit reset target re-derives its L2 chain, so it starts a full sequencing window earlier,
+	// to include all the batches that may derive the blocks after it.
+	lookback := eq.cfg.ChannelTimeout
+	result := eq.resetTarget
+	if result != nil {
+		lookback = eq.cfg.SeqWindowSize
+	} else {
+		var err error
+		result, err = sync.FindL2Heads(ctx, eq.cfg, eq.l1Fetcher, eq.engine, eq.log)
+		if err != nil {
+			return NewTemporaryError(fmt.Errorf("failed to find the L2 Heads to start from: %w", err))
+		}
 	}
 	finalized, safe, unsafe := result.Finalized, result.Safe, result.Unsafe
 	l1Origin, err := eq.l1Fetcher.L1BlockRefByHash(ctx, safe.L1Origin.Hash)
@@ -704,8 +722,8 @@ func (eq *EngineQueue) Reset(ctx context.Context, _ eth.L1BlockRef, _ eth.System
 	for {
 		afterL2Genesis := pipelineL2.Number > eq.cfg.Genesis.L2.Number
 		afterL1Genesis := pipelineL2.L1Origin.Number > eq.cfg.Genesis.L1.Number
-		afterChannelTimeout := pipelineL2.L1Origin.Number+eq.cfg.ChannelTimeout > l1Origin.Number
-		if afterL2Genesis && afterL1Genesis && afterChannelTimeout {
+		afterLookback := pipelineL2.L1Origin.Number+lookback > l1Origin.Number
+		if afterL2Genesis && afterL1Genesis && afterLookback {
 			parent, err := eq.engine.L2BlockRefByHash(ctx, pipelineL2.ParentHash)
 			if err != nil {
 				return NewResetError(fmt.Errorf("failed to fetch L2 parent block %s", pipelineL2.ParentID()))
@@ -735,13 +753,30 @@ func (eq *EngineQueue) Reset(ctx context.Context, _ eth.L1BlockRef, _ eth.System
 	// note: we do not clear the unsafe payloads queue; if the payloads are not applicable anymore the parent hash checks will clear out the old payloads.
 	eq.origin = pipelineOrigin
 	eq.sysCfg = l1Cfg
+	eq.resetTarget = nil
 	eq.metrics.RecordL2Ref("l2_finalized", finalized)
 	eq.metrics.RecordL2Ref("l2_safe", safe)
 	eq.metrics.RecordL2Ref("l2_unsafe", unsafe)
+	eq.traceSafeHead(EventSafeHeadReset)
 	eq.logSyncProgress("reset derivation work")
 	return io.EOF
 }
 
+// ResetTo sets the L2 heads that the next reset starts from, without walking back to the heads
+// that are consistent with the canonical L1 chain. The pipeline origin is the L1 origin of the safe head,
+// minus the sequencing window.
+func (eq *EngineQueue) ResetTo(unsafe, safe, finalized eth.L2BlockRef) {
+	eq.resetTarget = &sync.FindHeadsResult{Unsafe: unsafe, Safe: safe, Finalized: finalized}
+}
+
+// traceSafeHead emits a trace event of the current safe head, with the L1 block it was derived from as origin.
+func (eq *EngineQueue) traceSafeHead(kind string) {
+	ev := newTraceEvent(StageEngineQueue, kind, eq.origin)
+	safeHead := eq.safeHead
+	ev.SafeHead = &safeHead
+	eq.tracer.OnPipelineEvent(ev)
+}
+
 // UnsafeL2SyncTarget retrieves the first queued-up L2 unsafe payload, or a zeroed reference if there is none.
 func (eq *EngineQueue) UnsafeL2SyncTarget() eth.L2BlockRef {
 	if first := eq.unsafePayloads.Peek(); first != nil {
@@ -246,7 +246,7 @@ func TestEngineQueue_Finalize(t *testing.T) {
 
 	prev := &fakeAttributesQueue{}
 
-	eq := NewEngineQueue(logger, cfg, eng, metrics, prev, l1F)
+	eq := NewEngineQueue(logger, cfg, NoopTracer, eng, metrics, prev, l1F)
 	require.ErrorIs(t, eq.Reset(context.Background(), eth.L1BlockRef{}, eth.SystemConfig{}), io.EOF)
 
 	require.Equal(t, refB1, eq.SafeL2Head(), "L2 reset should go back to sequence window ago: blocks with origin E and D are not safe until we reconcile, C is extra, and B1 is the end we look for")
@@ -480,7 +480,7 @@ func TestEngineQueue_ResetWhenUnsafeOriginNotCanonical(t *testing.T) {
 
 	prev := &fakeAttributesQueue{origin: refE}
 
-	eq := NewEngineQueue(logger, cfg, eng, metrics, prev, l1F)
+	eq := NewEngineQueue(logger, cfg, NoopTracer, eng, metrics, prev, l1F)
 	require.ErrorIs(t, eq.Reset(context.Background(), eth.L1BlockRef{}, eth.SystemConfig{}), io.EOF)
 
 	require.Equal(t, refB1, eq.SafeL2Head(), "L2 reset should go back to sequence window ago: blocks with origin E and D are not safe until we reconcile, C is extra, and B1 is the end we look for")
@@ -811,7 +811,7 @@ func TestVerifyNewL1Origin(t *testing.T) {
 			}, nil)
 
 			prev := &fakeAttributesQueue{origin: refE}
-			eq := NewEngineQueue(logger, cfg, eng, metrics, prev, l1F)
+			eq := NewEngineQueue(logger, cfg, NoopTracer, eng, metrics, prev, l1F)
 			require.ErrorIs(t, eq.Reset(context.Background(), eth.L1BlockRef{}, eth.SystemConfig{}), io.EOF)
 
 			require.Equal(t, refB1, eq.SafeL2Head(), "L2 reset should go back to sequence window ago: blocks with origin E and D are not safe until we reconcile, C is extra, and B1 is the end we look for")
@@ -909,7 +909,7 @@ func TestBlockBuildingRace(t *testing.T) {
 	}
 
 	prev := &fakeAttributesQueue{origin: refA, attrs: attrs}
-	eq := NewEngineQueue(logger, cfg, eng, metrics, prev, l1F)
+	eq := NewEngineQueue(logger, cfg, NoopTracer, eng, metrics, prev, l1F)
 	require.ErrorIs(t, eq.Reset(context.Background(), eth.L1BlockRef{}, eth.SystemConfig{}), io.EOF)
 
 	id := eth.PayloadID{0xff}
@@ -1079,7 +1079,7 @@ func TestResetLoop(t *testing.T) {
 
 	prev := &fakeAttributesQueue{origin: refA, attrs: attrs}
 
-	eq := NewEngineQueue(logger, cfg, eng, metrics.NoopMetrics, prev, l1F)
+	eq := NewEngineQueue(logger, cfg, NoopTracer, eng, metrics.NoopMetrics, prev, l1F)
 	eq.unsafeHead = refA2
 	eq.safeHead = refA1
 	eq.finalized = refA0
@@ -1107,3 +1107,67 @@ func TestResetLoop(t *testing.T) {
 	l1F.AssertExpectations(t)
 	eng.AssertExpectations(t)
 }
+
+func TestEngineQueue_ResetTo(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlInfo)
+	rng := rand.New(rand.NewSource(1234))
+
+	refA := testutils.RandomBlockRef(rng)
+	refA.Time = 100
+	refB := testutils.NextRandomRef(rng, refA)
+	refC := testutils.NextRandomRef(rng, refB)
+	refD := testutils.NextRandomRef(rng, refC)
+
+	refA0 := eth.L2BlockRef{
+		Hash:     testutils.RandomHash(rng),
+		Number:   0,
+		Time:     refA.Time,
+		L1Origin: refA.ID(),
+	}
+	cfg := &rollup.Config{
+		Genesis: rollup.Genesis{
+			L1:     refA.ID(),
+			L2:     refA0.ID(),
+			L2Time: refA0.Time,
+		},
+		BlockTime:      1000,
+		SeqWindowSize:  2,
+		ChannelTimeout: 1,
+	}
+	next := func(parent eth.L2BlockRef, origin eth.L1BlockRef) eth.L2BlockRef {
+		return eth.L2BlockRef{
+			Hash:       testutils.RandomHash(rng),
+			Number:     parent.Number + 1,
+			ParentHash: parent.Hash,
+			Time:       parent.Time + cfg.BlockTime,
+			L1Origin:   origin.ID(),
+		}
+	}
+	refB0 := next(refA0, refB)
+	refC0 := next(refB0, refC)
+	refD0 := next(refC0, refD)
+
+	eng := &testutils.MockEngine{}
+	l1F := &testutils.MockL1Source{}
+
+	// The pipeline origin is a full sequencing window before the L1 origin of the target,
+	// rather than the channel timeout of a regular reset.
+	l1F.ExpectL1BlockRefByHash(refD.Hash, refD, nil)
+	eng.ExpectL2BlockRefByHash(refD0.ParentHash, refC0, nil)
+	eng.ExpectL2BlockRefByHash(refC0.ParentHash, refB0, nil)
+	l1F.ExpectL1BlockRefByHash(refB.Hash, refB, nil)
+	eng.ExpectSystemConfigByL2Hash(refB0.Hash, eth.SystemConfig{GasLimit: 20_000_000}, nil)
+
+	eq := NewEngineQueue(logger, cfg, NoopTracer, eng, metrics.NoopMetrics, &fakeAttributesQueue{}, l1F)
+	eq.ResetTo(refD0, refD0, refA0)
+	require.ErrorIs(t, eq.Reset(context.Background(), eth.L1BlockRef{}, eth.SystemConfig{}), io.EOF)
+
+	require.Equal(t, refD0, eq.UnsafeL2Head())
+	require.Equal(t, refD0, eq.SafeL2Head())
+	require.Equal(t, refA0, eq.Finalized())
+	require.Equal(t, refB, eq.Origin())
+	require.Equal(t, eth.SystemConfig{GasLimit: 20_000_000}, eq.SystemConfig())
+
+	l1F.AssertExpectations(t)
+	eng.AssertExpectations(t)
+}
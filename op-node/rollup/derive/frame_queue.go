@@ -18,14 +18,16 @@ type NextDataProvider interface {
 
 type FrameQueue struct {
 	log    log.Logger
+	tracer Tracer
 	frames []Frame
 	prev   NextDataProvider
 }
 
-func NewFrameQueue(log log.Logger, prev NextDataProvider) *FrameQueue {
+func NewFrameQueue(log log.Logger, tracer Tracer, prev NextDataProvider) *FrameQueue {
 	return &FrameQueue{
-		log:  log,
-		prev: prev,
+		log:    log,
+		tracer: tracer,
+		prev:   prev,
 	}
 }
 
@@ -41,8 +43,16 @@ func (fq *FrameQueue) NextFrame(ctx context.Context) (Frame, error) {
 		} else {
 			if new, err := ParseFrames(data); err == nil {
 				fq.frames = append(fq.frames, new...)
+				for _, f := range new {
+					ev := newTraceEvent(StageFrameQueue, EventFrameIngested, fq.prev.Origin())
+					ev.Frame = &FrameTrace{Channel: f.ID, FrameNumber: f.FrameNumber, Length: len(f.Data), IsLast: f.IsLast}
+					fq.tracer.OnPipelineEvent(ev)
+				}
 			} else {
 				fq.log.Warn("Failed to parse frames", "origin", fq.prev.Origin(), "err", err)
+				ev := newTraceEvent(StageFrameQueue, EventFramesInvalid, fq.prev.Origin())
+				ev.Reason = err.Error()
+				fq.tracer.OnPipelineEvent(ev)
 			}
 		}
 	}
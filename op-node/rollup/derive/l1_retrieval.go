@@ -24,17 +24,21 @@ type L1Retrieval struct {
 	log     log.Logger
 	dataSrc DataAvailabilitySource
 	prev    NextBlockProvider
+	tracer  Tracer
 
 	datas DataIter
+	// opened is the L1 block that datas was opened for.
+	opened eth.L1BlockRef
 }
 
 var _ ResetableStage = (*L1Retrieval)(nil)
 
-func NewL1Retrieval(log log.Logger, dataSrc DataAvailabilitySource, prev NextBlockProvider) *L1Retrieval {
+func NewL1Retrieval(log log.Logger, tracer Tracer, dataSrc DataAvailabilitySource, prev NextBlockProvider) *L1Retrieval {
 	return &L1Retrieval{
 		log:     log,
 		dataSrc: dataSrc,
 		prev:    prev,
+		tracer:  tracer,
 	}
 }
 
@@ -57,6 +61,8 @@ func (l1r *L1Retrieval) NextData(ctx context.Context) ([]byte, error) {
 		if err != nil {
 			return nil, err
 		}
+		l1r.opened = next
+		l1r.tracer.OnPipelineEvent(newTraceEvent(StageL1Retrieval, EventL1DataOpened, next))
 	}
 
 	l1r.log.Debug("fetching next piece of data")
@@ -68,6 +74,9 @@ func (l1r *L1Retrieval) NextData(ctx context.Context) ([]byte, error) {
 		// CalldataSource appropriately wraps the error so avoid double wrapping errors here.
 		return nil, err
 	} else {
+		ev := newTraceEvent(StageL1Retrieval, EventL1DataRetrieved, l1r.opened)
+		ev.Data = &DataTrace{Length: len(data)}
+		l1r.tracer.OnPipelineEvent(ev)
 		return data, nil
 	}
 }
@@ -77,6 +86,7 @@ func (l1r *L1Retrieval) NextData(ctx context.Context) ([]byte, error) {
 // internal invariants that later propagate up the derivation pipeline.
 func (l1r *L1Retrieval) Reset(ctx context.Context, base eth.L1BlockRef, sysCfg eth.SystemConfig) error {
 	l1r.datas, _ = l1r.dataSrc.OpenData(ctx, base.ID(), sysCfg.BatcherAddr)
+	l1r.opened = base
 	l1r.log.Info("Reset of L1Retrieval done", "origin", base)
 	return io.EOF
 }
@@ -92,7 +92,7 @@ func TestL1RetrievalReset(t *testing.T) {
 	dataSrc.ExpectOpenData(a.ID(), &fakeDataIter{}, l1Cfg.BatcherAddr)
 	defer dataSrc.AssertExpectations(t)
 
-	l1r := NewL1Retrieval(testlog.Logger(t, log.LvlError), dataSrc, nil)
+	l1r := NewL1Retrieval(testlog.Logger(t, log.LvlError), NoopTracer, dataSrc, nil)
 
 	// We assert that it opens up the correct data on a reset
 	_ = l1r.Reset(context.Background(), a, l1Cfg)
@@ -149,7 +149,8 @@ func TestL1RetrievalNextData(t *testing.T) {
 			dataSrc := &MockDataSource{}
 			dataSrc.ExpectOpenData(test.prevBlock.ID(), &fakeDataIter{data: test.datas, errs: test.datasErrs}, test.sysCfg.BatcherAddr)
 
-			ret := NewL1Retrieval(testlog.Logger(t, log.LvlCrit), dataSrc, l1t)
+			tracer := new(recordingTracer)
+			ret := NewL1Retrieval(testlog.Logger(t, log.LvlCrit), tracer, dataSrc, l1t)
 
 			// If prevErr != nil we forced an error while getting data from the previous stage
 			if test.openErr != nil {
@@ -167,6 +168,30 @@ func TestL1RetrievalNextData(t *testing.T) {
 				require.ErrorIs(t, err, test.expectedErrs[i])
 			}
 
+			// The opened L1 block and every retrieved piece of data are traced.
+			var retrieved int
+			for i, ev := range tracer.events {
+				require.Equal(t, StageL1Retrieval, ev.Stage)
+				require.Equal(t, test.prevBlock.ID(), ev.Origin)
+				if i == 0 {
+					require.Equal(t, EventL1DataOpened, ev.Kind)
+					continue
+				}
+				require.Equal(t, EventL1DataRetrieved, ev.Kind)
+				require.Equal(t, len(test.datas[retrieved]), ev.Data.Length)
+				retrieved++
+			}
+			var expectedRetrieved int
+			for _, err := range test.expectedErrs {
+				if err == nil {
+					expectedRetrieved++
+				}
+			}
+			require.Equal(t, expectedRetrieved, retrieved)
+			if test.prevErr != nil {
+				require.Empty(t, tracer.events)
+			}
+
 			l1t.AssertExpectations(t)
 		})
 	}
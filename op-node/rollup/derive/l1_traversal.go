@@ -29,15 +29,17 @@ type L1Traversal struct {
 	log      log.Logger
 	sysCfg   eth.SystemConfig
 	cfg      *rollup.Config
+	tracer   Tracer
 }
 
 var _ ResetableStage = (*L1Traversal)(nil)
 
-func NewL1Traversal(log log.Logger, cfg *rollup.Config, l1Blocks L1BlockRefByNumberFetcher) *L1Traversal {
+func NewL1Traversal(log log.Logger, cfg *rollup.Config, tracer Tracer, l1Blocks L1BlockRefByNumberFetcher) *L1Traversal {
 	return &L1Traversal{
 		log:      log,
 		l1Blocks: l1Blocks,
 		cfg:      cfg,
+		tracer:   tracer,
 	}
 }
 
@@ -67,6 +69,9 @@ func (l1t *L1Traversal) AdvanceL1Block(ctx context.Context) error {
 		return NewTemporaryError(fmt.Errorf("failed to find L1 block info by number, at origin %s next %d: %w", origin, origin.Number+1, err))
 	}
 	if l1t.block.Hash != nextL1Origin.ParentHash {
+		ev := newTraceEvent(StageL1Traversal, EventL1ReorgDetected, origin)
+		ev.L1Block = &nextL1Origin
+		l1t.tracer.OnPipelineEvent(ev)
 		return NewResetError(fmt.Errorf("detected L1 reorg from %s to %s with conflicting parent %s", l1t.block, nextL1Origin, nextL1Origin.ParentID()))
 	}
 
@@ -82,6 +87,9 @@ func (l1t *L1Traversal) AdvanceL1Block(ctx context.Context) error {
 
 	l1t.block = nextL1Origin
 	l1t.done = false
+	ev := newTraceEvent(StageL1Traversal, EventL1BlockAdvanced, origin)
+	ev.L1Block = &nextL1Origin
+	l1t.tracer.OnPipelineEvent(ev)
 	return nil
 }
 
@@ -91,6 +99,7 @@ func (l1t *L1Traversal) Reset(ctx context.Context, base eth.L1BlockRef, cfg eth.
 	l1t.done = false
 	l1t.sysCfg = cfg
 	l1t.log.Info("completed reset of derivation pipeline", "origin", base)
+	l1t.tracer.OnPipelineEvent(newTraceEvent(StageL1Traversal, EventL1TraversalReset, base))
 	return io.EOF
 }
 
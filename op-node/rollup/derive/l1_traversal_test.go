@@ -36,7 +36,7 @@ func TestL1TraversalNext(t *testing.T) {
 		Genesis:               rollup.Genesis{SystemConfig: l1Cfg},
 		L1SystemConfigAddress: sysCfgAddr,
 	}
-	tr := NewL1Traversal(testlog.Logger(t, log.LvlError), cfg, nil)
+	tr := NewL1Traversal(testlog.Logger(t, log.LvlError), cfg, NoopTracer, nil)
 
 	_ = tr.Reset(context.Background(), a, l1Cfg)
 
@@ -75,6 +75,8 @@ func TestL1TraversalAdvance(t *testing.T) {
 		l1Receipts   []*types.Receipt
 		fetcherErr   error
 		expectedErr  error
+		// expectedEvent is the kind of the trace event after the reset, if any
+		expectedEvent string
 	}{
 		{
 			name:       "simple extension",
@@ -85,16 +87,18 @@ func TestL1TraversalAdvance(t *testing.T) {
 				Overhead:    [32]byte{22},
 				Scalar:      [32]byte{33},
 			},
-			l1Receipts:  []*types.Receipt{},
-			fetcherErr:  nil,
-			expectedErr: nil,
+			l1Receipts:    []*types.Receipt{},
+			fetcherErr:    nil,
+			expectedErr:   nil,
+			expectedEvent: EventL1BlockAdvanced,
 		},
 		{
-			name:        "reorg",
-			startBlock:  a,
-			nextBlock:   x,
-			fetcherErr:  nil,
-			expectedErr: ErrReset,
+			name:          "reorg",
+			startBlock:    a,
+			nextBlock:     x,
+			fetcherErr:    nil,
+			expectedErr:   ErrReset,
+			expectedEvent: EventL1ReorgDetected,
 		},
 		{
 			name:        "not found",
@@ -132,7 +136,8 @@ func TestL1TraversalAdvance(t *testing.T) {
 				Genesis:               rollup.Genesis{SystemConfig: test.initialL1Cfg},
 				L1SystemConfigAddress: sysCfgAddr,
 			}
-			tr := NewL1Traversal(testlog.Logger(t, log.LvlError), cfg, src)
+			tracer := new(recordingTracer)
+			tr := NewL1Traversal(testlog.Logger(t, log.LvlError), cfg, tracer, src)
 			// Load up the initial state with a reset
 			_ = tr.Reset(context.Background(), test.startBlock, test.initialL1Cfg)
 
@@ -140,6 +145,18 @@ func TestL1TraversalAdvance(t *testing.T) {
 			err := tr.AdvanceL1Block(context.Background())
 			require.ErrorIs(t, err, test.expectedErr)
 
+			require.Equal(t, EventL1TraversalReset, tracer.events[0].Kind)
+			if test.expectedEvent != "" {
+				require.Len(t, tracer.events, 2)
+				ev := tracer.events[1]
+				require.Equal(t, StageL1Traversal, ev.Stage)
+				require.Equal(t, test.expectedEvent, ev.Kind)
+				require.Equal(t, test.startBlock.ID(), ev.Origin)
+				require.Equal(t, test.nextBlock, *ev.L1Block)
+			} else {
+				require.Len(t, tracer.events, 1)
+			}
+
 			if test.expectedErr == nil {
 				ref, err := tr.NextL1Block(context.Background())
 				require.Nil(t, err)
@@ -49,6 +49,7 @@ type EngineQueueStage interface {
 	Origin() eth.L1BlockRef
 	SystemConfig() eth.SystemConfig
 	SetUnsafeHead(head eth.L2BlockRef)
+	ResetTo(unsafe, safe, finalized eth.L2BlockRef)
 
 	Finalize(l1Origin eth.L1BlockRef)
 	AddUnsafePayload(payload *eth.ExecutionPayload)
@@ -75,21 +76,22 @@ type DerivationPipeline struct {
 }
 
 // NewDerivationPipeline creates a derivation pipeline, which should be reset before use.
-func NewDerivationPipeline(log log.Logger, cfg *rollup.Config, daCfg *rollup.DAConfig, l1Fetcher L1Fetcher, engine Engine, metrics Metrics) *DerivationPipeline {
+// The tracer receives structured events of the pipeline stages, use NoopTracer if these are not needed.
+func NewDerivationPipeline(log log.Logger, cfg *rollup.Config, daCfg *rollup.DAConfig, l1Fetcher L1Fetcher, engine Engine, metrics Metrics, tracer Tracer) *DerivationPipeline {
 
 	// Pull stages
-	l1Traversal := NewL1Traversal(log, cfg, l1Fetcher)
-	dataSrc := NewDataSourceFactory(log, cfg, daCfg, l1Fetcher) // auxiliary stage for L1Retrieval
-	l1Src := NewL1Retrieval(log, dataSrc, l1Traversal)
-	frameQueue := NewFrameQueue(log, l1Src)
-	bank := NewChannelBank(log, cfg, frameQueue, l1Fetcher)
-	chInReader := NewChannelInReader(log, bank, metrics)
-	batchQueue := NewBatchQueue(log, cfg, chInReader)
+	l1Traversal := NewL1Traversal(log, cfg, tracer, l1Fetcher)
+	dataSrc := NewDataSourceFactory(log, cfg, daCfg, tracer, l1Fetcher) // auxiliary stage for L1Retrieval
+	l1Src := NewL1Retrieval(log, tracer, dataSrc, l1Traversal)
+	frameQueue := NewFrameQueue(log, tracer, l1Src)
+	bank := NewChannelBank(log, cfg, tracer, frameQueue, l1Fetcher)
+	chInReader := NewChannelInReader(log, bank, metrics, tracer)
+	batchQueue := NewBatchQueue(log, cfg, tracer, chInReader)
 	attrBuilder := NewFetchingAttributesBuilder(cfg, l1Fetcher, engine)
-	attributesQueue := NewAttributesQueue(log, cfg, attrBuilder, batchQueue)
+	attributesQueue := NewAttributesQueue(log, cfg, tracer, attrBuilder, batchQueue)
 
 	// Step stages
-	eng := NewEngineQueue(log, cfg, engine, metrics, attributesQueue, l1Fetcher)
+	eng := NewEngineQueue(log, cfg, tracer, engine, metrics, attributesQueue, l1Fetcher)
 
 	// Reset from engine queue then up from L1 Traversal. The stages do not talk to each other during
 	// the reset, but after the engine queue, this is the order in which the stages could talk to each other.
@@ -118,6 +120,13 @@ func (dp *DerivationPipeline) Reset() {
 	dp.resetting = 0
 }
 
+// ResetTo resets the pipeline to start deriving from the given safe head,
+// with the L1 origin of the safe head, minus the sequencing window, as pipeline origin.
+func (dp *DerivationPipeline) ResetTo(unsafe, safe, finalized eth.L2BlockRef) {
+	dp.eng.ResetTo(unsafe, safe, finalized)
+	dp.resetting = 0
+}
+
 // Origin is the L1 block of the inner-most stage of the derivation pipeline,
 // i.e. the L1 chain up to and including this point included and/or produced all the safe L2 blocks.
 func (dp *DerivationPipeline) Origin() eth.L1BlockRef {
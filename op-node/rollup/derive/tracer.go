@@ -0,0 +1,151 @@
+package derive
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// Pipeline stage names, as reported in trace events.
+const (
+	StageL1Traversal     = "l1_traversal"
+	StageL1Retrieval     = "l1_retrieval"
+	StageDataSource      = "data_source"
+	StageFrameQueue      = "frame_queue"
+	StageChannelBank     = "channel_bank"
+	StageChannelInReader = "channel_in_reader"
+	StageBatchQueue      = "batch_queue"
+	StageAttributesQueue = "attributes_queue"
+	StageEngineQueue     = "engine_queue"
+)
+
+// Trace event kinds.
+const (
+	EventL1BlockAdvanced     = "l1_block_advanced"
+	EventL1ReorgDetected     = "l1_reorg_detected"
+	EventL1TraversalReset    = "l1_traversal_reset"
+	EventL1DataOpened        = "l1_data_opened"
+	EventL1DataRetrieved     = "l1_data_retrieved"
+	EventDataResolved        = "data_resolved"
+	EventFrameIngested       = "frame_ingested"
+	EventFramesInvalid       = "frames_invalid"
+	EventChannelOpened       = "channel_opened"
+	EventChannelFrameDropped = "channel_frame_dropped"
+	EventChannelTimedOut     = "channel_timed_out"
+	EventChannelPruned       = "channel_pruned"
+	EventChannelRead         = "channel_read"
+	EventChannelInvalid      = "channel_invalid"
+	EventBatchAccepted       = "batch_accepted"
+	EventBatchDropped        = "batch_dropped"
+	EventBatchGenerated      = "batch_generated"
+	EventAttributesGenerated = "attributes_generated"
+	EventSafeHeadUpdated     = "safe_head_updated"
+	EventSafeHeadReset       = "safe_head_reset"
+)
+
+// TraceEvent is a structured event emitted by a derivation pipeline stage, for debugging purposes.
+// Only the details relevant to the event kind are set.
+type TraceEvent struct {
+	Time   time.Time   `json:"time"`
+	Stage  string      `json:"stage"`
+	Kind   string      `json:"kind"`
+	Origin eth.BlockID `json:"origin"`
+	// Reason explains why data was dropped, if any was dropped.
+	Reason string `json:"reason,omitempty"`
+
+	// L1Block is the L1 block that was traversed to, or that conflicts with the origin on an L1 reorg.
+	L1Block    *eth.L1BlockRef  `json:"l1_block,omitempty"`
+	Data       *DataTrace       `json:"data,omitempty"`
+	DA         *DATrace         `json:"da,omitempty"`
+	Frame      *FrameTrace      `json:"frame,omitempty"`
+	Channel    *ChannelTrace    `json:"channel,omitempty"`
+	Batch      *BatchTrace      `json:"batch,omitempty"`
+	Attributes *AttributesTrace `json:"attributes,omitempty"`
+	// SafeHead is the new L2 safe head, after it was updated or reset.
+	SafeHead *eth.L2BlockRef `json:"safe_head,omitempty"`
+}
+
+// DataTrace describes a piece of data retrieved from L1.
+type DataTrace struct {
+	Length int `json:"length"`
+}
+
+// DATrace describes the frame data that a frame reference resolved to on the DA layer.
+type DATrace struct {
+	Height     uint64        `json:"height"`
+	Commitment hexutil.Bytes `json:"commitment"`
+	Source     string        `json:"source"`
+	Length     int           `json:"length"`
+}
+
+type FrameTrace struct {
+	Channel     ChannelID `json:"channel"`
+	FrameNumber uint16    `json:"frame_number"`
+	Length      int       `json:"length"`
+	IsLast      bool      `json:"is_last"`
+}
+
+type ChannelTrace struct {
+	ID        ChannelID `json:"id"`
+	OpenBlock uint64    `json:"open_block"`
+	Size      uint64    `json:"size"`
+	Frames    int       `json:"frames"`
+}
+
+type BatchTrace struct {
+	Timestamp        uint64      `json:"timestamp"`
+	ParentHash       common.Hash `json:"parent_hash"`
+	Epoch            eth.BlockID `json:"epoch"`
+	Transactions     int         `json:"transactions"`
+	L1InclusionBlock eth.BlockID `json:"l1_inclusion_block"`
+}
+
+type AttributesTrace struct {
+	Timestamp    uint64      `json:"timestamp"`
+	Parent       eth.BlockID `json:"parent"`
+	Transactions int         `json:"transactions"`
+}
+
+// Tracer receives the trace events of the derivation pipeline stages.
+// Events are emitted synchronously by the stages: the tracer must not block.
+type Tracer interface {
+	OnPipelineEvent(ev TraceEvent)
+}
+
+type noopTracer struct{}
+
+func (noopTracer) OnPipelineEvent(ev TraceEvent) {}
+
+// NoopTracer is a Tracer that discards all events.
+var NoopTracer Tracer = noopTracer{}
+
+func newTraceEvent(stage string, kind string, origin eth.L1BlockRef) TraceEvent {
+	return TraceEvent{
+		Time:   time.Now(),
+		Stage:  stage,
+		Kind:   kind,
+		Origin: origin.ID(),
+	}
+}
+
+func channelTrace(ch *Channel) *ChannelTrace {
+	return &ChannelTrace{
+		ID:        ch.id,
+		OpenBlock: ch.OpenBlockNumber(),
+		Size:      ch.Size(),
+		Frames:    len(ch.inputs),
+	}
+}
+
+func batchTrace(batch *BatchWithL1InclusionBlock) *BatchTrace {
+	return &BatchTrace{
+		Timestamp:        batch.Batch.Timestamp,
+		ParentHash:       batch.Batch.ParentHash,
+		Epoch:            batch.Batch.Epoch(),
+		Transactions:     len(batch.Batch.Transactions),
+		L1InclusionBlock: batch.L1InclusionBlock.ID(),
+	}
+}
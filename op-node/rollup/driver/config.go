@@ -20,4 +20,17 @@ type Config struct {
 	// SequencerMaxSafeLag is the maximum number of L2 blocks for restricting the distance between L2 safe and unsafe.
 	// Disabled if 0.
 	SequencerMaxSafeLag uint64 `json:"sequencer_max_safe_lag"`
+
+	// SequencerPolicy restricts the inclusion of tx-pool transactions by the sequencer.
+	// It can be changed at runtime through the admin RPC.
+	SequencerPolicy SequencerPolicy `json:"sequencer_policy"`
+
+	// L1ReorgHaltDepth is the L1 reorg depth above which derivation and sequencing are halted,
+	// until the reorg is acknowledged by an operator through the admin RPC.
+	// Disabled if 0.
+	L1ReorgHaltDepth uint64 `json:"l1_reorg_halt_depth"`
+
+	// L1ReorgHalt is the L1 reorg that halted derivation and sequencing before a restart, restored from the persisted state.
+	// Nil if not halted.
+	L1ReorgHalt *L1Reorg `json:"-"`
 }
@@ -28,6 +28,8 @@ type Metrics interface {
 	SetDerivationIdle(idle bool)
 
 	RecordL1ReorgDepth(d uint64)
+	RecordL1Reorg(oldHead eth.L1BlockRef, newHead eth.L1BlockRef)
+	RecordL1ReorgHalt(halted bool)
 
 	EngineMetrics
 	L1FetcherMetrics
@@ -48,6 +50,7 @@ type L2Chain interface {
 
 type DerivationPipeline interface {
 	Reset()
+	ResetTo(unsafe, safe, finalized eth.L2BlockRef)
 	Step(ctx context.Context) error
 	AddUnsafePayload(payload *eth.ExecutionPayload)
 	UnsafeL2SyncTarget() eth.L2BlockRef
@@ -61,7 +64,7 @@ type DerivationPipeline interface {
 }
 
 type L1StateIface interface {
-	HandleNewL1HeadBlock(head eth.L1BlockRef)
+	HandleNewL1HeadBlock(ctx context.Context, head eth.L1BlockRef) *L1Reorg
 	HandleNewL1SafeBlock(safe eth.L1BlockRef)
 	HandleNewL1FinalizedBlock(finalized eth.L1BlockRef)
 
@@ -76,6 +79,8 @@ type SequencerIface interface {
 	PlanNextSequencerAction() time.Duration
 	RunNextSequencerAction(ctx context.Context) (*eth.ExecutionPayload, error)
 	BuildingOnto() eth.L2BlockRef
+	SetPolicy(policy SequencerPolicy)
+	Policy() SequencerPolicy
 }
 
 type Network interface {
@@ -105,29 +110,43 @@ type AltSync interface {
 type SequencerStateListener interface {
 	SequencerStarted() error
 	SequencerStopped() error
+	SequencerPolicyUpdated(policy SequencerPolicy) error
+	// L1ReorgHaltUpdated is called when derivation and sequencing are halted by an L1 reorg,
+	// or with nil when the halt is lifted.
+	L1ReorgHaltUpdated(reorg *L1Reorg) error
 }
 
 // NewDriver composes an events handler that tracks L1 state, triggers L2 derivation, and optionally sequences new L2 blocks.
-func NewDriver(driverCfg *Config, cfg *rollup.Config, daCfg *rollup.DAConfig, l2 L2Chain, l1 L1Chain, altSync AltSync, network Network, log log.Logger, snapshotLog log.Logger, metrics Metrics, sequencerStateListener SequencerStateListener) *Driver {
+func NewDriver(driverCfg *Config, cfg *rollup.Config, daCfg *rollup.DAConfig, l2 L2Chain, l1 L1Chain, altSync AltSync, network Network, log log.Logger, snapshotLog log.Logger, metrics Metrics, tracer derive.Tracer, sequencerStateListener SequencerStateListener) *Driver {
 	l1 = NewMeteredL1Fetcher(l1, metrics)
-	l1State := NewL1State(log, metrics)
+	var maxL1ReorgDepth uint64
+	if driverCfg.L1ReorgHaltDepth > 0 {
+		// measure reorgs just deep enough to tell if they exceed the halt depth
+		maxL1ReorgDepth = driverCfg.L1ReorgHaltDepth + 1
+	}
+	l1State := NewL1State(log, metrics, l1, maxL1ReorgDepth)
 	sequencerConfDepth := NewConfDepth(driverCfg.SequencerConfDepth, l1State.L1Head, l1)
 	findL1Origin := NewL1OriginSelector(log, cfg, sequencerConfDepth)
 	verifConfDepth := NewConfDepth(driverCfg.VerifierConfDepth, l1State.L1Head, l1)
-	derivationPipeline := derive.NewDerivationPipeline(log, cfg, daCfg, verifConfDepth, l2, metrics)
+	derivationPipeline := derive.NewDerivationPipeline(log, cfg, daCfg, verifConfDepth, l2, metrics, tracer)
 	attrBuilder := derive.NewFetchingAttributesBuilder(cfg, l1, l2)
 	engine := derivationPipeline
 	meteredEngine := NewMeteredEngine(cfg, engine, metrics, log)
 	sequencer := NewSequencer(log, cfg, meteredEngine, attrBuilder, findL1Origin, metrics)
+	sequencer.SetPolicy(driverCfg.SequencerPolicy)
 
 	return &Driver{
 		l1State:          l1State,
 		derivation:       derivationPipeline,
 		stateReq:         make(chan chan struct{}),
 		forceReset:       make(chan chan struct{}, 10),
+		rollbackSafeHead: make(chan rollbackRequest, 10),
 		startSequencer:   make(chan hashAndErrorChannel, 10),
 		stopSequencer:    make(chan chan hashAndError, 10),
 		sequencerActive:  make(chan chan bool, 10),
+		sequencerPolicy:  make(chan policyRequest, 10),
+		ackL1Reorg:       make(chan chan reorgAndError, 10),
+		l1ReorgHalt:      driverCfg.L1ReorgHalt,
 		sequencerNotifs:  sequencerStateListener,
 		config:           cfg,
 		driverConfig:     driverCfg,
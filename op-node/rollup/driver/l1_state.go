@@ -1,35 +1,93 @@
 package driver
 
 import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 )
 
+const (
+	// l1HistorySize is the number of recent L1 head blocks that is tracked to determine the depth of L1 reorgs.
+	// Reorgs deeper than this are reported with a lower bound of the depth.
+	l1HistorySize = 256
+	// l1DefaultReorgWalkDepth is the default number of blocks to walk back the new L1 chain
+	// to find the common ancestor with the previous L1 chain.
+	l1DefaultReorgWalkDepth = 64
+	// l1ReorgWalkTimeout bounds the total time spent walking back the new L1 chain,
+	// since the walk blocks the driver event loop.
+	l1ReorgWalkTimeout = 5 * time.Second
+)
+
 type L1Metrics interface {
 	RecordL1ReorgDepth(d uint64)
+	RecordL1Reorg(oldHead eth.L1BlockRef, newHead eth.L1BlockRef)
 	RecordL1Ref(name string, ref eth.L1BlockRef)
 }
 
+type L1BlockRefByHashFetcher interface {
+	L1BlockRefByHash(ctx context.Context, hash common.Hash) (eth.L1BlockRef, error)
+}
+
+// L1Reorg describes a reorg of the L1 chain, as seen through the L1 head signals.
+type L1Reorg struct {
+	// OldHead is the L1 head before the reorg.
+	OldHead eth.L1BlockRef `json:"oldHead"`
+	// NewHead is the L1 head after the reorg.
+	NewHead eth.L1BlockRef `json:"newHead"`
+	// CommonAncestor is the last block that the old and new chain have in common.
+	// It is zeroed if the common ancestor could not be found within the tracked L1 history.
+	CommonAncestor eth.BlockID `json:"commonAncestor"`
+	// Depth is the number of blocks of the old chain that were reorged out.
+	// If the common ancestor is unknown, it is a lower bound.
+	Depth uint64 `json:"depth"`
+}
+
 // L1State tracks L1 head, safe and finalized blocks. It is not safe to write and read concurrently.
 type L1State struct {
 	log     log.Logger
 	metrics L1Metrics
+	l1      L1BlockRefByHashFetcher
 
 	// Latest recorded head, safe block and finalized block of the L1 Chain, independent of derivation work
 	l1Head      eth.L1BlockRef
 	l1Safe      eth.L1BlockRef
 	l1Finalized eth.L1BlockRef
+
+	// canonical tracks the hashes of recent canonical L1 blocks by number, up to l1HistorySize blocks behind the L1 head.
+	canonical map[uint64]common.Hash
+
+	// maxReorgDepth is the reorg depth up to which the common ancestor is searched for.
+	// Deeper reorgs are reported with a lower bound of the depth.
+	maxReorgDepth uint64
 }
 
-func NewL1State(log log.Logger, metrics L1Metrics) *L1State {
+// NewL1State creates a new L1State. The L1 fetcher is used to determine the depth of L1 reorgs,
+// the reorg depth is approximated from the block numbers if it is nil.
+// The fetcher should be cached: the recent L1 blocks it walks back are typically already fetched by the derivation.
+// Reorgs are measured up to maxReorgDepth blocks deep, or l1DefaultReorgWalkDepth if 0, and at most l1HistorySize.
+func NewL1State(log log.Logger, metrics L1Metrics, l1 L1BlockRefByHashFetcher, maxReorgDepth uint64) *L1State {
+	if maxReorgDepth == 0 {
+		maxReorgDepth = l1DefaultReorgWalkDepth
+	}
+	if maxReorgDepth > l1HistorySize {
+		maxReorgDepth = l1HistorySize
+	}
 	return &L1State{
-		log:     log,
-		metrics: metrics,
+		log:           log,
+		metrics:       metrics,
+		l1:            l1,
+		canonical:     make(map[uint64]common.Hash),
+		maxReorgDepth: maxReorgDepth,
 	}
 }
 
-func (s *L1State) HandleNewL1HeadBlock(head eth.L1BlockRef) {
+// HandleNewL1HeadBlock updates the L1 head, and returns the L1 reorg, if the new head reorged out any blocks.
+func (s *L1State) HandleNewL1HeadBlock(ctx context.Context, head eth.L1BlockRef) *L1Reorg {
+	var reorg *L1Reorg
 	// We don't need to do anything if the head hasn't changed.
 	if s.l1Head == (eth.L1BlockRef{}) {
 		s.log.Info("Received first L1 head signal", "l1_head", head)
@@ -40,15 +98,76 @@ func (s *L1State) HandleNewL1HeadBlock(head eth.L1BlockRef) {
 		// dealing with a linear extension (new block is the immediate child of the old one).
 		s.log.Debug("L1 head moved forward", "l1_head", head)
 	} else {
-		if s.l1Head.Number >= head.Number {
-			s.metrics.RecordL1ReorgDepth(s.l1Head.Number - head.Number)
-		}
 		// New L1 block is not the same as the current head or a single step linear extension.
 		// This could either be a long L1 extension, or a reorg, or we simply missed a head update.
 		s.log.Warn("L1 head signal indicates a possible L1 re-org", "old_l1_head", s.l1Head, "new_l1_head_parent", head.ParentHash, "new_l1_head", head)
+		reorg = s.findReorg(ctx, head)
+		if reorg != nil {
+			s.metrics.RecordL1ReorgDepth(reorg.Depth)
+			s.metrics.RecordL1Reorg(reorg.OldHead, reorg.NewHead)
+			s.log.Warn("Detected L1 re-org", "depth", reorg.Depth, "common_ancestor", reorg.CommonAncestor,
+				"old_l1_head", reorg.OldHead, "new_l1_head", reorg.NewHead)
+		}
 	}
 	s.metrics.RecordL1Ref("l1_head", head)
 	s.l1Head = head
+	s.canonical[head.Number] = head.Hash
+	// forget about the blocks after the new head, in case of a reorg to a shorter chain, and blocks that are too old
+	for n := range s.canonical {
+		if n > head.Number || n+l1HistorySize < head.Number {
+			delete(s.canonical, n)
+		}
+	}
+	return reorg
+}
+
+// findReorg walks back the new L1 chain, until it finds a block of the tracked canonical chain.
+// It returns nil if the new head is an extension of the previous head.
+// The walk is bounded by the max reorg depth and by l1ReorgWalkTimeout, since it runs in the driver event loop.
+func (s *L1State) findReorg(ctx context.Context, head eth.L1BlockRef) *L1Reorg {
+	oldHead := s.l1Head
+	if s.l1 == nil {
+		if oldHead.Number < head.Number {
+			return nil
+		}
+		return &L1Reorg{OldHead: oldHead, NewHead: head, Depth: oldHead.Number - head.Number}
+	}
+	if head.Number > oldHead.Number+s.maxReorgDepth {
+		// walking back this far would take too many requests, and the tracked history is too old to compare against
+		s.log.Warn("L1 head moved too far ahead to check for re-orgs", "old_l1_head", oldHead, "new_l1_head", head)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, l1ReorgWalkTimeout)
+	defer cancel()
+	// the new chain, to update the canonical chain with, if the walk succeeds
+	newChain := make(map[uint64]common.Hash)
+	cur := head
+	for {
+		if h, ok := s.canonical[cur.Number]; ok && h == cur.Hash {
+			break
+		}
+		newChain[cur.Number] = cur.Hash
+		if cur.Number == 0 || cur.Number+s.maxReorgDepth <= oldHead.Number {
+			// the common ancestor is older than the max reorg depth
+			s.log.Warn("L1 re-org is deeper than the max measured depth", "old_l1_head", oldHead, "new_l1_head", head,
+				"walked_back_to", cur, "max_depth", s.maxReorgDepth)
+			return &L1Reorg{OldHead: oldHead, NewHead: head, Depth: oldHead.Number - cur.Number}
+		}
+		parent, err := s.l1.L1BlockRefByHash(ctx, cur.ParentHash)
+		if err != nil {
+			s.log.Error("Failed to determine L1 re-org depth", "old_l1_head", oldHead, "new_l1_head", head, "walked_back_to", cur, "err", err)
+			return nil
+		}
+		cur = parent
+	}
+	for n, h := range newChain {
+		s.canonical[n] = h
+	}
+	if cur.Hash == oldHead.Hash {
+		s.log.Info("L1 head moved forward by multiple blocks", "old_l1_head", oldHead, "new_l1_head", head)
+		return nil
+	}
+	return &L1Reorg{OldHead: oldHead, NewHead: head, CommonAncestor: cur.ID(), Depth: oldHead.Number - cur.Number}
 }
 
 func (s *L1State) HandleNewL1SafeBlock(safe eth.L1BlockRef) {
@@ -0,0 +1,98 @@
+package driver
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+func TestL1StateReorgDepth(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	logger := testlog.Logger(t, log.LvlError)
+	ctx := context.Background()
+
+	l1 := &testutils.MockL1Source{}
+	var reorged []eth.L1BlockRef
+	metrics := &testutils.TestDerivationMetrics{
+		FnRecordL1Reorg: func(oldHead eth.L1BlockRef, newHead eth.L1BlockRef) {
+			reorged = append(reorged, oldHead, newHead)
+		},
+	}
+	state := NewL1State(logger, metrics, l1, 0)
+
+	// canonical chain: a0 <- a1 <- a2 <- a3
+	a0 := testutils.RandomBlockRef(rng)
+	a1 := testutils.NextRandomRef(rng, a0)
+	a2 := testutils.NextRandomRef(rng, a1)
+	a3 := testutils.NextRandomRef(rng, a2)
+	for _, ref := range []eth.L1BlockRef{a0, a1, a2, a3} {
+		require.Nil(t, state.HandleNewL1HeadBlock(ctx, ref))
+	}
+
+	// alternative chain: a1 <- b2 <- b3 <- b4, reorging out a2 and a3
+	b2 := testutils.NextRandomRef(rng, a1)
+	b3 := testutils.NextRandomRef(rng, b2)
+	b4 := testutils.NextRandomRef(rng, b3)
+	l1.ExpectL1BlockRefByHash(b4.ParentHash, b3, nil)
+	l1.ExpectL1BlockRefByHash(b3.ParentHash, b2, nil)
+	l1.ExpectL1BlockRefByHash(b2.ParentHash, a1, nil)
+	reorg := state.HandleNewL1HeadBlock(ctx, b4)
+	require.NotNil(t, reorg)
+	require.Equal(t, uint64(2), reorg.Depth)
+	require.Equal(t, a1.ID(), reorg.CommonAncestor)
+	require.Equal(t, a3, reorg.OldHead)
+	require.Equal(t, b4, reorg.NewHead)
+	require.Equal(t, []eth.L1BlockRef{a3, b4}, reorged)
+	l1.AssertExpectations(t)
+
+	// a long extension of the new chain is not a reorg
+	b5 := testutils.NextRandomRef(rng, b4)
+	b6 := testutils.NextRandomRef(rng, b5)
+	l1.ExpectL1BlockRefByHash(b6.ParentHash, b5, nil)
+	l1.ExpectL1BlockRefByHash(b5.ParentHash, b4, nil)
+	require.Nil(t, state.HandleNewL1HeadBlock(ctx, b6))
+	l1.AssertExpectations(t)
+	require.Len(t, reorged, 2)
+}
+
+func TestL1StateMaxReorgDepth(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	logger := testlog.Logger(t, log.LvlError)
+	ctx := context.Background()
+
+	l1 := &testutils.MockL1Source{}
+	state := NewL1State(logger, &testutils.TestDerivationMetrics{}, l1, 2)
+
+	// canonical chain: a0 <- a1 <- a2 <- a3 <- a4
+	chain := []eth.L1BlockRef{testutils.RandomBlockRef(rng)}
+	for i := 1; i < 5; i++ {
+		chain = append(chain, testutils.NextRandomRef(rng, chain[i-1]))
+	}
+	for _, ref := range chain {
+		require.Nil(t, state.HandleNewL1HeadBlock(ctx, ref))
+	}
+
+	// alternative chain: a0 <- b1 <- b2 <- b3 <- b4 <- b5, reorging out 4 blocks
+	b1 := testutils.NextRandomRef(rng, chain[0])
+	b2 := testutils.NextRandomRef(rng, b1)
+	b3 := testutils.NextRandomRef(rng, b2)
+	b4 := testutils.NextRandomRef(rng, b3)
+	b5 := testutils.NextRandomRef(rng, b4)
+	// the walk stops at the max depth, without fetching the blocks beyond it
+	l1.ExpectL1BlockRefByHash(b5.ParentHash, b4, nil)
+	l1.ExpectL1BlockRefByHash(b4.ParentHash, b3, nil)
+	l1.ExpectL1BlockRefByHash(b3.ParentHash, b2, nil)
+	reorg := state.HandleNewL1HeadBlock(ctx, b5)
+	require.NotNil(t, reorg)
+	require.Equal(t, uint64(2), reorg.Depth, "depth is a lower bound")
+	require.Equal(t, eth.BlockID{}, reorg.CommonAncestor, "common ancestor is unknown")
+	l1.AssertExpectations(t)
+}
@@ -0,0 +1,41 @@
+package driver
+
+// SequencerPolicy is the set of operator policies that restrict which transactions the sequencer includes.
+// Deposits are always included: the policies only affect the inclusion of transactions from the tx-pool.
+type SequencerPolicy struct {
+	// DepositOnly forces the sequencer to produce blocks with only deposits.
+	DepositOnly bool `json:"depositOnly"`
+
+	// TxPoolPaused temporarily stops the inclusion of tx-pool transactions, e.g. during an incident.
+	TxPoolPaused bool `json:"txPoolPaused"`
+
+	// MaxGasPerBlock is the maximum L2 gas that the sequencer uses per block. Disabled if 0.
+	// The block gas limit is part of the system config, and verified by all nodes, so it cannot be lowered by the sequencer.
+	// Instead, tx-pool transactions are excluded from the next block after a block that used more gas than this maximum.
+	MaxGasPerBlock uint64 `json:"maxGasPerBlock"`
+}
+
+// Reason for not including tx-pool transactions in a new block
+const (
+	NoTxPoolDrift       = "drift"
+	NoTxPoolDepositOnly = "deposit_only"
+	NoTxPoolPaused      = "paused"
+	NoTxPoolMaxGas      = "max_gas"
+)
+
+// noTxPoolReason returns the reason for the next block to exclude tx-pool transactions, or an empty string if there is none.
+// The sequencer drift takes priority, since it is enforced by the protocol rather than the policy.
+func (p *SequencerPolicy) noTxPoolReason(drift bool, parentGasUsed uint64) string {
+	switch {
+	case drift:
+		return NoTxPoolDrift
+	case p.DepositOnly:
+		return NoTxPoolDepositOnly
+	case p.TxPoolPaused:
+		return NoTxPoolPaused
+	case p.MaxGasPerBlock != 0 && parentGasUsed > p.MaxGasPerBlock:
+		return NoTxPoolMaxGas
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,30 @@
+package driver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequencerPolicyNoTxPoolReason(t *testing.T) {
+	testCases := []struct {
+		name          string
+		policy        SequencerPolicy
+		drift         bool
+		parentGasUsed uint64
+		expected      string
+	}{
+		{"default", SequencerPolicy{}, false, 30_000_000, ""},
+		{"drift", SequencerPolicy{}, true, 0, NoTxPoolDrift},
+		{"drift over policy", SequencerPolicy{DepositOnly: true, TxPoolPaused: true}, true, 0, NoTxPoolDrift},
+		{"deposit only", SequencerPolicy{DepositOnly: true}, false, 0, NoTxPoolDepositOnly},
+		{"paused", SequencerPolicy{TxPoolPaused: true}, false, 0, NoTxPoolPaused},
+		{"under max gas", SequencerPolicy{MaxGasPerBlock: 1000}, false, 1000, ""},
+		{"over max gas", SequencerPolicy{MaxGasPerBlock: 1000}, false, 1001, NoTxPoolMaxGas},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.policy.noTxPoolReason(tc.drift, tc.parentGasUsed))
+		})
+	}
+}
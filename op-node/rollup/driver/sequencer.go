@@ -27,6 +27,8 @@ type L1OriginSelectorIface interface {
 type SequencerMetrics interface {
 	RecordSequencerInconsistentL1Origin(from eth.BlockID, to eth.BlockID)
 	RecordSequencerReset()
+	RecordSequencerPolicy(depositOnly bool, txPoolPaused bool, maxGasPerBlock uint64)
+	RecordSequencerNoTxPool(reason string)
 }
 
 // Sequencer implements the sequencing interface of the driver: it starts and completes block building jobs.
@@ -45,6 +47,11 @@ type Sequencer struct {
 	timeNow func() time.Time
 
 	nextAction time.Time
+
+	policy SequencerPolicy
+	// lastBlock and lastGasUsed track the gas used by the last sequenced block, to apply the max-gas policy.
+	lastBlock   common.Hash
+	lastGasUsed uint64
 }
 
 func NewSequencer(log log.Logger, cfg *rollup.Config, engine derive.ResettableEngineControl, attributesBuilder derive.AttributesBuilder, l1OriginSelector L1OriginSelectorIface, metrics SequencerMetrics) *Sequencer {
@@ -89,11 +96,21 @@ func (d *Sequencer) StartBuildingBlock(ctx context.Context) error {
 	// empty blocks (other than the L1 info deposit and any user deposits). We handle this by
 	// setting NoTxPool to true, which will cause the Sequencer to not include any transactions
 	// from the transaction pool.
-	attrs.NoTxPool = uint64(attrs.Timestamp) > l1Origin.Time+d.config.MaxSequencerDrift
+	// The operator policy may exclude transactions from the transaction pool as well.
+	drift := uint64(attrs.Timestamp) > l1Origin.Time+d.config.MaxSequencerDrift
+	var parentGasUsed uint64
+	if d.lastBlock == l2Head.Hash {
+		parentGasUsed = d.lastGasUsed
+	}
+	noTxPoolReason := d.policy.noTxPoolReason(drift, parentGasUsed)
+	attrs.NoTxPool = noTxPoolReason != ""
+	if attrs.NoTxPool {
+		d.metrics.RecordSequencerNoTxPool(noTxPoolReason)
+	}
 
 	d.log.Debug("prepared attributes for new block",
 		"num", l2Head.Number+1, "time", uint64(attrs.Timestamp),
-		"origin", l1Origin, "origin_time", l1Origin.Time, "noTxPool", attrs.NoTxPool)
+		"origin", l1Origin, "origin_time", l1Origin.Time, "noTxPool", attrs.NoTxPool, "reason", noTxPoolReason)
 
 	// Start a payload building process.
 	errTyp, err := d.engine.StartPayload(ctx, l2Head, attrs, false)
@@ -111,9 +128,22 @@ func (d *Sequencer) CompleteBuildingBlock(ctx context.Context) (*eth.ExecutionPa
 	if err != nil {
 		return nil, fmt.Errorf("failed to complete building block: error (%d): %w", errTyp, err)
 	}
+	d.lastBlock = payload.BlockHash
+	d.lastGasUsed = uint64(payload.GasUsed)
 	return payload, nil
 }
 
+// SetPolicy changes the operator policy, and applies it from the next block building job onwards.
+func (d *Sequencer) SetPolicy(policy SequencerPolicy) {
+	d.policy = policy
+	d.metrics.RecordSequencerPolicy(policy.DepositOnly, policy.TxPoolPaused, policy.MaxGasPerBlock)
+}
+
+// Policy returns the current operator policy.
+func (d *Sequencer) Policy() SequencerPolicy {
+	return d.policy
+}
+
 // CancelBuildingBlock cancels the current open block building job.
 // This sequencer only maintains one block building job at a time.
 func (d *Sequencer) CancelBuildingBlock(ctx context.Context) {
@@ -39,6 +39,11 @@ type Driver struct {
 	// It tells the caller that the reset occurred by closing the passed in channel.
 	forceReset chan chan struct{}
 
+	// Upon receiving a request in this channel, the L2 chain is rolled back to the requested block,
+	// and the derivation pipeline is reset to re-derive from there.
+	// It tells the caller the block that was rolled back to (or returns an error).
+	rollbackSafeHead chan rollbackRequest
+
 	// Upon receiving a hash in this channel, the sequencer is started at the given hash.
 	// It tells the caller that the sequencer started by closing the passed in channel (or returning an error).
 	startSequencer chan hashAndErrorChannel
@@ -52,6 +57,17 @@ type Driver struct {
 	// true when the sequencer is active, false when it is not.
 	sequencerActive chan chan bool
 
+	// Upon receiving a request in this channel, the sequencer policy is queried, or updated and persisted if a new policy is set.
+	// It tells the caller the resulting policy (or returns an error).
+	sequencerPolicy chan policyRequest
+
+	// Upon receiving a channel in this channel, the L1 reorg halt is lifted and persisted.
+	// It tells the caller the L1 reorg that caused the halt, nil if derivation was not halted, or an error.
+	ackL1Reorg chan chan reorgAndError
+
+	// l1ReorgHalt is the L1 reorg that halted derivation and sequencing, nil if not halted.
+	l1ReorgHalt *L1Reorg
+
 	// sequencerNotifs is notified when the sequencer is started or stopped
 	sequencerNotifs SequencerStateListener
 
@@ -97,6 +113,11 @@ func (s *Driver) Start() error {
 	s.derivation.Reset()
 
 	log.Info("Starting driver", "sequencerEnabled", s.driverConfig.SequencerEnabled, "sequencerStopped", s.driverConfig.SequencerStopped)
+	if s.l1ReorgHalt != nil {
+		s.log.Error("Derivation and sequencing remain halted after deep L1 re-org, until acknowledged by an operator",
+			"depth", s.l1ReorgHalt.Depth, "old_l1_head", s.l1ReorgHalt.OldHead, "new_l1_head", s.l1ReorgHalt.NewHead)
+		s.metrics.RecordL1ReorgHalt(true)
+	}
 	if s.driverConfig.SequencerEnabled {
 		// Notify the initial sequencer state
 		// This ensures persistence can write the state correctly and that the state file exists
@@ -233,7 +254,8 @@ func (s *Driver) eventLoop() {
 		// If we are sequencing, and the L1 state is ready, update the trigger for the next sequencer action.
 		// This may adjust at any time based on fork-choice changes or previous errors.
 		// And avoid sequencing if the derivation pipeline indicates the engine is not ready.
-		if s.driverConfig.SequencerEnabled && !s.driverConfig.SequencerStopped &&
+		// And avoid sequencing while halted after a deep L1 reorg.
+		if s.driverConfig.SequencerEnabled && !s.driverConfig.SequencerStopped && s.l1ReorgHalt == nil &&
 			s.l1State.L1Head() != (eth.L1BlockRef{}) && s.derivation.EngineReady() {
 			if s.driverConfig.SequencerMaxSafeLag > 0 && s.derivation.SafeL2Head().Number+s.driverConfig.SequencerMaxSafeLag <= s.derivation.UnsafeL2Head().Number {
 				// If the safe head has fallen behind by a significant number of blocks, delay creating new blocks
@@ -296,7 +318,17 @@ func (s *Driver) eventLoop() {
 			reqStep()
 
 		case newL1Head := <-s.l1HeadSig:
-			s.l1State.HandleNewL1HeadBlock(newL1Head)
+			reorg := s.l1State.HandleNewL1HeadBlock(ctx, newL1Head)
+			if reorg != nil && s.driverConfig.L1ReorgHaltDepth > 0 && reorg.Depth > s.driverConfig.L1ReorgHaltDepth && s.l1ReorgHalt == nil {
+				s.log.Error("Halting derivation and sequencing after deep L1 re-org, until acknowledged by an operator",
+					"depth", reorg.Depth, "max_depth", s.driverConfig.L1ReorgHaltDepth,
+					"old_l1_head", reorg.OldHead, "new_l1_head", reorg.NewHead, "common_ancestor", reorg.CommonAncestor)
+				s.l1ReorgHalt = reorg
+				s.metrics.RecordL1ReorgHalt(true)
+				if err := s.sequencerNotifs.L1ReorgHaltUpdated(reorg); err != nil {
+					s.log.Error("Failed to persist L1 re-org halt", "err", err)
+				}
+			}
 			reqStep() // a new L1 head may mean we have the data to not get an EOF again.
 		case newL1Safe := <-s.l1SafeSig:
 			s.l1State.HandleNewL1SafeBlock(newL1Safe)
@@ -309,6 +341,10 @@ func (s *Driver) eventLoop() {
 			delayedStepReq = nil
 			step()
 		case <-stepReqCh:
+			if s.l1ReorgHalt != nil {
+				s.log.Debug("Derivation is halted after deep L1 re-org", "depth", s.l1ReorgHalt.Depth)
+				continue
+			}
 			s.metrics.SetDerivationIdle(false)
 			s.log.Debug("Derivation process step", "onto_origin", s.derivation.Origin(), "attempts", stepAttempts)
 			err := s.derivation.Step(context.Background())
@@ -350,6 +386,16 @@ func (s *Driver) eventLoop() {
 			s.derivation.Reset()
 			s.metrics.RecordPipelineReset()
 			close(respCh)
+		case req := <-s.rollbackSafeHead:
+			ref, err := s.rollbackTo(ctx, req.number, req.force)
+			if err != nil {
+				req.resp <- refAndError{err: err}
+				continue
+			}
+			s.log.Warn("Rolled back L2 chain, derivation pipeline is reset", "target", ref, "force", req.force)
+			s.metrics.RecordPipelineReset()
+			req.resp <- refAndError{ref: ref}
+			reqStep() // re-derive from the new heads
 		case resp := <-s.startSequencer:
 			unsafeHead := s.derivation.UnsafeL2Head().Hash
 			if !s.driverConfig.SequencerStopped {
@@ -380,6 +426,33 @@ func (s *Driver) eventLoop() {
 			}
 		case respCh := <-s.sequencerActive:
 			respCh <- !s.driverConfig.SequencerStopped
+		case respCh := <-s.ackL1Reorg:
+			reorg := s.l1ReorgHalt
+			if reorg != nil {
+				if err := s.sequencerNotifs.L1ReorgHaltUpdated(nil); err != nil {
+					respCh <- reorgAndError{err: fmt.Errorf("l1 re-org halt notification: %w", err)}
+					continue
+				}
+				s.log.Warn("L1 re-org halt was acknowledged, resuming derivation and sequencing", "depth", reorg.Depth)
+				s.l1ReorgHalt = nil
+				s.metrics.RecordL1ReorgHalt(false)
+				reqStep()
+			}
+			respCh <- reorgAndError{reorg: reorg}
+		case req := <-s.sequencerPolicy:
+			if req.policy == nil {
+				req.resp <- policyAndError{policy: s.sequencer.Policy()}
+				continue
+			}
+			if err := s.sequencerNotifs.SequencerPolicyUpdated(*req.policy); err != nil {
+				req.resp <- policyAndError{err: fmt.Errorf("sequencer policy notification: %w", err)}
+				continue
+			}
+			s.sequencer.SetPolicy(*req.policy)
+			s.driverConfig.SequencerPolicy = *req.policy
+			s.log.Warn("Sequencer policy has been updated", "deposit_only", req.policy.DepositOnly,
+				"tx_pool_paused", req.policy.TxPoolPaused, "max_gas_per_block", req.policy.MaxGasPerBlock)
+			req.resp <- policyAndError{policy: *req.policy}
 		case <-s.done:
 			return
 		}
@@ -404,6 +477,76 @@ func (s *Driver) ResetDerivationPipeline(ctx context.Context) error {
 	}
 }
 
+// RollbackSafeHead rolls back the unsafe, safe and finalized L2 heads to the L2 block with the given number,
+// and resets the derivation pipeline to re-derive from there.
+// The pipeline reset starts from the L1 origin of the block minus the sequencing window.
+// Rolling back past the finalized L2 head is only allowed with force.
+// The sequencer must be stopped, to not race against the rollback.
+func (s *Driver) RollbackSafeHead(ctx context.Context, number uint64, force bool) (eth.L2BlockRef, error) {
+	req := rollbackRequest{
+		number: number,
+		force:  force,
+		resp:   make(chan refAndError, 1),
+	}
+	select {
+	case <-ctx.Done():
+		return eth.L2BlockRef{}, ctx.Err()
+	case s.rollbackSafeHead <- req:
+		select {
+		case <-ctx.Done():
+			return eth.L2BlockRef{}, ctx.Err()
+		case re := <-req.resp:
+			return re.ref, re.err
+		}
+	}
+}
+
+// rollbackTo updates the forkchoice state of the engine to the given L2 block,
+// and resets the derivation pipeline to the block as unsafe and safe head.
+// It should only be called synchronously with the driver event loop.
+func (s *Driver) rollbackTo(ctx context.Context, number uint64, force bool) (eth.L2BlockRef, error) {
+	if s.driverConfig.SequencerEnabled && !s.driverConfig.SequencerStopped {
+		return eth.L2BlockRef{}, errors.New("sequencer must be stopped before rolling back")
+	}
+	if number < s.config.Genesis.L2.Number {
+		return eth.L2BlockRef{}, fmt.Errorf("cannot roll back to block %d before genesis %s", number, s.config.Genesis.L2)
+	}
+	unsafe := s.derivation.UnsafeL2Head()
+	if number > unsafe.Number {
+		return eth.L2BlockRef{}, fmt.Errorf("cannot roll back to block %d after unsafe head %s", number, unsafe)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+	target, err := s.l2.L2BlockRefByNumber(ctx, number)
+	if err != nil {
+		return eth.L2BlockRef{}, fmt.Errorf("failed to fetch L2 block %d to roll back to: %w", number, err)
+	}
+	finalized := s.derivation.Finalized()
+	if target.Number < finalized.Number {
+		if !force {
+			return eth.L2BlockRef{}, fmt.Errorf("cannot roll back to block %s before finalized block %s without force", target, finalized)
+		}
+		s.log.Warn("Rolling back past finalized L2 block", "target", target, "finalized", finalized)
+		finalized = target
+	}
+
+	fc := eth.ForkchoiceState{
+		HeadBlockHash:      target.Hash,
+		SafeBlockHash:      target.Hash,
+		FinalizedBlockHash: finalized.Hash,
+	}
+	res, err := s.l2.ForkchoiceUpdate(ctx, &fc, nil)
+	if err != nil {
+		return eth.L2BlockRef{}, fmt.Errorf("failed to update forkchoice to roll back to %s: %w", target, err)
+	}
+	if res.PayloadStatus.Status != eth.ExecutionValid {
+		return eth.L2BlockRef{}, fmt.Errorf("engine rejected forkchoice update to roll back to %s: %w", target, eth.ForkchoiceUpdateErr(res.PayloadStatus))
+	}
+	s.derivation.ResetTo(target, target, finalized)
+	return target, nil
+}
+
 func (s *Driver) StartSequencer(ctx context.Context, blockHash common.Hash) error {
 	if !s.driverConfig.SequencerEnabled {
 		return errors.New("sequencer is not enabled")
@@ -461,6 +604,74 @@ func (s *Driver) SequencerActive(ctx context.Context) (bool, error) {
 	}
 }
 
+// SequencerPolicy returns the current operator policy of the sequencer.
+func (s *Driver) SequencerPolicy(ctx context.Context) (SequencerPolicy, error) {
+	return s.sequencerPolicyRequest(ctx, nil)
+}
+
+// SetSequencerPolicy replaces the operator policy of the sequencer, and persists it.
+// The policy applies from the next block building job onwards.
+func (s *Driver) SetSequencerPolicy(ctx context.Context, policy SequencerPolicy) (SequencerPolicy, error) {
+	return s.sequencerPolicyRequest(ctx, &policy)
+}
+
+func (s *Driver) sequencerPolicyRequest(ctx context.Context, policy *SequencerPolicy) (SequencerPolicy, error) {
+	if !s.driverConfig.SequencerEnabled {
+		return SequencerPolicy{}, errors.New("sequencer is not enabled")
+	}
+	req := policyRequest{
+		policy: policy,
+		resp:   make(chan policyAndError, 1),
+	}
+	select {
+	case <-ctx.Done():
+		return SequencerPolicy{}, ctx.Err()
+	case s.sequencerPolicy <- req:
+		select {
+		case <-ctx.Done():
+			return SequencerPolicy{}, ctx.Err()
+		case pe := <-req.resp:
+			return pe.policy, pe.err
+		}
+	}
+}
+
+// L1ReorgHalt returns the L1 reorg that halted derivation and sequencing, or nil if not halted.
+func (s *Driver) L1ReorgHalt(ctx context.Context) (*L1Reorg, error) {
+	wait := make(chan struct{})
+	select {
+	case s.stateReq <- wait:
+		reorg := s.l1ReorgHalt
+		<-wait
+		return reorg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// AcknowledgeL1Reorg lifts the halt of derivation and sequencing after a deep L1 reorg,
+// and returns the L1 reorg that caused the halt.
+func (s *Driver) AcknowledgeL1Reorg(ctx context.Context) (*L1Reorg, error) {
+	respCh := make(chan reorgAndError, 1)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case s.ackL1Reorg <- respCh:
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case re := <-respCh:
+			if re.err != nil {
+				return nil, re.err
+			}
+			if re.reorg == nil {
+				return nil, errors.New("derivation is not halted by an L1 re-org")
+			}
+			return re.reorg, nil
+		}
+	}
+}
+
 // syncStatus returns the current sync status, and should only be called synchronously with
 // the driver event loop to avoid retrieval of an inconsistent status.
 func (s *Driver) syncStatus() *eth.SyncStatus {
@@ -532,6 +743,33 @@ type hashAndError struct {
 	err  error
 }
 
+type refAndError struct {
+	ref eth.L2BlockRef
+	err error
+}
+
+type rollbackRequest struct {
+	number uint64
+	force  bool
+	resp   chan refAndError
+}
+
+type policyAndError struct {
+	policy SequencerPolicy
+	err    error
+}
+
+type reorgAndError struct {
+	reorg *L1Reorg
+	err   error
+}
+
+// policyRequest queries the sequencer policy if policy is nil, or updates it otherwise.
+type policyRequest struct {
+	policy *SequencerPolicy
+	resp   chan policyAndError
+}
+
 type hashAndErrorChannel struct {
 	hash common.Hash
 	err  chan error
@@ -0,0 +1,158 @@
+package driver
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+)
+
+// fakeDerivationPipeline reports fixed L2 heads, and records the resets of the pipeline.
+type fakeDerivationPipeline struct {
+	DerivationPipeline
+
+	unsafe    eth.L2BlockRef
+	finalized eth.L2BlockRef
+
+	resets   int
+	resetTos []eth.L2BlockRef // unsafe, safe and finalized head of every ResetTo call
+}
+
+func (f *fakeDerivationPipeline) Reset() {
+	f.resets++
+}
+
+func (f *fakeDerivationPipeline) ResetTo(unsafe, safe, finalized eth.L2BlockRef) {
+	f.resetTos = append(f.resetTos, unsafe, safe, finalized)
+}
+
+func (f *fakeDerivationPipeline) UnsafeL2Head() eth.L2BlockRef {
+	return f.unsafe
+}
+
+func (f *fakeDerivationPipeline) Finalized() eth.L2BlockRef {
+	return f.finalized
+}
+
+func TestRollbackTo(t *testing.T) {
+	rng := rand.New(rand.NewSource(1234))
+	genesis := testutils.RandomL2BlockRef(rng)
+	genesis.Number = 10
+	refAt := func(num uint64) eth.L2BlockRef {
+		ref := testutils.RandomL2BlockRef(rng)
+		ref.Number = num
+		return ref
+	}
+	unsafe := refAt(100)
+	finalized := refAt(50)
+
+	setup := func(sequencerEnabled, sequencerStopped bool) (*Driver, *fakeDerivationPipeline, *testutils.MockEngine) {
+		pipeline := &fakeDerivationPipeline{unsafe: unsafe, finalized: finalized}
+		engine := &testutils.MockEngine{}
+		d := &Driver{
+			derivation:   pipeline,
+			l2:           engine,
+			config:       &rollup.Config{Genesis: rollup.Genesis{L2: genesis.ID()}},
+			driverConfig: &Config{SequencerEnabled: sequencerEnabled, SequencerStopped: sequencerStopped},
+			log:          testlog.Logger(t, log.LvlError),
+		}
+		return d, pipeline, engine
+	}
+	expectRollback := func(engine *testutils.MockEngine, target, finalized eth.L2BlockRef) {
+		engine.ExpectL2BlockRefByNumber(target.Number, target, nil)
+		engine.ExpectForkchoiceUpdate(&eth.ForkchoiceState{
+			HeadBlockHash:      target.Hash,
+			SafeBlockHash:      target.Hash,
+			FinalizedBlockHash: finalized.Hash,
+		}, nil, &eth.ForkchoiceUpdatedResult{PayloadStatus: eth.PayloadStatusV1{Status: eth.ExecutionValid}}, nil)
+	}
+
+	t.Run("RollbackAfterFinalized", func(t *testing.T) {
+		d, pipeline, engine := setup(false, false)
+		target := refAt(80)
+		expectRollback(engine, target, finalized)
+		ref, err := d.rollbackTo(context.Background(), target.Number, false)
+		require.NoError(t, err)
+		require.Equal(t, target, ref)
+		require.Equal(t, []eth.L2BlockRef{target, target, finalized}, pipeline.resetTos)
+		require.Zero(t, pipeline.resets, "pipeline is reset to the target, not to the engine heads")
+		engine.AssertExpectations(t)
+	})
+
+	t.Run("StoppedSequencer", func(t *testing.T) {
+		d, pipeline, engine := setup(true, true)
+		target := refAt(80)
+		expectRollback(engine, target, finalized)
+		_, err := d.rollbackTo(context.Background(), target.Number, false)
+		require.NoError(t, err)
+		require.Len(t, pipeline.resetTos, 3)
+		engine.AssertExpectations(t)
+	})
+
+	t.Run("RunningSequencer", func(t *testing.T) {
+		d, pipeline, engine := setup(true, false)
+		_, err := d.rollbackTo(context.Background(), 80, false)
+		require.ErrorContains(t, err, "sequencer must be stopped")
+		require.Empty(t, pipeline.resetTos)
+		engine.AssertExpectations(t)
+	})
+
+	t.Run("BeforeFinalizedWithoutForce", func(t *testing.T) {
+		d, pipeline, engine := setup(false, false)
+		target := refAt(40)
+		engine.ExpectL2BlockRefByNumber(target.Number, target, nil)
+		_, err := d.rollbackTo(context.Background(), target.Number, false)
+		require.ErrorContains(t, err, "before finalized block")
+		require.Empty(t, pipeline.resetTos)
+		engine.AssertExpectations(t)
+	})
+
+	t.Run("BeforeFinalizedWithForce", func(t *testing.T) {
+		d, pipeline, engine := setup(false, false)
+		target := refAt(40)
+		expectRollback(engine, target, target)
+		ref, err := d.rollbackTo(context.Background(), target.Number, true)
+		require.NoError(t, err)
+		require.Equal(t, target, ref)
+		require.Equal(t, []eth.L2BlockRef{target, target, target}, pipeline.resetTos)
+		engine.AssertExpectations(t)
+	})
+
+	t.Run("BeforeGenesis", func(t *testing.T) {
+		d, pipeline, engine := setup(false, false)
+		_, err := d.rollbackTo(context.Background(), genesis.Number-1, true)
+		require.ErrorContains(t, err, "before genesis")
+		require.Empty(t, pipeline.resetTos)
+		engine.AssertExpectations(t)
+	})
+
+	t.Run("AfterUnsafeHead", func(t *testing.T) {
+		d, pipeline, engine := setup(false, false)
+		_, err := d.rollbackTo(context.Background(), unsafe.Number+1, false)
+		require.ErrorContains(t, err, "after unsafe head")
+		require.Empty(t, pipeline.resetTos)
+		engine.AssertExpectations(t)
+	})
+
+	t.Run("EngineRejectsForkchoice", func(t *testing.T) {
+		d, pipeline, engine := setup(false, false)
+		target := refAt(80)
+		engine.ExpectL2BlockRefByNumber(target.Number, target, nil)
+		engine.ExpectForkchoiceUpdate(&eth.ForkchoiceState{
+			HeadBlockHash:      target.Hash,
+			SafeBlockHash:      target.Hash,
+			FinalizedBlockHash: finalized.Hash,
+		}, nil, &eth.ForkchoiceUpdatedResult{PayloadStatus: eth.PayloadStatusV1{Status: eth.ExecutionInvalid}}, nil)
+		_, err := d.rollbackTo(context.Background(), target.Number, false)
+		require.ErrorContains(t, err, "engine rejected")
+		require.Empty(t, pipeline.resetTos)
+		engine.AssertExpectations(t)
+	})
+}
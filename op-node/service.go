@@ -16,9 +16,13 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/ethereum-optimism/optimism/op-node/flags"
+	"github.com/ethereum-optimism/optimism/op-node/leader"
 	"github.com/ethereum-optimism/optimism/op-node/node"
+	"github.com/ethereum-optimism/optimism/op-node/node/safedb"
+	"github.com/ethereum-optimism/optimism/op-node/p2p"
 	p2pcli "github.com/ethereum-optimism/optimism/op-node/p2p/cli"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
@@ -39,7 +43,7 @@ func NewConfig(ctx *cli.Context, log log.Logger) (*node.Config, error) {
 
 	driverConfig := NewDriverConfig(ctx)
 
-	p2pSignerSetup, err := p2pcli.LoadSignerSetup(ctx)
+	p2pSignerSetup, err := p2pcli.LoadSignerSetup(ctx, log)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load p2p signer: %w", err)
 	}
@@ -69,6 +73,11 @@ func NewConfig(ctx *cli.Context, log log.Logger) (*node.Config, error) {
 		return nil, fmt.Errorf("failed to load da config: %w", err)
 	}
 
+	attesters, err := loadSafeHeadAttesters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &node.Config{
 		L1:       l1Endpoint,
 		L2:       l2Endpoint,
@@ -80,6 +89,9 @@ func NewConfig(ctx *cli.Context, log log.Logger) (*node.Config, error) {
 			ListenAddr:  ctx.String(flags.RPCListenAddr.Name),
 			ListenPort:  ctx.Int(flags.RPCListenPort.Name),
 			EnableAdmin: ctx.Bool(flags.RPCEnableAdmin.Name),
+			EnableDebug: ctx.Bool(flags.RPCEnableDebug.Name),
+
+			EnableBlockFeed: ctx.Bool(flags.RPCEnableBlockFeed.Name),
 		},
 		Metrics: node.MetricsConfig{
 			Enabled:    ctx.Bool(flags.MetricsEnabledFlag.Name),
@@ -99,7 +111,25 @@ func NewConfig(ctx *cli.Context, log log.Logger) (*node.Config, error) {
 			Moniker: ctx.String(flags.HeartbeatMonikerFlag.Name),
 			URL:     ctx.String(flags.HeartbeatURLFlag.Name),
 		},
-		ConfigPersistence: configPersistence,
+		ConfigPersistence:          configPersistence,
+		SequencerMaxGasPerBlockSet: ctx.IsSet(flags.SequencerMaxGasPerBlockFlag.Name),
+		SequencerLeader: &leader.FileLockConfig{
+			Path:          ctx.String(flags.SequencerLeaderLockFlag.Name),
+			RetryInterval: ctx.Duration(flags.SequencerLeaderRetryIntervalFlag.Name),
+		},
+		PipelineTraceFile: ctx.String(flags.PipelineTraceFile.Name),
+		SafeDB: safedb.Config{
+			Path:      ctx.String(flags.SafeDBPath.Name),
+			Retention: ctx.Uint64(flags.SafeDBRetention.Name),
+		},
+		BlockFeed: p2p.BlockFeedConfig{
+			URL:          ctx.String(flags.UnsafeBlockFeedURL.Name),
+			PollInterval: ctx.Duration(flags.UnsafeBlockFeedPollInterval.Name),
+		},
+		SafeHeadAttestations: node.SafeHeadAttestationsConfig{
+			Attesters: attesters,
+			Path:      ctx.String(flags.SafeHeadAttestationsPathFlag.Name),
+		},
 	}
 
 	if err := cfg.LoadPersisted(log); err != nil {
@@ -119,6 +149,7 @@ func NewL1EndpointConfig(ctx *cli.Context) *node.L1EndpointConfig {
 		L1RPCKind:        sources.RPCProviderKind(strings.ToLower(ctx.String(flags.L1RPCProviderKind.Name))),
 		RateLimit:        ctx.Float64(flags.L1RPCRateLimit.Name),
 		BatchSize:        ctx.Int(flags.L1RPCMaxBatchSize.Name),
+		PrefetchWindow:   ctx.Int(flags.L1PrefetchWindow.Name),
 		HttpPollInterval: ctx.Duration(flags.L1HTTPPollInterval.Name),
 	}
 }
@@ -177,6 +208,10 @@ func NewDriverConfig(ctx *cli.Context) *driver.Config {
 		SequencerEnabled:    ctx.Bool(flags.SequencerEnabledFlag.Name),
 		SequencerStopped:    ctx.Bool(flags.SequencerStoppedFlag.Name),
 		SequencerMaxSafeLag: ctx.Uint64(flags.SequencerMaxSafeLagFlag.Name),
+		SequencerPolicy: driver.SequencerPolicy{
+			MaxGasPerBlock: ctx.Uint64(flags.SequencerMaxGasPerBlockFlag.Name),
+		},
+		L1ReorgHaltDepth: ctx.Uint64(flags.L1ReorgHaltDepthFlag.Name),
 	}
 }
 
@@ -220,3 +255,19 @@ func NewSnapshotLogger(ctx *cli.Context) (log.Logger, error) {
 	logger.SetHandler(handler)
 	return logger, nil
 }
+
+func loadSafeHeadAttesters(ctx *cli.Context) ([]peer.ID, error) {
+	var attesters []peer.ID
+	for _, idStr := range strings.Split(ctx.String(flags.SafeHeadAttestersFlag.Name), ",") {
+		idStr = strings.TrimSpace(idStr)
+		if idStr == "" {
+			continue
+		}
+		id, err := peer.Decode(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid safe head attester peer ID %q: %w", idStr, err)
+		}
+		attesters = append(attesters, id)
+	}
+	return attesters, nil
+}
@@ -41,3 +41,7 @@ func NewLRUCache(m Metrics, label string, maxSize int) *LRUCache {
 		inner: cache,
 	}
 }
+
+func (c *LRUCache) Remove(key any) (present bool) {
+	return c.inner.Remove(key)
+}
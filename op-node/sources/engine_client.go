@@ -2,6 +2,7 @@ package sources
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,31 +16,68 @@ import (
 
 type EngineClientConfig struct {
 	L2ClientConfig
+
+	// PayloadStatusCacheSize is the number of block hashes to remember the engine validated,
+	// to skip redundant engine_newPayload calls with.
+	PayloadStatusCacheSize int
 }
 
 func EngineClientDefaultConfig(config *rollup.Config) *EngineClientConfig {
+	// engine is trusted, no need to recompute responses etc.
+	l2Cfg := L2ClientDefaultConfig(config, true)
 	return &EngineClientConfig{
-		// engine is trusted, no need to recompute responses etc.
-		L2ClientConfig: *L2ClientDefaultConfig(config, true),
+		L2ClientConfig:         *l2Cfg,
+		PayloadStatusCacheSize: l2Cfg.PayloadsCacheSize,
 	}
 }
 
+type EngineMetrics interface {
+	RecordEngineRequest(method string) func(outcome string)
+}
+
 // EngineClient extends L2Client with engine API bindings.
 type EngineClient struct {
 	*L2Client
+
+	m EngineMetrics
+
+	// cache the status of payloads that the engine validated, by block hash
+	// common.Hash -> eth.PayloadStatusV1
+	payloadStatusCache *caching.LRUCache
 }
 
-func NewEngineClient(client client.RPC, log log.Logger, metrics caching.Metrics, config *EngineClientConfig) (*EngineClient, error) {
+// NewEngineClient creates an engine client. The engine metrics are optional, and may be nil.
+func NewEngineClient(client client.RPC, log log.Logger, metrics caching.Metrics, engineMetrics EngineMetrics, config *EngineClientConfig) (*EngineClient, error) {
 	l2Client, err := NewL2Client(client, log, metrics, &config.L2ClientConfig)
 	if err != nil {
 		return nil, err
 	}
+	if engineMetrics == nil {
+		engineMetrics = noopEngineMetrics{}
+	}
 
 	return &EngineClient{
-		L2Client: l2Client,
+		L2Client:           l2Client,
+		m:                  engineMetrics,
+		payloadStatusCache: caching.NewLRUCache(metrics, "payloadstatus", config.PayloadStatusCacheSize),
 	}, nil
 }
 
+type noopEngineMetrics struct{}
+
+func (noopEngineMetrics) RecordEngineRequest(method string) func(outcome string) {
+	return func(outcome string) {}
+}
+
+// engineErrorOutcome labels the outcome of a failed engine API request for metrics.
+func engineErrorOutcome(err error) string {
+	var inputErr eth.InputError
+	if errors.As(err, &inputErr) {
+		return "input_error"
+	}
+	return "error"
+}
+
 // ForkchoiceUpdate updates the forkchoice on the execution client. If attributes is not nil, the engine client will also begin building a block
 // based on attributes after the new head block and return the payload ID.
 //
@@ -52,8 +90,26 @@ func (s *EngineClient) ForkchoiceUpdate(ctx context.Context, fc *eth.ForkchoiceS
 	e.Trace("Sharing forkchoice-updated signal")
 	fcCtx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
+	record := s.m.RecordEngineRequest("engine_forkchoiceUpdatedV1")
+	result, err := s.forkchoiceUpdate(fcCtx, e, fc, attributes)
+	if err != nil {
+		record(engineErrorOutcome(err))
+		// the engine may have lost the head block, do not skip its execution if it is sent again
+		s.payloadStatusCache.Remove(fc.HeadBlockHash)
+		return nil, err
+	}
+	record(string(result.PayloadStatus.Status))
+	if result.PayloadStatus.Status == eth.ExecutionValid {
+		s.payloadStatusCache.Add(fc.HeadBlockHash, result.PayloadStatus)
+	} else {
+		s.payloadStatusCache.Remove(fc.HeadBlockHash)
+	}
+	return result, nil
+}
+
+func (s *EngineClient) forkchoiceUpdate(ctx context.Context, e log.Logger, fc *eth.ForkchoiceState, attributes *eth.PayloadAttributes) (*eth.ForkchoiceUpdatedResult, error) {
 	var result eth.ForkchoiceUpdatedResult
-	err := s.client.CallContext(fcCtx, &result, "engine_forkchoiceUpdatedV1", fc, attributes)
+	err := s.client.CallContext(ctx, &result, "engine_forkchoiceUpdatedV1", fc, attributes)
 	if err == nil {
 		e.Trace("Shared forkchoice-updated signal")
 		if attributes != nil { // block building is optional, we only get a payload ID if we are building a block
@@ -81,19 +137,36 @@ func (s *EngineClient) ForkchoiceUpdate(ctx context.Context, fc *eth.ForkchoiceS
 // NewPayload executes a full block on the execution engine.
 // This returns a PayloadStatusV1 which encodes any validation/processing error,
 // and this type of error is kept separate from the returned `error` used for RPC errors, like timeouts.
+//
+// The same payload may be received from gossip, alt-sync and derivation:
+// payloads that the engine already validated are not executed again.
 func (s *EngineClient) NewPayload(ctx context.Context, payload *eth.ExecutionPayload) (*eth.PayloadStatusV1, error) {
 	e := s.log.New("block_hash", payload.BlockHash)
+	if status, ok := s.payloadStatusCache.Get(payload.BlockHash); ok {
+		// the block hash commits to the full payload, but only if the payload matches it
+		if _, match := payload.CheckBlockHash(); match {
+			e.Trace("Skipping execution of payload that the engine validated before")
+			result := status.(eth.PayloadStatusV1)
+			return &result, nil
+		}
+	}
 	e.Trace("sending payload for execution")
 
 	execCtx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
+	record := s.m.RecordEngineRequest("engine_newPayloadV1")
 	var result eth.PayloadStatusV1
 	err := s.client.CallContext(execCtx, &result, "engine_newPayloadV1", payload)
 	e.Trace("Received payload execution result", "status", result.Status, "latestValidHash", result.LatestValidHash, "message", result.ValidationError)
 	if err != nil {
+		record(engineErrorOutcome(err))
 		e.Error("Payload execution failed", "err", err)
 		return nil, fmt.Errorf("failed to execute payload: %w", err)
 	}
+	record(string(result.Status))
+	if result.Status == eth.ExecutionValid {
+		s.payloadStatusCache.Add(payload.BlockHash, result)
+	}
 	return &result, nil
 }
 
@@ -104,6 +177,17 @@ func (s *EngineClient) NewPayload(ctx context.Context, payload *eth.ExecutionPay
 func (s *EngineClient) GetPayload(ctx context.Context, payloadId eth.PayloadID) (*eth.ExecutionPayload, error) {
 	e := s.log.New("payload_id", payloadId)
 	e.Trace("getting payload")
+	record := s.m.RecordEngineRequest("engine_getPayloadV1")
+	result, err := s.getPayload(ctx, e, payloadId)
+	if err != nil {
+		record(engineErrorOutcome(err))
+		return nil, err
+	}
+	record("ok")
+	return result, nil
+}
+
+func (s *EngineClient) getPayload(ctx context.Context, e log.Logger, payloadId eth.PayloadID) (*eth.ExecutionPayload, error) {
 	var result eth.ExecutionPayload
 	err := s.client.CallContext(ctx, &result, "engine_getPayloadV1", payloadId)
 	if err != nil {
@@ -0,0 +1,88 @@
+package sources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+type engineRequest struct {
+	method  string
+	outcome string
+}
+
+type recordingEngineMetrics struct {
+	requests []engineRequest
+}
+
+func (m *recordingEngineMetrics) RecordEngineRequest(method string) func(outcome string) {
+	return func(outcome string) {
+		m.requests = append(m.requests, engineRequest{method, outcome})
+	}
+}
+
+func TestEngineClient_NewPayloadStatusCache(t *testing.T) {
+	m := new(mockRPC)
+	metrics := new(recordingEngineMetrics)
+	ctx := context.Background()
+	s, err := NewEngineClient(m, testlog.Logger(t, log.LvlError), nil, metrics, EngineClientDefaultConfig(&rollup.Config{SeqWindowSize: 10, BlockTime: 2}))
+	require.NoError(t, err)
+
+	payload := &eth.ExecutionPayload{BlockNumber: 1, Timestamp: 2}
+	payload.BlockHash, _ = payload.CheckBlockHash()
+	respond := func(status eth.ExecutePayloadStatus) func(args mock.Arguments) {
+		return func(args mock.Arguments) {
+			*args[1].(*eth.PayloadStatusV1) = eth.PayloadStatusV1{Status: status}
+		}
+	}
+	m.On("CallContext", mock.Anything, new(eth.PayloadStatusV1), "engine_newPayloadV1", []any{payload}).
+		Run(respond(eth.ExecutionValid)).Return([]error{nil})
+
+	status, err := s.NewPayload(ctx, payload)
+	require.NoError(t, err)
+	require.Equal(t, eth.ExecutionValid, status.Status)
+	m.AssertNumberOfCalls(t, "CallContext", 1)
+
+	// the engine already validated the payload, it is not executed again
+	status, err = s.NewPayload(ctx, payload)
+	require.NoError(t, err)
+	require.Equal(t, eth.ExecutionValid, status.Status)
+	m.AssertNumberOfCalls(t, "CallContext", 1)
+
+	// a different payload that claims the same block hash is executed
+	tampered := *payload
+	tampered.GasUsed = 1
+	m.On("CallContext", mock.Anything, new(eth.PayloadStatusV1), "engine_newPayloadV1", []any{&tampered}).
+		Run(respond(eth.ExecutionInvalidBlockHash)).Return([]error{nil})
+	status, err = s.NewPayload(ctx, &tampered)
+	require.NoError(t, err)
+	require.Equal(t, eth.ExecutionInvalidBlockHash, status.Status)
+	m.AssertNumberOfCalls(t, "CallContext", 2)
+
+	// if the engine does not consider the block valid anymore, e.g. after losing it, the payload is executed again
+	fc := &eth.ForkchoiceState{HeadBlockHash: payload.BlockHash, SafeBlockHash: common.Hash{}, FinalizedBlockHash: common.Hash{}}
+	m.On("CallContext", mock.Anything, new(eth.ForkchoiceUpdatedResult), "engine_forkchoiceUpdatedV1", []any{fc, (*eth.PayloadAttributes)(nil)}).
+		Run(func(args mock.Arguments) {
+			*args[1].(*eth.ForkchoiceUpdatedResult) = eth.ForkchoiceUpdatedResult{PayloadStatus: eth.PayloadStatusV1{Status: eth.ExecutionSyncing}}
+		}).Return([]error{nil})
+	_, err = s.ForkchoiceUpdate(ctx, fc, nil)
+	require.NoError(t, err)
+	_, err = s.NewPayload(ctx, payload)
+	require.NoError(t, err)
+	m.AssertNumberOfCalls(t, "CallContext", 4)
+
+	require.Equal(t, []engineRequest{
+		{"engine_newPayloadV1", "VALID"},
+		{"engine_newPayloadV1", "INVALID_BLOCK_HASH"},
+		{"engine_forkchoiceUpdatedV1", "SYNCING"},
+		{"engine_newPayloadV1", "VALID"},
+	}, metrics.requests)
+}
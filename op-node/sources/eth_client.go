@@ -349,18 +349,7 @@ func (s *EthClient) FetchReceipts(ctx context.Context, blockHash common.Hash) (e
 	if err != nil {
 		return nil, nil, err
 	}
-	// Try to reuse the receipts fetcher because is caches the results of intermediate calls. This means
-	// that if just one of many calls fail, we only retry the failed call rather than all of the calls.
-	// The underlying fetcher uses the receipts hash to verify receipt integrity.
-	var job *receiptsFetchingJob
-	if v, ok := s.receiptsCache.Get(blockHash); ok {
-		job = v.(*receiptsFetchingJob)
-	} else {
-		txHashes := eth.TransactionsToHashes(txs)
-		job = NewReceiptsFetchingJob(s, s.client, s.maxBatchSize, eth.ToBlockID(info), info.ReceiptHash(), txHashes)
-		s.receiptsCache.Add(blockHash, job)
-	}
-	receipts, err := job.Fetch(ctx)
+	receipts, err := s.receiptsJob(info, txs).Fetch(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -368,6 +357,20 @@ func (s *EthClient) FetchReceipts(ctx context.Context, blockHash common.Hash) (e
 	return info, receipts, nil
 }
 
+// receiptsJob returns the cached receipts fetching job of the block, or creates and caches a new one.
+func (s *EthClient) receiptsJob(info eth.BlockInfo, txs types.Transactions) *receiptsFetchingJob {
+	// Try to reuse the receipts fetcher because is caches the results of intermediate calls. This means
+	// that if just one of many calls fail, we only retry the failed call rather than all of the calls.
+	// The underlying fetcher uses the receipts hash to verify receipt integrity.
+	if v, ok := s.receiptsCache.Get(info.Hash()); ok {
+		return v.(*receiptsFetchingJob)
+	}
+	txHashes := eth.TransactionsToHashes(txs)
+	job := NewReceiptsFetchingJob(s, s.client, s.maxBatchSize, eth.ToBlockID(info), info.ReceiptHash(), txHashes)
+	s.receiptsCache.Add(info.Hash(), job)
+	return job
+}
+
 // GetProof returns an account proof result, with any optional requested storage proofs.
 // The retrieval does sanity-check that storage proofs for the expected keys are present in the response,
 // but does not verify the result. Call accountResult.Verify(stateRoot) to verify the result.
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/ethereum-optimism/optimism/op-node/client"
@@ -20,6 +21,10 @@ type L1ClientConfig struct {
 	EthClientConfig
 
 	L1BlockRefsCacheSize int
+
+	// PrefetchWindow is the number of L1 blocks to prefetch the headers, transactions and receipts of,
+	// ahead of the last L1 block that was requested by number. Disabled if 0.
+	PrefetchWindow int
 }
 
 func L1ClientDefaultConfig(config *rollup.Config, trustRPC bool, kind RPCProviderKind) *L1ClientConfig {
@@ -57,18 +62,38 @@ type L1Client struct {
 	// cache L1BlockRef by hash
 	// common.Hash -> eth.L1BlockRef
 	l1BlockRefsCache *caching.LRUCache
+
+	// prefetcher is nil if prefetching is disabled
+	prefetcher *l1Prefetcher
 }
 
 // NewL1Client wraps a RPC with bindings to fetch L1 data, while logging errors, tracking metrics (optional), and caching.
 func NewL1Client(client client.RPC, log log.Logger, metrics caching.Metrics, config *L1ClientConfig) (*L1Client, error) {
+	if config.PrefetchWindow < 0 {
+		return nil, fmt.Errorf("invalid prefetch window: %d", config.PrefetchWindow)
+	}
+	if config.PrefetchWindow > config.ReceiptsCacheSize || config.PrefetchWindow > config.TransactionsCacheSize {
+		return nil, fmt.Errorf("prefetch window %d exceeds the receipts or transactions cache size", config.PrefetchWindow)
+	}
 	ethClient, err := NewEthClient(client, log, metrics, &config.EthClientConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	var prefetcher *l1Prefetcher
+	if config.PrefetchWindow > 0 {
+		// leave half of the concurrent requests to the regular (non-prefetch) requests
+		concurrency := config.MaxConcurrentRequests / 2
+		if concurrency < 1 {
+			concurrency = 1
+		}
+		prefetcher = newL1Prefetcher(log, ethClient, metrics, config.PrefetchWindow, concurrency)
+	}
+
 	return &L1Client{
 		EthClient:        ethClient,
 		l1BlockRefsCache: caching.NewLRUCache(metrics, "blockrefs", config.L1BlockRefsCacheSize),
+		prefetcher:       prefetcher,
 	}, nil
 }
 
@@ -98,6 +123,10 @@ func (s *L1Client) L1BlockRefByNumber(ctx context.Context, num uint64) (eth.L1Bl
 	}
 	ref := eth.InfoToL1BlockRef(info)
 	s.l1BlockRefsCache.Add(ref.Hash, ref)
+	// The derivation pipeline traverses L1 by number, prefetch the blocks it will need next.
+	if s.prefetcher != nil {
+		s.prefetcher.Schedule(num)
+	}
 	return ref, nil
 }
 
@@ -115,3 +144,26 @@ func (s *L1Client) L1BlockRefByHash(ctx context.Context, hash common.Hash) (eth.
 	s.l1BlockRefsCache.Add(ref.Hash, ref)
 	return ref, nil
 }
+
+// InfoAndTxsByHash retrieves the block header and transactions, which may already be prefetched.
+func (s *L1Client) InfoAndTxsByHash(ctx context.Context, hash common.Hash) (eth.BlockInfo, types.Transactions, error) {
+	if s.prefetcher != nil {
+		s.prefetcher.Hit(hash, false)
+	}
+	return s.EthClient.InfoAndTxsByHash(ctx, hash)
+}
+
+// FetchReceipts retrieves the block header and receipts, which may already be prefetched.
+func (s *L1Client) FetchReceipts(ctx context.Context, blockHash common.Hash) (eth.BlockInfo, types.Receipts, error) {
+	if s.prefetcher != nil {
+		s.prefetcher.Hit(blockHash, true)
+	}
+	return s.EthClient.FetchReceipts(ctx, blockHash)
+}
+
+func (s *L1Client) Close() {
+	if s.prefetcher != nil {
+		s.prefetcher.Close()
+	}
+	s.EthClient.Close()
+}
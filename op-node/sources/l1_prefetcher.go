@@ -0,0 +1,193 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/sources/caching"
+)
+
+// l1PrefetchTimeout is the timeout for prefetching a single batch of blocks, including their receipts.
+const l1PrefetchTimeout = time.Minute
+
+// l1Prefetcher fetches the headers, transactions and receipts of upcoming L1 blocks ahead of time,
+// into the caches of the EthClient, to not be bound by the RPC latency when the derivation pipeline catches up.
+//
+// Blocks are prefetched by number, in batches of at most the max batch size of the client.
+// Receipts are fetched with the receipts fetching method of the RPC provider kind.
+// The results are cached by block hash, so prefetched blocks that are reorged out are never served.
+type l1Prefetcher struct {
+	log    log.Logger
+	client *EthClient
+
+	// window is the number of blocks to prefetch ahead of the last requested block
+	window uint64
+	// concurrency is the number of blocks to fetch receipts for concurrently
+	concurrency int
+
+	// prefetchedTxs and prefetchedReceipts track the prefetched blocks, to meter prefetch hits.
+	// common.Hash -> struct{}
+	prefetchedTxs      *caching.LRUCache
+	prefetchedReceipts *caching.LRUCache
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running bool
+	// next is the next block number to prefetch
+	next uint64
+	// target is the last block number to prefetch
+	target uint64
+}
+
+func newL1Prefetcher(log log.Logger, client *EthClient, metrics caching.Metrics, window int, concurrency int) *l1Prefetcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &l1Prefetcher{
+		log:                log,
+		client:             client,
+		window:             uint64(window),
+		concurrency:        concurrency,
+		prefetchedTxs:      caching.NewLRUCache(metrics, "prefetch_txs", window*2),
+		prefetchedReceipts: caching.NewLRUCache(metrics, "prefetch_receipts", window*2),
+		ctx:                ctx,
+		cancel:             cancel,
+	}
+}
+
+// Schedule prefetches the blocks following the given block number, up to the prefetch window.
+// Blocks that were already prefetched are not fetched again, unless the requested block number moved back.
+func (p *l1Prefetcher) Schedule(num uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Start over if we moved past the prefetched range, or moved back before it, e.g. after a pipeline reset.
+	if num >= p.next || p.next > num+1+p.window {
+		p.next = num + 1
+	}
+	p.target = num + p.window
+	if p.running || p.ctx.Err() != nil {
+		return
+	}
+	p.running = true
+	p.wg.Add(1)
+	go p.run()
+}
+
+func (p *l1Prefetcher) run() {
+	defer p.wg.Done()
+	for {
+		p.mu.Lock()
+		if p.next > p.target {
+			p.running = false
+			p.mu.Unlock()
+			return
+		}
+		from := p.next
+		to := p.target
+		if max := from + uint64(p.client.maxBatchSize) - 1; to > max {
+			to = max
+		}
+		p.next = to + 1
+		p.mu.Unlock()
+
+		fetched, err := p.fetchRange(from, to)
+		if err != nil || fetched <= to {
+			p.mu.Lock()
+			// retry the blocks we did not get on the next schedule
+			if p.next > fetched {
+				p.next = fetched
+			}
+			p.running = false
+			p.mu.Unlock()
+			if err != nil {
+				p.log.Debug("Stopped prefetching L1 blocks", "from", from, "to", to, "next", fetched, "err", err)
+			}
+			return
+		}
+	}
+}
+
+// fetchRange prefetches the blocks in the given inclusive range,
+// and returns the number of the first block that was not prefetched.
+func (p *l1Prefetcher) fetchRange(from, to uint64) (uint64, error) {
+	ctx, cancel := context.WithTimeout(p.ctx, l1PrefetchTimeout)
+	defer cancel()
+
+	blocks := make([]*rpcBlock, to-from+1)
+	elems := make([]rpc.BatchElem, len(blocks))
+	for i := range elems {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []any{numberID(from + uint64(i)).Arg(), true},
+			Result: &blocks[i],
+		}
+	}
+	if err := p.client.client.BatchCallContext(ctx, elems); err != nil {
+		return from, fmt.Errorf("failed to fetch blocks %d - %d: %w", from, to, err)
+	}
+
+	var group errgroup.Group
+	group.SetLimit(p.concurrency)
+	next := from
+	var stopErr error
+	for i, elem := range elems {
+		num := from + uint64(i)
+		if elem.Error != nil {
+			stopErr = fmt.Errorf("failed to fetch block %d: %w", num, elem.Error)
+			break
+		}
+		if blocks[i] == nil { // beyond the L1 head
+			break
+		}
+		info, txs, err := blocks[i].Info(p.client.trustRPC, p.client.mustBePostMerge)
+		if err != nil {
+			stopErr = fmt.Errorf("invalid block %d: %w", num, err)
+			break
+		}
+		if err := numberID(num).CheckID(eth.ToBlockID(info)); err != nil {
+			stopErr = fmt.Errorf("fetched block data does not match requested ID: %w", err)
+			break
+		}
+		p.client.headersCache.Add(info.Hash(), info)
+		p.client.transactionsCache.Add(info.Hash(), txs)
+		p.prefetchedTxs.Add(info.Hash(), struct{}{})
+
+		job := p.client.receiptsJob(info, txs)
+		group.Go(func() error {
+			if _, err := job.Fetch(ctx); err != nil {
+				return fmt.Errorf("failed to fetch receipts of block %s: %w", job.block, err)
+			}
+			p.prefetchedReceipts.Add(job.block.Hash, struct{}{})
+			return nil
+		})
+		next = num + 1
+	}
+	// Failed receipts are retried by the receipts fetching job when they are requested.
+	if err := group.Wait(); err != nil && stopErr == nil {
+		stopErr = err
+	}
+	return next, stopErr
+}
+
+// Hit records whether the block was prefetched, when the transactions or receipts of the block are requested.
+func (p *l1Prefetcher) Hit(hash common.Hash, receipts bool) {
+	if receipts {
+		p.prefetchedReceipts.Get(hash)
+	} else {
+		p.prefetchedTxs.Get(hash)
+	}
+}
+
+func (p *l1Prefetcher) Close() {
+	p.cancel()
+	p.wg.Wait()
+}
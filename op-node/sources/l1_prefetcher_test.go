@@ -0,0 +1,107 @@
+package sources
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+type testCacheMetrics struct {
+	mu   sync.Mutex
+	adds map[string]int
+	hits map[string]int
+}
+
+func (m *testCacheMetrics) CacheAdd(label string, cacheSize int, evicted bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.adds[label] += 1
+}
+
+func (m *testCacheMetrics) CacheGet(label string, hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if hit {
+		m.hits[label] += 1
+	}
+}
+
+func (m *testCacheMetrics) count(counts map[string]int, label string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return counts[label]
+}
+
+func randEmptyBlock(num uint64) *rpcBlock {
+	_, rhdr := randHeader()
+	rhdr.Number = hexutil.Uint64(num)
+	rhdr.ReceiptHash = types.EmptyReceiptsHash
+	rhdr.TxHash = types.EmptyTxsHash
+	return &rpcBlock{rpcHeader: *rhdr, Transactions: []*types.Transaction{}}
+}
+
+func TestL1Client_Prefetch(t *testing.T) {
+	m := new(mockRPC)
+	ctx := context.Background()
+	head := randEmptyBlock(100)
+	next := []*rpcBlock{randEmptyBlock(101), randEmptyBlock(102)}
+
+	m.On("CallContext", ctx, new(*rpcHeader),
+		"eth_getBlockByNumber", []any{hexutil.EncodeUint64(100), false}).Run(func(args mock.Arguments) {
+		*args[1].(**rpcHeader) = &head.rpcHeader
+	}).Return([]error{nil})
+	isBlockBatch := mock.MatchedBy(func(b []rpc.BatchElem) bool {
+		return len(b) > 0 && b[0].Method == "eth_getBlockByNumber"
+	})
+	m.On("BatchCallContext", mock.Anything, isBlockBatch).Run(func(args mock.Arguments) {
+		batch := args[1].([]rpc.BatchElem)
+		require.Len(t, batch, 3, "prefetch window")
+		for i := range batch {
+			require.Equal(t, []any{hexutil.EncodeUint64(uint64(101 + i)), true}, batch[i].Args)
+			if i < len(next) {
+				*batch[i].Result.(**rpcBlock) = next[i]
+			} // the last block is beyond the head, and left nil
+		}
+	}).Return([]error{nil}).Once()
+
+	metrics := &testCacheMetrics{adds: make(map[string]int), hits: make(map[string]int)}
+	cfg := L1ClientDefaultConfig(&rollup.Config{SeqWindowSize: 10}, true, RPCKindBasic)
+	cfg.PrefetchWindow = 3
+	s, err := NewL1Client(m, testlog.Logger(t, log.LvlError), metrics, cfg)
+	require.NoError(t, err)
+	defer s.prefetcher.Close()
+
+	ref, err := s.L1BlockRefByNumber(ctx, 100)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), ref.Number)
+
+	require.Eventually(t, func() bool {
+		return metrics.count(metrics.adds, "prefetch_receipts") == len(next)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// served from the prefetched caches, without any further RPC calls
+	for _, block := range next {
+		info, txs, err := s.InfoAndTxsByHash(ctx, block.Hash)
+		require.NoError(t, err)
+		require.Equal(t, block.Hash, info.Hash())
+		require.Empty(t, txs)
+		_, receipts, err := s.FetchReceipts(ctx, block.Hash)
+		require.NoError(t, err)
+		require.Empty(t, receipts)
+	}
+	require.Equal(t, len(next), metrics.count(metrics.hits, "prefetch_txs"))
+	require.Equal(t, len(next), metrics.count(metrics.hits, "prefetch_receipts"))
+	m.AssertExpectations(t)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/client"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 )
 
 type RollupClient struct {
@@ -25,6 +26,24 @@ func (r *RollupClient) OutputAtBlock(ctx context.Context, blockNum uint64) (*eth
 	return output, err
 }
 
+func (r *RollupClient) OutputWithProofs(ctx context.Context, blockNum uint64, slots []common.Hash) (*eth.OutputWithProofsResponse, error) {
+	var output *eth.OutputWithProofsResponse
+	err := r.rpc.CallContext(ctx, &output, "optimism_outputWithProofs", hexutil.Uint64(blockNum), slots)
+	return output, err
+}
+
+func (r *RollupClient) SafeHeadRecordByL2Block(ctx context.Context, blockNum uint64) (*eth.SafeHeadRecord, error) {
+	var record *eth.SafeHeadRecord
+	err := r.rpc.CallContext(ctx, &record, "optimism_safeHeadRecordByL2Block", hexutil.Uint64(blockNum))
+	return record, err
+}
+
+func (r *RollupClient) SafeHeadRecordsByL1Block(ctx context.Context, blockNum uint64) ([]eth.SafeHeadRecord, error) {
+	var records []eth.SafeHeadRecord
+	err := r.rpc.CallContext(ctx, &records, "optimism_safeHeadRecordsByL1Block", hexutil.Uint64(blockNum))
+	return records, err
+}
+
 func (r *RollupClient) SyncStatus(ctx context.Context) (*eth.SyncStatus, error) {
 	var output *eth.SyncStatus
 	err := r.rpc.CallContext(ctx, &output, "optimism_syncStatus")
@@ -58,3 +77,33 @@ func (r *RollupClient) SequencerActive(ctx context.Context) (bool, error) {
 	err := r.rpc.CallContext(ctx, &result, "admin_sequencerActive")
 	return result, err
 }
+
+func (r *RollupClient) SequencerPolicy(ctx context.Context) (driver.SequencerPolicy, error) {
+	var result driver.SequencerPolicy
+	err := r.rpc.CallContext(ctx, &result, "admin_sequencerPolicy")
+	return result, err
+}
+
+func (r *RollupClient) SetSequencerPolicy(ctx context.Context, policy driver.SequencerPolicy) (driver.SequencerPolicy, error) {
+	var result driver.SequencerPolicy
+	err := r.rpc.CallContext(ctx, &result, "admin_setSequencerPolicy", policy)
+	return result, err
+}
+
+func (r *RollupClient) HandoverSequencerLeadership(ctx context.Context) (eth.BlockID, error) {
+	var result eth.BlockID
+	err := r.rpc.CallContext(ctx, &result, "admin_handoverSequencerLeadership")
+	return result, err
+}
+
+func (r *RollupClient) L1ReorgHalt(ctx context.Context) (*driver.L1Reorg, error) {
+	var result *driver.L1Reorg
+	err := r.rpc.CallContext(ctx, &result, "admin_l1ReorgHalt")
+	return result, err
+}
+
+func (r *RollupClient) AcknowledgeL1Reorg(ctx context.Context) (*driver.L1Reorg, error) {
+	var result *driver.L1Reorg
+	err := r.rpc.CallContext(ctx, &result, "admin_acknowledgeL1Reorg")
+	return result, err
+}
@@ -8,6 +8,7 @@ import (
 // Optionally a test may hook into the metrics
 type TestDerivationMetrics struct {
 	FnRecordL1ReorgDepth      func(d uint64)
+	FnRecordL1Reorg           func(oldHead eth.L1BlockRef, newHead eth.L1BlockRef)
 	FnRecordL1Ref             func(name string, ref eth.L1BlockRef)
 	FnRecordL2Ref             func(name string, ref eth.L2BlockRef)
 	FnRecordUnsafePayloads    func(length uint64, memSize uint64, next eth.BlockID)
@@ -20,6 +21,12 @@ func (t *TestDerivationMetrics) RecordL1ReorgDepth(d uint64) {
 	}
 }
 
+func (t *TestDerivationMetrics) RecordL1Reorg(oldHead eth.L1BlockRef, newHead eth.L1BlockRef) {
+	if t.FnRecordL1Reorg != nil {
+		t.FnRecordL1Reorg(oldHead, newHead)
+	}
+}
+
 func (t *TestDerivationMetrics) RecordL1Ref(name string, ref eth.L1BlockRef) {
 	if t.FnRecordL1Ref != nil {
 		t.FnRecordL1Ref(name, ref)
@@ -11,6 +11,8 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient/gethclient"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
 )
 
 type proofDB struct {
@@ -98,3 +100,30 @@ func VerifyProof(stateRoot common.Hash, proof *gethclient.AccountResult) error {
 	}
 	return nil
 }
+
+// VerifyWithdrawalProof verifies the parameters to prove a withdrawal with locally, like the OptimismPortal does on L1:
+// the output root proof must match the output root of the output proposal, and the withdrawal proof must prove
+// that the withdrawal hash is stored in the L2ToL1MessagePasser storage.
+func VerifyWithdrawalProof(params ProvenWithdrawalParameters, withdrawalHash common.Hash, outputRoot common.Hash) error {
+	root, err := rollup.ComputeL2OutputRoot(&params.OutputRootProof)
+	if err != nil {
+		return fmt.Errorf("failed to compute output root: %w", err)
+	}
+	if common.Hash(root) != outputRoot {
+		return fmt.Errorf("output root proof computes to %s, expected %s", common.Hash(root), outputRoot)
+	}
+	db := &proofDB{m: make(map[string][]byte)}
+	for _, node := range params.WithdrawalProof {
+		db.m[string(crypto.Keccak256(node))] = node
+	}
+	slot := StorageSlotOfWithdrawalHash(withdrawalHash)
+	value, err := trie.VerifyProof(params.OutputRootProof.MessagePasserStorageRoot, crypto.Keccak256(slot[:]), db)
+	if err != nil {
+		return fmt.Errorf("failed to verify withdrawal proof: %w", err)
+	}
+	// The sentMessages mapping stores true for initiated withdrawals, the RLP encoding of 1.
+	if !bytes.Equal(value, []byte{1}) {
+		return fmt.Errorf("withdrawal proof proves value %x, the withdrawal is not stored", value)
+	}
+	return nil
+}
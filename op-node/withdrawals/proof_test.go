@@ -0,0 +1,105 @@
+package withdrawals
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+// withdrawalProof builds a message passer storage trie with the given withdrawal hashes,
+// and returns its root and the proof of the first withdrawal hash.
+func withdrawalProof(t *testing.T, hashes ...common.Hash) (common.Hash, [][]byte) {
+	tr := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	for _, h := range hashes {
+		slot := StorageSlotOfWithdrawalHash(h)
+		require.NoError(t, tr.Update(crypto.Keccak256(slot[:]), []byte{1}))
+	}
+	slot := StorageSlotOfWithdrawalHash(hashes[0])
+	proofDB := memorydb.New()
+	require.NoError(t, tr.Prove(crypto.Keccak256(slot[:]), 0, proofDB))
+	var nodes [][]byte
+	it := proofDB.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		nodes = append(nodes, common.CopyBytes(it.Value()))
+	}
+	return tr.Hash(), nodes
+}
+
+func TestVerifyWithdrawalProof(t *testing.T) {
+	withdrawalHash := common.Hash{0xaa}
+	storageRoot, nodes := withdrawalProof(t, withdrawalHash, common.Hash{0xbb}, common.Hash{0xcc})
+	params := ProvenWithdrawalParameters{
+		OutputRootProof: bindings.TypesOutputRootProof{
+			StateRoot:                common.Hash{1},
+			MessagePasserStorageRoot: storageRoot,
+			LatestBlockhash:          common.Hash{2},
+		},
+		WithdrawalProof: nodes,
+	}
+	outputRoot, err := rollup.ComputeL2OutputRoot(&params.OutputRootProof)
+	require.NoError(t, err)
+
+	require.NoError(t, VerifyWithdrawalProof(params, withdrawalHash, common.Hash(outputRoot)))
+
+	err = VerifyWithdrawalProof(params, withdrawalHash, common.Hash{0xff})
+	require.ErrorContains(t, err, "output root proof computes to")
+
+	err = VerifyWithdrawalProof(params, common.Hash{0xdd}, common.Hash(outputRoot))
+	require.Error(t, err, "proof of another withdrawal does not prove this one")
+
+	params.WithdrawalProof = params.WithdrawalProof[1:]
+	err = VerifyWithdrawalProof(params, withdrawalHash, common.Hash(outputRoot))
+	require.ErrorContains(t, err, "failed to verify withdrawal proof")
+}
+
+func TestWithdrawalTxData(t *testing.T) {
+	portalABI, err := bindings.OptimismPortalMetaData.GetAbi()
+	require.NoError(t, err)
+	params := ProvenWithdrawalParameters{
+		Nonce:           big.NewInt(1),
+		Sender:          common.Address{1},
+		Target:          common.Address{2},
+		Value:           big.NewInt(3),
+		GasLimit:        big.NewInt(4),
+		L2OutputIndex:   big.NewInt(5),
+		Data:            []byte{6},
+		WithdrawalProof: [][]byte{{7}},
+	}
+
+	data, err := ProveWithdrawalTxData(params)
+	require.NoError(t, err)
+	require.Equal(t, portalABI.Methods["proveWithdrawalTransaction"].ID, data[:4])
+	args, err := portalABI.Methods["proveWithdrawalTransaction"].Inputs.Unpack(data[4:])
+	require.NoError(t, err)
+	require.Equal(t, params.L2OutputIndex, args[1])
+	require.Equal(t, params.WithdrawalProof, args[3])
+
+	data, err = FinalizeWithdrawalTxData(bindings.TypesWithdrawalTransaction{
+		Nonce:    params.Nonce,
+		Sender:   params.Sender,
+		Target:   params.Target,
+		Value:    params.Value,
+		GasLimit: params.GasLimit,
+		Data:     params.Data,
+	})
+	require.NoError(t, err)
+	require.Equal(t, portalABI.Methods["finalizeWithdrawalTransaction"].ID, data[:4])
+}
+
+func TestWithdrawalStatusString(t *testing.T) {
+	require.Equal(t, "initiated", StatusInitiated.String())
+	require.Equal(t, "finalizable", StatusFinalizable.String())
+	text, err := StatusProven.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "proven", string(text))
+}
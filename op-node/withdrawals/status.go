@@ -0,0 +1,229 @@
+package withdrawals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+)
+
+// WithdrawalStatus is the stage of a withdrawal in its lifecycle, from L2 to L1.
+type WithdrawalStatus uint8
+
+const (
+	// StatusInitiated means the withdrawal is included on L2, but there is no output proposal on L1 yet to prove it against.
+	StatusInitiated WithdrawalStatus = iota
+	// StatusProvable means there is an output proposal to prove the withdrawal against, but it is not proven yet,
+	// or it was proven against an output proposal that has been deleted since.
+	StatusProvable
+	// StatusProven means the withdrawal is proven, but the finalization period has not elapsed yet.
+	StatusProven
+	// StatusFinalizable means the withdrawal is proven, and the finalization period has elapsed.
+	StatusFinalizable
+	// StatusFinalized means the withdrawal is finalized on L1.
+	StatusFinalized
+)
+
+func (s WithdrawalStatus) String() string {
+	switch s {
+	case StatusInitiated:
+		return "initiated"
+	case StatusProvable:
+		return "provable"
+	case StatusProven:
+		return "proven"
+	case StatusFinalizable:
+		return "finalizable"
+	case StatusFinalized:
+		return "finalized"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(s))
+	}
+}
+
+func (s WithdrawalStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// L1Client is the L1 client to determine the status of a withdrawal with.
+type L1Client interface {
+	bind.ContractCaller
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// WithdrawalInfo is the status of a withdrawal, and the L1 and L2 details it is based on.
+type WithdrawalInfo struct {
+	Status     WithdrawalStatus                    `json:"status"`
+	Hash       common.Hash                         `json:"hash"`
+	Withdrawal bindings.TypesWithdrawalTransaction `json:"withdrawal"`
+	// L2BlockNumber is the L2 block that includes the withdrawal transaction.
+	L2BlockNumber uint64 `json:"l2BlockNumber"`
+	// L2OutputOracle is the L2OutputOracle that the OptimismPortal proves withdrawals against.
+	L2OutputOracle common.Address `json:"l2OutputOracle"`
+	// L2OutputIndex is the index of the first output proposal that covers the L2 block of the withdrawal.
+	// It is nil if there is no such output proposal yet.
+	L2OutputIndex *big.Int `json:"l2OutputIndex,omitempty"`
+	// ProvenOutputIndex is the index of the output proposal that the withdrawal was proven against, nil if not proven.
+	ProvenOutputIndex *big.Int `json:"provenOutputIndex,omitempty"`
+	// ProvenAt is the L1 timestamp of the proof, 0 if not proven.
+	ProvenAt uint64 `json:"provenAt,omitempty"`
+	// FinalizableAfter is the L1 timestamp after which the proven withdrawal can be finalized, 0 if not proven.
+	FinalizableAfter uint64 `json:"finalizableAfter,omitempty"`
+}
+
+// GetWithdrawalStatus determines the status of the withdrawal initiated by the given L2 transaction.
+// It only supports a single withdrawal per transaction, like ParseMessagePassed.
+func GetWithdrawalStatus(ctx context.Context, l1 L1Client, l2ReceiptCl ReceiptClient, portalAddr common.Address, txHash common.Hash) (*WithdrawalInfo, error) {
+	receipt, err := l2ReceiptCl.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawal receipt: %w", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, errors.New("withdrawal transaction failed on L2")
+	}
+	ev, err := ParseMessagePassed(receipt)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := WithdrawalHash(ev)
+	if err != nil {
+		return nil, err
+	}
+	if hash != ev.WithdrawalHash {
+		return nil, fmt.Errorf("computed withdrawal hash %s does not match the emitted withdrawal hash %s", hash, common.Hash(ev.WithdrawalHash))
+	}
+	info := &WithdrawalInfo{
+		Hash: hash,
+		Withdrawal: bindings.TypesWithdrawalTransaction{
+			Nonce:    ev.Nonce,
+			Sender:   ev.Sender,
+			Target:   ev.Target,
+			Value:    ev.Value,
+			GasLimit: ev.GasLimit,
+			Data:     ev.Data,
+		},
+		L2BlockNumber: receipt.BlockNumber.Uint64(),
+	}
+
+	opts := &bind.CallOpts{Context: ctx}
+	portal, err := bindings.NewOptimismPortalCaller(portalAddr, l1)
+	if err != nil {
+		return nil, err
+	}
+	info.L2OutputOracle, err = portal.L2ORACLE(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get L2OutputOracle address: %w", err)
+	}
+	l2OO, err := bindings.NewL2OutputOracleCaller(info.L2OutputOracle, l1)
+	if err != nil {
+		return nil, err
+	}
+
+	finalized, err := portal.FinalizedWithdrawals(opts, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if withdrawal is finalized: %w", err)
+	}
+	if finalized {
+		info.Status = StatusFinalized
+		return info, nil
+	}
+
+	latest, err := l2OO.LatestBlockNumber(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest proposed L2 block: %w", err)
+	}
+	if latest.Cmp(receipt.BlockNumber) < 0 {
+		info.Status = StatusInitiated
+		return info, nil
+	}
+	info.L2OutputIndex, err = l2OO.GetL2OutputIndexAfter(opts, receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get output index: %w", err)
+	}
+
+	proven, err := portal.ProvenWithdrawals(opts, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proven withdrawal: %w", err)
+	}
+	if proven.Timestamp == nil || proven.Timestamp.Sign() == 0 {
+		info.Status = StatusProvable
+		return info, nil
+	}
+	// The proof is only valid if the output proposal it was proven against has not been deleted or replaced since.
+	next, err := l2OO.NextOutputIndex(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next output index: %w", err)
+	}
+	if proven.L2OutputIndex.Cmp(next) >= 0 {
+		info.Status = StatusProvable
+		return info, nil
+	}
+	output, err := l2OO.GetL2Output(opts, proven.L2OutputIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proven output: %w", err)
+	}
+	if output.OutputRoot != proven.OutputRoot {
+		info.Status = StatusProvable
+		return info, nil
+	}
+	info.ProvenOutputIndex = proven.L2OutputIndex
+	info.ProvenAt = proven.Timestamp.Uint64()
+
+	finalizationPeriod, err := l2OO.FINALIZATIONPERIODSECONDS(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get finalization period: %w", err)
+	}
+	// Both the proof and the output proposal have to be older than the finalization period.
+	after := info.ProvenAt
+	if ts := output.Timestamp.Uint64(); ts > after {
+		after = ts
+	}
+	info.FinalizableAfter = after + finalizationPeriod.Uint64()
+
+	head, err := l1.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get L1 head: %w", err)
+	}
+	if head.Time > info.FinalizableAfter {
+		info.Status = StatusFinalizable
+	} else {
+		info.Status = StatusProven
+	}
+	return info, nil
+}
+
+// ProveWithdrawalTxData returns the calldata of the OptimismPortal call to prove the withdrawal with.
+func ProveWithdrawalTxData(params ProvenWithdrawalParameters) ([]byte, error) {
+	portalABI, err := bindings.OptimismPortalMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return portalABI.Pack("proveWithdrawalTransaction",
+		bindings.TypesWithdrawalTransaction{
+			Nonce:    params.Nonce,
+			Sender:   params.Sender,
+			Target:   params.Target,
+			Value:    params.Value,
+			GasLimit: params.GasLimit,
+			Data:     params.Data,
+		},
+		params.L2OutputIndex,
+		params.OutputRootProof,
+		params.WithdrawalProof,
+	)
+}
+
+// FinalizeWithdrawalTxData returns the calldata of the OptimismPortal call to finalize the withdrawal with.
+func FinalizeWithdrawalTxData(withdrawal bindings.TypesWithdrawalTransaction) ([]byte, error) {
+	portalABI, err := bindings.OptimismPortalMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return portalABI.Pack("finalizeWithdrawalTransaction", withdrawal)
+}
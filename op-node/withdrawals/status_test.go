@@ -0,0 +1,231 @@
+package withdrawals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+)
+
+type fakeReceiptClient struct {
+	receipt *types.Receipt
+}
+
+func (f *fakeReceiptClient) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if hash != f.receipt.TxHash {
+		return nil, ethereum.NotFound
+	}
+	return f.receipt, nil
+}
+
+// fakeL1 serves the OptimismPortal and L2OutputOracle calls that determine the status of a withdrawal.
+type fakeL1 struct {
+	portalABI *abi.ABI
+	oracleABI *abi.ABI
+	oracle    common.Address
+
+	finalized          bool
+	latestBlockNumber  uint64
+	outputIndexAfter   uint64
+	provenRoot         common.Hash
+	provenTimestamp    uint64
+	provenOutputIndex  uint64
+	nextOutputIndex    uint64
+	output             bindings.TypesOutputProposal
+	finalizationPeriod uint64
+	headTime           uint64
+}
+
+func (f *fakeL1) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x01}, nil
+}
+
+func (f *fakeL1) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	contractABI := f.portalABI
+	if *call.To == f.oracle {
+		contractABI = f.oracleABI
+	}
+	method, err := contractABI.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	u64 := func(v uint64) *big.Int { return new(big.Int).SetUint64(v) }
+	switch method.Name {
+	case "L2_ORACLE":
+		return method.Outputs.Pack(f.oracle)
+	case "finalizedWithdrawals":
+		return method.Outputs.Pack(f.finalized)
+	case "provenWithdrawals":
+		return method.Outputs.Pack(f.provenRoot, u64(f.provenTimestamp), u64(f.provenOutputIndex))
+	case "latestBlockNumber":
+		return method.Outputs.Pack(u64(f.latestBlockNumber))
+	case "getL2OutputIndexAfter":
+		return method.Outputs.Pack(u64(f.outputIndexAfter))
+	case "nextOutputIndex":
+		return method.Outputs.Pack(u64(f.nextOutputIndex))
+	case "getL2Output":
+		return method.Outputs.Pack(f.output)
+	case "FINALIZATION_PERIOD_SECONDS":
+		return method.Outputs.Pack(u64(f.finalizationPeriod))
+	default:
+		return nil, fmt.Errorf("unexpected call to %s", method.Name)
+	}
+}
+
+func (f *fakeL1) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Time: f.headTime}, nil
+}
+
+func TestGetWithdrawalStatus(t *testing.T) {
+	file, err := os.Open(path.Join("testdata", "bridge-withdrawal.json"))
+	require.NoError(t, err)
+	defer file.Close()
+	receipt := new(types.Receipt)
+	require.NoError(t, json.NewDecoder(file).Decode(receipt))
+	l2 := &fakeReceiptClient{receipt: receipt}
+	l2Block := receipt.BlockNumber.Uint64()
+
+	portalABI, err := bindings.OptimismPortalMetaData.GetAbi()
+	require.NoError(t, err)
+	oracleABI, err := bindings.L2OutputOracleMetaData.GetAbi()
+	require.NoError(t, err)
+	portal := common.Address{0xaa}
+	oracle := common.Address{0xbb}
+	provenRoot := common.Hash{0xcc}
+
+	// proven returns an L1 state where the withdrawal is proven at L1 time 1000, against the output proposal
+	// at index 3 that was proposed at L1 time 900, with a finalization period of 100 seconds.
+	proven := func() *fakeL1 {
+		return &fakeL1{
+			latestBlockNumber:  l2Block + 10,
+			outputIndexAfter:   3,
+			provenRoot:         provenRoot,
+			provenTimestamp:    1000,
+			provenOutputIndex:  3,
+			nextOutputIndex:    5,
+			output:             bindings.TypesOutputProposal{OutputRoot: provenRoot, Timestamp: big.NewInt(900), L2BlockNumber: big.NewInt(int64(l2Block + 5))},
+			finalizationPeriod: 100,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		l1     func() *fakeL1
+		status WithdrawalStatus
+		// finalizableAfter is the expected L1 time after which the withdrawal can be finalized, 0 if not proven
+		finalizableAfter uint64
+	}{
+		{
+			name: "no output proposal yet",
+			l1: func() *fakeL1 {
+				return &fakeL1{latestBlockNumber: l2Block - 1}
+			},
+			status: StatusInitiated,
+		},
+		{
+			name: "not proven",
+			l1: func() *fakeL1 {
+				return &fakeL1{latestBlockNumber: l2Block + 10, outputIndexAfter: 3, nextOutputIndex: 5}
+			},
+			status: StatusProvable,
+		},
+		{
+			name: "proven output deleted",
+			l1: func() *fakeL1 {
+				l1 := proven()
+				l1.nextOutputIndex = 3
+				return l1
+			},
+			status: StatusProvable,
+		},
+		{
+			name: "proven output replaced",
+			l1: func() *fakeL1 {
+				l1 := proven()
+				l1.output.OutputRoot = common.Hash{0xdd}
+				return l1
+			},
+			status: StatusProvable,
+		},
+		{
+			name: "inside challenge window",
+			l1: func() *fakeL1 {
+				l1 := proven()
+				l1.headTime = 1100
+				return l1
+			},
+			status:           StatusProven,
+			finalizableAfter: 1100,
+		},
+		{
+			name: "output proposed after proof",
+			l1: func() *fakeL1 {
+				l1 := proven()
+				l1.output.Timestamp = big.NewInt(1050)
+				l1.headTime = 1101
+				return l1
+			},
+			status:           StatusProven,
+			finalizableAfter: 1150,
+		},
+		{
+			name: "finalizable",
+			l1: func() *fakeL1 {
+				l1 := proven()
+				l1.headTime = 1101
+				return l1
+			},
+			status:           StatusFinalizable,
+			finalizableAfter: 1100,
+		},
+		{
+			name: "finalized",
+			l1: func() *fakeL1 {
+				return &fakeL1{finalized: true}
+			},
+			status: StatusFinalized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l1 := test.l1()
+			l1.portalABI = portalABI
+			l1.oracleABI = oracleABI
+			l1.oracle = oracle
+			info, err := GetWithdrawalStatus(context.Background(), l1, l2, portal, receipt.TxHash)
+			require.NoError(t, err)
+			require.Equal(t, test.status, info.Status)
+			require.Equal(t, common.HexToHash("0x0d827f8148288e3a2466018f71b968ece4ea9f9e2a81c30da9bd46cce2868285"), info.Hash)
+			require.Equal(t, l2Block, info.L2BlockNumber)
+			require.Equal(t, test.finalizableAfter, info.FinalizableAfter)
+			if test.finalizableAfter != 0 {
+				require.Equal(t, uint64(1000), info.ProvenAt)
+				require.Equal(t, uint64(3), info.ProvenOutputIndex.Uint64())
+			} else {
+				require.Nil(t, info.ProvenOutputIndex)
+			}
+			if test.status == StatusInitiated || test.status == StatusFinalized {
+				require.Nil(t, info.L2OutputIndex)
+			} else {
+				require.Equal(t, uint64(3), info.L2OutputIndex.Uint64())
+				require.Equal(t, oracle, info.L2OutputOracle)
+			}
+		})
+	}
+
+	t.Run("unknown transaction", func(t *testing.T) {
+		_, err := GetWithdrawalStatus(context.Background(), proven(), l2, portal, common.Hash{0x01})
+		require.ErrorIs(t, err, ethereum.NotFound)
+	})
+}
@@ -107,3 +107,29 @@ func (s *SignerClient) SignTransaction(ctx context.Context, chainId *big.Int, fr
 
 	return signed, nil
 }
+
+// BlockPayloadArgs are the arguments to request a signature over the signing hash of a block payload.
+// The signer computes the signing hash from the domain, chain ID and payload hash.
+type BlockPayloadArgs struct {
+	Domain        hexutil.Bytes   `json:"domain"`
+	ChainID       *hexutil.Big    `json:"chainId"`
+	PayloadHash   hexutil.Bytes   `json:"payloadHash"`
+	SenderAddress *common.Address `json:"senderAddress"`
+}
+
+func (s *SignerClient) SignBlockPayload(ctx context.Context, args *BlockPayloadArgs) ([65]byte, error) {
+	var sig [65]byte
+	var result hexutil.Bytes
+	if err := s.client.CallContext(ctx, &result, "opsigner_signBlockPayload", args); err != nil {
+		return sig, fmt.Errorf("opsigner_signBlockPayload failed: %w", err)
+	}
+	if len(result) != len(sig) {
+		return sig, fmt.Errorf("invalid signature length: %d", len(result))
+	}
+	copy(sig[:], result)
+	return sig, nil
+}
+
+func (s *SignerClient) Close() {
+	s.client.Close()
+}
@@ -0,0 +1,208 @@
+package proxyd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	ContextKeyAPIKey = "api_key"
+
+	QuotaRequests     = "requests"
+	QuotaComputeUnits = "compute_units"
+
+	defaultComputeUnits = 1
+)
+
+type quotaLimiterFactory func(dur time.Duration, max int, prefix string) QuotaLimiter
+
+// APIKeyTier holds the quotas, method allowlist and backend group
+// routing that are shared by all API keys of the tier.
+type APIKeyTier struct {
+	Name string
+
+	requestLim          QuotaLimiter
+	computeUnitLim      QuotaLimiter
+	defaultComputeUnits int
+	computeUnits        map[string]int
+	allowedMethods      *StringSet
+	methodMappings      map[string]string
+}
+
+func NewAPIKeyTier(name string, cfg *APIKeyTierConfig, limiterFactory quotaLimiterFactory) *APIKeyTier {
+	tier := &APIKeyTier{
+		Name:                name,
+		defaultComputeUnits: cfg.DefaultComputeUnits,
+		computeUnits:        cfg.ComputeUnits,
+		methodMappings:      cfg.RPCMethodMappings,
+	}
+	if tier.defaultComputeUnits == 0 {
+		tier.defaultComputeUnits = defaultComputeUnits
+	}
+	if len(cfg.AllowedMethods) > 0 {
+		tier.allowedMethods = NewStringSetFromStrings(cfg.AllowedMethods)
+	}
+	// The quotas are keyed by API key alias, not by tier. With Redis limiters,
+	// usage carries over when a key is moved to a different tier. In-memory
+	// limiters belong to this tier, so usage starts over on a tier move, and
+	// whenever the tier changes on a reload.
+	if cfg.RequestLimit > 0 {
+		tier.requestLim = limiterFactory(time.Duration(cfg.RequestInterval), cfg.RequestLimit, "api_key_requests")
+	}
+	if cfg.ComputeUnitLimit > 0 {
+		tier.computeUnitLim = limiterFactory(time.Duration(cfg.ComputeUnitInterval), cfg.ComputeUnitLimit, "api_key_compute_units")
+	}
+	return tier
+}
+
+// ComputeUnits returns the compute units that a call of the method consumes.
+func (t *APIKeyTier) ComputeUnits(method string) int {
+	if cu, ok := t.computeUnits[method]; ok {
+		return cu
+	}
+	return t.defaultComputeUnits
+}
+
+// AllowsMethod returns whether keys of the tier may call the method.
+// All methods are allowed if the tier has no allowlist.
+func (t *APIKeyTier) AllowsMethod(method string) bool {
+	return t.allowedMethods == nil || t.allowedMethods.Has(method)
+}
+
+// BackendGroup returns the backend group that serves calls of the method for
+// keys of the tier, falling back to the given default method mappings. An empty
+// string is returned if the method is not allowed.
+func (t *APIKeyTier) BackendGroup(method string, defaults map[string]string) string {
+	if !t.AllowsMethod(method) {
+		return ""
+	}
+	if group, ok := t.methodMappings[method]; ok {
+		return group
+	}
+	return defaults[method]
+}
+
+// APIKey is an authenticated API key, with the usage counters of this proxyd instance.
+type APIKey struct {
+	Alias string
+	Tier  *APIKeyTier
+
+	totalRequests     uint64
+	totalComputeUnits uint64
+	totalRejected     uint64
+	mtx               sync.Mutex
+}
+
+func NewAPIKey(alias string, tier *APIKeyTier) *APIKey {
+	return &APIKey{
+		Alias: alias,
+		Tier:  tier,
+	}
+}
+
+// Take consumes the request and compute unit quotas of the key for a call of the method.
+// Both quotas are checked before either is consumed, so a rejected call consumes neither.
+func (k *APIKey) Take(ctx context.Context, method string) error {
+	cu := k.Tier.ComputeUnits(method)
+	var (
+		takes  []QuotaTake
+		quotas []string
+	)
+	if k.Tier.requestLim != nil {
+		takes = append(takes, QuotaTake{Limiter: k.Tier.requestLim, N: 1})
+		quotas = append(quotas, QuotaRequests)
+	}
+	if k.Tier.computeUnitLim != nil {
+		takes = append(takes, QuotaTake{Limiter: k.Tier.computeUnitLim, N: cu})
+		quotas = append(quotas, QuotaComputeUnits)
+	}
+	rejected, err := TakeQuotas(ctx, k.Alias, takes)
+	if err != nil {
+		log.Error("error taking api key quota", "auth", k.Alias, "err", err, "req_id", GetReqID(ctx))
+		return ErrInternal
+	}
+	if rejected >= 0 {
+		k.mtx.Lock()
+		k.totalRejected++
+		k.mtx.Unlock()
+		RecordAPIKeyQuotaExceeded(k, quotas[rejected])
+		log.Debug("api key over quota", "auth", k.Alias, "tier", k.Tier.Name, "quota", quotas[rejected], "req_id", GetReqID(ctx))
+		return ErrOverAPIKeyQuota
+	}
+
+	k.mtx.Lock()
+	k.totalRequests++
+	k.totalComputeUnits += uint64(cu)
+	k.mtx.Unlock()
+	RecordAPIKeyUsage(k, cu)
+	return nil
+}
+
+// APIKeyQuotaUsage is the usage of a quota over its current sliding window.
+type APIKeyQuotaUsage struct {
+	Used     int    `json:"used"`
+	Limit    int    `json:"limit"`
+	Interval string `json:"interval"`
+}
+
+// APIKeyUsage is the usage of an API key, as reported by the admin endpoint.
+// The quota usage is shared by all proxyd instances if the quotas are stored
+// in Redis, while the totals only cover this instance since it started.
+type APIKeyUsage struct {
+	Alias             string            `json:"alias"`
+	Tier              string            `json:"tier"`
+	Requests          *APIKeyQuotaUsage `json:"requests,omitempty"`
+	ComputeUnits      *APIKeyQuotaUsage `json:"compute_units,omitempty"`
+	TotalRequests     uint64            `json:"total_requests"`
+	TotalComputeUnits uint64            `json:"total_compute_units"`
+	TotalRejected     uint64            `json:"total_rejected"`
+}
+
+func (k *APIKey) Usage(ctx context.Context) (*APIKeyUsage, error) {
+	requests, err := quotaUsage(ctx, k.Tier.requestLim, k.Alias)
+	if err != nil {
+		return nil, err
+	}
+	computeUnits, err := quotaUsage(ctx, k.Tier.computeUnitLim, k.Alias)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+	return &APIKeyUsage{
+		Alias:             k.Alias,
+		Tier:              k.Tier.Name,
+		Requests:          requests,
+		ComputeUnits:      computeUnits,
+		TotalRequests:     k.totalRequests,
+		TotalComputeUnits: k.totalComputeUnits,
+		TotalRejected:     k.totalRejected,
+	}, nil
+}
+
+func quotaUsage(ctx context.Context, lim QuotaLimiter, key string) (*APIKeyQuotaUsage, error) {
+	if lim == nil {
+		return nil, nil
+	}
+	used, err := lim.Usage(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &APIKeyQuotaUsage{
+		Used:     used,
+		Limit:    lim.Limit(),
+		Interval: lim.Interval().String(),
+	}, nil
+}
+
+func GetAPIKey(ctx context.Context) *APIKey {
+	key, ok := ctx.Value(ContextKeyAPIKey).(*APIKey)
+	if !ok {
+		return nil
+	}
+	return key
+}
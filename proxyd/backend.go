@@ -97,6 +97,11 @@ var (
 		Message:       "block is out of range",
 		HTTPErrorCode: 400,
 	}
+	ErrOverAPIKeyQuota = &RPCErr{
+		Code:          JSONRPCErrorInternal - 20,
+		Message:       "api key is over quota",
+		HTTPErrorCode: 429,
+	}
 
 	ErrBackendUnexpectedJSONRPC = errors.New("backend returned an unexpected JSON-RPC response")
 
@@ -120,6 +125,14 @@ func ErrInvalidParams(msg string) *RPCErr {
 	}
 }
 
+func ErrTxRejected(msg string) *RPCErr {
+	return &RPCErr{
+		Code:          JSONRPCErrorInternal - 21,
+		Message:       msg,
+		HTTPErrorCode: 400,
+	}
+}
+
 type Backend struct {
 	Name                 string
 	rpcURL               string
@@ -379,14 +392,14 @@ func (b *Backend) Forward(ctx context.Context, reqs []*RPCReq, isBatch bool) ([]
 	return nil, wrapErr(lastError, "permanent error forwarding request")
 }
 
-func (b *Backend) ProxyWS(clientConn *websocket.Conn, methodWhitelist *StringSet) (*WSProxier, error) {
+func (b *Backend) ProxyWS(clientConn *websocket.Conn, methodWhitelist *StringSet, notWhitelistedErr *RPCErr, txHandler TxHandler) (*WSProxier, error) {
 	backendConn, _, err := b.dialer.Dial(b.wsURL, nil) // nolint:bodyclose
 	if err != nil {
 		return nil, wrapErr(err, "error dialing backend")
 	}
 
 	activeBackendWsConnsGauge.WithLabelValues(b.Name).Inc()
-	return NewWSProxier(b, clientConn, backendConn, methodWhitelist), nil
+	return NewWSProxier(b, clientConn, backendConn, methodWhitelist, notWhitelistedErr, txHandler), nil
 }
 
 // ForwardRPC makes a call directly to a backend and populate the response into `res`
@@ -656,9 +669,18 @@ type BackendGroup struct {
 	Name      string
 	Backends  []*Backend
 	Consensus *ConsensusPoller
+	Shadow    *ShadowGroup
 }
 
 func (bg *BackendGroup) Forward(ctx context.Context, rpcReqs []*RPCReq, isBatch bool) ([]*RPCRes, error) {
+	res, err := bg.forward(ctx, rpcReqs, isBatch)
+	if err == nil && bg.Shadow != nil && len(res) > 0 {
+		bg.Shadow.Mirror(ctx, bg.Name, rpcReqs, res, isBatch)
+	}
+	return res, err
+}
+
+func (bg *BackendGroup) forward(ctx context.Context, rpcReqs []*RPCReq, isBatch bool) ([]*RPCRes, error) {
 	if len(rpcReqs) == 0 {
 		return nil, nil
 	}
@@ -768,9 +790,14 @@ func (bg *BackendGroup) Forward(ctx context.Context, rpcReqs []*RPCReq, isBatch
 	return nil, ErrNoBackends
 }
 
-func (bg *BackendGroup) ProxyWS(ctx context.Context, clientConn *websocket.Conn, methodWhitelist *StringSet) (*WSProxier, error) {
-	for _, back := range bg.Backends {
-		proxier, err := back.ProxyWS(clientConn, methodWhitelist)
+func (bg *BackendGroup) ProxyWS(ctx context.Context, clientConn *websocket.Conn, methodWhitelist *StringSet, notWhitelistedErr *RPCErr, txHandler TxHandler) (*WSProxier, error) {
+	backends := bg.Backends
+	if bg.Consensus != nil {
+		// only pin connections to backends that are in the consensus group
+		backends = bg.loadBalancedConsensusGroup()
+	}
+	for _, back := range backends {
+		proxier, err := back.ProxyWS(clientConn, methodWhitelist, notWhitelistedErr, txHandler)
 		if errors.Is(err, ErrBackendOffline) {
 			log.Warn(
 				"skipping offline backend",
@@ -852,19 +879,23 @@ func calcBackoff(i int) time.Duration {
 }
 
 type WSProxier struct {
-	backend         *Backend
-	clientConn      *websocket.Conn
-	backendConn     *websocket.Conn
-	methodWhitelist *StringSet
-	clientConnMu    sync.Mutex
+	backend           *Backend
+	clientConn        *websocket.Conn
+	backendConn       *websocket.Conn
+	methodWhitelist   *StringSet
+	notWhitelistedErr *RPCErr
+	txHandler         TxHandler
+	clientConnMu      sync.Mutex
 }
 
-func NewWSProxier(backend *Backend, clientConn, backendConn *websocket.Conn, methodWhitelist *StringSet) *WSProxier {
+func NewWSProxier(backend *Backend, clientConn, backendConn *websocket.Conn, methodWhitelist *StringSet, notWhitelistedErr *RPCErr, txHandler TxHandler) *WSProxier {
 	return &WSProxier{
-		backend:         backend,
-		clientConn:      clientConn,
-		backendConn:     backendConn,
-		methodWhitelist: methodWhitelist,
+		backend:           backend,
+		clientConn:        clientConn,
+		backendConn:       backendConn,
+		methodWhitelist:   methodWhitelist,
+		notWhitelistedErr: notWhitelistedErr,
+		txHandler:         txHandler,
 	}
 }
 
@@ -906,7 +937,7 @@ func (w *WSProxier) clientPump(ctx context.Context, errC chan error) {
 
 		// Don't bother sending invalid requests to the backend,
 		// just handle them here.
-		req, err := w.prepareClientMsg(msg)
+		req, err := w.prepareClientMsg(ctx, msg)
 		if err != nil {
 			var id json.RawMessage
 			method := MethodUnknown
@@ -944,6 +975,19 @@ func (w *WSProxier) clientPump(ctx context.Context, errC chan error) {
 			continue
 		}
 
+		// Transactions go through the send pipeline of HTTP calls. Unless they are broadcast,
+		// they are sent to the backend of the connection over HTTP, to get the response here.
+		if req.Method == "eth_sendRawTransaction" && w.txHandler != nil {
+			RecordRPCForward(ctx, w.backend.Name, req.Method, RPCRequestSourceWS)
+			msg = mustMarshalJSON(w.txHandler(ctx, req, w.backend.Forward))
+			err = w.writeClientConn(msgType, msg)
+			if err != nil {
+				errC <- err
+				return
+			}
+			continue
+		}
+
 		RecordRPCForward(ctx, w.backend.Name, req.Method, RPCRequestSourceWS)
 		log.Info(
 			"forwarded WS message to backend",
@@ -1026,14 +1070,30 @@ func (w *WSProxier) close() {
 	activeBackendWsConnsGauge.WithLabelValues(w.backend.Name).Dec()
 }
 
-func (w *WSProxier) prepareClientMsg(msg []byte) (*RPCReq, error) {
+func (w *WSProxier) prepareClientMsg(ctx context.Context, msg []byte) (*RPCReq, error) {
+	return prepareWSClientMsg(ctx, msg, w.methodWhitelist, w.notWhitelistedErr)
+}
+
+// prepareWSClientMsg parses a websocket client message, and checks that the method
+// is whitelisted and allowed by the API key of the client. Rejected methods get
+// notWhitelistedErr, which carries the configured error message.
+func prepareWSClientMsg(ctx context.Context, msg []byte, methodWhitelist *StringSet, notWhitelistedErr *RPCErr) (*RPCReq, error) {
 	req, err := ParseRPCReq(msg)
 	if err != nil {
 		return nil, err
 	}
 
-	if !w.methodWhitelist.Has(req.Method) {
-		return req, ErrMethodNotWhitelisted
+	if !methodWhitelist.Has(req.Method) {
+		return req, notWhitelistedErr
+	}
+
+	if apiKey := GetAPIKey(ctx); apiKey != nil {
+		if !apiKey.Tier.AllowsMethod(req.Method) {
+			return req, notWhitelistedErr
+		}
+		if err := apiKey.Take(ctx, req.Method); err != nil {
+			return req, err
+		}
 	}
 
 	return req, nil
@@ -23,36 +23,65 @@ const (
 	memoryCacheLimit = 4096
 	// Set a large ttl to avoid expirations. However, a ttl must be set for volatile-lru to take effect.
 	redisTTL = 30 * 7 * 24 * time.Hour
+	// Responses of block-number-based calls expire sooner, as they are
+	// only immutable as long as the finalized chain does not change.
+	blockNumberCacheTTL = time.Hour
 )
 
+type memoryCacheEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
 type cache struct {
 	lru *lru.Cache
+	ttl time.Duration
 }
 
 func newMemoryCache() *cache {
-	rep, _ := lru.New(memoryCacheLimit)
-	return &cache{rep}
+	return newMemoryCacheWithLimits(memoryCacheLimit, 0)
+}
+
+// newMemoryCacheWithLimits creates an in-memory LRU cache of at most maxEntries
+// entries, which expire after the ttl. Entries do not expire if the ttl is 0.
+func newMemoryCacheWithLimits(maxEntries int, ttl time.Duration) *cache {
+	rep, _ := lru.New(maxEntries)
+	return &cache{rep, ttl}
 }
 
 func (c *cache) Get(ctx context.Context, key string) (string, error) {
 	if val, ok := c.lru.Get(key); ok {
-		return val.(string), nil
+		entry := val.(memoryCacheEntry)
+		if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+			c.lru.Remove(key)
+			return "", nil
+		}
+		return entry.value, nil
 	}
 	return "", nil
 }
 
 func (c *cache) Put(ctx context.Context, key string, value string) error {
-	c.lru.Add(key, value)
+	entry := memoryCacheEntry{value: value}
+	if c.ttl > 0 {
+		entry.expiresAt = time.Now().Add(c.ttl)
+	}
+	c.lru.Add(key, entry)
 	return nil
 }
 
 type redisCache struct {
 	rdb    *redis.Client
 	prefix string
+	ttl    time.Duration
 }
 
 func newRedisCache(rdb *redis.Client, prefix string) *redisCache {
-	return &redisCache{rdb, prefix}
+	return newRedisCacheWithTTL(rdb, prefix, redisTTL)
+}
+
+func newRedisCacheWithTTL(rdb *redis.Client, prefix string, ttl time.Duration) *redisCache {
+	return &redisCache{rdb, prefix, ttl}
 }
 
 func (c *redisCache) namespaced(key string) string {
@@ -78,7 +107,7 @@ func (c *redisCache) Get(ctx context.Context, key string) (string, error) {
 
 func (c *redisCache) Put(ctx context.Context, key string, value string) error {
 	start := time.Now()
-	err := c.rdb.SetEX(ctx, c.namespaced(key), value, redisTTL).Err()
+	err := c.rdb.SetEX(ctx, c.namespaced(key), value, c.ttl).Err()
 	redisCacheDurationSumm.WithLabelValues("SETEX").Observe(float64(time.Since(start).Milliseconds()))
 
 	if err != nil {
@@ -115,6 +144,28 @@ func (c *cacheWithCompression) Put(ctx context.Context, key string, value string
 	return c.cache.Put(ctx, key, string(encodedVal))
 }
 
+// cacheWithMaxEntrySize skips values larger than the max entry size,
+// e.g. the results of eth_getLogs calls over large block ranges.
+type cacheWithMaxEntrySize struct {
+	cache   Cache
+	maxSize int
+}
+
+func newCacheWithMaxEntrySize(cache Cache, maxSize int) *cacheWithMaxEntrySize {
+	return &cacheWithMaxEntrySize{cache, maxSize}
+}
+
+func (c *cacheWithMaxEntrySize) Get(ctx context.Context, key string) (string, error) {
+	return c.cache.Get(ctx, key)
+}
+
+func (c *cacheWithMaxEntrySize) Put(ctx context.Context, key string, value string) error {
+	if len(value) > c.maxSize {
+		return nil
+	}
+	return c.cache.Put(ctx, key, value)
+}
+
 type RPCCache interface {
 	GetRPC(ctx context.Context, req *RPCReq) (*RPCRes, error)
 	PutRPC(ctx context.Context, req *RPCReq, res *RPCRes) error
@@ -125,7 +176,15 @@ type rpcCache struct {
 	handlers map[string]RPCMethodHandler
 }
 
+// newRPCCache creates a cache of immutable, hash-addressed calls.
 func newRPCCache(cache Cache) RPCCache {
+	return newRPCCacheWithBlockNumbers(cache, nil, nil)
+}
+
+// newRPCCacheWithBlockNumbers creates a cache of immutable, hash-addressed calls,
+// and of block-number-based calls at finalized blocks if blockCache is not nil.
+// The generations of the block number cache are kept in the given store.
+func newRPCCacheWithBlockNumbers(cache Cache, blockCache Cache, generations cacheGenerationStore) RPCCache {
 	staticHandler := &StaticMethodHandler{cache: cache}
 	debugGetRawReceiptsHandler := &StaticMethodHandler{cache: cache,
 		filter: func(req *RPCReq) bool {
@@ -152,6 +211,26 @@ func newRPCCache(cache Cache) RPCCache {
 		"eth_getUncleByBlockHashAndIndex":       staticHandler,
 		"debug_getRawReceipts":                  debugGetRawReceiptsHandler,
 	}
+	if blockCache != nil {
+		generation := newCacheGeneration(generations)
+		blockParamHandler := func(pos int) RPCMethodHandler {
+			return &BlockNumberMethodHandler{
+				cache:      blockCache,
+				generation: generation,
+				blockNumber: func(req *RPCReq) (uint64, bool) {
+					return blockNumberParam(req, pos)
+				},
+			}
+		}
+		for method, pos := range blockNumberParamPositions {
+			handlers[method] = blockParamHandler(pos)
+		}
+		handlers["eth_getLogs"] = &BlockNumberMethodHandler{
+			cache:       blockCache,
+			generation:  generation,
+			blockNumber: logsFilterToBlock,
+		}
+	}
 	return &rpcCache{
 		cache:    cache,
 		handlers: handlers,
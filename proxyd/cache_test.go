@@ -2,9 +2,13 @@ package proxyd
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/require"
 )
 
@@ -211,3 +215,167 @@ func TestRPCCacheUnsupportedMethod(t *testing.T) {
 	}
 
 }
+
+func TestRPCCacheBlockNumbers(t *testing.T) {
+	cache := newRPCCacheWithBlockNumbers(newMemoryCache(), newMemoryCache(), newMemoryCacheGenerations())
+	ID := []byte(strconv.Itoa(1))
+	finalizedCtx := func(number uint64) context.Context {
+		return context.WithValue(context.Background(), ContextKeyFinalizedBlock, finalizedBlock{"main", number})
+	}
+	ctx := finalizedCtx(100)
+
+	rpcs := []struct {
+		name      string
+		method    string
+		params    interface{}
+		cacheable bool
+	}{
+		{"block by finalized number", "eth_getBlockByNumber", []interface{}{"0x64", false}, true},
+		{"block by unfinalized number", "eth_getBlockByNumber", []interface{}{"0x65", false}, false},
+		{"block by tag", "eth_getBlockByNumber", []interface{}{"finalized", false}, false},
+		{"block by earliest", "eth_getBlockByNumber", []interface{}{"earliest", false}, true},
+		{"call at finalized number", "eth_call", []interface{}{map[string]string{"to": "0x0000000000000000000000000000000000000001"}, "0x10"}, true},
+		{"call at latest", "eth_call", []interface{}{map[string]string{"to": "0x0000000000000000000000000000000000000001"}, "latest"}, false},
+		{"call without block", "eth_call", []interface{}{map[string]string{"to": "0x0000000000000000000000000000000000000001"}}, false},
+		{"call at block hash", "eth_call", []interface{}{map[string]string{"to": "0x0000000000000000000000000000000000000001"}, map[string]string{"blockHash": "0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b"}}, false},
+		{"storage at finalized number", "eth_getStorageAt", []interface{}{"0x0000000000000000000000000000000000000001", "0x0", "0x10"}, true},
+		{"logs in finalized range", "eth_getLogs", []interface{}{map[string]string{"fromBlock": "0x1", "toBlock": "0x64"}}, true},
+		{"logs in unfinalized range", "eth_getLogs", []interface{}{map[string]string{"fromBlock": "0x1", "toBlock": "0x65"}}, false},
+		{"logs to latest", "eth_getLogs", []interface{}{map[string]string{"fromBlock": "0x1"}}, false},
+		{"logs from tag", "eth_getLogs", []interface{}{map[string]string{"fromBlock": "safe", "toBlock": "0x10"}}, false},
+		{"logs by block hash", "eth_getLogs", []interface{}{map[string]string{"blockHash": "0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b"}}, false},
+	}
+
+	for _, rpc := range rpcs {
+		t.Run(rpc.name, func(t *testing.T) {
+			req := &RPCReq{
+				JSONRPC: "2.0",
+				Method:  rpc.method,
+				Params:  mustMarshalJSON(rpc.params),
+				ID:      ID,
+			}
+			res := &RPCRes{JSONRPC: "2.0", Result: rpc.name, ID: ID}
+			require.NoError(t, cache.PutRPC(ctx, req, res))
+
+			cachedRes, err := cache.GetRPC(ctx, req)
+			require.NoError(t, err)
+			if rpc.cacheable {
+				require.Equal(t, res, cachedRes)
+			} else {
+				require.Nil(t, cachedRes)
+			}
+
+			// block-number-based calls are never cached without a finalized block
+			cachedRes, err = cache.GetRPC(context.Background(), req)
+			require.NoError(t, err)
+			require.Nil(t, cachedRes)
+		})
+	}
+
+	t.Run("finalized block going backwards invalidates the cache", func(t *testing.T) {
+		req := &RPCReq{
+			JSONRPC: "2.0",
+			Method:  "eth_getBlockByNumber",
+			Params:  mustMarshalJSON([]interface{}{"0x10", false}),
+			ID:      ID,
+		}
+		res := &RPCRes{JSONRPC: "2.0", Result: "block", ID: ID}
+		otherCtx := context.WithValue(context.Background(), ContextKeyFinalizedBlock, finalizedBlock{"other", 50})
+		require.NoError(t, cache.PutRPC(ctx, req, res))
+		require.NoError(t, cache.PutRPC(otherCtx, req, res))
+
+		cachedRes, err := cache.GetRPC(finalizedCtx(99), req)
+		require.NoError(t, err)
+		require.Nil(t, cachedRes)
+
+		// other backend groups are not invalidated
+		cachedRes, err = cache.GetRPC(otherCtx, req)
+		require.NoError(t, err)
+		require.Equal(t, res, cachedRes)
+
+		require.NoError(t, cache.PutRPC(finalizedCtx(99), req, res))
+		cachedRes, err = cache.GetRPC(finalizedCtx(120), req)
+		require.NoError(t, err)
+		require.Equal(t, res, cachedRes)
+	})
+}
+
+func TestRPCCacheBlockNumbersSharedGeneration(t *testing.T) {
+	redisServer, err := miniredis.Run()
+	require.NoError(t, err)
+	defer redisServer.Close()
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: fmt.Sprintf("127.0.0.1:%s", redisServer.Port()),
+	})
+
+	// two proxyd instances sharing the cache
+	blockCache := newRedisCache(redisClient, "proxyd")
+	instanceA := newRPCCacheWithBlockNumbers(newMemoryCache(), blockCache, newRedisCacheGenerations(redisClient, "proxyd"))
+	instanceB := newRPCCacheWithBlockNumbers(newMemoryCache(), blockCache, newRedisCacheGenerations(redisClient, "proxyd"))
+
+	finalizedCtx := func(number uint64) context.Context {
+		return context.WithValue(context.Background(), ContextKeyFinalizedBlock, finalizedBlock{"main", number})
+	}
+	ID := []byte(strconv.Itoa(1))
+	req := &RPCReq{
+		JSONRPC: "2.0",
+		Method:  "eth_getBlockByNumber",
+		Params:  mustMarshalJSON([]interface{}{"0x10", false}),
+		ID:      ID,
+	}
+	res := &RPCRes{JSONRPC: "2.0", Result: "block", ID: ID}
+
+	require.NoError(t, instanceA.PutRPC(finalizedCtx(100), req, res))
+	cachedRes, err := instanceB.GetRPC(finalizedCtx(100), req)
+	require.NoError(t, err)
+	require.Equal(t, res, cachedRes)
+
+	// A observes the finalized block going backwards, which invalidates the cache of B too
+	cachedRes, err = instanceA.GetRPC(finalizedCtx(99), req)
+	require.NoError(t, err)
+	require.Nil(t, cachedRes)
+	cachedRes, err = instanceB.GetRPC(finalizedCtx(100), req)
+	require.NoError(t, err)
+	require.Nil(t, cachedRes)
+}
+
+func TestCacheLimits(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ttl", func(t *testing.T) {
+		cache := newMemoryCacheWithLimits(10, 50*time.Millisecond)
+		require.NoError(t, cache.Put(ctx, "foo", "bar"))
+		val, err := cache.Get(ctx, "foo")
+		require.NoError(t, err)
+		require.Equal(t, "bar", val)
+		time.Sleep(100 * time.Millisecond)
+		val, err = cache.Get(ctx, "foo")
+		require.NoError(t, err)
+		require.Equal(t, "", val)
+	})
+
+	t.Run("max entries", func(t *testing.T) {
+		cache := newMemoryCacheWithLimits(2, 0)
+		for _, key := range []string{"a", "b", "c"} {
+			require.NoError(t, cache.Put(ctx, key, key))
+		}
+		val, err := cache.Get(ctx, "a")
+		require.NoError(t, err)
+		require.Equal(t, "", val)
+		val, err = cache.Get(ctx, "c")
+		require.NoError(t, err)
+		require.Equal(t, "c", val)
+	})
+
+	t.Run("max entry size", func(t *testing.T) {
+		cache := newCacheWithMaxEntrySize(newMemoryCache(), 4)
+		require.NoError(t, cache.Put(ctx, "small", "1234"))
+		require.NoError(t, cache.Put(ctx, "large", "12345"))
+		val, err := cache.Get(ctx, "small")
+		require.NoError(t, err)
+		require.Equal(t, "1234", val)
+		val, err = cache.Get(ctx, "large")
+		require.NoError(t, err)
+		require.Equal(t, "", val)
+	})
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/ethereum-optimism/optimism/proxyd"
@@ -37,7 +39,41 @@ func main() {
 		log.Crit("error reading config file", "err", err)
 	}
 
-	// update log level from config
+	setLogLevel(config)
+
+	srv, shutdown, err := proxyd.Start(config)
+	if err != nil {
+		log.Crit("error starting proxyd", "err", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if config.Server.WatchConfigSeconds > 0 {
+		interval := time.Duration(config.Server.WatchConfigSeconds) * time.Second
+		go proxyd.WatchConfigFile(ctx, srv, os.Args[1], interval, setLogLevel)
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	for recvSig := range sig {
+		if recvSig == syscall.SIGHUP {
+			log.Info("caught SIGHUP, reloading config")
+			config, err := proxyd.ReloadConfigFile(srv, os.Args[1])
+			if err != nil {
+				log.Error("error reloading config, keeping the running config", "err", err)
+				continue
+			}
+			setLogLevel(config)
+			continue
+		}
+		log.Info("caught signal, shutting down", "signal", recvSig)
+		break
+	}
+	cancel()
+	shutdown()
+}
+
+// setLogLevel updates the log level from the config.
+func setLogLevel(config *proxyd.Config) {
 	logLevel, err := log.LvlFromString(config.Server.LogLevel)
 	if err != nil {
 		logLevel = log.LvlInfo
@@ -51,15 +87,4 @@ func main() {
 			log.StreamHandler(os.Stdout, log.JSONFormat()),
 		),
 	)
-
-	_, shutdown, err := proxyd.Start(config)
-	if err != nil {
-		log.Crit("error starting proxyd", "err", err)
-	}
-
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
-	recvSig := <-sig
-	log.Info("caught signal, shutting down", "signal", recvSig)
-	shutdown()
 }
@@ -23,10 +23,33 @@ type ServerConfig struct {
 
 	EnableRequestLog     bool `toml:"enable_request_log"`
 	MaxRequestBodyLogLen int  `toml:"max_request_body_log_len"`
+
+	// AdminToken enables the admin endpoints of the RPC server, which require
+	// it as bearer token. It will be read from the environment if prefixed with $.
+	AdminToken string `toml:"admin_token"`
+
+	// WatchConfigSeconds is the interval at which the config file is checked for changes,
+	// which are then reloaded. The config is only reloaded on SIGHUP if it is 0.
+	WatchConfigSeconds int `toml:"watch_config_seconds"`
 }
 
 type CacheConfig struct {
 	Enabled bool `toml:"enabled"`
+
+	// TTL of cached immutable, hash-addressed calls.
+	TTL TOMLDuration `toml:"ttl"`
+	// MaxEntries bounds the number of cached immutable calls in memory.
+	MaxEntries int `toml:"max_entries"`
+	// MaxEntrySizeBytes bounds the size of cached results. Larger results are not cached.
+	MaxEntrySizeBytes int `toml:"max_entry_size_bytes"`
+
+	// BlockNumbers enables caching of block-number-based calls at blocks that are finalized,
+	// according to the consensus of consensus aware backend groups.
+	BlockNumbers bool `toml:"block_numbers"`
+	// BlockNumberTTL is the TTL of cached block-number-based calls.
+	BlockNumberTTL TOMLDuration `toml:"block_number_ttl"`
+	// BlockNumberMaxEntries bounds the number of cached block-number-based calls in memory.
+	BlockNumberMaxEntries int `toml:"block_number_max_entries"`
 }
 
 type RedisConfig struct {
@@ -107,6 +130,9 @@ type BackendGroupConfig struct {
 	ConsensusMaxUpdateThreshold TOMLDuration `toml:"consensus_max_update_threshold"`
 	ConsensusMaxBlockLag        uint64       `toml:"consensus_max_block_lag"`
 	ConsensusMinPeerCount       int          `toml:"consensus_min_peer_count"`
+
+	ShadowGroup      string  `toml:"shadow_group"`
+	ShadowSampleRate float64 `toml:"shadow_sample_rate"`
 }
 
 type BackendGroupsConfig map[string]*BackendGroupConfig
@@ -126,6 +152,52 @@ type SenderRateLimitConfig struct {
 	Limit    int
 }
 
+// WSSubscriptionsConfig configures the termination of websocket subscriptions in proxyd.
+// When enabled, client connections are not pinned to a backend: newHeads and logs
+// subscriptions are served from a single upstream subscription of the ws backend group,
+// and all other requests are forwarded to the group like HTTP requests.
+type WSSubscriptionsConfig struct {
+	Enabled    bool `toml:"enabled"`
+	MaxPerConn int  `toml:"max_per_conn"`
+}
+
+// TxForwardingConfig configures the checks and forwarding of eth_sendRawTransaction calls.
+// Transactions are forwarded to the mapped backend group, or to all broadcast backends
+// in parallel if any are configured.
+type TxForwardingConfig struct {
+	MaxSizeBytes      int      `toml:"max_size_bytes"`
+	ChainID           uint64   `toml:"chain_id"`
+	MinTipWei         uint64   `toml:"min_tip_wei"`
+	DenySenders       []string `toml:"deny_senders"`
+	DenyRecipients    []string `toml:"deny_recipients"`
+	BroadcastBackends []string `toml:"broadcast_backends"`
+	LogAccepted       bool     `toml:"log_accepted"`
+}
+
+// APIKeyConfig configures an API key. The key is the secret path segment
+// that authenticates requests, and is read from the environment if prefixed with $.
+type APIKeyConfig struct {
+	Key  string `toml:"key"`
+	Tier string `toml:"tier"`
+}
+
+type APIKeysConfig map[string]*APIKeyConfig
+
+// APIKeyTierConfig configures the quotas, method allowlist and
+// backend group routing of the API keys of a tier.
+type APIKeyTierConfig struct {
+	RequestLimit        int               `toml:"request_limit"`
+	RequestInterval     TOMLDuration      `toml:"request_interval"`
+	ComputeUnitLimit    int               `toml:"compute_unit_limit"`
+	ComputeUnitInterval TOMLDuration      `toml:"compute_unit_interval"`
+	DefaultComputeUnits int               `toml:"default_compute_units"`
+	ComputeUnits        map[string]int    `toml:"compute_units"`
+	AllowedMethods      []string          `toml:"allowed_methods"`
+	RPCMethodMappings   map[string]string `toml:"rpc_method_mappings"`
+}
+
+type APIKeyTiersConfig map[string]*APIKeyTierConfig
+
 type Config struct {
 	WSBackendGroup        string                `toml:"ws_backend_group"`
 	Server                ServerConfig          `toml:"server"`
@@ -137,11 +209,15 @@ type Config struct {
 	Backends              BackendsConfig        `toml:"backends"`
 	BatchConfig           BatchConfig           `toml:"batch"`
 	Authentication        map[string]string     `toml:"authentication"`
+	APIKeys               APIKeysConfig         `toml:"api_keys"`
+	APIKeyTiers           APIKeyTiersConfig     `toml:"api_key_tiers"`
 	BackendGroups         BackendGroupsConfig   `toml:"backend_groups"`
 	RPCMethodMappings     map[string]string     `toml:"rpc_method_mappings"`
 	WSMethodWhitelist     []string              `toml:"ws_method_whitelist"`
+	WSSubscriptions       WSSubscriptionsConfig `toml:"ws_subscriptions"`
 	WhitelistErrorMessage string                `toml:"whitelist_error_message"`
 	SenderRateLimit       SenderRateLimitConfig `toml:"sender_rate_limit"`
+	TxForwarding          TxForwardingConfig    `toml:"tx_forwarding"`
 }
 
 func ReadFromEnvOrConfig(value string) (string, error) {
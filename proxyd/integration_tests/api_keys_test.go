@@ -0,0 +1,141 @@
+package integration_tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	overAPIKeyQuotaResponse      = `{"jsonrpc":"2.0","error":{"code":-32020,"message":"api key is over quota"},"id":999}`
+	apiKeyNotWhitelistedResponse = `{"jsonrpc":"2.0","error":{"code":-32001,"message":"rpc method is not whitelisted"},"id":999}`
+)
+
+func TestAPIKeys(t *testing.T) {
+	goodBackend := NewMockBackend(BatchedResponseHandler(200, goodResponse))
+	defer goodBackend.Close()
+	archiveBackend := NewMockBackend(BatchedResponseHandler(200, goodResponse))
+	defer archiveBackend.Close()
+
+	require.NoError(t, os.Setenv("GOOD_BACKEND_RPC_URL", goodBackend.URL()))
+	require.NoError(t, os.Setenv("ARCHIVE_BACKEND_RPC_URL", archiveBackend.URL()))
+	require.NoError(t, os.Setenv("PARTNER_A_API_KEY", "partner_secret"))
+	require.NoError(t, os.Setenv("PROXYD_ADMIN_TOKEN", "admin_secret"))
+
+	config := ReadConfig("api_keys")
+	_, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	freeClient := NewProxydClient("http://127.0.0.1:8545/free_secret")
+	partnerClient := NewProxydClient("http://127.0.0.1:8545/partner_secret")
+	legacyClient := NewProxydClient("http://127.0.0.1:8545/legacy_secret")
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, code, err := NewProxydClient("http://127.0.0.1:8545/unknown").SendRPC(ethChainID, nil)
+		require.NoError(t, err)
+		require.Equal(t, 401, code)
+	})
+
+	t.Run("method not in tier allowlist", func(t *testing.T) {
+		res, code, err := freeClient.SendRPC("eth_getLogs", nil)
+		require.NoError(t, err)
+		require.Equal(t, 403, code)
+		RequireEqualJSON(t, []byte(apiKeyNotWhitelistedResponse), res)
+	})
+
+	t.Run("request quota", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, code, err := freeClient.SendRPC(ethChainID, nil)
+			require.NoError(t, err)
+			require.Equal(t, 200, code)
+		}
+		res, code, err := freeClient.SendRPC("eth_call", nil)
+		require.NoError(t, err)
+		require.Equal(t, 429, code)
+		RequireEqualJSON(t, []byte(overAPIKeyQuotaResponse), res)
+
+		// other keys are not affected
+		_, code, err = legacyClient.SendRPC(ethChainID, nil)
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+	})
+
+	t.Run("tier method mappings", func(t *testing.T) {
+		goodBackend.Reset()
+		archiveBackend.Reset()
+
+		_, code, err := partnerClient.SendRPC("debug_traceTransaction", nil)
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		require.Len(t, archiveBackend.Requests(), 1)
+		require.Len(t, goodBackend.Requests(), 0)
+
+		// keys of other tiers still use the default mappings
+		res, code, err := legacyClient.SendRPC("debug_traceTransaction", nil)
+		require.NoError(t, err)
+		require.Equal(t, 403, code)
+		RequireEqualJSON(t, []byte(apiKeyNotWhitelistedResponse), res)
+	})
+
+	t.Run("compute unit quota in batch", func(t *testing.T) {
+		// the trace above consumed all 20 compute units
+		out, code, err := partnerClient.SendBatchRPC(
+			NewRPCReq("1", ethChainID, nil),
+			NewRPCReq("2", "eth_getLogs", nil),
+		)
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		var res []proxyd.RPCRes
+		require.NoError(t, json.Unmarshal(out, &res))
+		require.Len(t, res, 2)
+		require.Equal(t, proxyd.ErrOverAPIKeyQuota.Code, res[0].Error.Code)
+		require.Equal(t, proxyd.ErrOverAPIKeyQuota.Code, res[1].Error.Code)
+	})
+
+	t.Run("admin usage endpoint", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "http://127.0.0.1:8545/admin/api_keys", nil)
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, 401, res.StatusCode)
+
+		req.Header.Set("Authorization", "Bearer admin_secret")
+		res, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, 200, res.StatusCode)
+		var usage []proxyd.APIKeyUsage
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&usage))
+		require.Len(t, usage, 2)
+
+		require.Equal(t, "free_user", usage[0].Alias)
+		require.Equal(t, "free", usage[0].Tier)
+		require.Equal(t, 2, usage[0].Requests.Used)
+		require.Equal(t, 2, usage[0].Requests.Limit)
+		require.Equal(t, "1m0s", usage[0].Requests.Interval)
+		require.Nil(t, usage[0].ComputeUnits)
+		require.Equal(t, uint64(2), usage[0].TotalRequests)
+		require.Equal(t, uint64(1), usage[0].TotalRejected)
+
+		require.Equal(t, "partner_a", usage[1].Alias)
+		require.Nil(t, usage[1].Requests)
+		require.Equal(t, 20, usage[1].ComputeUnits.Used)
+		require.Equal(t, uint64(1), usage[1].TotalRequests)
+		require.Equal(t, uint64(20), usage[1].TotalComputeUnits)
+		require.Equal(t, uint64(2), usage[1].TotalRejected)
+
+		req, err = http.NewRequest("GET", "http://127.0.0.1:8545/admin/api_keys/unknown", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer admin_secret")
+		res, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, 404, res.StatusCode)
+	})
+}
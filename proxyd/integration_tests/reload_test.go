@@ -0,0 +1,109 @@
+package integration_tests
+
+import (
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	a := NewMockBackend(SingleResponseHandler(200, `{"id": 999, "jsonrpc": "2.0", "result": "0xa"}`))
+	defer a.Close()
+	b := NewMockBackend(SingleResponseHandler(200, `{"id": 999, "jsonrpc": "2.0", "result": "0xb"}`))
+	defer b.Close()
+	wsBackend := NewMockWSBackend(nil, nil, nil)
+	defer wsBackend.Close()
+
+	require.NoError(t, os.Setenv("A_BACKEND_RPC_URL", a.URL()))
+	require.NoError(t, os.Setenv("A_BACKEND_WS_URL", wsBackend.URL()))
+	require.NoError(t, os.Setenv("B_BACKEND_RPC_URL", b.URL()))
+
+	config := ReadConfig("reload")
+	client := NewProxydClient("http://127.0.0.1:8545")
+	srv, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	var wsClosed int32
+	wsMsgs := make(chan []byte, 1)
+	wsClient, err := NewProxydWSClient("ws://127.0.0.1:8546", func(_ int, msg []byte) {
+		wsMsgs <- msg
+	}, func(err error) {
+		atomic.StoreInt32(&wsClosed, 1)
+	})
+	require.NoError(t, err)
+	defer wsClient.HardClose()
+
+	requireChainID := func(expected string) {
+		res, _, err := client.SendRPC("eth_chainId", nil)
+		require.NoError(t, err)
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","result":"`+expected+`","id":999}`), res)
+	}
+	requireChainID("0xa")
+
+	t.Run("new method mapping is applied", func(t *testing.T) {
+		next := ReadConfig("reload")
+		next.RPCMethodMappings["eth_chainId"] = "other"
+		require.NoError(t, srv.Reload(next))
+		requireChainID("0xb")
+
+		// unchanged groups are kept, with their backends and state
+		require.Same(t, srv.BackendGroups["main"], srv.Current().BackendGroups["main"])
+		require.Same(t, srv.BackendGroups["other"], srv.Current().BackendGroups["other"])
+	})
+
+	t.Run("changed backends replace their groups", func(t *testing.T) {
+		require.NoError(t, os.Setenv("B_BACKEND_RPC_URL", a.URL()))
+		defer func() {
+			require.NoError(t, os.Setenv("B_BACKEND_RPC_URL", b.URL()))
+		}()
+		next := ReadConfig("reload")
+		next.RPCMethodMappings["eth_chainId"] = "other"
+		require.NoError(t, srv.Reload(next))
+		requireChainID("0xa")
+
+		require.Same(t, srv.BackendGroups["main"], srv.Current().BackendGroups["main"])
+		require.NotSame(t, srv.BackendGroups["other"], srv.Current().BackendGroups["other"])
+	})
+
+	t.Run("invalid config is rejected", func(t *testing.T) {
+		next := ReadConfig("reload")
+		next.RPCMethodMappings["eth_chainId"] = "undefined"
+		require.Error(t, srv.Reload(next))
+		requireChainID("0xa")
+	})
+
+	t.Run("listener changes are rejected", func(t *testing.T) {
+		next := ReadConfig("reload")
+		next.Server.RPCPort = 8547
+		require.Error(t, srv.Reload(next))
+		requireChainID("0xa")
+	})
+
+	t.Run("custom error messages follow the config", func(t *testing.T) {
+		requireNotWhitelisted := func(expected string) {
+			res, code, err := client.SendRPC("eth_syncing", nil)
+			require.NoError(t, err)
+			require.Equal(t, 403, code)
+			RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","error":{"code":-32001,"message":"`+expected+`"},"id":999}`), res)
+		}
+
+		next := ReadConfig("reload")
+		next.WhitelistErrorMessage = "custom message"
+		require.NoError(t, srv.Reload(next))
+		requireNotWhitelisted("custom message")
+
+		require.NoError(t, srv.Reload(ReadConfig("reload")))
+		requireNotWhitelisted("rpc method is not whitelisted")
+	})
+
+	t.Run("websocket connections survive reloads", func(t *testing.T) {
+		require.Zero(t, atomic.LoadInt32(&wsClosed))
+		require.NoError(t, wsClient.WriteMessage(websocket.TextMessage, []byte(`{"id": 1, "jsonrpc": "2.0", "method": "eth_accounts", "params": []}`)))
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","result":[],"id":1}`), <-wsMsgs)
+	})
+}
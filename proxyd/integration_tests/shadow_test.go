@@ -0,0 +1,78 @@
+package integration_tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func shadowMismatches(t *testing.T, method string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "proxyd_shadow_mismatches_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["backend_group_name"] == "main" && labels["method"] == method {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestShadowTraffic(t *testing.T) {
+	primaryBackend := NewMockBackend(BatchedResponseHandler(200, `{"jsonrpc":"2.0","id":999,"result":{"number":"0x1","hash":"0x1"}}`))
+	defer primaryBackend.Close()
+	shadowBackend := NewMockBackend(BatchedResponseHandler(200, `{"jsonrpc":"2.0","id":999,"result":{"hash":"0x1","number":"0x1"}}`))
+	defer shadowBackend.Close()
+
+	require.NoError(t, os.Setenv("PRIMARY_BACKEND_RPC_URL", primaryBackend.URL()))
+	require.NoError(t, os.Setenv("SHADOW_BACKEND_RPC_URL", shadowBackend.URL()))
+
+	config := ReadConfig("shadow")
+	client := NewProxydClient("http://127.0.0.1:8545")
+	_, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	t.Run("results that only differ in field order match", func(t *testing.T) {
+		res, code, err := client.SendRPC("eth_getBlockByNumber", []interface{}{"0x1", false})
+		require.NoError(t, err)
+		require.Equal(t, 200, code)
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","id":999,"result":{"number":"0x1","hash":"0x1"}}`), res)
+		require.Eventually(t, func() bool {
+			return len(shadowBackend.Requests()) == 1
+		}, time.Second, 10*time.Millisecond)
+		require.Equal(t, float64(0), shadowMismatches(t, "eth_getBlockByNumber"))
+	})
+
+	t.Run("mismatches are recorded and not returned", func(t *testing.T) {
+		shadowBackend.SetHandler(BatchedResponseHandler(200, `{"jsonrpc":"2.0","id":999,"result":"0x2"}`))
+		primaryBackend.SetHandler(BatchedResponseHandler(200, `{"jsonrpc":"2.0","id":999,"result":"0x1"}`))
+		res, _, err := client.SendRPC("eth_chainId", nil)
+		require.NoError(t, err)
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","id":999,"result":"0x1"}`), res)
+		require.Eventually(t, func() bool {
+			return shadowMismatches(t, "eth_chainId") == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("writes are not mirrored", func(t *testing.T) {
+		shadowBackend.Reset()
+		_, _, err := client.SendRPC("eth_sendRawTransaction", []interface{}{"0x00"})
+		require.NoError(t, err)
+		require.Len(t, primaryBackend.Requests(), 3)
+		time.Sleep(100 * time.Millisecond)
+		require.Empty(t, shadowBackend.Requests())
+	})
+}
@@ -0,0 +1,182 @@
+package integration_tests
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+const txAcceptedRes = `{"id": 999, "jsonrpc": "2.0", "result": "0x1234"}`
+
+const txFailedRes = `{"id": 999, "jsonrpc": "2.0", "error": {"code": -32000, "message": "nonce too low"}}`
+
+func signTestTx(t *testing.T, key *ecdsa.PrivateKey, chainID int64, tip int64, to common.Address, data []byte) string {
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(chainID),
+		Nonce:     1,
+		GasTipCap: big.NewInt(tip),
+		GasFeeCap: big.NewInt(1_000_000),
+		Gas:       100_000,
+		To:        &to,
+		Data:      data,
+	})
+	signer := types.LatestSignerForChainID(big.NewInt(chainID))
+	signed, err := types.SignTx(tx, signer, key)
+	require.NoError(t, err)
+	raw, err := signed.MarshalBinary()
+	require.NoError(t, err)
+	return hexutil.Encode(raw)
+}
+
+func TestTxForwarding(t *testing.T) {
+	sequencer := NewMockBackend(SingleResponseHandler(200, txAcceptedRes))
+	defer sequencer.Close()
+	replica := NewMockBackend(SingleResponseHandler(200, txAcceptedRes))
+	defer replica.Close()
+
+	require.NoError(t, os.Setenv("SEQUENCER_BACKEND_RPC_URL", sequencer.URL()))
+	require.NoError(t, os.Setenv("REPLICA_BACKEND_RPC_URL", replica.URL()))
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	deniedKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	config := ReadConfig("tx_forwarding")
+	config.TxForwarding.DenySenders = []string{crypto.PubkeyToAddress(deniedKey.PublicKey).Hex()}
+	client := NewProxydClient("http://127.0.0.1:8545")
+	_, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	to := common.HexToAddress("0x1234")
+	signTx := func(key *ecdsa.PrivateKey, chainID int64, tip int64, to common.Address, data []byte) string {
+		return signTestTx(t, key, chainID, tip, to, data)
+	}
+
+	sendTx := func(rawTx string) []byte {
+		res, _, err := client.SendRPC("eth_sendRawTransaction", []interface{}{rawTx})
+		require.NoError(t, err)
+		return res
+	}
+
+	rejected := func(msg string) []byte {
+		return []byte(fmt.Sprintf(`{"jsonrpc":"2.0","error":{"code":-32021,"message":"%s"},"id":999}`, msg))
+	}
+
+	t.Run("policy checks", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			rawTx string
+			msg   string
+		}{
+			{"wrong chain id", signTx(key, 11, 1000, to, nil), "invalid chain id"},
+			{"tip below minimum", signTx(key, 10, 999, to, nil), "transaction tip is below the minimum"},
+			{"too large", signTx(key, 10, 1000, to, make([]byte, 1024)), "transaction is too large"},
+			{"denied sender", signTx(deniedKey, 10, 1000, to, nil), "sender is not allowed"},
+			{"denied recipient", signTx(key, 10, 1000, common.HexToAddress("0xdead"), nil), "recipient is not allowed"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				RequireEqualJSON(t, rejected(tt.msg), sendTx(tt.rawTx))
+			})
+		}
+		require.Empty(t, sequencer.Requests())
+		require.Empty(t, replica.Requests())
+	})
+
+	goodTx := signTx(key, 10, 1000, to, nil)
+
+	t.Run("broadcast to all backends", func(t *testing.T) {
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","result":"0x1234","id":999}`), sendTx(goodTx))
+		require.Eventually(t, func() bool {
+			return len(sequencer.Requests()) == 1 && len(replica.Requests()) == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("first success is returned", func(t *testing.T) {
+		sequencer.SetHandler(SingleResponseHandler(200, txFailedRes))
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","result":"0x1234","id":999}`), sendTx(goodTx))
+	})
+
+	t.Run("response of the first backend is returned if all fail", func(t *testing.T) {
+		replica.SetHandler(SingleResponseHandler(503, "unavailable"))
+		RequireEqualJSON(t, []byte(`{"jsonrpc":"2.0","error":{"code":-32000,"message":"nonce too low"},"id":999}`), sendTx(goodTx))
+	})
+}
+
+func TestTxForwardingWS(t *testing.T) {
+	for _, subscriptions := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ws_subscriptions=%t", subscriptions), func(t *testing.T) {
+			sequencer := NewMockBackend(SingleResponseHandler(200, txAcceptedRes))
+			defer sequencer.Close()
+			replica := NewMockBackend(SingleResponseHandler(200, txAcceptedRes))
+			defer replica.Close()
+			// transactions must not be sent over the websocket of the backend, bypassing the pipeline
+			var wsTxs int32
+			wsBackend := NewMockWSBackend(nil, func(conn *websocket.Conn, msgType int, data []byte) {
+				atomic.AddInt32(&wsTxs, 1)
+			}, nil)
+			defer wsBackend.Close()
+
+			require.NoError(t, os.Setenv("SEQUENCER_BACKEND_RPC_URL", sequencer.URL()))
+			require.NoError(t, os.Setenv("SEQUENCER_BACKEND_WS_URL", wsBackend.URL()))
+			require.NoError(t, os.Setenv("REPLICA_BACKEND_RPC_URL", replica.URL()))
+
+			config := ReadConfig("tx_forwarding_ws")
+			config.WSSubscriptions.Enabled = subscriptions
+			_, shutdown, err := proxyd.Start(config)
+			require.NoError(t, err)
+			defer shutdown()
+
+			msgC := make(chan []byte, 10)
+			client, err := NewProxydWSClient("ws://127.0.0.1:8546", func(msgType int, data []byte) {
+				msgC <- data
+			}, nil)
+			require.NoError(t, err)
+			defer client.HardClose()
+
+			key, err := crypto.GenerateKey()
+			require.NoError(t, err)
+			sendTx := func(rawTx string) map[string]interface{} {
+				req := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["%s"]}`, rawTx)
+				require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(req)))
+				return readWSMsg(t, msgC)
+			}
+
+			res := sendTx(signTestTx(t, key, 10, 1000, common.HexToAddress("0xdead"), nil))
+			require.Equal(t, "recipient is not allowed", res["error"].(map[string]interface{})["message"])
+			require.Empty(t, sequencer.Requests())
+			require.Empty(t, replica.Requests())
+
+			res = sendTx(signTestTx(t, key, 10, 1000, common.HexToAddress("0x1234"), nil))
+			require.Equal(t, "0x1234", res["result"])
+			require.Eventually(t, func() bool {
+				return len(sequencer.Requests()) == 1 && len(replica.Requests()) == 1
+			}, time.Second, 10*time.Millisecond)
+			require.Zero(t, atomic.LoadInt32(&wsTxs))
+		})
+	}
+}
+
+func TestTxForwardingInvalidDenyList(t *testing.T) {
+	require.NoError(t, os.Setenv("SEQUENCER_BACKEND_RPC_URL", "http://127.0.0.1:1"))
+	require.NoError(t, os.Setenv("REPLICA_BACKEND_RPC_URL", "http://127.0.0.1:1"))
+
+	config := ReadConfig("tx_forwarding")
+	config.TxForwarding.DenyRecipients = []string{"0x12345678901234567890123456789012345678"}
+	_, _, err := proxyd.Start(config)
+	require.ErrorContains(t, err, "invalid address 0x12345678901234567890123456789012345678 in tx_forwarding deny_recipients")
+}
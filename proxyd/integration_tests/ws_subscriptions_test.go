@@ -0,0 +1,171 @@
+package integration_tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/proxyd"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func testBlockHash(n int) string {
+	return fmt.Sprintf("0x%064x", n)
+}
+
+func testHeader(n int) string {
+	return fmt.Sprintf(`{"number":"0x%x","hash":"%s","parentHash":"%s"}`, n, testBlockHash(n), testBlockHash(n-1))
+}
+
+func readWSMsg(t *testing.T, ch chan []byte) map[string]interface{} {
+	select {
+	case msg := <-ch:
+		var out map[string]interface{}
+		require.NoError(t, json.Unmarshal(msg, &out))
+		return out
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for ws message")
+		return nil
+	}
+}
+
+func requireNoWSMsg(t *testing.T, ch chan []byte) {
+	select {
+	case msg := <-ch:
+		t.Fatalf("unexpected ws message: %s", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func notificationResult(t *testing.T, msg map[string]interface{}) map[string]interface{} {
+	require.Equal(t, "eth_subscription", msg["method"])
+	return msg["params"].(map[string]interface{})["result"].(map[string]interface{})
+}
+
+func TestWSSubscriptions(t *testing.T) {
+	var subscribeCalls int32
+	upstreamC := make(chan *websocket.Conn, 1)
+	wsBackend := NewMockWSBackend(nil, func(conn *websocket.Conn, msgType int, data []byte) {
+		req, err := proxyd.ParseRPCReq(data)
+		require.NoError(t, err)
+		require.Equal(t, "eth_subscribe", req.Method)
+		atomic.AddInt32(&subscribeCalls, 1)
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"result":"0xupstream"}`)))
+		upstreamC <- conn
+	}, nil)
+	defer wsBackend.Close()
+
+	rpcBackend := NewMockBackend(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req proxyd.RPCReq
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		var result string
+		switch req.Method {
+		case "eth_chainId":
+			result = `"0x2a"`
+		case "eth_getBlockByNumber":
+			// body fields are stripped from fetched heads
+			result = fmt.Sprintf(`{"number":"0x2","hash":"%s","parentHash":"%s","transactions":[],"size":"0x1"}`, testBlockHash(2), testBlockHash(1))
+		case "eth_getLogs":
+			result = fmt.Sprintf(`[
+				{"address":"0x00000000000000000000000000000000000000aa","topics":["%s"],"blockHash":"%s"},
+				{"address":"0x00000000000000000000000000000000000000bb","topics":[],"blockHash":"%s"}
+			]`, testBlockHash(100), testBlockHash(1), testBlockHash(1))
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)))
+	}))
+	defer rpcBackend.Close()
+
+	require.NoError(t, os.Setenv("GOOD_BACKEND_RPC_URL", rpcBackend.URL()))
+	require.NoError(t, os.Setenv("GOOD_BACKEND_WS_URL", wsBackend.URL()))
+
+	config := ReadConfig("ws_subscriptions")
+	_, shutdown, err := proxyd.Start(config)
+	require.NoError(t, err)
+	defer shutdown()
+
+	headsC1 := make(chan []byte, 16)
+	client1, err := NewProxydWSClient("ws://127.0.0.1:8546", func(msgType int, data []byte) { headsC1 <- data }, nil)
+	require.NoError(t, err)
+	defer client1.HardClose()
+	headsC2 := make(chan []byte, 16)
+	client2, err := NewProxydWSClient("ws://127.0.0.1:8546", func(msgType int, data []byte) { headsC2 <- data }, nil)
+	require.NoError(t, err)
+	defer client2.HardClose()
+	logsC := make(chan []byte, 16)
+	client3, err := NewProxydWSClient("ws://127.0.0.1:8546", func(msgType int, data []byte) { logsC <- data }, nil)
+	require.NoError(t, err)
+	defer client3.HardClose()
+
+	require.NoError(t, client1.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`)))
+	sub1 := readWSMsg(t, headsC1)["result"].(string)
+	require.NoError(t, client2.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`)))
+	sub2 := readWSMsg(t, headsC2)["result"].(string)
+	require.NotEqual(t, sub1, sub2)
+	require.NoError(t, client3.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["logs",{"address":"0x00000000000000000000000000000000000000aa"}]}`)))
+	logsSub := readWSMsg(t, logsC)["result"].(string)
+
+	upstream := <-upstreamC
+	sendHead := func(n int) {
+		msg := fmt.Sprintf(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xupstream","result":%s}}`, testHeader(n))
+		require.NoError(t, upstream.WriteMessage(websocket.TextMessage, []byte(msg)))
+	}
+
+	t.Run("heads are fanned out from a single upstream subscription", func(t *testing.T) {
+		sendHead(1)
+		for _, ch := range []chan []byte{headsC1, headsC2} {
+			head := notificationResult(t, readWSMsg(t, ch))
+			require.Equal(t, testBlockHash(1), head["hash"])
+		}
+		require.Equal(t, int32(1), atomic.LoadInt32(&subscribeCalls))
+	})
+
+	t.Run("logs are filtered per subscription", func(t *testing.T) {
+		msg := readWSMsg(t, logsC)
+		require.Equal(t, logsSub, msg["params"].(map[string]interface{})["subscription"])
+		require.Equal(t, "0x00000000000000000000000000000000000000aa", notificationResult(t, msg)["address"])
+		requireNoWSMsg(t, logsC)
+	})
+
+	t.Run("duplicate heads are dropped and gaps are filled", func(t *testing.T) {
+		sendHead(1)
+		sendHead(3)
+		head := notificationResult(t, readWSMsg(t, headsC1))
+		require.Equal(t, testBlockHash(2), head["hash"])
+		require.NotContains(t, head, "transactions")
+		head = notificationResult(t, readWSMsg(t, headsC1))
+		require.Equal(t, testBlockHash(3), head["hash"])
+		requireNoWSMsg(t, headsC1)
+	})
+
+	t.Run("other methods are forwarded to the backend group", func(t *testing.T) {
+		require.NoError(t, client3.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":2,"method":"eth_chainId","params":[]}`)))
+		// skip the log notifications of the heads above
+		for {
+			msg := readWSMsg(t, logsC)
+			if msg["method"] == nil {
+				require.Equal(t, "0x2a", msg["result"], msg)
+				break
+			}
+		}
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		require.NoError(t, client2.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"eth_unsubscribe","params":["%s"]}`, sub2))))
+		// drain the heads sent before the unsubscribe
+		for {
+			msg := readWSMsg(t, headsC2)
+			if msg["method"] == nil {
+				require.Equal(t, true, msg["result"])
+				break
+			}
+		}
+		sendHead(4)
+		require.Equal(t, testBlockHash(4), notificationResult(t, readWSMsg(t, headsC1))["hash"])
+		requireNoWSMsg(t, headsC2)
+	})
+}
@@ -5,10 +5,13 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/go-redis/redis/v8"
 )
 
 type RPCMethodHandler interface {
@@ -84,3 +87,270 @@ func (e *StaticMethodHandler) PutRPCMethod(ctx context.Context, req *RPCReq, res
 	}
 	return nil
 }
+
+// ContextKeyFinalizedBlock is the context key of the consensus finalized block
+// of the backend group that serves the request.
+const ContextKeyFinalizedBlock = "finalized_block"
+
+type finalizedBlock struct {
+	backendGroup string
+	number       uint64
+}
+
+// withFinalizedBlock adds the consensus finalized block of the backend group to the context,
+// to cache block-number-based calls with. The context is returned as-is if the backend
+// group is not consensus aware, or if it has not resolved a finalized block yet.
+func withFinalizedBlock(ctx context.Context, bg *BackendGroup) context.Context {
+	if bg == nil || bg.Consensus == nil {
+		return ctx
+	}
+	number := uint64(bg.Consensus.GetFinalizedBlockNumber())
+	if number == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, ContextKeyFinalizedBlock, finalizedBlock{bg.Name, number}) // nolint:staticcheck
+}
+
+func getFinalizedBlock(ctx context.Context) (finalizedBlock, bool) {
+	fb, ok := ctx.Value(ContextKeyFinalizedBlock).(finalizedBlock)
+	return fb, ok
+}
+
+// cacheGenerationStore stores the generation of the block number cache of every backend group.
+// The generations are shared by all proxyd instances that share the cache.
+type cacheGenerationStore interface {
+	Get(ctx context.Context, backendGroup string) (uint64, error)
+	Incr(ctx context.Context, backendGroup string) (uint64, error)
+}
+
+type memoryCacheGenerations struct {
+	mtx         sync.Mutex
+	generations map[string]uint64
+}
+
+func newMemoryCacheGenerations() *memoryCacheGenerations {
+	return &memoryCacheGenerations{
+		generations: make(map[string]uint64),
+	}
+}
+
+func (m *memoryCacheGenerations) Get(ctx context.Context, backendGroup string) (uint64, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.generations[backendGroup], nil
+}
+
+func (m *memoryCacheGenerations) Incr(ctx context.Context, backendGroup string) (uint64, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.generations[backendGroup]++
+	return m.generations[backendGroup], nil
+}
+
+// redisCacheGenerations stores the generations in Redis, under the namespace of the cache.
+type redisCacheGenerations struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+func newRedisCacheGenerations(rdb *redis.Client, prefix string) *redisCacheGenerations {
+	return &redisCacheGenerations{rdb, prefix}
+}
+
+func (r *redisCacheGenerations) key(backendGroup string) string {
+	key := strings.Join([]string{"cache", "generation", backendGroup}, ":")
+	if r.prefix == "" {
+		return key
+	}
+	return strings.Join([]string{r.prefix, key}, ":")
+}
+
+func (r *redisCacheGenerations) Get(ctx context.Context, backendGroup string) (uint64, error) {
+	generation, err := r.rdb.Get(ctx, r.key(backendGroup)).Uint64()
+	if err == redis.Nil {
+		return 0, nil
+	} else if err != nil {
+		RecordRedisError("CacheGenerationGet")
+		return 0, err
+	}
+	return generation, nil
+}
+
+func (r *redisCacheGenerations) Incr(ctx context.Context, backendGroup string) (uint64, error) {
+	generation, err := r.rdb.Incr(ctx, r.key(backendGroup)).Uint64()
+	if err != nil {
+		RecordRedisError("CacheGenerationIncr")
+		return 0, err
+	}
+	return generation, nil
+}
+
+// cacheGeneration invalidates cached block-number-based calls of a backend group when
+// its finalized block goes backwards, since the blocks above it may reorg. The instance
+// that observes the finalized block going backwards bumps the shared generation.
+type cacheGeneration struct {
+	store     cacheGenerationStore
+	mtx       sync.Mutex
+	finalized map[string]uint64
+}
+
+func newCacheGeneration(store cacheGenerationStore) *cacheGeneration {
+	return &cacheGeneration{
+		store:     store,
+		finalized: make(map[string]uint64),
+	}
+}
+
+// observe records the finalized block of the backend group, and returns the current generation of the group.
+func (g *cacheGeneration) observe(ctx context.Context, fb finalizedBlock) (uint64, error) {
+	g.mtx.Lock()
+	prev := g.finalized[fb.backendGroup]
+	g.finalized[fb.backendGroup] = fb.number
+	g.mtx.Unlock()
+
+	if fb.number >= prev {
+		return g.store.Get(ctx, fb.backendGroup)
+	}
+	generation, err := g.store.Incr(ctx, fb.backendGroup)
+	if err != nil {
+		return 0, err
+	}
+	log.Warn("finalized block went backwards, invalidating block number cache",
+		"backend_group", fb.backendGroup,
+		"prev_finalized", prev,
+		"finalized", fb.number,
+		"generation", generation,
+	)
+	return generation, nil
+}
+
+// BlockNumberMethodHandler caches calls that are addressed by block number,
+// if the highest block that the call depends on is finalized.
+type BlockNumberMethodHandler struct {
+	cache      Cache
+	generation *cacheGeneration
+	// blockNumber returns the highest block number that the result of the request depends on,
+	// or false if the request depends on a block tag, or cannot be parsed.
+	blockNumber func(*RPCReq) (uint64, bool)
+}
+
+// key returns the cache key of the request, or false if the request is not cacheable.
+// The key includes the backend group and its generation.
+func (e *BlockNumberMethodHandler) key(ctx context.Context, req *RPCReq) (string, bool, error) {
+	fb, ok := getFinalizedBlock(ctx)
+	if !ok {
+		return "", false, nil
+	}
+	number, ok := e.blockNumber(req)
+	if !ok || number > fb.number {
+		return "", false, nil
+	}
+	generation, err := e.generation.observe(ctx, fb)
+	if err != nil {
+		log.Error("error reading cache generation", "backend_group", fb.backendGroup, "method", req.Method, "err", err)
+		return "", false, err
+	}
+
+	h := sha256.New()
+	h.Write(req.Params)
+	signature := fmt.Sprintf("%x", h.Sum(nil))
+	return strings.Join([]string{"cache", "block", fb.backendGroup, strconv.FormatUint(generation, 10), req.Method, signature}, ":"), true, nil
+}
+
+func (e *BlockNumberMethodHandler) GetRPCMethod(ctx context.Context, req *RPCReq) (*RPCRes, error) {
+	key, ok, err := e.key(ctx, req)
+	if err != nil || !ok {
+		return nil, err
+	}
+	val, err := e.cache.Get(ctx, key)
+	if err != nil {
+		log.Error("error reading from cache", "key", key, "method", req.Method, "err", err)
+		return nil, err
+	}
+	if val == "" {
+		return nil, nil
+	}
+
+	var result interface{}
+	if err := json.Unmarshal([]byte(val), &result); err != nil {
+		log.Error("error unmarshalling value from cache", "key", key, "method", req.Method, "err", err)
+		return nil, err
+	}
+	return &RPCRes{
+		JSONRPC: req.JSONRPC,
+		Result:  result,
+		ID:      req.ID,
+	}, nil
+}
+
+func (e *BlockNumberMethodHandler) PutRPCMethod(ctx context.Context, req *RPCReq, res *RPCRes) error {
+	key, ok, err := e.key(ctx, req)
+	if err != nil || !ok {
+		return err
+	}
+	value := mustMarshalJSON(res.Result)
+	if err := e.cache.Put(ctx, key, string(value)); err != nil {
+		log.Error("error putting into cache", "key", key, "method", req.Method, "err", err)
+		return err
+	}
+	return nil
+}
+
+// blockNumberParamPositions are the positions of the block parameter of the methods that
+// are cacheable by block number. These are the same positions as the tag rewrites use.
+var blockNumberParamPositions = map[string]int{
+	"eth_getBlockByNumber":                    0,
+	"eth_getBlockTransactionCountByNumber":    0,
+	"eth_getTransactionByBlockNumberAndIndex": 0,
+	"eth_getBalance":                          1,
+	"eth_getCode":                             1,
+	"eth_getTransactionCount":                 1,
+	"eth_call":                                1,
+	"eth_getStorageAt":                        2,
+}
+
+// blockNumberParam returns the block number of the block parameter at the position.
+// Missing parameters default to the latest block, and are not cacheable.
+func blockNumberParam(req *RPCReq, pos int) (uint64, bool) {
+	var p []json.RawMessage
+	if err := json.Unmarshal(req.Params, &p); err != nil || len(p) <= pos {
+		return 0, false
+	}
+	return parseBlockNumber(p[pos])
+}
+
+// logsFilterToBlock returns the end of the block range of an eth_getLogs filter.
+// Filters by block hash, or with a missing or tagged range, are not cacheable.
+func logsFilterToBlock(req *RPCReq) (uint64, bool) {
+	var p []struct {
+		FromBlock json.RawMessage `json:"fromBlock"`
+		ToBlock   json.RawMessage `json:"toBlock"`
+		BlockHash json.RawMessage `json:"blockHash"`
+	}
+	if err := json.Unmarshal(req.Params, &p); err != nil || len(p) != 1 {
+		return 0, false
+	}
+	if p[0].BlockHash != nil {
+		return 0, false
+	}
+	if _, ok := parseBlockNumber(p[0].FromBlock); !ok {
+		return 0, false
+	}
+	return parseBlockNumber(p[0].ToBlock)
+}
+
+func parseBlockNumber(raw json.RawMessage) (uint64, bool) {
+	if len(raw) == 0 {
+		return 0, false
+	}
+	var bnh rpc.BlockNumberOrHash
+	if err := bnh.UnmarshalJSON(raw); err != nil {
+		return 0, false
+	}
+	number, ok := bnh.Number()
+	// earliest is the genesis block, all other tags are negative
+	if !ok || number < 0 {
+		return 0, false
+	}
+	return uint64(number), true
+}
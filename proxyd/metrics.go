@@ -373,6 +373,117 @@ var (
 	}, []string{
 		"backend_name",
 	})
+
+	apiKeyRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "api_key_requests_total",
+		Help:      "Count of RPC calls admitted per API key.",
+	}, []string{
+		"auth",
+		"tier",
+	})
+
+	apiKeyComputeUnitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "api_key_compute_units_total",
+		Help:      "Count of compute units consumed per API key.",
+	}, []string{
+		"auth",
+		"tier",
+	})
+
+	apiKeyQuotaExceededTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "api_key_quota_exceeded_total",
+		Help:      "Count of RPC calls rejected because the API key is over quota.",
+	}, []string{
+		"auth",
+		"tier",
+		"quota",
+	})
+
+	activeWSSubscriptionsGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Name:      "active_ws_subscriptions",
+		Help:      "Gauge of active client WS subscriptions that are served by proxyd.",
+	}, []string{
+		"backend_group_name",
+		"type",
+	})
+
+	wsHubHeadsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "ws_hub_heads_total",
+		Help:      "Count of heads sent to WS subscriptions, by whether they were received from the upstream subscription or fetched.",
+	}, []string{
+		"backend_group_name",
+		"source",
+	})
+
+	txRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "tx_rejected_total",
+		Help:      "Count of eth_sendRawTransaction calls rejected by proxyd, by the step of the send pipeline that rejected them.",
+	}, []string{
+		"step",
+	})
+
+	txBroadcastsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "tx_broadcasts_total",
+		Help:      "Count of transactions broadcast to each backend.",
+	}, []string{
+		"backend_name",
+		"success",
+	})
+
+	txAcceptedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "tx_accepted_total",
+		Help:      "Count of eth_sendRawTransaction calls that were accepted by a backend.",
+	})
+
+	shadowCallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "shadow_calls_total",
+		Help:      "Count of RPC calls mirrored to the shadow group of a backend group.",
+	}, []string{
+		"backend_group_name",
+		"method",
+	})
+
+	shadowMismatchesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "shadow_mismatches_total",
+		Help:      "Count of mirrored RPC calls for which the shadow group returned a different result.",
+	}, []string{
+		"backend_group_name",
+		"method",
+	})
+
+	shadowErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "shadow_errors_total",
+		Help:      "Count of mirrored requests that could not be forwarded to the shadow group.",
+	}, []string{
+		"backend_group_name",
+	})
+
+	shadowDroppedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "shadow_dropped_total",
+		Help:      "Count of sampled requests that were not mirrored because the shadow group was at capacity.",
+	}, []string{
+		"backend_group_name",
+	})
+
+	configReloadsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "config_reloads_total",
+		Help:      "Count of config reloads.",
+	}, []string{
+		"success",
+	})
 )
 
 func RecordRedisError(source string) {
@@ -508,6 +619,46 @@ func RecordBackendNetworkErrorRateSlidingWindow(b *Backend, rate float64) {
 	networkErrorRateBackend.WithLabelValues(b.Name).Set(rate)
 }
 
+func RecordAPIKeyUsage(key *APIKey, computeUnits int) {
+	apiKeyRequestsTotal.WithLabelValues(key.Alias, key.Tier.Name).Inc()
+	apiKeyComputeUnitsTotal.WithLabelValues(key.Alias, key.Tier.Name).Add(float64(computeUnits))
+}
+
+func RecordAPIKeyQuotaExceeded(key *APIKey, quota string) {
+	apiKeyQuotaExceededTotal.WithLabelValues(key.Alias, key.Tier.Name, quota).Inc()
+}
+
+func RecordTxRejected(step string) {
+	txRejectedTotal.WithLabelValues(step).Inc()
+}
+
+func RecordTxBroadcast(backendName string, success bool) {
+	txBroadcastsTotal.WithLabelValues(backendName, strconv.FormatBool(success)).Inc()
+}
+
+func RecordTxAccepted() {
+	txAcceptedTotal.Inc()
+}
+
+func RecordShadowCall(backendGroup, method string, match bool) {
+	shadowCallsTotal.WithLabelValues(backendGroup, method).Inc()
+	if !match {
+		shadowMismatchesTotal.WithLabelValues(backendGroup, method).Inc()
+	}
+}
+
+func RecordShadowError(backendGroup string) {
+	shadowErrorsTotal.WithLabelValues(backendGroup).Inc()
+}
+
+func RecordShadowDropped(backendGroup string) {
+	shadowDroppedTotal.WithLabelValues(backendGroup).Inc()
+}
+
+func RecordConfigReload(success bool) {
+	configReloadsTotal.WithLabelValues(strconv.FormatBool(success)).Inc()
+}
+
 func boolToFloat64(b bool) float64 {
 	if b {
 		return 1
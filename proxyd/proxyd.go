@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"reflect"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/math"
@@ -16,58 +17,109 @@ import (
 )
 
 func Start(config *Config) (*Server, func(), error) {
+	srv, err := newServerFromConfig(config, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if config.Metrics.Enabled {
+		addr := fmt.Sprintf("%s:%d", config.Metrics.Host, config.Metrics.Port)
+		log.Info("starting metrics server", "addr", addr)
+		go func() {
+			if err := http.ListenAndServe(addr, promhttp.Handler()); err != nil {
+				log.Error("error starting metrics server", "err", err)
+			}
+		}()
+	}
+
+	// To allow integration tests to cleanly come up, wait
+	// 10ms to give the below goroutines enough time to
+	// encounter an error creating their servers
+	errTimer := time.NewTimer(10 * time.Millisecond)
+
+	if config.Server.RPCPort != 0 {
+		go func() {
+			if err := srv.RPCListenAndServe(config.Server.RPCHost, config.Server.RPCPort); err != nil {
+				if errors.Is(err, http.ErrServerClosed) {
+					log.Info("RPC server shut down")
+					return
+				}
+				log.Crit("error starting RPC server", "err", err)
+			}
+		}()
+	}
+
+	if config.Server.WSPort != 0 {
+		go func() {
+			if err := srv.WSListenAndServe(config.Server.WSHost, config.Server.WSPort); err != nil {
+				if errors.Is(err, http.ErrServerClosed) {
+					log.Info("WS server shut down")
+					return
+				}
+				log.Crit("error starting WS server", "err", err)
+			}
+		}()
+	} else {
+		log.Info("WS server not enabled (ws_port is set to 0)")
+	}
+
+	<-errTimer.C
+	log.Info("started proxyd")
+
+	shutdownFunc := func() {
+		log.Info("shutting down proxyd")
+		srv.Shutdown()
+		log.Info("goodbye")
+	}
+
+	return srv, shutdownFunc, nil
+}
+
+// newServerFromConfig validates the config and creates a server from it, without starting
+// its listeners. When reloading, the unchanged backends, backend groups and caches of the
+// previous server are reused, so that their state and connections are kept.
+func newServerFromConfig(config *Config, prev *Server) (*Server, error) {
 	if len(config.Backends) == 0 {
-		return nil, nil, errors.New("must define at least one backend")
+		return nil, errors.New("must define at least one backend")
 	}
 	if len(config.BackendGroups) == 0 {
-		return nil, nil, errors.New("must define at least one backend group")
+		return nil, errors.New("must define at least one backend group")
 	}
 	if len(config.RPCMethodMappings) == 0 {
-		return nil, nil, errors.New("must define at least one RPC method mapping")
+		return nil, errors.New("must define at least one RPC method mapping")
 	}
 
 	for authKey := range config.Authentication {
 		if authKey == "none" {
-			return nil, nil, errors.New("cannot use none as an auth key")
+			return nil, errors.New("cannot use none as an auth key")
 		}
 	}
 
 	var redisClient *redis.Client
-	if config.Redis.URL != "" {
+	if prev != nil {
+		// changes to the redis config are rejected by Reload
+		redisClient = prev.redisClient
+	} else if config.Redis.URL != "" {
 		rURL, err := ReadFromEnvOrConfig(config.Redis.URL)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		redisClient, err = NewRedisClient(rURL)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 	}
 
 	if redisClient == nil && config.RateLimit.UseRedis {
-		return nil, nil, errors.New("must specify a Redis URL if UseRedis is true in rate limit config")
-	}
-
-	// While modifying shared globals is a bad practice, the alternative
-	// is to clone these errors on every invocation. This is inefficient.
-	// We'd also have to make sure that errors.Is and errors.As continue
-	// to function properly on the cloned errors.
-	if config.RateLimit.ErrorMessage != "" {
-		ErrOverRateLimit.Message = config.RateLimit.ErrorMessage
-	}
-	if config.WhitelistErrorMessage != "" {
-		ErrMethodNotWhitelisted.Message = config.WhitelistErrorMessage
-	}
-	if config.BatchConfig.ErrorMessage != "" {
-		ErrTooManyBatchRequests.Message = config.BatchConfig.ErrorMessage
+		return nil, errors.New("must specify a Redis URL if UseRedis is true in rate limit config")
 	}
 
 	if config.SenderRateLimit.Enabled {
 		if config.SenderRateLimit.Limit <= 0 {
-			return nil, nil, errors.New("limit in sender_rate_limit must be > 0")
+			return nil, errors.New("limit in sender_rate_limit must be > 0")
 		}
 		if time.Duration(config.SenderRateLimit.Interval) < time.Second {
-			return nil, nil, errors.New("interval in sender_rate_limit must be >= 1s")
+			return nil, errors.New("interval in sender_rate_limit must be >= 1s")
 		}
 	}
 
@@ -76,22 +128,41 @@ func Start(config *Config) (*Server, func(), error) {
 		maxConcurrentRPCs = math.MaxInt64
 	}
 	rpcRequestSemaphore := semaphore.NewWeighted(maxConcurrentRPCs)
+	// Backends share the semaphore, so they can only be reused if it is unchanged.
+	reuseBackends := prev != nil &&
+		prev.config.Server.MaxConcurrentRPCs == config.Server.MaxConcurrentRPCs &&
+		reflect.DeepEqual(prev.config.BackendOptions, config.BackendOptions)
+	if reuseBackends {
+		rpcRequestSemaphore = prev.rpcRequestSemaphore
+	}
 
 	backendNames := make([]string, 0)
 	backendsByName := make(map[string]*Backend)
+	reusedBackends := make(map[string]bool)
 	for name, cfg := range config.Backends {
 		opts := make([]BackendOpt, 0)
 
 		rpcURL, err := ReadFromEnvOrConfig(cfg.RPCURL)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		wsURL, err := ReadFromEnvOrConfig(cfg.WSURL)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		if rpcURL == "" {
-			return nil, nil, fmt.Errorf("must define an RPC URL for backend %s", name)
+			return nil, fmt.Errorf("must define an RPC URL for backend %s", name)
+		}
+
+		if reuseBackends {
+			if back := prev.backendsByName[name]; back != nil &&
+				back.rpcURL == rpcURL && back.wsURL == wsURL &&
+				reflect.DeepEqual(prev.config.Backends[name], cfg) {
+				backendNames = append(backendNames, name)
+				backendsByName[name] = back
+				reusedBackends[name] = true
+				continue
+			}
 		}
 
 		if config.BackendOptions.ResponseTimeoutSeconds != 0 {
@@ -125,13 +196,13 @@ func Start(config *Config) (*Server, func(), error) {
 		if cfg.Password != "" {
 			passwordVal, err := ReadFromEnvOrConfig(cfg.Password)
 			if err != nil {
-				return nil, nil, err
+				return nil, err
 			}
 			opts = append(opts, WithBasicAuth(cfg.Username, passwordVal))
 		}
 		tlsConfig, err := configureBackendTLS(cfg)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		if tlsConfig != nil {
 			log.Info("using custom TLS config for backend", "name", name)
@@ -145,11 +216,11 @@ func Start(config *Config) (*Server, func(), error) {
 
 		receiptsTarget, err := ReadFromEnvOrConfig(cfg.ConsensusReceiptsTarget)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		receiptsTarget, err = validateReceiptsTarget(receiptsTarget)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		opts = append(opts, WithConsensusReceiptTarget(receiptsTarget))
 
@@ -164,13 +235,23 @@ func Start(config *Config) (*Server, func(), error) {
 	}
 
 	backendGroups := make(map[string]*BackendGroup)
+	reusedGroups := make(map[string]bool)
 	for bgName, bg := range config.BackendGroups {
 		backends := make([]*Backend, 0)
+		reuse := prev != nil && prev.BackendGroups[bgName] != nil &&
+			reflect.DeepEqual(prev.config.BackendGroups[bgName], bg)
 		for _, bName := range bg.Backends {
 			if backendsByName[bName] == nil {
-				return nil, nil, fmt.Errorf("backend %s is not defined", bName)
+				return nil, fmt.Errorf("backend %s is not defined", bName)
 			}
 			backends = append(backends, backendsByName[bName])
+			reuse = reuse && reusedBackends[bName]
+		}
+		if reuse {
+			// keeps the consensus poller, and the shadow group if it is reused as well
+			backendGroups[bgName] = prev.BackendGroups[bgName]
+			reusedGroups[bgName] = true
+			continue
 		}
 		group := &BackendGroup{
 			Name:     bgName,
@@ -178,50 +259,173 @@ func Start(config *Config) (*Server, func(), error) {
 		}
 		backendGroups[bgName] = group
 	}
+	// A group cannot keep mirroring to a shadow group that is replaced.
+	// Shadow groups have no shadow group themselves, so one pass is enough.
+	for bgName, bg := range config.BackendGroups {
+		if reusedGroups[bgName] && bg.ShadowGroup != "" && !reusedGroups[bg.ShadowGroup] {
+			backendGroups[bgName] = &BackendGroup{
+				Name:     bgName,
+				Backends: backendGroups[bgName].Backends,
+			}
+			delete(reusedGroups, bgName)
+		}
+	}
+
+	for bgName, bg := range config.BackendGroups {
+		if bg.ShadowGroup == "" || reusedGroups[bgName] {
+			continue
+		}
+		shadow := backendGroups[bg.ShadowGroup]
+		if shadow == nil {
+			return nil, fmt.Errorf("shadow group %s of backend group %s does not exist", bg.ShadowGroup, bgName)
+		}
+		if config.BackendGroups[bg.ShadowGroup].ShadowGroup != "" {
+			return nil, fmt.Errorf("shadow group %s of backend group %s cannot have a shadow group", bg.ShadowGroup, bgName)
+		}
+		if bg.ShadowSampleRate <= 0 || bg.ShadowSampleRate > 1 {
+			return nil, fmt.Errorf("shadow_sample_rate of backend group %s must be > 0 and <= 1", bgName)
+		}
+		backendGroups[bgName].Shadow = NewShadowGroup(shadow, bg.ShadowSampleRate)
+	}
 
 	var wsBackendGroup *BackendGroup
 	if config.WSBackendGroup != "" {
 		wsBackendGroup = backendGroups[config.WSBackendGroup]
 		if wsBackendGroup == nil {
-			return nil, nil, fmt.Errorf("ws backend group %s does not exist", config.WSBackendGroup)
+			return nil, fmt.Errorf("ws backend group %s does not exist", config.WSBackendGroup)
 		}
 	}
 
 	if wsBackendGroup == nil && config.Server.WSPort != 0 {
-		return nil, nil, fmt.Errorf("a ws port was defined, but no ws group was defined")
+		return nil, fmt.Errorf("a ws port was defined, but no ws group was defined")
+	}
+
+	txBroadcastBackends := make([]*Backend, 0, len(config.TxForwarding.BroadcastBackends))
+	for _, bName := range config.TxForwarding.BroadcastBackends {
+		if backendsByName[bName] == nil {
+			return nil, fmt.Errorf("tx broadcast backend %s is not defined", bName)
+		}
+		txBroadcastBackends = append(txBroadcastBackends, backendsByName[bName])
+	}
+
+	if config.WSSubscriptions.Enabled && wsBackendGroup == nil {
+		return nil, fmt.Errorf("ws subscriptions are enabled, but no ws group was defined")
 	}
 
 	for _, bg := range config.RPCMethodMappings {
 		if backendGroups[bg] == nil {
-			return nil, nil, fmt.Errorf("undefined backend group %s", bg)
+			return nil, fmt.Errorf("undefined backend group %s", bg)
+		}
+	}
+
+	for name, tier := range config.APIKeyTiers {
+		if tier.RequestLimit > 0 && time.Duration(tier.RequestInterval) <= 0 {
+			return nil, fmt.Errorf("request_interval of api key tier %s must be > 0", name)
+		}
+		if tier.ComputeUnitLimit > 0 && time.Duration(tier.ComputeUnitInterval) <= 0 {
+			return nil, fmt.Errorf("compute_unit_interval of api key tier %s must be > 0", name)
+		}
+		for _, bg := range tier.RPCMethodMappings {
+			if backendGroups[bg] == nil {
+				return nil, fmt.Errorf("undefined backend group %s in api key tier %s", bg, name)
+			}
 		}
 	}
 
 	var resolvedAuth map[string]string
 
-	if config.Authentication != nil {
+	if config.Authentication != nil || config.APIKeys != nil {
 		resolvedAuth = make(map[string]string)
 		for secret, alias := range config.Authentication {
 			resolvedSecret, err := ReadFromEnvOrConfig(secret)
 			if err != nil {
-				return nil, nil, err
+				return nil, err
 			}
 			resolvedAuth[resolvedSecret] = alias
 		}
 	}
 
+	authAliases := make(map[string]bool)
+	for _, alias := range config.Authentication {
+		authAliases[alias] = true
+	}
+	apiKeyTierNames := make(map[string]string)
+	for alias, key := range config.APIKeys {
+		if authAliases[alias] {
+			return nil, fmt.Errorf("api key alias %s is already used in authentication", alias)
+		}
+		if config.APIKeyTiers[key.Tier] == nil {
+			return nil, fmt.Errorf("api key %s has undefined tier %s", alias, key.Tier)
+		}
+		resolvedKey, err := ReadFromEnvOrConfig(key.Key)
+		if err != nil {
+			return nil, err
+		}
+		if resolvedKey == "" || resolvedKey == "none" {
+			return nil, fmt.Errorf("api key %s must have a key other than none", alias)
+		}
+		if _, ok := resolvedAuth[resolvedKey]; ok {
+			return nil, fmt.Errorf("key of api key %s is already used", alias)
+		}
+		resolvedAuth[resolvedKey] = alias
+		apiKeyTierNames[alias] = key.Tier
+	}
+
+	adminToken, err := ReadFromEnvOrConfig(config.Server.AdminToken)
+	if err != nil {
+		return nil, err
+	}
+
 	var (
 		cache    Cache
 		rpcCache RPCCache
 	)
 	if config.Cache.Enabled {
+		ttl := time.Duration(config.Cache.TTL)
+		if ttl == 0 {
+			ttl = redisTTL
+		}
+		maxEntries := config.Cache.MaxEntries
+		if maxEntries == 0 {
+			maxEntries = memoryCacheLimit
+		}
 		if redisClient == nil {
 			log.Warn("redis is not configured, using in-memory cache")
-			cache = newMemoryCache()
+			cache = newMemoryCacheWithLimits(maxEntries, ttl)
 		} else {
-			cache = newRedisCache(redisClient, config.Redis.Namespace)
+			cache = newRedisCacheWithTTL(redisClient, config.Redis.Namespace, ttl)
 		}
-		rpcCache = newRPCCache(newCacheWithCompression(cache))
+		cache = newCacheWithCompression(cache)
+		if config.Cache.MaxEntrySizeBytes > 0 {
+			cache = newCacheWithMaxEntrySize(cache, config.Cache.MaxEntrySizeBytes)
+		}
+
+		var (
+			blockCache  Cache
+			generations cacheGenerationStore
+		)
+		if config.Cache.BlockNumbers {
+			blockTTL := time.Duration(config.Cache.BlockNumberTTL)
+			if blockTTL == 0 {
+				blockTTL = blockNumberCacheTTL
+			}
+			blockMaxEntries := config.Cache.BlockNumberMaxEntries
+			if blockMaxEntries == 0 {
+				blockMaxEntries = memoryCacheLimit
+			}
+			if redisClient == nil {
+				blockCache = newMemoryCacheWithLimits(blockMaxEntries, blockTTL)
+				generations = newMemoryCacheGenerations()
+			} else {
+				blockCache = newRedisCacheWithTTL(redisClient, config.Redis.Namespace, blockTTL)
+				generations = newRedisCacheGenerations(redisClient, config.Redis.Namespace)
+			}
+			blockCache = newCacheWithCompression(blockCache)
+			if config.Cache.MaxEntrySizeBytes > 0 {
+				blockCache = newCacheWithMaxEntrySize(blockCache, config.Cache.MaxEntrySizeBytes)
+			}
+		}
+		rpcCache = newRPCCacheWithBlockNumbers(cache, blockCache, generations)
 	}
 
 	srv, err := NewServer(
@@ -240,55 +444,20 @@ func Start(config *Config) (*Server, func(), error) {
 		config.Server.MaxRequestBodyLogLen,
 		config.BatchConfig.MaxSize,
 		redisClient,
+		config.APIKeyTiers,
+		apiKeyTierNames,
+		adminToken,
+		config.WSSubscriptions,
+		config.TxForwarding,
+		txBroadcastBackends,
 	)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error creating server: %w", err)
-	}
-
-	if config.Metrics.Enabled {
-		addr := fmt.Sprintf("%s:%d", config.Metrics.Host, config.Metrics.Port)
-		log.Info("starting metrics server", "addr", addr)
-		go func() {
-			if err := http.ListenAndServe(addr, promhttp.Handler()); err != nil {
-				log.Error("error starting metrics server", "err", err)
-			}
-		}()
-	}
-
-	// To allow integration tests to cleanly come up, wait
-	// 10ms to give the below goroutines enough time to
-	// encounter an error creating their servers
-	errTimer := time.NewTimer(10 * time.Millisecond)
-
-	if config.Server.RPCPort != 0 {
-		go func() {
-			if err := srv.RPCListenAndServe(config.Server.RPCHost, config.Server.RPCPort); err != nil {
-				if errors.Is(err, http.ErrServerClosed) {
-					log.Info("RPC server shut down")
-					return
-				}
-				log.Crit("error starting RPC server", "err", err)
-			}
-		}()
-	}
-
-	if config.Server.WSPort != 0 {
-		go func() {
-			if err := srv.WSListenAndServe(config.Server.WSHost, config.Server.WSPort); err != nil {
-				if errors.Is(err, http.ErrServerClosed) {
-					log.Info("WS server shut down")
-					return
-				}
-				log.Crit("error starting WS server", "err", err)
-			}
-		}()
-	} else {
-		log.Info("WS server not enabled (ws_port is set to 0)")
+		return nil, fmt.Errorf("error creating server: %w", err)
 	}
 
 	for bgName, bg := range backendGroups {
 		bgcfg := config.BackendGroups[bgName]
-		if bgcfg.ConsensusAware {
+		if bgcfg.ConsensusAware && !reusedGroups[bgName] {
 			log.Info("creating poller for consensus aware backend_group", "name", bgName)
 
 			copts := make([]ConsensusOpt, 0)
@@ -314,16 +483,32 @@ func Start(config *Config) (*Server, func(), error) {
 		}
 	}
 
-	<-errTimer.C
-	log.Info("started proxyd")
-
-	shutdownFunc := func() {
-		log.Info("shutting down proxyd")
-		srv.Shutdown()
-		log.Info("goodbye")
+	srv.config = config
+	srv.backendsByName = backendsByName
+	srv.rpcRequestSemaphore = rpcRequestSemaphore
+	srv.redisClient = redisClient
+	if prev != nil {
+		srv.inherit(prev, reusedGroups)
 	}
 
-	return srv, shutdownFunc, nil
+	// The custom messages are kept per server, so that a reload can change or
+	// remove them without touching the shared error values.
+	srv.overRateLimitErr = withErrorMessage(ErrOverRateLimit, config.RateLimit.ErrorMessage)
+	srv.notWhitelistedErr = withErrorMessage(ErrMethodNotWhitelisted, config.WhitelistErrorMessage)
+	srv.tooManyBatchesErr = withErrorMessage(ErrTooManyBatchRequests, config.BatchConfig.ErrorMessage)
+
+	return srv, nil
+}
+
+// withErrorMessage returns a copy of err with the given message, or err itself
+// if no message is configured.
+func withErrorMessage(err *RPCErr, msg string) *RPCErr {
+	if msg == "" {
+		return err
+	}
+	custom := err.Clone()
+	custom.Message = msg
+	return custom
 }
 
 func validateReceiptsTarget(val string) (string, error) {
@@ -0,0 +1,262 @@
+package proxyd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// QuotaLimiter enforces a quota of units per key over a sliding window.
+//
+// The sliding window is approximated with two fixed windows: the units used in
+// the previous window are weighted by the part of it that still overlaps with
+// the sliding window, and added to the units used in the current window.
+type QuotaLimiter interface {
+	// Take consumes n units of the quota of the key. It returns a boolean
+	// denoting if the units could be taken, or an error if a failure occurred
+	// in the backing implementation. Units are not consumed if they could not
+	// be taken.
+	Take(ctx context.Context, key string, n int) (bool, error)
+
+	// Usage returns the units used by the key over the sliding window.
+	Usage(ctx context.Context, key string) (int, error)
+
+	// Limit returns the maximum number of units per window.
+	Limit() int
+
+	// Interval returns the length of the sliding window.
+	Interval() time.Duration
+}
+
+// QuotaTake is a number of units to take from a quota.
+type QuotaTake struct {
+	Limiter QuotaLimiter
+	N       int
+}
+
+// TakeQuotas consumes the units of all quotas of the key, or none of them if any quota
+// would be exceeded. It returns the index of the first quota that would be exceeded, or -1
+// if all units were taken. The limiters must all be memory limiters, or all Redis limiters
+// sharing the same client, as created by a single limiter factory.
+func TakeQuotas(ctx context.Context, key string, takes []QuotaTake) (int, error) {
+	if len(takes) == 0 {
+		return -1, nil
+	}
+	switch takes[0].Limiter.(type) {
+	case *MemoryQuotaLimiter:
+		return takeMemoryQuotas(key, takes)
+	case *RedisQuotaLimiter:
+		return takeRedisQuotas(ctx, key, takes)
+	default:
+		return 0, fmt.Errorf("unsupported quota limiter %T", takes[0].Limiter)
+	}
+}
+
+// slidingWindow returns the index of the fixed window that contains now,
+// and the weight of the previous fixed window in the sliding window ending now.
+func slidingWindow(now time.Time, dur time.Duration) (int64, float64) {
+	elapsed := now.UnixNano() % int64(dur)
+	return now.UnixNano() / int64(dur), 1 - float64(elapsed)/float64(dur)
+}
+
+type quotaWindows struct {
+	window int64
+	curr   int
+	prev   int
+}
+
+// advance moves the windows forward to the given window index.
+func (q *quotaWindows) advance(window int64) {
+	switch {
+	case window == q.window:
+	case window == q.window+1:
+		q.prev, q.curr = q.curr, 0
+	default:
+		q.prev, q.curr = 0, 0
+	}
+	q.window = window
+}
+
+// MemoryQuotaLimiter is a quota limiter that stores the
+// used units of each key in local memory.
+type MemoryQuotaLimiter struct {
+	dur  time.Duration
+	max  int
+	keys map[string]*quotaWindows
+	now  func() time.Time
+	mtx  sync.Mutex
+}
+
+func NewMemoryQuotaLimiter(dur time.Duration, max int) QuotaLimiter {
+	return &MemoryQuotaLimiter{
+		dur:  dur,
+		max:  max,
+		keys: make(map[string]*quotaWindows),
+		now:  time.Now,
+	}
+}
+
+func (m *MemoryQuotaLimiter) Take(ctx context.Context, key string, n int) (bool, error) {
+	rejected, err := takeMemoryQuotas(key, []QuotaTake{{Limiter: m, N: n}})
+	return rejected < 0, err
+}
+
+// takeMemoryQuotas takes the units of all quotas while holding the locks of all limiters,
+// so that either all or none of the units are consumed.
+func takeMemoryQuotas(key string, takes []QuotaTake) (int, error) {
+	lims := make([]*MemoryQuotaLimiter, len(takes))
+	windows := make([]*quotaWindows, len(takes))
+	for i, take := range takes {
+		lims[i] = take.Limiter.(*MemoryQuotaLimiter)
+		lims[i].mtx.Lock()
+		defer lims[i].mtx.Unlock()
+	}
+	for i, take := range takes {
+		m := lims[i]
+		window, weight := slidingWindow(m.now(), m.dur)
+		windows[i] = m.windows(key, window)
+		if float64(windows[i].prev)*weight+float64(windows[i].curr+take.N) > float64(m.max) {
+			return i, nil
+		}
+	}
+	for i, take := range takes {
+		windows[i].curr += take.N
+	}
+	return -1, nil
+}
+
+func (m *MemoryQuotaLimiter) Usage(ctx context.Context, key string) (int, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	window, weight := slidingWindow(m.now(), m.dur)
+	q := m.windows(key, window)
+	return int(float64(q.prev)*weight) + q.curr, nil
+}
+
+func (m *MemoryQuotaLimiter) windows(key string, window int64) *quotaWindows {
+	q, ok := m.keys[key]
+	if !ok {
+		q = &quotaWindows{window: window}
+		m.keys[key] = q
+	}
+	q.advance(window)
+	return q
+}
+
+func (m *MemoryQuotaLimiter) Limit() int {
+	return m.max
+}
+
+func (m *MemoryQuotaLimiter) Interval() time.Duration {
+	return m.dur
+}
+
+// RedisQuotaLimiter is a quota limiter that stores the used units
+// of each fixed window in Redis, so that the quota is shared across
+// all proxyd instances using the same Redis.
+type RedisQuotaLimiter struct {
+	r      *redis.Client
+	dur    time.Duration
+	max    int
+	prefix string
+	now    func() time.Time
+}
+
+func NewRedisQuotaLimiter(r *redis.Client, dur time.Duration, max int, prefix string) QuotaLimiter {
+	return &RedisQuotaLimiter{
+		r:      r,
+		dur:    dur,
+		max:    max,
+		prefix: prefix,
+		now:    time.Now,
+	}
+}
+
+func (r *RedisQuotaLimiter) windowKey(key string, window int64) string {
+	return fmt.Sprintf("quota:%s:%s:%d", r.prefix, key, window)
+}
+
+func (r *RedisQuotaLimiter) Take(ctx context.Context, key string, n int) (bool, error) {
+	rejected, err := takeRedisQuotas(ctx, key, []QuotaTake{{Limiter: r, N: n}})
+	return rejected < 0, err
+}
+
+// takeQuotasScript checks the sliding windows of all quotas, and only increments
+// the current windows if all quotas have enough units left. The keys are the previous
+// and current window key of every quota, the arguments are the units to take, the
+// maximum, the weight of the previous window and the expiry of every quota.
+// It returns the 1-based index of the first quota that was exceeded, or 0.
+var takeQuotasScript = redis.NewScript(`
+local count = #KEYS / 2
+for i = 1, count do
+	local prev = tonumber(redis.call('GET', KEYS[2*i-1]) or '0')
+	local curr = tonumber(redis.call('GET', KEYS[2*i]) or '0')
+	local n = tonumber(ARGV[4*i-3])
+	local max = tonumber(ARGV[4*i-2])
+	local weight = tonumber(ARGV[4*i-1])
+	if prev * weight + curr + n > max then
+		return i
+	end
+end
+for i = 1, count do
+	redis.call('INCRBY', KEYS[2*i], ARGV[4*i-3])
+	-- keep the window around while it is the previous window of the sliding window
+	redis.call('PEXPIRE', KEYS[2*i], ARGV[4*i])
+end
+return 0
+`)
+
+// takeRedisQuotas takes the units of all quotas in a single script, so that either all
+// or none of the units are consumed, and no units are consumed for rejected calls.
+func takeRedisQuotas(ctx context.Context, key string, takes []QuotaTake) (int, error) {
+	var client *redis.Client
+	keys := make([]string, 0, 2*len(takes))
+	args := make([]interface{}, 0, 4*len(takes))
+	for _, take := range takes {
+		r := take.Limiter.(*RedisQuotaLimiter)
+		client = r.r
+		window, weight := slidingWindow(r.now(), r.dur)
+		keys = append(keys, r.windowKey(key, window-1), r.windowKey(key, window))
+		args = append(args, take.N, r.max, weight, (2 * r.dur).Milliseconds())
+	}
+	res, err := takeQuotasScript.Run(ctx, client, keys, args...).Int()
+	if err != nil {
+		RecordRedisError("QuotaLimiterTake")
+		return 0, err
+	}
+	return res - 1, nil
+}
+
+func (r *RedisQuotaLimiter) Usage(ctx context.Context, key string) (int, error) {
+	var prev, curr *redis.StringCmd
+	window, weight := slidingWindow(r.now(), r.dur)
+	_, err := r.r.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		prev = pipe.Get(ctx, r.windowKey(key, window-1))
+		curr = pipe.Get(ctx, r.windowKey(key, window))
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		RecordRedisError("QuotaLimiterUsage")
+		return 0, err
+	}
+	prevVal, err := prev.Int()
+	if err != nil && err != redis.Nil {
+		return 0, err
+	}
+	currVal, err := curr.Int()
+	if err != nil && err != redis.Nil {
+		return 0, err
+	}
+	return int(float64(prevVal)*weight) + currVal, nil
+}
+
+func (r *RedisQuotaLimiter) Limit() int {
+	return r.max
+}
+
+func (r *RedisQuotaLimiter) Interval() time.Duration {
+	return r.dur
+}
@@ -0,0 +1,139 @@
+package proxyd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaLimiter(t *testing.T) {
+	redisServer, err := miniredis.Run()
+	require.NoError(t, err)
+	defer redisServer.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: fmt.Sprintf("127.0.0.1:%s", redisServer.Port()),
+	})
+
+	now := time.Unix(1000, 0)
+	clock := func() time.Time { return now }
+
+	memLim := NewMemoryQuotaLimiter(10*time.Second, 10).(*MemoryQuotaLimiter)
+	memLim.now = clock
+	redisLim := NewRedisQuotaLimiter(redisClient, 10*time.Second, 10, "test").(*RedisQuotaLimiter)
+	redisLim.now = clock
+
+	lims := []struct {
+		name string
+		lim  QuotaLimiter
+	}{
+		{"memory", memLim},
+		{"redis", redisLim},
+	}
+
+	for _, cfg := range lims {
+		lim := cfg.lim
+		ctx := context.Background()
+		t.Run(cfg.name, func(t *testing.T) {
+			now = time.Unix(1000, 0)
+
+			ok, err := lim.Take(ctx, "foo", 4)
+			require.NoError(t, err)
+			require.True(t, ok)
+			ok, err = lim.Take(ctx, "foo", 6)
+			require.NoError(t, err)
+			require.True(t, ok)
+			// rejected units are not consumed
+			ok, err = lim.Take(ctx, "foo", 1)
+			require.NoError(t, err)
+			require.False(t, ok)
+			used, err := lim.Usage(ctx, "foo")
+			require.NoError(t, err)
+			require.Equal(t, 10, used)
+
+			// keys have separate quotas
+			ok, err = lim.Take(ctx, "bar", 10)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			// halfway through the next window, half of the previous window still counts
+			now = now.Add(15 * time.Second)
+			used, err = lim.Usage(ctx, "foo")
+			require.NoError(t, err)
+			require.Equal(t, 5, used)
+			ok, err = lim.Take(ctx, "foo", 6)
+			require.NoError(t, err)
+			require.False(t, ok)
+			ok, err = lim.Take(ctx, "foo", 5)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			// once the window passed, its usage no longer counts
+			now = now.Add(20 * time.Second)
+			used, err = lim.Usage(ctx, "foo")
+			require.NoError(t, err)
+			require.Equal(t, 0, used)
+			ok, err = lim.Take(ctx, "foo", 10)
+			require.NoError(t, err)
+			require.True(t, ok)
+		})
+	}
+}
+
+func TestTakeQuotas(t *testing.T) {
+	redisServer, err := miniredis.Run()
+	require.NoError(t, err)
+	defer redisServer.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: fmt.Sprintf("127.0.0.1:%s", redisServer.Port()),
+	})
+
+	factories := []struct {
+		name    string
+		factory quotaLimiterFactory
+	}{
+		{"memory", func(dur time.Duration, max int, prefix string) QuotaLimiter {
+			return NewMemoryQuotaLimiter(dur, max)
+		}},
+		{"redis", func(dur time.Duration, max int, prefix string) QuotaLimiter {
+			return NewRedisQuotaLimiter(redisClient, dur, max, prefix)
+		}},
+	}
+
+	for _, cfg := range factories {
+		ctx := context.Background()
+		t.Run(cfg.name, func(t *testing.T) {
+			requests := cfg.factory(time.Hour, 10, "requests")
+			computeUnits := cfg.factory(time.Hour, 5, "compute_units")
+			take := func(cu int) int {
+				rejected, err := TakeQuotas(ctx, "foo", []QuotaTake{
+					{Limiter: requests, N: 1},
+					{Limiter: computeUnits, N: cu},
+				})
+				require.NoError(t, err)
+				return rejected
+			}
+
+			require.Equal(t, -1, take(5))
+			// the compute units are exhausted, the request quota is not consumed either
+			require.Equal(t, 1, take(1))
+			used, err := requests.Usage(ctx, "foo")
+			require.NoError(t, err)
+			require.Equal(t, 1, used)
+			used, err = computeUnits.Usage(ctx, "foo")
+			require.NoError(t, err)
+			require.Equal(t, 5, used)
+
+			for i := 0; i < 9; i++ {
+				require.Equal(t, -1, take(0))
+			}
+			require.Equal(t, 0, take(0))
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package proxyd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Current returns the server that handles requests, which is
+// the server built from the last successfully reloaded config.
+func (s *Server) Current() *Server {
+	if next, ok := s.reloaded.Load().(*Server); ok {
+		return next
+	}
+	return s
+}
+
+// forwardTo returns a handler that passes requests to the current server.
+func (s *Server) forwardTo(h func(*Server, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h(s.Current(), w, r)
+	}
+}
+
+// Reload applies a new config to a running server. The new config is fully validated and
+// built before it replaces the current one, so a bad config leaves the running state untouched.
+// Backends and backend groups whose config did not change are kept, together with their
+// consensus state, and so are the cache, the rate limiters and the websocket subscription hub.
+// Listeners, metrics and redis cannot be changed without a restart.
+func (s *Server) Reload(config *Config) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	prev := s.Current()
+	if err := checkReloadable(prev.config, config); err != nil {
+		RecordConfigReload(false)
+		return err
+	}
+	next, err := newServerFromConfig(config, prev)
+	if err != nil {
+		RecordConfigReload(false)
+		return err
+	}
+	s.reloaded.Store(next)
+	prev.shutdownReplaced(next)
+	RecordConfigReload(true)
+
+	reused := 0
+	for name, bg := range next.BackendGroups {
+		if prev.BackendGroups[name] == bg {
+			reused++
+		}
+	}
+	log.Info("reloaded config", "backend_groups", len(next.BackendGroups), "reused_backend_groups", reused)
+	return nil
+}
+
+// checkReloadable returns an error if the config changes settings that require a restart.
+func checkReloadable(prev, next *Config) error {
+	if prev.Server.RPCHost != next.Server.RPCHost || prev.Server.RPCPort != next.Server.RPCPort ||
+		prev.Server.WSHost != next.Server.WSHost || prev.Server.WSPort != next.Server.WSPort {
+		return fmt.Errorf("changing the server listeners requires a restart")
+	}
+	if !reflect.DeepEqual(prev.Metrics, next.Metrics) {
+		return fmt.Errorf("changing the metrics config requires a restart")
+	}
+	if !reflect.DeepEqual(prev.Redis, next.Redis) {
+		return fmt.Errorf("changing the redis config requires a restart")
+	}
+	return nil
+}
+
+// inherit carries over the state of the previous server that is still valid under the new config.
+func (s *Server) inherit(prev *Server, reusedGroups map[string]bool) {
+	if reflect.DeepEqual(prev.config.Cache, s.config.Cache) {
+		s.cache = prev.cache
+	}
+
+	if reflect.DeepEqual(prev.config.RateLimit, s.config.RateLimit) {
+		s.mainLim = prev.mainLim
+		s.overrideLims = prev.overrideLims
+		if reflect.DeepEqual(prev.config.SenderRateLimit, s.config.SenderRateLimit) {
+			s.senderLim = prev.senderLim
+		}
+	}
+
+	// Quotas are kept for the keys of unchanged tiers, and usage totals for
+	// the keys that stay in them.
+	tiers := make(map[string]*APIKeyTier)
+	for alias, key := range s.apiKeys {
+		name := key.Tier.Name
+		if tiers[name] == nil {
+			tiers[name] = key.Tier
+			if prevTier := prev.findTier(name); prevTier != nil &&
+				s.config.RateLimit.UseRedis == prev.config.RateLimit.UseRedis &&
+				reflect.DeepEqual(prev.config.APIKeyTiers[name], s.config.APIKeyTiers[name]) {
+				tiers[name] = prevTier
+			}
+		}
+		if prevKey := prev.apiKeys[alias]; prevKey != nil && prevKey.Tier == tiers[name] {
+			s.apiKeys[alias] = prevKey
+		} else {
+			s.apiKeys[alias] = NewAPIKey(alias, tiers[name])
+		}
+	}
+
+	if prev.wsHub != nil && s.wsHub != nil && reusedGroups[s.config.WSBackendGroup] &&
+		prev.config.WSBackendGroup == s.config.WSBackendGroup &&
+		reflect.DeepEqual(prev.config.WSSubscriptions, s.config.WSSubscriptions) {
+		s.wsHub = prev.wsHub
+	}
+}
+
+func (s *Server) findTier(name string) *APIKeyTier {
+	for _, key := range s.apiKeys {
+		if key.Tier.Name == name {
+			return key.Tier
+		}
+	}
+	return nil
+}
+
+// shutdownReplaced stops the background work of the backend groups and the
+// subscription hub that are not used by the next server anymore.
+func (s *Server) shutdownReplaced(next *Server) {
+	for name, bg := range s.BackendGroups {
+		if next.BackendGroups[name] != bg {
+			bg.Shutdown()
+		}
+	}
+	if s.wsHub != nil && s.wsHub != next.wsHub {
+		s.wsHub.Shutdown()
+	}
+}
+
+// ReloadConfigFile reads the config file and applies it to the server.
+func ReloadConfigFile(srv *Server, path string) (*Config, error) {
+	config := new(Config)
+	if _, err := toml.DecodeFile(path, config); err != nil {
+		RecordConfigReload(false)
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+	if err := srv.Reload(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// WatchConfigFile reloads the config file whenever its modification time or size changes,
+// until the context is canceled. The file is polled, so that it also works for config
+// files that are replaced rather than written to, like mounted Kubernetes config maps.
+// The callback is called with every successfully reloaded config.
+func WatchConfigFile(ctx context.Context, srv *Server, path string, interval time.Duration, onReload func(*Config)) {
+	stat := func() (time.Time, int64) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return info.ModTime(), info.Size()
+	}
+	lastMod, lastSize := stat()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		mod, size := stat()
+		if size < 0 || (mod.Equal(lastMod) && size == lastSize) {
+			continue
+		}
+		lastMod, lastSize = mod, size
+
+		log.Info("config file changed, reloading", "path", path)
+		config, err := ReloadConfigFile(srv, path)
+		if err != nil {
+			log.Error("error reloading config, keeping the running config", "err", err)
+			continue
+		}
+		if onReload != nil {
+			onReload(config)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package proxyd
 import (
 	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -11,20 +12,20 @@ import (
 	"math"
 	"net/http"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/go-redis/redis/v8"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/cors"
+	"golang.org/x/sync/semaphore"
 )
 
 const (
@@ -44,11 +45,15 @@ type Server struct {
 	BackendGroups          map[string]*BackendGroup
 	wsBackendGroup         *BackendGroup
 	wsMethodWhitelist      *StringSet
+	wsHub                  *SubscriptionHub
+	wsMaxSubsPerConn       int
 	rpcMethodMappings      map[string]string
 	maxBodySize            int64
 	enableRequestLog       bool
 	maxRequestBodyLogLen   int
 	authenticatedPaths     map[string]string
+	apiKeys                map[string]*APIKey
+	adminToken             string
 	timeout                time.Duration
 	maxUpstreamBatchSize   int
 	maxBatchSize           int
@@ -56,13 +61,27 @@ type Server struct {
 	mainLim                FrontendRateLimiter
 	overrideLims           map[string]FrontendRateLimiter
 	senderLim              FrontendRateLimiter
+	txPolicy               *TxPolicy
+	txBroadcastBackends    []*Backend
+	logAcceptedTxs         bool
 	limExemptOrigins       []*regexp.Regexp
 	limExemptUserAgents    []*regexp.Regexp
 	globallyLimitedMethods map[string]bool
 	rpcServer              *http.Server
 	wsServer               *http.Server
 	cache                  RPCCache
+	overRateLimitErr       *RPCErr
+	notWhitelistedErr      *RPCErr
+	tooManyBatchesErr      *RPCErr
 	srvMu                  sync.Mutex
+
+	// state used to build the server of a reloaded config
+	config              *Config
+	backendsByName      map[string]*Backend
+	rpcRequestSemaphore *semaphore.Weighted
+	redisClient         *redis.Client
+	reloaded            atomic.Value
+	reloadMu            sync.Mutex
 }
 
 type limiterFunc func(method string) bool
@@ -83,6 +102,12 @@ func NewServer(
 	maxRequestBodyLogLen int,
 	maxBatchSize int,
 	redisClient *redis.Client,
+	apiKeyTiers APIKeyTiersConfig,
+	apiKeyTierNames map[string]string,
+	adminToken string,
+	wsSubscriptions WSSubscriptionsConfig,
+	txForwarding TxForwardingConfig,
+	txBroadcastBackends []*Backend,
 ) (*Server, error) {
 	if cache == nil {
 		cache = &NoopRPCCache{}
@@ -153,13 +178,48 @@ func NewServer(
 		senderLim = limiterFactory(time.Duration(senderRateLimitConfig.Interval), senderRateLimitConfig.Limit, "senders")
 	}
 
+	quotaLimiterFactory := func(dur time.Duration, max int, prefix string) QuotaLimiter {
+		if rateLimitConfig.UseRedis {
+			return NewRedisQuotaLimiter(redisClient, dur, max, prefix)
+		}
+
+		return NewMemoryQuotaLimiter(dur, max)
+	}
+
+	tiers := make(map[string]*APIKeyTier)
+	for name, cfg := range apiKeyTiers {
+		tiers[name] = NewAPIKeyTier(name, cfg, quotaLimiterFactory)
+	}
+	apiKeys := make(map[string]*APIKey)
+	for alias, tierName := range apiKeyTierNames {
+		tier := tiers[tierName]
+		if tier == nil {
+			return nil, fmt.Errorf("api key %s has undefined tier %s", alias, tierName)
+		}
+		apiKeys[alias] = NewAPIKey(alias, tier)
+	}
+
+	txPolicy, err := NewTxPolicy(&txForwarding)
+	if err != nil {
+		return nil, err
+	}
+
+	var wsHub *SubscriptionHub
+	if wsSubscriptions.Enabled && wsBackendGroup != nil {
+		wsHub = NewSubscriptionHub(wsBackendGroup)
+	}
+
 	return &Server{
 		BackendGroups:        backendGroups,
 		wsBackendGroup:       wsBackendGroup,
 		wsMethodWhitelist:    wsMethodWhitelist,
+		wsHub:                wsHub,
+		wsMaxSubsPerConn:     wsSubscriptions.MaxPerConn,
 		rpcMethodMappings:    rpcMethodMappings,
 		maxBodySize:          maxBodySize,
 		authenticatedPaths:   authenticatedPaths,
+		apiKeys:              apiKeys,
+		adminToken:           adminToken,
 		timeout:              timeout,
 		maxUpstreamBatchSize: maxUpstreamBatchSize,
 		cache:                cache,
@@ -173,8 +233,14 @@ func NewServer(
 		overrideLims:           overrideLims,
 		globallyLimitedMethods: globalMethodLims,
 		senderLim:              senderLim,
+		txPolicy:               txPolicy,
+		txBroadcastBackends:    txBroadcastBackends,
+		logAcceptedTxs:         txForwarding.LogAccepted,
 		limExemptOrigins:       limExemptOrigins,
 		limExemptUserAgents:    limExemptUserAgents,
+		overRateLimitErr:       ErrOverRateLimit,
+		notWhitelistedErr:      ErrMethodNotWhitelisted,
+		tooManyBatchesErr:      ErrTooManyBatchRequests,
 	}, nil
 }
 
@@ -182,8 +248,11 @@ func (s *Server) RPCListenAndServe(host string, port int) error {
 	s.srvMu.Lock()
 	hdlr := mux.NewRouter()
 	hdlr.HandleFunc("/healthz", s.HandleHealthz).Methods("GET")
-	hdlr.HandleFunc("/", s.HandleRPC).Methods("POST")
-	hdlr.HandleFunc("/{authorization}", s.HandleRPC).Methods("POST")
+	// The admin token can be set by a config reload, so the routes always exist.
+	hdlr.HandleFunc("/admin/api_keys", s.forwardTo((*Server).HandleAPIKeysUsage)).Methods("GET")
+	hdlr.HandleFunc("/admin/api_keys/{alias}", s.forwardTo((*Server).HandleAPIKeysUsage)).Methods("GET")
+	hdlr.HandleFunc("/", s.forwardTo((*Server).HandleRPC)).Methods("POST")
+	hdlr.HandleFunc("/{authorization}", s.forwardTo((*Server).HandleRPC)).Methods("POST")
 	c := cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
 	})
@@ -200,8 +269,8 @@ func (s *Server) RPCListenAndServe(host string, port int) error {
 func (s *Server) WSListenAndServe(host string, port int) error {
 	s.srvMu.Lock()
 	hdlr := mux.NewRouter()
-	hdlr.HandleFunc("/", s.HandleWS)
-	hdlr.HandleFunc("/{authorization}", s.HandleWS)
+	hdlr.HandleFunc("/", s.forwardTo((*Server).HandleWS))
+	hdlr.HandleFunc("/{authorization}", s.forwardTo((*Server).HandleWS))
 	c := cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
 	})
@@ -224,7 +293,11 @@ func (s *Server) Shutdown() {
 	if s.wsServer != nil {
 		_ = s.wsServer.Shutdown(context.Background())
 	}
-	for _, bg := range s.BackendGroups {
+	cur := s.Current()
+	if cur.wsHub != nil {
+		cur.wsHub.Shutdown()
+	}
+	for _, bg := range cur.BackendGroups {
 		bg.Shutdown()
 	}
 }
@@ -233,6 +306,59 @@ func (s *Server) HandleHealthz(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte("OK"))
 }
 
+// HandleAPIKeysUsage reports the quota usage of all API keys,
+// or of the API key with the alias in the path.
+func (s *Server) HandleAPIKeysUsage(w http.ResponseWriter, r *http.Request) {
+	if s.adminToken == "" {
+		httpResponseCodesTotal.WithLabelValues("404").Inc()
+		w.WriteHeader(404)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+s.adminToken)) != 1 {
+		httpResponseCodesTotal.WithLabelValues("401").Inc()
+		w.WriteHeader(401)
+		return
+	}
+
+	keys := make([]*APIKey, 0, len(s.apiKeys))
+	if alias, ok := mux.Vars(r)["alias"]; ok {
+		key := s.apiKeys[alias]
+		if key == nil {
+			httpResponseCodesTotal.WithLabelValues("404").Inc()
+			w.WriteHeader(404)
+			return
+		}
+		keys = append(keys, key)
+	} else {
+		for _, key := range s.apiKeys {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].Alias < keys[j].Alias
+		})
+	}
+
+	usage := make([]*APIKeyUsage, 0, len(keys))
+	for _, key := range keys {
+		u, err := key.Usage(r.Context())
+		if err != nil {
+			log.Error("error reading api key usage", "auth", key.Alias, "err", err)
+			httpResponseCodesTotal.WithLabelValues("500").Inc()
+			w.WriteHeader(500)
+			return
+		}
+		usage = append(usage, u)
+	}
+
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(200)
+	if err := json.NewEncoder(w).Encode(usage); err != nil {
+		log.Error("error writing api key usage", "err", err)
+		return
+	}
+	httpResponseCodesTotal.WithLabelValues("200").Inc()
+}
+
 func (s *Server) HandleRPC(w http.ResponseWriter, r *http.Request) {
 	ctx := s.populateContext(w, r)
 	if ctx == nil {
@@ -280,7 +406,7 @@ func (s *Server) HandleRPC(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if isLimited("") {
-		RecordRPCError(ctx, BackendProxyd, "unknown", ErrOverRateLimit)
+		RecordRPCError(ctx, BackendProxyd, "unknown", s.overRateLimitErr)
 		log.Warn(
 			"rate limited request",
 			"req_id", GetReqID(ctx),
@@ -289,7 +415,7 @@ func (s *Server) HandleRPC(w http.ResponseWriter, r *http.Request) {
 			"origin", origin,
 			"remote_ip", xff,
 		)
-		writeRPCError(ctx, w, nil, ErrOverRateLimit)
+		writeRPCError(ctx, w, nil, s.overRateLimitErr)
 		return
 	}
 
@@ -330,8 +456,8 @@ func (s *Server) HandleRPC(w http.ResponseWriter, r *http.Request) {
 		RecordBatchSize(len(reqs))
 
 		if len(reqs) > s.maxBatchSize {
-			RecordRPCError(ctx, BackendProxyd, MethodUnknown, ErrTooManyBatchRequests)
-			writeRPCError(ctx, w, nil, ErrTooManyBatchRequests)
+			RecordRPCError(ctx, BackendProxyd, MethodUnknown, s.tooManyBatchesErr)
+			writeRPCError(ctx, w, nil, s.tooManyBatchesErr)
 			return
 		}
 
@@ -390,6 +516,7 @@ func (s *Server) handleBatchRPC(ctx context.Context, reqs []json.RawMessage, isL
 	responses := make([]*RPCRes, len(reqs))
 	batches := make(map[batchGroup][]batchElem)
 	ids := make(map[string]int, len(reqs))
+	rawTxs := make(map[int]*rawTransaction)
 
 	for i := range reqs {
 		parsedReq, err := ParseRPCReq(reqs[i])
@@ -411,7 +538,11 @@ func (s *Server) handleBatchRPC(ctx context.Context, reqs []json.RawMessage, isL
 			continue
 		}
 
+		apiKey := GetAPIKey(ctx)
 		group := s.rpcMethodMappings[parsedReq.Method]
+		if apiKey != nil {
+			group = apiKey.Tier.BackendGroup(parsedReq.Method, s.rpcMethodMappings)
+		}
 		if group == "" {
 			// use unknown below to prevent DOS vector that fills up memory
 			// with arbitrary method names.
@@ -421,11 +552,20 @@ func (s *Server) handleBatchRPC(ctx context.Context, reqs []json.RawMessage, isL
 				"req_id", GetReqID(ctx),
 				"method", parsedReq.Method,
 			)
-			RecordRPCError(ctx, BackendProxyd, MethodUnknown, ErrMethodNotWhitelisted)
-			responses[i] = NewRPCErrorRes(parsedReq.ID, ErrMethodNotWhitelisted)
+			RecordRPCError(ctx, BackendProxyd, MethodUnknown, s.notWhitelistedErr)
+			responses[i] = NewRPCErrorRes(parsedReq.ID, s.notWhitelistedErr)
 			continue
 		}
 
+		// Take the quotas of the API key, if the request is authenticated with one.
+		if apiKey != nil {
+			if err := apiKey.Take(ctx, parsedReq.Method); err != nil {
+				RecordRPCError(ctx, BackendProxyd, parsedReq.Method, err)
+				responses[i] = NewRPCErrorRes(parsedReq.ID, err)
+				continue
+			}
+		}
+
 		// Take rate limit for specific methods.
 		// NOTE: eventually, this should apply to all batch requests. However,
 		// since we don't have data right now on the size of each batch, we
@@ -437,20 +577,27 @@ func (s *Server) handleBatchRPC(ctx context.Context, reqs []json.RawMessage, isL
 				"req_id", GetReqID(ctx),
 				"method", parsedReq.Method,
 			)
-			RecordRPCError(ctx, BackendProxyd, parsedReq.Method, ErrOverRateLimit)
-			responses[i] = NewRPCErrorRes(parsedReq.ID, ErrOverRateLimit)
+			RecordRPCError(ctx, BackendProxyd, parsedReq.Method, s.overRateLimitErr)
+			responses[i] = NewRPCErrorRes(parsedReq.ID, s.overRateLimitErr)
 			continue
 		}
 
-		// Apply a sender-based rate limit if it is enabled. Note that sender-based rate
-		// limits apply regardless of origin or user-agent. As such, they don't use the
-		// isLimited method.
-		if parsedReq.Method == "eth_sendRawTransaction" && s.senderLim != nil {
-			if err := s.rateLimitSender(ctx, parsedReq); err != nil {
+		// Apply the transaction policy and a sender-based rate limit if they are enabled.
+		// Note that sender-based rate limits apply regardless of origin or user-agent.
+		// As such, they don't use the isLimited method.
+		if parsedReq.Method == "eth_sendRawTransaction" && (s.senderLim != nil || s.txPolicy != nil || s.logAcceptedTxs) {
+			rawTx, err := s.checkTransaction(ctx, parsedReq)
+			if err != nil {
 				RecordRPCError(ctx, BackendProxyd, parsedReq.Method, err)
 				responses[i] = NewRPCErrorRes(parsedReq.ID, err)
 				continue
 			}
+			rawTxs[i] = rawTx
+		}
+
+		if parsedReq.Method == "eth_sendRawTransaction" && len(s.txBroadcastBackends) > 0 {
+			responses[i] = broadcastTransaction(ctx, s.txBroadcastBackends, parsedReq)
+			continue
 		}
 
 		id := string(parsedReq.ID)
@@ -465,8 +612,10 @@ func (s *Server) handleBatchRPC(ctx context.Context, reqs []json.RawMessage, isL
 	for group, batch := range batches {
 		var cacheMisses []batchElem
 
+		// Block-number-based calls are cached up to the finalized block of the backend group.
+		cacheCtx := withFinalizedBlock(ctx, s.BackendGroups[group.backendGroup])
 		for _, req := range batch {
-			backendRes, _ := s.cache.GetRPC(ctx, req.Req)
+			backendRes, _ := s.cache.GetRPC(cacheCtx, req.Req)
 			if backendRes != nil {
 				responses[req.Index] = backendRes
 				cached = true
@@ -515,7 +664,7 @@ func (s *Server) handleBatchRPC(ctx context.Context, reqs []json.RawMessage, isL
 
 				// TODO(inphi): batch put these
 				if res[i].Error == nil && res[i].Result != nil {
-					if err := s.cache.PutRPC(ctx, elems[i].Req, res[i]); err != nil {
+					if err := s.cache.PutRPC(cacheCtx, elems[i].Req, res[i]); err != nil {
 						log.Warn(
 							"cache put error",
 							"req_id", GetReqID(ctx),
@@ -527,6 +676,15 @@ func (s *Server) handleBatchRPC(ctx context.Context, reqs []json.RawMessage, isL
 		}
 	}
 
+	for i, rawTx := range rawTxs {
+		if res := responses[i]; res != nil && !res.IsError() {
+			RecordTxAccepted()
+			if s.logAcceptedTxs {
+				logAcceptedTransaction(ctx, rawTx)
+			}
+		}
+	}
+
 	return responses, cached, nil
 }
 
@@ -544,7 +702,20 @@ func (s *Server) HandleWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	proxier, err := s.wsBackendGroup.ProxyWS(ctx, clientConn, s.wsMethodWhitelist)
+	if s.wsHub != nil {
+		conn := NewWSSubscriptionConn(clientConn, s.wsBackendGroup, s.wsHub, s.wsMethodWhitelist, s.notWhitelistedErr, s.wsMaxSubsPerConn, s.wsTxHandler())
+		activeClientWsConnsGauge.WithLabelValues(GetAuthCtx(ctx)).Inc()
+		go func() {
+			if err := conn.Serve(ctx); err != nil {
+				log.Debug("ws connection closed", "auth", GetAuthCtx(ctx), "req_id", GetReqID(ctx), "err", err)
+			}
+			activeClientWsConnsGauge.WithLabelValues(GetAuthCtx(ctx)).Dec()
+		}()
+		log.Info("accepted WS connection", "auth", GetAuthCtx(ctx), "req_id", GetReqID(ctx))
+		return
+	}
+
+	proxier, err := s.wsBackendGroup.ProxyWS(ctx, clientConn, s.wsMethodWhitelist, s.notWhitelistedErr, s.wsTxHandler())
 	if err != nil {
 		if errors.Is(err, ErrNoBackends) {
 			RecordUnserviceableRequest(ctx, RPCRequestSourceWS)
@@ -586,7 +757,11 @@ func (s *Server) populateContext(w http.ResponseWriter, r *http.Request) context
 			return nil
 		}
 
-		ctx = context.WithValue(ctx, ContextKeyAuth, s.authenticatedPaths[authorization]) // nolint:staticcheck
+		alias := s.authenticatedPaths[authorization]
+		ctx = context.WithValue(ctx, ContextKeyAuth, alias) // nolint:staticcheck
+		if apiKey := s.apiKeys[alias]; apiKey != nil {
+			ctx = context.WithValue(ctx, ContextKeyAPIKey, apiKey) // nolint:staticcheck
+		}
 	}
 
 	return context.WithValue(
@@ -596,6 +771,15 @@ func (s *Server) populateContext(w http.ResponseWriter, r *http.Request) context
 	)
 }
 
+// detachedContext keeps the values of a context, without its deadline and cancellation.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
 func randStr(l int) string {
 	b := make([]byte, l)
 	if _, err := rand.Read(b); err != nil {
@@ -627,47 +811,88 @@ func (s *Server) isGlobalLimit(method string) bool {
 	return s.globallyLimitedMethods[method]
 }
 
-func (s *Server) rateLimitSender(ctx context.Context, req *RPCReq) error {
-	var params []string
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		log.Debug("error unmarshaling raw transaction params", "err", err, "req_Id", GetReqID(ctx))
-		return ErrParseErr
+// checkTransaction decodes the transaction of an eth_sendRawTransaction call, and applies
+// the transaction policy and the sender-based rate limit to it.
+func (s *Server) checkTransaction(ctx context.Context, req *RPCReq) (*rawTransaction, error) {
+	rawTx, err := decodeRawTransaction(ctx, req)
+	if err != nil {
+		RecordTxRejected(TxStepDecode)
+		return nil, err
+	}
+
+	if s.txPolicy != nil {
+		if step, err := s.txPolicy.Check(rawTx); err != nil {
+			log.Debug(
+				"transaction rejected by policy",
+				"step", step,
+				"tx_hash", rawTx.tx.Hash(),
+				"sender", rawTx.from,
+				"req_id", GetReqID(ctx),
+			)
+			RecordTxRejected(step)
+			return nil, err
+		}
 	}
 
-	if len(params) != 1 {
-		log.Debug("raw transaction request has invalid number of params", "req_id", GetReqID(ctx))
-		// The error below is identical to the one Geth responds with.
-		return ErrInvalidParams("missing value for required argument 0")
+	if s.senderLim != nil {
+		if err := s.rateLimitSender(ctx, rawTx); err != nil {
+			RecordTxRejected(TxStepSenderRateLimit)
+			return nil, err
+		}
 	}
+	return rawTx, nil
+}
 
-	var data hexutil.Bytes
-	if err := data.UnmarshalText([]byte(params[0])); err != nil {
-		log.Debug("error decoding raw tx data", "err", err, "req_id", GetReqID(ctx))
-		// Geth returns the raw error from UnmarshalText.
-		return ErrInvalidParams(err.Error())
+// wsTxHandler returns the handler of eth_sendRawTransaction calls of websocket clients,
+// or nil if transactions are forwarded like any other call.
+func (s *Server) wsTxHandler() TxHandler {
+	if s.senderLim == nil && s.txPolicy == nil && !s.logAcceptedTxs && len(s.txBroadcastBackends) == 0 {
+		return nil
 	}
+	return s.sendRawTransaction
+}
 
-	// Inflates a types.Transaction object from the transaction's raw bytes.
-	tx := new(types.Transaction)
-	if err := tx.UnmarshalBinary(data); err != nil {
-		log.Debug("could not unmarshal transaction", "err", err, "req_id", GetReqID(ctx))
-		return ErrInvalidParams(err.Error())
+// sendRawTransaction applies the same checks to an eth_sendRawTransaction call as handleBatchRPC,
+// and broadcasts it or sends it on with forward.
+func (s *Server) sendRawTransaction(ctx context.Context, req *RPCReq, forward func(context.Context, []*RPCReq, bool) ([]*RPCRes, error)) *RPCRes {
+	var rawTx *rawTransaction
+	if s.senderLim != nil || s.txPolicy != nil || s.logAcceptedTxs {
+		var err error
+		if rawTx, err = s.checkTransaction(ctx, req); err != nil {
+			RecordRPCError(ctx, BackendProxyd, req.Method, err)
+			return NewRPCErrorRes(req.ID, err)
+		}
 	}
 
-	// Convert the transaction into a Message object so that we can get the
-	// sender. This method performs an ecrecover, which can be expensive.
-	msg, err := core.TransactionToMessage(tx, types.LatestSignerForChainID(tx.ChainId()), nil)
-	if err != nil {
-		log.Debug("could not get message from transaction", "err", err, "req_id", GetReqID(ctx))
-		return ErrInvalidParams(err.Error())
+	var res *RPCRes
+	if len(s.txBroadcastBackends) > 0 {
+		res = broadcastTransaction(ctx, s.txBroadcastBackends, req)
+	} else {
+		out, err := forward(ctx, []*RPCReq{req}, false)
+		if err != nil {
+			RecordRPCError(ctx, BackendProxyd, req.Method, err)
+			return NewRPCErrorRes(req.ID, err)
+		}
+		res = out[0]
 	}
-	ok, err := s.senderLim.Take(ctx, fmt.Sprintf("%s:%d", msg.From.Hex(), tx.Nonce()))
+
+	if rawTx != nil && !res.IsError() {
+		RecordTxAccepted()
+		if s.logAcceptedTxs {
+			logAcceptedTransaction(ctx, rawTx)
+		}
+	}
+	return res
+}
+
+func (s *Server) rateLimitSender(ctx context.Context, rawTx *rawTransaction) error {
+	ok, err := s.senderLim.Take(ctx, fmt.Sprintf("%s:%d", rawTx.from.Hex(), rawTx.tx.Nonce()))
 	if err != nil {
 		log.Error("error taking from sender limiter", "err", err, "req_id", GetReqID(ctx))
 		return ErrInternal
 	}
 	if !ok {
-		log.Debug("sender rate limit exceeded", "sender", msg.From.Hex(), "req_id", GetReqID(ctx))
+		log.Debug("sender rate limit exceeded", "sender", rawTx.from.Hex(), "req_id", GetReqID(ctx))
 		return ErrOverSenderRateLimit
 	}
 
@@ -0,0 +1,167 @@
+package proxyd
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// maxInFlightShadowCalls bounds the number of concurrent calls to a shadow group.
+	// Calls are not mirrored while the shadow group is at capacity.
+	maxInFlightShadowCalls = 100
+	// shadowMismatchLogInterval is the minimum time between two logged mismatches of a group.
+	shadowMismatchLogInterval = time.Second
+	// maxShadowMismatchLogLen is the maximum length of each logged response.
+	maxShadowMismatchLogLen = 1000
+)
+
+// ShadowGroup mirrors a sample of the read calls of a backend group to another backend group,
+// e.g. one running a new client version, and compares the results with the primary responses.
+// Shadow responses are only compared, and never returned to clients.
+type ShadowGroup struct {
+	Group      *BackendGroup
+	sampleRate float64
+	inFlight   chan struct{}
+
+	logMtx  sync.Mutex
+	lastLog time.Time
+}
+
+func NewShadowGroup(group *BackendGroup, sampleRate float64) *ShadowGroup {
+	return &ShadowGroup{
+		Group:      group,
+		sampleRate: sampleRate,
+		inFlight:   make(chan struct{}, maxInFlightShadowCalls),
+	}
+}
+
+// shadowReadMethods are the read methods, besides the methods that are cacheable by block number,
+// that are mirrored to shadow groups. All other methods are not mirrored, since they may have side
+// effects on the backends, e.g. sending transactions or installing filters.
+var shadowReadMethods = map[string]bool{
+	"eth_chainId":                           true,
+	"net_version":                           true,
+	"eth_blockNumber":                       true,
+	"eth_gasPrice":                          true,
+	"eth_maxPriorityFeePerGas":              true,
+	"eth_feeHistory":                        true,
+	"eth_estimateGas":                       true,
+	"eth_getBlockByHash":                    true,
+	"eth_getBlockTransactionCountByHash":    true,
+	"eth_getTransactionByHash":              true,
+	"eth_getTransactionByBlockHashAndIndex": true,
+	"eth_getTransactionReceipt":             true,
+	"eth_getBlockReceipts":                  true,
+	"eth_getUncleCountByBlockHash":          true,
+	"eth_getUncleCountByBlockNumber":        true,
+	"eth_getUncleByBlockHashAndIndex":       true,
+	"eth_getUncleByBlockNumberAndIndex":     true,
+	"eth_getProof":                          true,
+	"eth_getLogs":                           true,
+	"debug_getRawReceipts":                  true,
+}
+
+// isShadowReadMethod returns whether the method only reads state, so that it may be mirrored.
+func isShadowReadMethod(method string) bool {
+	if _, ok := blockNumberParamPositions[method]; ok {
+		return true
+	}
+	return shadowReadMethods[method]
+}
+
+// Mirror sends a sample of the calls to the shadow group in the background,
+// and compares the results with the responses of the primary group.
+func (s *ShadowGroup) Mirror(ctx context.Context, primary string, reqs []*RPCReq, res []*RPCRes, isBatch bool) {
+	for _, req := range reqs {
+		if !isShadowReadMethod(req.Method) {
+			return
+		}
+	}
+	if rand.Float64() >= s.sampleRate {
+		return
+	}
+
+	select {
+	case s.inFlight <- struct{}{}:
+	default:
+		RecordShadowDropped(primary)
+		return
+	}
+
+	// The primary group may still use the requests, while the shadow group rewrites them.
+	shadowReqs := make([]*RPCReq, len(reqs))
+	for i, req := range reqs {
+		r := *req
+		shadowReqs[i] = &r
+	}
+
+	go func() {
+		defer func() { <-s.inFlight }()
+		// The call must not be aborted once the client got the primary response.
+		shadowRes, err := s.Group.Forward(detachedContext{ctx}, shadowReqs, isBatch)
+		if err != nil {
+			RecordShadowError(primary)
+			log.Debug("error forwarding to shadow group", "backend_group", primary, "shadow_group", s.Group.Name, "err", err, "req_id", GetReqID(ctx))
+			return
+		}
+		s.compare(ctx, primary, shadowReqs, res, shadowRes)
+	}()
+}
+
+func (s *ShadowGroup) compare(ctx context.Context, primary string, reqs []*RPCReq, res []*RPCRes, shadowRes []*RPCRes) {
+	for i, req := range reqs {
+		var shadow *RPCRes
+		if len(shadowRes) == len(res) {
+			shadow = shadowRes[i]
+		}
+		match := shadow != nil && shadowResponsesMatch(res[i], shadow)
+		RecordShadowCall(primary, req.Method, match)
+		if !match {
+			s.logMismatch(ctx, primary, req, res[i], shadow)
+		}
+	}
+}
+
+func (s *ShadowGroup) logMismatch(ctx context.Context, primary string, req *RPCReq, res, shadow *RPCRes) {
+	s.logMtx.Lock()
+	if time.Since(s.lastLog) < shadowMismatchLogInterval {
+		s.logMtx.Unlock()
+		return
+	}
+	s.lastLog = time.Now()
+	s.logMtx.Unlock()
+
+	log.Warn(
+		"shadow response mismatch",
+		"backend_group", primary,
+		"shadow_group", s.Group.Name,
+		"method", req.Method,
+		"params", truncate(string(req.Params), maxShadowMismatchLogLen),
+		"response", truncate(string(mustMarshalJSON(res)), maxShadowMismatchLogLen),
+		"shadow_response", truncate(string(mustMarshalJSON(shadow)), maxShadowMismatchLogLen),
+		"req_id", GetReqID(ctx),
+	)
+}
+
+// shadowResponsesMatch compares the results, or error codes, of two responses.
+// Results are compared as JSON values, so that the order of fields does not matter.
+func shadowResponsesMatch(a, b *RPCRes) bool {
+	if a.IsError() || b.IsError() {
+		return a.IsError() && b.IsError() && a.Error.Code == b.Error.Code
+	}
+	return reflect.DeepEqual(normalizeJSON(a.Result), normalizeJSON(b.Result))
+}
+
+func normalizeJSON(v interface{}) interface{} {
+	var out interface{}
+	if err := json.Unmarshal(mustMarshalJSON(v), &out); err != nil {
+		return v
+	}
+	return out
+}
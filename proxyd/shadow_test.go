@@ -0,0 +1,48 @@
+package proxyd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowResponsesMatch(t *testing.T) {
+	res := func(body string) *RPCRes {
+		var r RPCRes
+		require.NoError(t, json.Unmarshal([]byte(body), &r))
+		return &r
+	}
+
+	tests := []struct {
+		name  string
+		a, b  string
+		match bool
+	}{
+		{"equal", `{"jsonrpc":"2.0","id":1,"result":"0x1"}`, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`, true},
+		{"field order", `{"jsonrpc":"2.0","id":1,"result":{"a":1,"b":[1,2]}}`, `{"jsonrpc":"2.0","id":1,"result":{"b":[1,2],"a":1}}`, true},
+		{"different result", `{"jsonrpc":"2.0","id":1,"result":{"a":1}}`, `{"jsonrpc":"2.0","id":1,"result":{"a":2}}`, false},
+		{"extra field", `{"jsonrpc":"2.0","id":1,"result":{"a":1}}`, `{"jsonrpc":"2.0","id":1,"result":{"a":1,"b":2}}`, false},
+		{"array order", `{"jsonrpc":"2.0","id":1,"result":[1,2]}`, `{"jsonrpc":"2.0","id":1,"result":[2,1]}`, false},
+		{"null result", `{"jsonrpc":"2.0","id":1,"result":null}`, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`, false},
+		{"same error code", `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"a"}}`, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"b"}}`, true},
+		{"different error code", `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"a"}}`, `{"jsonrpc":"2.0","id":1,"error":{"code":-32001,"message":"a"}}`, false},
+		{"error and result", `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"a"}}`, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.match, shadowResponsesMatch(res(tt.a), res(tt.b)))
+			require.Equal(t, tt.match, shadowResponsesMatch(res(tt.b), res(tt.a)))
+		})
+	}
+}
+
+func TestShadowReadMethods(t *testing.T) {
+	for _, method := range []string{"eth_chainId", "eth_getBlockByNumber", "eth_call", "eth_getLogs", "eth_getTransactionReceipt"} {
+		require.True(t, isShadowReadMethod(method), method)
+	}
+	// writes, stateful filters and unknown methods are never mirrored
+	for _, method := range []string{"eth_sendRawTransaction", "eth_sendTransaction", "eth_sign", "eth_newFilter", "eth_uninstallFilter", "personal_unlockAccount", "admin_addPeer", "eth_foo"} {
+		require.False(t, isShadowReadMethod(method), method)
+	}
+}
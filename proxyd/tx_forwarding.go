@@ -0,0 +1,195 @@
+package proxyd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Steps of the eth_sendRawTransaction pipeline, used as metric labels.
+const (
+	TxStepDecode          = "decode"
+	TxStepSize            = "size"
+	TxStepChainID         = "chain_id"
+	TxStepMinTip          = "min_tip"
+	TxStepDenySender      = "deny_sender"
+	TxStepDenyRecipient   = "deny_recipient"
+	TxStepSenderRateLimit = "sender_rate_limit"
+)
+
+// rawTransaction is a decoded eth_sendRawTransaction call.
+type rawTransaction struct {
+	tx   *types.Transaction
+	from common.Address
+	size int
+}
+
+// decodeRawTransaction decodes the transaction of an eth_sendRawTransaction call and recovers its sender.
+func decodeRawTransaction(ctx context.Context, req *RPCReq) (*rawTransaction, error) {
+	var params []string
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		log.Debug("error unmarshaling raw transaction params", "err", err, "req_Id", GetReqID(ctx))
+		return nil, ErrParseErr
+	}
+
+	if len(params) != 1 {
+		log.Debug("raw transaction request has invalid number of params", "req_id", GetReqID(ctx))
+		// The error below is identical to the one Geth responds with.
+		return nil, ErrInvalidParams("missing value for required argument 0")
+	}
+
+	var data hexutil.Bytes
+	if err := data.UnmarshalText([]byte(params[0])); err != nil {
+		log.Debug("error decoding raw tx data", "err", err, "req_id", GetReqID(ctx))
+		// Geth returns the raw error from UnmarshalText.
+		return nil, ErrInvalidParams(err.Error())
+	}
+
+	// Inflates a types.Transaction object from the transaction's raw bytes.
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		log.Debug("could not unmarshal transaction", "err", err, "req_id", GetReqID(ctx))
+		return nil, ErrInvalidParams(err.Error())
+	}
+
+	// Convert the transaction into a Message object so that we can get the
+	// sender. This method performs an ecrecover, which can be expensive.
+	msg, err := core.TransactionToMessage(tx, types.LatestSignerForChainID(tx.ChainId()), nil)
+	if err != nil {
+		log.Debug("could not get message from transaction", "err", err, "req_id", GetReqID(ctx))
+		return nil, ErrInvalidParams(err.Error())
+	}
+	return &rawTransaction{tx: tx, from: msg.From, size: len(data)}, nil
+}
+
+// TxPolicy holds the checks that eth_sendRawTransaction calls must pass before they are forwarded.
+// Checks are disabled when they are not configured.
+type TxPolicy struct {
+	maxSize        int
+	chainID        *big.Int
+	minTip         *big.Int
+	denySenders    map[common.Address]bool
+	denyRecipients map[common.Address]bool
+}
+
+// NewTxPolicy returns the policy of the config, or nil if it does not configure any check.
+// It returns an error if a deny list contains an invalid address.
+func NewTxPolicy(cfg *TxForwardingConfig) (*TxPolicy, error) {
+	if cfg.MaxSizeBytes == 0 && cfg.ChainID == 0 && cfg.MinTipWei == 0 &&
+		len(cfg.DenySenders) == 0 && len(cfg.DenyRecipients) == 0 {
+		return nil, nil
+	}
+	p := &TxPolicy{
+		maxSize:        cfg.MaxSizeBytes,
+		denySenders:    make(map[common.Address]bool),
+		denyRecipients: make(map[common.Address]bool),
+	}
+	if cfg.ChainID != 0 {
+		p.chainID = new(big.Int).SetUint64(cfg.ChainID)
+	}
+	if cfg.MinTipWei != 0 {
+		p.minTip = new(big.Int).SetUint64(cfg.MinTipWei)
+	}
+	for _, addr := range cfg.DenySenders {
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("invalid address %s in tx_forwarding deny_senders", addr)
+		}
+		p.denySenders[common.HexToAddress(addr)] = true
+	}
+	for _, addr := range cfg.DenyRecipients {
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("invalid address %s in tx_forwarding deny_recipients", addr)
+		}
+		p.denyRecipients[common.HexToAddress(addr)] = true
+	}
+	return p, nil
+}
+
+// Check returns the step and error of the first check that the transaction fails, if any.
+func (p *TxPolicy) Check(rawTx *rawTransaction) (string, error) {
+	tx := rawTx.tx
+	if p.maxSize > 0 && rawTx.size > p.maxSize {
+		return TxStepSize, ErrTxRejected("transaction is too large")
+	}
+	// Legacy transactions without replay protection have no chain ID, and are rejected as well.
+	if p.chainID != nil && tx.ChainId().Cmp(p.chainID) != 0 {
+		return TxStepChainID, ErrTxRejected("invalid chain id")
+	}
+	if p.minTip != nil && tx.GasTipCapIntCmp(p.minTip) < 0 {
+		return TxStepMinTip, ErrTxRejected("transaction tip is below the minimum")
+	}
+	if p.denySenders[rawTx.from] {
+		return TxStepDenySender, ErrTxRejected("sender is not allowed")
+	}
+	if to := tx.To(); to != nil && p.denyRecipients[*to] {
+		return TxStepDenyRecipient, ErrTxRejected("recipient is not allowed")
+	}
+	return "", nil
+}
+
+// TxHandler serves an eth_sendRawTransaction call of a websocket client with the send pipeline
+// of HTTP calls. forward sends the call on if it is not broadcast.
+type TxHandler func(ctx context.Context, req *RPCReq, forward func(context.Context, []*RPCReq, bool) ([]*RPCRes, error)) *RPCRes
+
+type broadcastResult struct {
+	backend *Backend
+	res     *RPCRes
+	err     error
+	success bool
+}
+
+// broadcastTransaction sends the transaction to all backends in parallel, and returns
+// the first successful response. If all backends fail, the response of the first backend
+// is returned. The transaction is sent to all backends even after the first success.
+func broadcastTransaction(ctx context.Context, backends []*Backend, req *RPCReq) *RPCRes {
+	// The call must not be aborted once the client got its response.
+	bctx := detachedContext{ctx}
+	resC := make(chan broadcastResult, len(backends))
+	for _, be := range backends {
+		go func(be *Backend) {
+			res, err := be.Forward(bctx, []*RPCReq{req}, false)
+			r := broadcastResult{backend: be, err: err}
+			if err == nil {
+				r.res = res[0]
+			}
+			r.success = err == nil && !r.res.IsError()
+			RecordTxBroadcast(be.Name, r.success)
+			resC <- r
+		}(be)
+	}
+
+	results := make(map[*Backend]broadcastResult, len(backends))
+	for range backends {
+		r := <-resC
+		if r.success {
+			return r.res
+		}
+		log.Debug("error broadcasting transaction", "backend", r.backend.Name, "err", r.err, "req_id", GetReqID(ctx))
+		results[r.backend] = r
+	}
+
+	first := results[backends[0]]
+	if first.err != nil {
+		return NewRPCErrorRes(req.ID, first.err)
+	}
+	return first.res
+}
+
+// logAcceptedTransaction logs the hash of a transaction that was accepted by a backend.
+func logAcceptedTransaction(ctx context.Context, rawTx *rawTransaction) {
+	log.Info(
+		"accepted transaction",
+		"tx_hash", rawTx.tx.Hash(),
+		"sender", rawTx.from,
+		"nonce", rawTx.tx.Nonce(),
+		"auth", GetAuthCtx(ctx),
+		"req_id", GetReqID(ctx),
+	)
+}
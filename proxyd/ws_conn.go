@@ -0,0 +1,218 @@
+package proxyd
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gorilla/websocket"
+)
+
+const defaultMaxWSSubscriptionsPerConn = 32
+
+// WSSubscriptionConn serves a websocket client without pinning it to a backend.
+// Subscriptions are served by the SubscriptionHub of the backend group, and all
+// other requests are forwarded to the backend group like HTTP requests, so the
+// connection survives backends failing or leaving the consensus group.
+type WSSubscriptionConn struct {
+	clientConn        *websocket.Conn
+	clientConnMu      sync.Mutex
+	bg                *BackendGroup
+	hub               *SubscriptionHub
+	methodWhitelist   *StringSet
+	notWhitelistedErr *RPCErr
+	maxSubs           int
+	txHandler         TxHandler
+
+	subs map[string]*wsSubscription
+	wg   sync.WaitGroup
+}
+
+func NewWSSubscriptionConn(clientConn *websocket.Conn, bg *BackendGroup, hub *SubscriptionHub, methodWhitelist *StringSet, notWhitelistedErr *RPCErr, maxSubs int, txHandler TxHandler) *WSSubscriptionConn {
+	if maxSubs == 0 {
+		maxSubs = defaultMaxWSSubscriptionsPerConn
+	}
+	return &WSSubscriptionConn{
+		clientConn:        clientConn,
+		bg:                bg,
+		hub:               hub,
+		methodWhitelist:   methodWhitelist,
+		notWhitelistedErr: notWhitelistedErr,
+		maxSubs:           maxSubs,
+		txHandler:         txHandler,
+		subs:              make(map[string]*wsSubscription),
+	}
+}
+
+// Serve handles the messages of the client until the connection is closed.
+func (c *WSSubscriptionConn) Serve(ctx context.Context) error {
+	// The context of the upgrade request is canceled once the upgrade handler returns.
+	ctx, cancel := context.WithCancel(detachedContext{ctx})
+	defer cancel()
+	defer c.close()
+	// The hub is shut down when it is replaced by a config reload,
+	// so the client has to reconnect to get its subscriptions back.
+	go func() {
+		select {
+		case <-c.hub.Done():
+			c.clientConn.Close()
+		case <-ctx.Done():
+		}
+	}()
+	for {
+		msgType, msg, err := c.clientConn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			return err
+		}
+
+		RecordWSMessage(ctx, BackendProxyd, SourceClient)
+		// control messages are handled by the websocket library
+		if msgType != websocket.TextMessage && msgType != websocket.BinaryMessage {
+			continue
+		}
+
+		rpcRequestsTotal.Inc()
+		res := c.handleMsg(ctx, msg)
+		if err := c.writeClientConn(mustMarshalJSON(res)); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *WSSubscriptionConn) handleMsg(ctx context.Context, msg []byte) *RPCRes {
+	req, err := prepareWSClientMsg(ctx, msg, c.methodWhitelist, c.notWhitelistedErr)
+	if err != nil {
+		var id json.RawMessage
+		method := MethodUnknown
+		if req != nil {
+			id = req.ID
+			method = req.Method
+		}
+		log.Info(
+			"error preparing client message",
+			"auth", GetAuthCtx(ctx),
+			"req_id", GetReqID(ctx),
+			"err", err,
+		)
+		RecordRPCError(ctx, BackendProxyd, method, err)
+		return NewRPCErrorRes(id, err)
+	}
+
+	switch req.Method {
+	case "eth_accounts":
+		RecordRPCForward(ctx, BackendProxyd, req.Method, RPCRequestSourceWS)
+		return NewRPCRes(req.ID, emptyArrayResponse)
+	case "eth_subscribe":
+		RecordRPCForward(ctx, BackendProxyd, req.Method, RPCRequestSourceWS)
+		return c.subscribe(ctx, req)
+	case "eth_unsubscribe":
+		RecordRPCForward(ctx, BackendProxyd, req.Method, RPCRequestSourceWS)
+		return c.unsubscribe(req)
+	}
+
+	if req.Method == "eth_sendRawTransaction" && c.txHandler != nil {
+		RecordRPCForward(ctx, c.bg.Name, req.Method, RPCRequestSourceWS)
+		return c.txHandler(ctx, req, c.bg.Forward)
+	}
+
+	res, err := c.bg.Forward(ctx, []*RPCReq{req}, false)
+	if err != nil {
+		if err == ErrNoBackends {
+			RecordUnserviceableRequest(ctx, RPCRequestSourceWS)
+		}
+		RecordRPCError(ctx, BackendProxyd, req.Method, err)
+		return NewRPCErrorRes(req.ID, err)
+	}
+	return res[0]
+}
+
+func (c *WSSubscriptionConn) subscribe(ctx context.Context, req *RPCReq) *RPCRes {
+	var params []json.RawMessage
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params) == 0 {
+		return NewRPCErrorRes(req.ID, ErrInvalidParams("missing subscription type"))
+	}
+	var kind string
+	if err := json.Unmarshal(params[0], &kind); err != nil {
+		return NewRPCErrorRes(req.ID, ErrInvalidParams("invalid subscription type"))
+	}
+
+	var filter *logFilter
+	switch kind {
+	case SubscriptionNewHeads:
+	case SubscriptionLogs:
+		var raw json.RawMessage
+		if len(params) > 1 {
+			raw = params[1]
+		}
+		var err error
+		if filter, err = parseLogFilter(raw); err != nil {
+			return NewRPCErrorRes(req.ID, ErrInvalidParams(err.Error()))
+		}
+	default:
+		return NewRPCErrorRes(req.ID, ErrInvalidParams("unsupported subscription type: "+kind))
+	}
+
+	if len(c.subs) >= c.maxSubs {
+		return NewRPCErrorRes(req.ID, ErrInvalidParams("too many subscriptions"))
+	}
+
+	sub := c.hub.Subscribe(kind, filter)
+	c.subs[sub.ID] = sub
+	log.Debug("added ws subscription", "id", sub.ID, "type", kind, "auth", GetAuthCtx(ctx), "req_id", GetReqID(ctx))
+
+	c.wg.Add(1)
+	go c.notify(ctx, sub)
+	return NewRPCRes(req.ID, sub.ID)
+}
+
+func (c *WSSubscriptionConn) unsubscribe(req *RPCReq) *RPCRes {
+	var params []string
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params) != 1 {
+		return NewRPCErrorRes(req.ID, ErrInvalidParams("expected a subscription id"))
+	}
+	id := params[0]
+	if _, ok := c.subs[id]; !ok {
+		return NewRPCErrorRes(req.ID, ErrInvalidParams("subscription not found"))
+	}
+	delete(c.subs, id)
+	c.hub.Unsubscribe(id)
+	return NewRPCRes(req.ID, true)
+}
+
+// notify sends the notifications of the subscription to the client, until the subscription ends.
+func (c *WSSubscriptionConn) notify(ctx context.Context, sub *wsSubscription) {
+	defer c.wg.Done()
+	for result := range sub.Notifications() {
+		msg := mustMarshalJSON(map[string]interface{}{
+			"jsonrpc": JSONRPCVersion,
+			"method":  "eth_subscription",
+			"params": map[string]interface{}{
+				"subscription": sub.ID,
+				"result":       result,
+			},
+		})
+		RecordWSMessage(ctx, BackendProxyd, SourceBackend)
+		if err := c.writeClientConn(msg); err != nil {
+			log.Debug("error writing ws notification", "id", sub.ID, "err", err, "req_id", GetReqID(ctx))
+		}
+	}
+}
+
+func (c *WSSubscriptionConn) writeClientConn(msg []byte) error {
+	c.clientConnMu.Lock()
+	err := c.clientConn.WriteMessage(websocket.TextMessage, msg)
+	c.clientConnMu.Unlock()
+	return err
+}
+
+func (c *WSSubscriptionConn) close() {
+	for id := range c.subs {
+		c.hub.Unsubscribe(id)
+	}
+	c.wg.Wait()
+	c.clientConn.Close()
+}
@@ -0,0 +1,719 @@
+package proxyd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	SubscriptionNewHeads = "newHeads"
+	SubscriptionLogs     = "logs"
+
+	// wsHubSubBuffer is the number of notifications buffered per client subscription.
+	// Notifications are dropped for subscriptions that do not keep up.
+	wsHubSubBuffer = 128
+	// wsHubMaxCatchUp is the maximum number of heads that are fetched to fill a gap
+	// in the chain of emitted heads. Larger gaps are skipped.
+	wsHubMaxCatchUp = 64
+	// wsHubReconnectDelay is the time to wait before resubscribing upstream after an error.
+	wsHubReconnectDelay = time.Second
+)
+
+// blockBodyFields are the fields of a block that are not part of its header,
+// and are removed from fetched blocks before sending them as newHeads notifications.
+var blockBodyFields = []string{"transactions", "uncles", "withdrawals", "size", "totalDifficulty"}
+
+var errUpstreamLeftConsensus = errors.New("upstream backend left the consensus group")
+
+// wsHead is a block header, as sent in newHeads notifications.
+type wsHead struct {
+	Number     hexutil.Uint64
+	Hash       common.Hash
+	ParentHash common.Hash
+	raw        json.RawMessage
+}
+
+func parseWSHead(raw json.RawMessage) (*wsHead, error) {
+	var h struct {
+		Number     *hexutil.Uint64 `json:"number"`
+		Hash       *common.Hash    `json:"hash"`
+		ParentHash *common.Hash    `json:"parentHash"`
+	}
+	if err := json.Unmarshal(raw, &h); err != nil {
+		return nil, err
+	}
+	if h.Number == nil || h.Hash == nil || h.ParentHash == nil {
+		return nil, errors.New("header is missing number, hash or parent hash")
+	}
+	return &wsHead{Number: *h.Number, Hash: *h.Hash, ParentHash: *h.ParentHash, raw: raw}, nil
+}
+
+// logFilter is the filter of a logs subscription.
+type logFilter struct {
+	Addresses []common.Address
+	Topics    [][]common.Hash
+}
+
+func parseLogFilter(raw json.RawMessage) (*logFilter, error) {
+	if len(raw) == 0 {
+		return &logFilter{}, nil
+	}
+	var f struct {
+		Address json.RawMessage   `json:"address"`
+		Topics  []json.RawMessage `json:"topics"`
+	}
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, err
+	}
+	out := &logFilter{}
+	if len(f.Address) > 0 && string(f.Address) != "null" {
+		var addr common.Address
+		if err := json.Unmarshal(f.Address, &addr); err == nil {
+			out.Addresses = []common.Address{addr}
+		} else if err := json.Unmarshal(f.Address, &out.Addresses); err != nil {
+			return nil, fmt.Errorf("invalid address: %w", err)
+		}
+	}
+	for i, t := range f.Topics {
+		var set []common.Hash
+		if len(t) > 0 && string(t) != "null" {
+			var topic common.Hash
+			if err := json.Unmarshal(t, &topic); err == nil {
+				set = []common.Hash{topic}
+			} else if err := json.Unmarshal(t, &set); err != nil {
+				return nil, fmt.Errorf("invalid topic %d: %w", i, err)
+			}
+		}
+		out.Topics = append(out.Topics, set)
+	}
+	return out, nil
+}
+
+// matches applies the filter the same way as eth_getLogs: any of the addresses,
+// and at each position any of the topics, where an empty position matches all topics.
+func (f *logFilter) matches(address common.Address, topics []common.Hash) bool {
+	if len(f.Addresses) > 0 {
+		found := false
+		for _, addr := range f.Addresses {
+			if addr == address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Topics) > len(topics) {
+		return false
+	}
+	for i, set := range f.Topics {
+		if len(set) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range set {
+			if topic == topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// wsEmittedHead is an emitted head, with the logs that were sent for its block.
+type wsEmittedHead struct {
+	head *wsHead
+	logs []json.RawMessage
+}
+
+// wsSubscription is a subscription of a client, served by the SubscriptionHub.
+type wsSubscription struct {
+	ID     string
+	Kind   string
+	filter *logFilter
+	ch     chan json.RawMessage
+}
+
+// Notifications returns the channel of the results of the subscription notifications.
+// It is closed when the subscription ends.
+func (s *wsSubscription) Notifications() <-chan json.RawMessage {
+	return s.ch
+}
+
+// SubscriptionHub terminates the newHeads and logs subscriptions of websocket clients,
+// and serves all of them from a single upstream newHeads subscription per backend group.
+//
+// The upstream subscription follows the consensus group of consensus aware backend groups.
+// Heads are only sent to clients once they are at or below the consensus latest block,
+// and form a chain: every head is sent once, and gaps, e.g. while switching the upstream
+// backend, are filled in by fetching the missing blocks from the backend group.
+// Logs are fetched for every head, if there are logs subscriptions.
+// If the chain reorgs, the blocks of the new chain are emitted from the common ancestor
+// with the emitted chain on, after the logs of the orphaned blocks are sent again with removed set.
+type SubscriptionHub struct {
+	bg *BackendGroup
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started sync.Once
+
+	mtx  sync.Mutex
+	subs map[string]*wsSubscription
+
+	heads chan *wsHead
+
+	// state of the emitted chain, only accessed by the event loop
+	pending    map[uint64]*wsHead
+	emitted    bool
+	lastNumber uint64
+	lastHash   common.Hash
+	// history holds the recently emitted heads by number, to find the common ancestor on reorgs with
+	history map[uint64]*wsEmittedHead
+	// replacement is the last upstream head at or below the last emitted head, that differs from the emitted head
+	replacement *wsHead
+
+	// latest returns the consensus latest block, which is 0 while it is not known yet,
+	// or false if the backend group is not consensus aware. In that case the heads are
+	// emitted as they are received from the upstream backend.
+	latest    func() (uint64, bool)
+	fetchHead func(ctx context.Context, number uint64) (*wsHead, error)
+	fetchLogs func(ctx context.Context, hash common.Hash) ([]json.RawMessage, error)
+}
+
+func NewSubscriptionHub(bg *BackendGroup) *SubscriptionHub {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &SubscriptionHub{
+		bg:      bg,
+		ctx:     ctx,
+		cancel:  cancel,
+		subs:    make(map[string]*wsSubscription),
+		heads:   make(chan *wsHead, wsHubSubBuffer),
+		pending: make(map[uint64]*wsHead),
+		history: make(map[uint64]*wsEmittedHead),
+	}
+	h.latest = func() (uint64, bool) {
+		// The consensus poller is set up after the servers are started, so it is resolved here.
+		if bg.Consensus == nil {
+			return 0, false
+		}
+		return uint64(bg.Consensus.GetLatestBlockNumber()), true
+	}
+	h.fetchHead = h.fetchHeadFromGroup
+	h.fetchLogs = h.fetchLogsFromGroup
+	return h
+}
+
+// Subscribe adds a subscription of the given kind. The filter is only used for logs subscriptions.
+// The upstream subscription is started with the first subscription.
+func (h *SubscriptionHub) Subscribe(kind string, filter *logFilter) *wsSubscription {
+	h.started.Do(h.start)
+	sub := &wsSubscription{
+		ID:     "0x" + randStr(16),
+		Kind:   kind,
+		filter: filter,
+		ch:     make(chan json.RawMessage, wsHubSubBuffer),
+	}
+	h.mtx.Lock()
+	h.subs[sub.ID] = sub
+	h.mtx.Unlock()
+	activeWSSubscriptionsGauge.WithLabelValues(h.bg.Name, kind).Inc()
+	return sub
+}
+
+// Unsubscribe ends the subscription, and returns false if it did not exist.
+func (h *SubscriptionHub) Unsubscribe(id string) bool {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	sub, ok := h.subs[id]
+	if !ok {
+		return false
+	}
+	delete(h.subs, id)
+	close(sub.ch)
+	activeWSSubscriptionsGauge.WithLabelValues(h.bg.Name, sub.Kind).Dec()
+	return true
+}
+
+func (h *SubscriptionHub) start() {
+	h.wg.Add(2)
+	go h.eventLoop()
+	go h.upstreamLoop()
+}
+
+// Shutdown stops the upstream subscription and ends all subscriptions.
+func (h *SubscriptionHub) Shutdown() {
+	h.cancel()
+	h.wg.Wait()
+	h.mtx.Lock()
+	for id, sub := range h.subs {
+		delete(h.subs, id)
+		close(sub.ch)
+		activeWSSubscriptionsGauge.WithLabelValues(h.bg.Name, sub.Kind).Dec()
+	}
+	h.mtx.Unlock()
+}
+
+// Done is closed when the hub is shut down.
+func (h *SubscriptionHub) Done() <-chan struct{} {
+	return h.ctx.Done()
+}
+
+func (h *SubscriptionHub) eventLoop() {
+	defer h.wg.Done()
+	ticker := time.NewTicker(PollerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case head := <-h.heads:
+			h.onHead(head)
+		case <-ticker.C:
+			// the consensus latest block may have moved on
+		case <-h.ctx.Done():
+			return
+		}
+		h.advance(h.ctx)
+	}
+}
+
+// onHead records a head of the upstream subscription, to be emitted once it is part of the chain.
+func (h *SubscriptionHub) onHead(head *wsHead) {
+	if h.emitted && uint64(head.Number) <= h.lastNumber {
+		// A head at or below the last emitted head that differs from the emitted head at its height
+		// is a reorg, which is emitted by the next advance. Heads older than the history are dropped.
+		if emitted := h.history[uint64(head.Number)]; emitted != nil && emitted.head.Hash != head.Hash {
+			h.replacement = head
+		}
+		return
+	}
+	h.pending[uint64(head.Number)] = head
+	// heads that are too far behind to be emitted are dropped, in case the consensus is stuck
+	delete(h.pending, uint64(head.Number)-wsHubMaxCatchUp)
+}
+
+// advance emits the heads up to the consensus latest block, or up to the highest upstream head
+// if the backend group is not consensus aware.
+func (h *SubscriptionHub) advance(ctx context.Context) {
+	target, ok := h.latest()
+	if h.replacement != nil {
+		h.advanceReplacement(ctx, ok)
+	}
+	if !ok {
+		for number := range h.pending {
+			if number > target {
+				target = number
+			}
+		}
+	}
+	if target == 0 || (h.emitted && target <= h.lastNumber) {
+		return
+	}
+
+	start := h.lastNumber + 1
+	if !h.emitted || target-h.lastNumber > wsHubMaxCatchUp {
+		// only send the latest head to clients when starting or after a long outage
+		start = target
+	}
+	for number := start; number <= target; number++ {
+		head := h.pending[number]
+		if head == nil || (h.emitted && head.ParentHash != h.lastHash) {
+			fetched, err := h.fetchHead(ctx, number)
+			if err != nil {
+				log.Warn("error fetching head in ws subscription hub", "backend_group", h.bg.Name, "number", number, "err", err)
+				break
+			}
+			head = fetched
+			wsHubHeadsTotal.WithLabelValues(h.bg.Name, "fetched").Inc()
+		} else {
+			wsHubHeadsTotal.WithLabelValues(h.bg.Name, "upstream").Inc()
+		}
+		if h.emitted && number == h.lastNumber+1 && head.ParentHash != h.lastHash {
+			log.Warn("reorg below consensus latest block in ws subscription hub",
+				"backend_group", h.bg.Name, "number", number, "parent", head.ParentHash, "last_hash", h.lastHash)
+			if !h.reorg(ctx, head) {
+				break
+			}
+			continue
+		}
+		h.emit(ctx, head)
+	}
+	for number := range h.pending {
+		if number <= h.lastNumber {
+			delete(h.pending, number)
+		}
+	}
+}
+
+// advanceReplacement emits the reorg to the replacement head. With consensus, the replacement
+// head of the upstream backend is only used to detect the reorg: the new block at its height is
+// fetched from the consensus group. Without consensus, the reorg is passed on to clients the way
+// the upstream backend reports it.
+func (h *SubscriptionHub) advanceReplacement(ctx context.Context, consensus bool) {
+	head := h.replacement
+	h.replacement = nil
+	if consensus {
+		fetched, err := h.fetchHead(ctx, uint64(head.Number))
+		if err != nil {
+			log.Warn("error fetching replaced head in ws subscription hub", "backend_group", h.bg.Name, "number", uint64(head.Number), "err", err)
+			return
+		}
+		head = fetched
+	}
+	emitted := h.history[uint64(head.Number)]
+	if emitted == nil || emitted.head.Hash == head.Hash || uint64(head.Number) > h.lastNumber {
+		return
+	}
+	log.Info("upstream reorg in ws subscription hub", "backend_group", h.bg.Name, "number", uint64(head.Number), "hash", head.Hash)
+	h.reorg(ctx, head)
+}
+
+// reorg emits the head, which does not extend the emitted chain. It walks back from the head
+// to the common ancestor with the emitted chain, sends the logs of the orphaned blocks again
+// with removed set, and emits the blocks of the new chain from the common ancestor on.
+// It returns false if the new chain could not be fetched.
+func (h *SubscriptionHub) reorg(ctx context.Context, head *wsHead) bool {
+	chain := []*wsHead{head}
+	var ancestor *wsEmittedHead
+	for {
+		cur := chain[len(chain)-1]
+		prev := h.history[uint64(cur.Number)-1]
+		if cur.Number == 0 || prev == nil || len(chain) > wsHubMaxCatchUp {
+			break
+		}
+		if prev.head.Hash == cur.ParentHash {
+			ancestor = prev
+			break
+		}
+		parent, err := h.fetchHead(ctx, uint64(cur.Number)-1)
+		if err != nil {
+			log.Warn("error fetching head in ws subscription hub", "backend_group", h.bg.Name, "number", uint64(cur.Number)-1, "err", err)
+			return false
+		}
+		if parent.Hash != cur.ParentHash {
+			log.Warn("chain changed while walking back reorg in ws subscription hub",
+				"backend_group", h.bg.Name, "number", uint64(parent.Number), "hash", parent.Hash, "expected", cur.ParentHash)
+			return false
+		}
+		wsHubHeadsTotal.WithLabelValues(h.bg.Name, "fetched").Inc()
+		chain = append(chain, parent)
+	}
+
+	if ancestor == nil {
+		// the common ancestor is older than the history, only send the head like after a long outage
+		log.Warn("reorg deeper than history of ws subscription hub", "backend_group", h.bg.Name, "number", uint64(head.Number), "hash", head.Hash)
+		chain = chain[:1]
+		numbers := make([]uint64, 0, len(h.history))
+		for number := range h.history {
+			numbers = append(numbers, number)
+		}
+		sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+		for _, number := range numbers {
+			h.sendRemovedLogs(h.history[number])
+		}
+		h.history = make(map[uint64]*wsEmittedHead)
+	} else {
+		for number := uint64(ancestor.head.Number) + 1; number <= h.lastNumber; number++ {
+			h.sendRemovedLogs(h.history[number])
+		}
+		h.lastNumber = uint64(ancestor.head.Number)
+		h.lastHash = ancestor.head.Hash
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		h.emit(ctx, chain[i])
+	}
+	return true
+}
+
+// emit sends the head, and the logs of its block, to the subscriptions.
+func (h *SubscriptionHub) emit(ctx context.Context, head *wsHead) {
+	h.emitted = true
+	h.lastNumber = uint64(head.Number)
+	h.lastHash = head.Hash
+	emitted := &wsEmittedHead{head: head}
+	for number := range h.history {
+		// heads above the emitted head were orphaned by a reorg
+		if number >= h.lastNumber || number+wsHubMaxCatchUp < h.lastNumber {
+			delete(h.history, number)
+		}
+	}
+	h.history[h.lastNumber] = emitted
+
+	h.mtx.Lock()
+	hasLogSubs := false
+	for _, sub := range h.subs {
+		switch sub.Kind {
+		case SubscriptionNewHeads:
+			h.send(sub, head.raw)
+		case SubscriptionLogs:
+			hasLogSubs = true
+		}
+	}
+	h.mtx.Unlock()
+	if !hasLogSubs {
+		return
+	}
+
+	logs, err := h.fetchLogs(ctx, head.Hash)
+	if err != nil {
+		log.Warn("error fetching logs in ws subscription hub", "backend_group", h.bg.Name, "hash", head.Hash, "err", err)
+		return
+	}
+	emitted.logs = logs
+	h.sendLogs(logs)
+}
+
+// sendRemovedLogs sends the logs of an orphaned block again, with removed set.
+func (h *SubscriptionHub) sendRemovedLogs(emitted *wsEmittedHead) {
+	if emitted == nil || len(emitted.logs) == 0 {
+		return
+	}
+	removed := make([]json.RawMessage, 0, len(emitted.logs))
+	for _, raw := range emitted.logs {
+		var l map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &l); err != nil {
+			log.Warn("error parsing log in ws subscription hub", "backend_group", h.bg.Name, "err", err)
+			continue
+		}
+		l["removed"] = json.RawMessage("true")
+		removed = append(removed, mustMarshalJSON(l))
+	}
+	h.sendLogs(removed)
+}
+
+// sendLogs sends the logs to the logs subscriptions with a matching filter.
+func (h *SubscriptionHub) sendLogs(logs []json.RawMessage) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	for _, raw := range logs {
+		var l struct {
+			Address common.Address `json:"address"`
+			Topics  []common.Hash  `json:"topics"`
+		}
+		if err := json.Unmarshal(raw, &l); err != nil {
+			log.Warn("error parsing log in ws subscription hub", "backend_group", h.bg.Name, "err", err)
+			continue
+		}
+		for _, sub := range h.subs {
+			if sub.Kind == SubscriptionLogs && sub.filter.matches(l.Address, l.Topics) {
+				h.send(sub, raw)
+			}
+		}
+	}
+}
+
+// send delivers a notification to the subscription, without blocking. Must be called with the lock held.
+func (h *SubscriptionHub) send(sub *wsSubscription, result json.RawMessage) {
+	select {
+	case sub.ch <- result:
+	default:
+		log.Warn("dropping notification for slow ws subscription", "backend_group", h.bg.Name, "id", sub.ID)
+	}
+}
+
+func (h *SubscriptionHub) fetchHeadFromGroup(ctx context.Context, number uint64) (*wsHead, error) {
+	res, err := h.forward(ctx, "eth_getBlockByNumber", hexutil.Uint64(number).String(), false)
+	if err != nil {
+		return nil, err
+	}
+	var block map[string]json.RawMessage
+	if err := json.Unmarshal(res, &block); err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	for _, field := range blockBodyFields {
+		delete(block, field)
+	}
+	return parseWSHead(mustMarshalJSON(block))
+}
+
+func (h *SubscriptionHub) fetchLogsFromGroup(ctx context.Context, hash common.Hash) ([]json.RawMessage, error) {
+	res, err := h.forward(ctx, "eth_getLogs", map[string]common.Hash{"blockHash": hash})
+	if err != nil {
+		return nil, err
+	}
+	var logs []json.RawMessage
+	if err := json.Unmarshal(res, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// forward calls the method on the backend group, which follows the consensus group if it is consensus aware.
+func (h *SubscriptionHub) forward(ctx context.Context, method string, params ...interface{}) (json.RawMessage, error) {
+	req := &RPCReq{
+		JSONRPC: JSONRPCVersion,
+		Method:  method,
+		Params:  mustMarshalJSON(params),
+		ID:      json.RawMessage("1"),
+	}
+	res, err := h.bg.Forward(ctx, []*RPCReq{req}, false)
+	if err != nil {
+		return nil, err
+	}
+	if res[0].IsError() {
+		return nil, res[0].Error
+	}
+	return mustMarshalJSON(res[0].Result), nil
+}
+
+func (h *SubscriptionHub) upstreamLoop() {
+	defer h.wg.Done()
+	for {
+		be := h.pickUpstream()
+		var err error
+		if be == nil {
+			err = ErrNoBackends
+		} else {
+			err = h.followUpstream(be)
+		}
+		if h.ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, errUpstreamLeftConsensus) {
+			log.Info("switching upstream of ws subscription hub", "backend_group", h.bg.Name, "backend", be.Name)
+			continue
+		}
+		log.Warn("upstream subscription of ws subscription hub failed", "backend_group", h.bg.Name, "err", err)
+		select {
+		case <-time.After(wsHubReconnectDelay):
+		case <-h.ctx.Done():
+			return
+		}
+	}
+}
+
+// pickUpstream returns the backend to subscribe to: a member of the consensus group
+// if the backend group is consensus aware, otherwise the first healthy backend.
+func (h *SubscriptionHub) pickUpstream() *Backend {
+	if h.bg.Consensus != nil {
+		backends := h.bg.loadBalancedConsensusGroup()
+		if len(backends) == 0 {
+			return nil
+		}
+		return backends[0]
+	}
+	for _, be := range h.bg.Backends {
+		if be.IsHealthy() {
+			return be
+		}
+	}
+	return nil
+}
+
+func (h *SubscriptionHub) inConsensusGroup(be *Backend) bool {
+	if h.bg.Consensus == nil {
+		return true
+	}
+	for _, member := range h.bg.Consensus.GetConsensusGroup() {
+		if member == be {
+			return true
+		}
+	}
+	return false
+}
+
+// followUpstream subscribes to the new heads of the backend, until the connection fails
+// or the backend leaves the consensus group.
+func (h *SubscriptionHub) followUpstream(be *Backend) error {
+	conn, _, err := be.dialer.Dial(be.wsURL, nil) // nolint:bodyclose
+	if err != nil {
+		return wrapErr(err, "error dialing backend")
+	}
+	defer conn.Close()
+	activeBackendWsConnsGauge.WithLabelValues(be.Name).Inc()
+	defer activeBackendWsConnsGauge.WithLabelValues(be.Name).Dec()
+
+	req := &RPCReq{
+		JSONRPC: JSONRPCVersion,
+		Method:  "eth_subscribe",
+		Params:  mustMarshalJSON([]string{SubscriptionNewHeads}),
+		ID:      json.RawMessage("1"),
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, mustMarshalJSON(req)); err != nil {
+		return err
+	}
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- h.readUpstream(be, conn)
+	}()
+
+	ticker := time.NewTicker(PollerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-errC:
+			return err
+		case <-ticker.C:
+			if !h.inConsensusGroup(be) {
+				return errUpstreamLeftConsensus
+			}
+		case <-h.ctx.Done():
+			return h.ctx.Err()
+		}
+	}
+}
+
+func (h *SubscriptionHub) readUpstream(be *Backend, conn *websocket.Conn) error {
+	var subID string
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		RecordWSMessage(h.ctx, be.Name, SourceBackend)
+
+		var m struct {
+			ID     json.RawMessage `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  *RPCErr         `json:"error"`
+			Params struct {
+				Subscription string          `json:"subscription"`
+				Result       json.RawMessage `json:"result"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(msg, &m); err != nil {
+			return wrapErr(err, "error parsing upstream message")
+		}
+		if m.Error != nil {
+			return m.Error
+		}
+		if subID == "" {
+			if err := json.Unmarshal(m.Result, &subID); err != nil || subID == "" {
+				return errors.New("unexpected response to eth_subscribe")
+			}
+			log.Info("subscribed to upstream new heads", "backend_group", h.bg.Name, "backend", be.Name)
+			continue
+		}
+		if m.Params.Subscription != subID {
+			continue
+		}
+		head, err := parseWSHead(m.Params.Result)
+		if err != nil {
+			log.Warn("invalid upstream head", "backend", be.Name, "err", err)
+			continue
+		}
+		select {
+		case h.heads <- head:
+		case <-h.ctx.Done():
+			return h.ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package proxyd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func testWSHead(n uint64, fork byte) *wsHead {
+	hash := func(n uint64) common.Hash {
+		if n == 0 {
+			return common.Hash{}
+		}
+		return common.Hash{fork, byte(n)}
+	}
+	raw := fmt.Sprintf(`{"number":"0x%x","hash":"%s","parentHash":"%s"}`, n, hash(n), hash(n-1))
+	head, err := parseWSHead(json.RawMessage(raw))
+	if err != nil {
+		panic(err)
+	}
+	return head
+}
+
+func TestSubscriptionHubConsensus(t *testing.T) {
+	hub := NewSubscriptionHub(&BackendGroup{Name: "test"})
+	var latest uint64
+	hub.latest = func() (uint64, bool) { return latest, true }
+	var fetched []uint64
+	hub.fetchHead = func(ctx context.Context, number uint64) (*wsHead, error) {
+		fetched = append(fetched, number)
+		return testWSHead(number, 1), nil
+	}
+	sub := hub.Subscribe(SubscriptionNewHeads, nil)
+	// only test the emission of heads, without upstream subscription
+	hub.cancel()
+	hub.wg.Wait()
+
+	ctx := context.Background()
+	requireHeads := func(numbers ...uint64) {
+		for _, n := range numbers {
+			select {
+			case raw := <-sub.Notifications():
+				head, err := parseWSHead(raw)
+				require.NoError(t, err)
+				require.Equal(t, n, uint64(head.Number))
+			default:
+				t.Fatalf("expected head %d", n)
+			}
+		}
+		require.Empty(t, sub.Notifications())
+	}
+
+	// nothing is emitted until the consensus latest block is known
+	hub.onHead(testWSHead(10, 1))
+	hub.advance(ctx)
+	requireHeads()
+
+	// heads are held back until the consensus reaches them
+	latest = 9
+	hub.onHead(testWSHead(11, 1))
+	hub.advance(ctx)
+	requireHeads(9)
+	require.Equal(t, []uint64{9}, fetched)
+
+	latest = 11
+	hub.advance(ctx)
+	requireHeads(10, 11)
+	require.Equal(t, []uint64{9}, fetched)
+
+	// duplicates are dropped
+	hub.onHead(testWSHead(11, 1))
+	hub.advance(ctx)
+	requireHeads()
+
+	// heads that do not extend the emitted chain are replaced by the consensus block
+	hub.onHead(testWSHead(12, 2))
+	latest = 12
+	hub.advance(ctx)
+	requireHeads(12)
+	require.Equal(t, []uint64{9, 12}, fetched)
+
+	// long gaps are skipped
+	latest = 12 + wsHubMaxCatchUp + 1
+	hub.advance(ctx)
+	requireHeads(latest)
+}
+
+func TestSubscriptionHubReorg(t *testing.T) {
+	// the canonical chain switches to the fork from the fork block on
+	var (
+		forkAt uint64 = math.MaxUint64
+		fork   byte
+	)
+	hash := func(n uint64, fork byte, forkAt uint64) common.Hash {
+		if n >= forkAt {
+			return common.Hash{fork, byte(n)}
+		}
+		return common.Hash{1, byte(n)}
+	}
+	forkHead := func(n uint64, fork byte, forkAt uint64) *wsHead {
+		raw := fmt.Sprintf(`{"number":"0x%x","hash":"%s","parentHash":"%s"}`, n, hash(n, fork, forkAt), hash(n-1, fork, forkAt))
+		head, err := parseWSHead(json.RawMessage(raw))
+		require.NoError(t, err)
+		return head
+	}
+
+	hub := NewSubscriptionHub(&BackendGroup{Name: "test"})
+	var latest uint64
+	hub.latest = func() (uint64, bool) { return latest, true }
+	hub.fetchHead = func(ctx context.Context, number uint64) (*wsHead, error) {
+		return forkHead(number, fork, forkAt), nil
+	}
+	hub.fetchLogs = func(ctx context.Context, hash common.Hash) ([]json.RawMessage, error) {
+		return []json.RawMessage{json.RawMessage(fmt.Sprintf(`{"address":"0x0000000000000000000000000000000000000001","topics":[],"blockHash":"%s","removed":false}`, hash))}, nil
+	}
+	heads := hub.Subscribe(SubscriptionNewHeads, nil)
+	logs := hub.Subscribe(SubscriptionLogs, &logFilter{})
+	hub.cancel()
+	hub.wg.Wait()
+
+	ctx := context.Background()
+	requireHeads := func(hashes ...common.Hash) {
+		for _, hash := range hashes {
+			select {
+			case raw := <-heads.Notifications():
+				head, err := parseWSHead(raw)
+				require.NoError(t, err)
+				require.Equal(t, hash, head.Hash)
+			default:
+				t.Fatalf("expected head %s", hash)
+			}
+		}
+		require.Empty(t, heads.Notifications())
+	}
+	type testLog struct {
+		BlockHash common.Hash `json:"blockHash"`
+		Removed   bool        `json:"removed"`
+	}
+	requireLogs := func(expected ...testLog) {
+		for _, l := range expected {
+			select {
+			case raw := <-logs.Notifications():
+				var got testLog
+				require.NoError(t, json.Unmarshal(raw, &got))
+				require.Equal(t, l, got)
+			default:
+				t.Fatalf("expected log of block %s", l.BlockHash)
+			}
+		}
+		require.Empty(t, logs.Notifications())
+	}
+
+	latest = 10
+	hub.advance(ctx)
+	latest = 12
+	hub.advance(ctx)
+	requireHeads(hash(10, 1, forkAt), hash(11, 1, forkAt), hash(12, 1, forkAt))
+	requireLogs(testLog{hash(10, 1, forkAt), false}, testLog{hash(11, 1, forkAt), false}, testLog{hash(12, 1, forkAt), false})
+
+	// a replacement head on a fork that the consensus group does not follow is ignored
+	hub.onHead(forkHead(12, 3, 12))
+	hub.advance(ctx)
+	requireHeads()
+	requireLogs()
+
+	// a replacement head at the same height, that the consensus group follows, reorgs from the common ancestor on
+	old11, old12 := hash(11, 1, forkAt), hash(12, 1, forkAt)
+	fork, forkAt = 2, 11
+	hub.onHead(forkHead(12, fork, forkAt))
+	hub.advance(ctx)
+	requireHeads(hash(11, fork, forkAt), hash(12, fork, forkAt))
+	requireLogs(testLog{old11, true}, testLog{old12, true}, testLog{hash(11, fork, forkAt), false}, testLog{hash(12, fork, forkAt), false})
+
+	// the new chain is extended
+	latest = 13
+	hub.onHead(forkHead(13, fork, forkAt))
+	hub.advance(ctx)
+	requireHeads(hash(13, fork, forkAt))
+	requireLogs(testLog{hash(13, fork, forkAt), false})
+}
+
+func TestLogFilter(t *testing.T) {
+	addrA := common.Address{0xa}
+	addrB := common.Address{0xb}
+	topicA := common.Hash{0xa}
+	topicB := common.Hash{0xb}
+
+	tests := []struct {
+		name    string
+		filter  string
+		address common.Address
+		topics  []common.Hash
+		match   bool
+	}{
+		{"empty filter", ``, addrA, nil, true},
+		{"single address", fmt.Sprintf(`{"address":"%s"}`, addrA), addrA, nil, true},
+		{"other address", fmt.Sprintf(`{"address":"%s"}`, addrA), addrB, nil, false},
+		{"address list", fmt.Sprintf(`{"address":["%s","%s"]}`, addrA, addrB), addrB, nil, true},
+		{"wildcard topic", fmt.Sprintf(`{"topics":[null,"%s"]}`, topicB), addrA, []common.Hash{topicA, topicB}, true},
+		{"topic list", fmt.Sprintf(`{"topics":[["%s","%s"]]}`, topicA, topicB), addrA, []common.Hash{topicB}, true},
+		{"other topic", fmt.Sprintf(`{"topics":["%s"]}`, topicA), addrA, []common.Hash{topicB}, false},
+		{"too few topics", fmt.Sprintf(`{"topics":[null,"%s"]}`, topicA), addrA, []common.Hash{topicA}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parseLogFilter(json.RawMessage(tt.filter))
+			require.NoError(t, err)
+			require.Equal(t, tt.match, f.matches(tt.address, tt.topics))
+		})
+	}
+}
+
+func TestSubscriptionHubShutdown(t *testing.T) {
+	hub := NewSubscriptionHub(&BackendGroup{Name: "test"})
+	sub := hub.Subscribe(SubscriptionNewHeads, nil)
+	hub.Shutdown()
+
+	_, ok := <-sub.Notifications()
+	require.False(t, ok)
+	require.False(t, hub.Unsubscribe(sub.ID))
+	select {
+	case <-hub.Done():
+	default:
+		t.Fatal("expected hub to be done")
+	}
+}